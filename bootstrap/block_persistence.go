@@ -0,0 +1,41 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package bootstrap
+
+import (
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/kvstore"
+	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+)
+
+type ClosableBlockPersistence interface {
+	adapter.BlockPersistence
+	supervised.GracefulShutdowner
+}
+
+// NewBlockPersistence opens the block persistence backend selected in config
+func NewBlockPersistence(nodeConfig config.NodeConfig, logger log.Logger, metricFactory metric.Factory) (ClosableBlockPersistence, error) {
+	var persistence ClosableBlockPersistence
+	var err error
+	switch nodeConfig.BlockStorageBackend() {
+	case config.BLOCK_STORAGE_BACKEND_FILESYSTEM:
+		persistence, err = filesystem.NewBlockPersistence(nodeConfig, logger, metricFactory)
+	case config.BLOCK_STORAGE_BACKEND_KVSTORE:
+		persistence, err = kvstore.NewBlockPersistence(nodeConfig, logger, metricFactory)
+	default:
+		err = errors.Errorf("unknown block storage backend %q", nodeConfig.BlockStorageBackend())
+	}
+	if err != nil { // the adapters return typed nil pointers on error
+		return nil, err
+	}
+	return persistence, nil
+}
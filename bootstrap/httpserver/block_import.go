@@ -0,0 +1,78 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/blockstream"
+	"github.com/orbs-network/scribe/log"
+	"net/http"
+	"sync/atomic"
+)
+
+// ImportBlocksResponse reports the blocks committed by an import, an import failing midway still commits the blocks preceding the failure
+type ImportBlocksResponse struct {
+	Imported        uint64
+	Skipped         uint64
+	LastBlockHeight uint64
+	Error           string `json:",omitempty"`
+}
+
+func (s *HttpServer) RegisterBlockImporter(target blockstream.ImportTarget) {
+	s.blockImporter = target
+}
+
+// expects a POST with a blocks stream as its body, as written by the transfer-blocks export command
+func (s *HttpServer) importBlocksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusMethodNotAllowed, nil, "blocks import expects a POST request"})
+		return
+	}
+	if s.blockImporter == nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusServiceUnavailable, nil, "blocks import is not available yet"})
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.importInProgress, 0, 1) {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusConflict, nil, "another blocks import is in progress"})
+		return
+	}
+	defer atomic.StoreInt32(&s.importInProgress, 0)
+
+	reader, err := blockstream.NewReader(r.Body, s.config.BlockStorageFileSystemMaxBlockSizeInBytes())
+	if err != nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, log.Error(err), err.Error()})
+		return
+	}
+
+	s.logger.Info("http HttpServer received blocks import")
+	result, err := blockstream.Import(r.Context(), reader, s.config.VirtualChainId(), s.blockImporter, s.logger)
+	if result == nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, log.Error(err), err.Error()})
+		return
+	}
+
+	response := ImportBlocksResponse{
+		Imported:        result.Imported,
+		Skipped:         result.Skipped,
+		LastBlockHeight: uint64(result.LastBlockHeight),
+	}
+	code := http.StatusOK
+	if err != nil {
+		s.logger.Info("blocks import failed", log.Error(err), log.Uint64("imported", result.Imported))
+		response.Error = err.Error()
+		code = http.StatusUnprocessableEntity
+	} else {
+		s.logger.Info("blocks import completed", log.Uint64("imported", result.Imported), log.Uint64("skipped", result.Skipped))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	data, _ := json.MarshalIndent(response, "", "  ")
+	if _, err = w.Write(data); err != nil {
+		s.logger.Info("error writing response", log.Error(err))
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/blockstream"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpServer_ImportBlocks_CommitsBlocks(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			blocks := builders.RandomizedBlockChain(3, rand.NewControlledRand(t))
+			target := &memoryImportTarget{memory.NewBlockPersistence(parent.Logger, metric.NewRegistry())}
+			h.server.RegisterBlockImporter(target)
+
+			rec := h.importBlocks(aBlocksStream(t, h.server.config.VirtualChainId(), blocks))
+
+			require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+			response := &ImportBlocksResponse{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+			require.EqualValues(t, 3, response.Imported)
+			require.EqualValues(t, 3, response.LastBlockHeight)
+			lastHeight, err := target.GetLastBlockHeight()
+			require.NoError(t, err)
+			require.EqualValues(t, 3, lastHeight)
+		})
+	})
+}
+
+func TestHttpServer_ImportBlocks_RejectsMalformedStream(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			h.server.RegisterBlockImporter(&memoryImportTarget{memory.NewBlockPersistence(parent.Logger, metric.NewRegistry())})
+
+			rec := h.importBlocks(bytes.NewReader([]byte("not a blocks stream")))
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	})
+}
+
+func TestHttpServer_ImportBlocks_DisabledByDefault(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			req, _ := http.NewRequest("POST", "/debug/blocks/import", nil)
+			_, pattern := h.server.Router().Handler(req)
+
+			require.Equal(t, "/", pattern, "blocks import should not be registered unless enabled in config")
+		})
+	})
+}
+
+func (h *harness) importBlocks(body io.Reader) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/debug/blocks/import", body)
+	rec := httptest.NewRecorder()
+	h.server.importBlocksHandler(rec, req)
+	return rec
+}
+
+func aBlocksStream(t *testing.T, vcid primitives.VirtualChainId, blocks []*protocol.BlockPairContainer) io.Reader {
+	stream := new(bytes.Buffer)
+	w, err := blockstream.NewWriter(stream, blockstream.Header{VirtualChainId: vcid})
+	require.NoError(t, err)
+	for _, block := range blocks {
+		require.NoError(t, w.WriteBlock(block))
+	}
+	require.NoError(t, w.Close())
+	return stream
+}
+
+// commits every block without validating it
+type memoryImportTarget struct {
+	*memory.InMemoryBlockPersistence
+}
+
+func (m *memoryImportTarget) GetLastCommittedBlockHeight(ctx context.Context, input *services.GetLastCommittedBlockHeightInput) (*services.GetLastCommittedBlockHeightOutput, error) {
+	height, err := m.GetLastBlockHeight()
+	return &services.GetLastCommittedBlockHeightOutput{LastCommittedBlockHeight: height}, err
+}
+
+func (m *memoryImportTarget) ValidateBlockForCommit(ctx context.Context, input *services.ValidateBlockForCommitInput) (*services.ValidateBlockForCommitOutput, error) {
+	return &services.ValidateBlockForCommitOutput{}, nil
+}
+
+func (m *memoryImportTarget) NodeSyncCommitBlock(ctx context.Context, input *services.CommitBlockInput) (*services.CommitBlockOutput, error) {
+	_, _, err := m.WriteNextBlock(input.BlockPair)
+	return &services.CommitBlockOutput{}, err
+}
+
+func (m *memoryImportTarget) UpdateConsensusAlgosAboutLastCommittedBlockInLocalPersistence(ctx context.Context) {
+}
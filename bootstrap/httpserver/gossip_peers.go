@@ -0,0 +1,92 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+// consensus needs two thirds of the committee, the status warns when fewer committee members are reachable
+const minReachableCommitteeFraction = 2.0 / 3
+
+type CommitteeProvider interface {
+	GetCurrentReference(ctx context.Context, input *services.GetCurrentReferenceInput) (*services.GetCurrentReferenceOutput, error)
+	GetCommittee(ctx context.Context, input *services.GetCommitteeInput) (*services.GetCommitteeOutput, error)
+}
+
+// GossipPeersResponse lists the connection to every topology peer, the reachable committee fraction counts this node
+// and the committee members it is connected to
+type GossipPeersResponse struct {
+	Timestamp                  time.Time
+	ReachableCommitteeFraction float64
+	Error                      string `json:",omitempty"`
+	Peers                      []*adapter.PeerConnectivity
+}
+
+func (s *HttpServer) RegisterGossipConnectivity(reporter adapter.ConnectivityReporter, committee CommitteeProvider) {
+	s.gossipConnectivity = reporter
+	s.committee = committee
+}
+
+func (s *HttpServer) getGossipPeers(w http.ResponseWriter, r *http.Request) {
+	if s.gossipConnectivity == nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusServiceUnavailable, nil, "gossip connectivity is not available yet"})
+		return
+	}
+
+	peers := s.gossipConnectivity.PeerConnectivity()
+	response := GossipPeersResponse{
+		Timestamp: time.Now(),
+		Peers:     peers,
+	}
+	if fraction, err := s.reachableCommitteeFraction(r.Context(), peers); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.ReachableCommitteeFraction = fraction
+	}
+
+	data, _ := json.MarshalIndent(response, "", "\t")
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		s.logger.Info("error writing gossip peers response", log.Error(err))
+	}
+}
+
+func (s *HttpServer) reachableCommitteeFraction(ctx context.Context, peers []*adapter.PeerConnectivity) (float64, error) {
+	ref, err := s.committee.GetCurrentReference(ctx, &services.GetCurrentReferenceInput{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed getting the current reference time")
+	}
+	committee, err := s.committee.GetCommittee(ctx, &services.GetCommitteeInput{Reference: ref.CurrentReference})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed getting the current committee")
+	}
+	if len(committee.Members) == 0 {
+		return 0, errors.New("the current committee is empty")
+	}
+
+	reachablePeers := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		reachablePeers[peer.NodeAddress] = peer.IsReachable()
+	}
+
+	reachable := 0
+	for _, member := range committee.Members {
+		if member.Equal(s.config.NodeAddress()) || reachablePeers[hex.EncodeToString(member)] {
+			reachable++
+		}
+	}
+	return float64(reachable) / float64(len(committee.Members)), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpServer_GossipPeers_ReportsPeersAndReachableCommitteeFraction(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			members := aCommittee(4)
+			h.server.RegisterGossipConnectivity(&connectivityStub{peers: []*adapter.PeerConnectivity{
+				aPeer(members[1], adapter.PeerConnected),
+				aPeer(members[2], adapter.PeerDisconnected),
+				aPeer(members[3], adapter.PeerConnected),
+			}}, &committeeStub{members: members})
+
+			rec := h.getGossipPeers()
+
+			require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"), "should have our content type")
+			response := &GossipPeersResponse{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+			require.Empty(t, response.Error)
+			require.Len(t, response.Peers, 3)
+			require.Equal(t, adapter.PeerDisconnected, response.Peers[1].State)
+			require.EqualValues(t, 0.5, response.ReachableCommitteeFraction, "two of the four committee members should be reachable")
+		})
+	})
+}
+
+func TestHttpServer_GossipPeers_Responds503UntilRegistered(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			h.AllowErrorsMatching("gossip connectivity is not available yet")
+
+			rec := h.getGossipPeers()
+
+			require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		})
+	})
+}
+
+func TestHttpServer_GetStatus_WarnsWhenTooFewCommitteeMembersAreReachable(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			h.AllowErrorsMatching("vc status issue")
+			members := aCommittee(3)
+			connectivity := &connectivityStub{peers: []*adapter.PeerConnectivity{
+				aPeer(members[0], adapter.PeerConnected),
+				aPeer(members[1], adapter.PeerConnecting),
+				aPeer(members[2], adapter.PeerDisconnected),
+			}}
+			h.server.RegisterGossipConnectivity(connectivity, &committeeStub{members: members})
+
+			require.Contains(t, h.getStatusMessage(), "Only 33% of the committee is reachable through gossip")
+
+			connectivity.peers[1].State = adapter.PeerConnected
+			require.NotContains(t, h.getStatusMessage(), "reachable through gossip")
+		})
+	})
+}
+
+type connectivityStub struct {
+	peers []*adapter.PeerConnectivity
+}
+
+func (c *connectivityStub) PeerConnectivity() []*adapter.PeerConnectivity {
+	return c.peers
+}
+
+type committeeStub struct {
+	members []primitives.NodeAddress
+}
+
+func (c *committeeStub) GetCurrentReference(ctx context.Context, input *services.GetCurrentReferenceInput) (*services.GetCurrentReferenceOutput, error) {
+	return &services.GetCurrentReferenceOutput{CurrentReference: 1000}, nil
+}
+
+func (c *committeeStub) GetCommittee(ctx context.Context, input *services.GetCommitteeInput) (*services.GetCommitteeOutput, error) {
+	return &services.GetCommitteeOutput{Members: c.members}, nil
+}
+
+func aCommittee(size int) []primitives.NodeAddress {
+	var members []primitives.NodeAddress
+	for i := 0; i < size; i++ {
+		members = append(members, keys.EcdsaSecp256K1KeyPairForTests(i).NodeAddress())
+	}
+	return members
+}
+
+func aPeer(address primitives.NodeAddress, state adapter.PeerConnectionState) *adapter.PeerConnectivity {
+	return &adapter.PeerConnectivity{
+		NodeAddress: hex.EncodeToString(address),
+		Endpoint:    "127.0.0.1:4400",
+		State:       state,
+	}
+}
+
+func (h *harness) getGossipPeers() *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/gossip/peers", nil)
+	rec := httptest.NewRecorder()
+	h.server.getGossipPeers(rec, req)
+	return rec
+}
+
+func (h *harness) getStatusMessage() string {
+	req, _ := http.NewRequest("GET", "/status", nil)
+	rec := httptest.NewRecorder()
+	h.server.getStatus(rec, req)
+	res := make(map[string]interface{})
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	return res["Status"].(string)
+}
@@ -0,0 +1,213 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	membuffers "github.com/orbs-network/membuffers/go"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/publicapi/pendingtransactions"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// all binary fields are hex encoded, SignedTransaction holds the raw membuffer
+type PendingTransactionsResponse struct {
+	RequestStatus       string
+	TotalCount          uint32
+	PendingTransactions []PendingTransactionJson
+}
+
+type PendingTransactionResponse struct {
+	RequestStatus      string
+	PendingTransaction *PendingTransactionJson `json:",omitempty"`
+}
+
+type PendingTransactionJson struct {
+	Txhash             string
+	ContractName       string
+	MethodName         string
+	SignedTransaction  string
+	GatewayNodeAddress string
+	TimeAdded          string
+	AgeMillis          uint64
+}
+
+func (s *HttpServer) listPendingTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	s.listPendingTransactions(w, r, false)
+}
+
+func (s *HttpServer) listPendingTransactionsAsJsonHandler(w http.ResponseWriter, r *http.Request) {
+	s.listPendingTransactions(w, r, true)
+}
+
+func (s *HttpServer) getPendingTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	s.getPendingTransaction(w, r, false)
+}
+
+func (s *HttpServer) getPendingTransactionAsJsonHandler(w http.ResponseWriter, r *http.Request) {
+	s.getPendingTransaction(w, r, true)
+}
+
+// expects optional query parameters offset, limit, a hex encoded signer public key and contract-name
+func (s *HttpServer) listPendingTransactions(w http.ResponseWriter, r *http.Request, asJson bool) {
+	input, e := readListPendingTransactionsInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	s.logger.Info("http HttpServer received list-pending-transactions", log.Uint32("offset", input.Offset), log.Uint32("limit", input.Limit), log.String("contract", string(input.ContractName)))
+	result, err := s.publicApi.ListPendingTransactions(r.Context(), input)
+	if err != nil && result == nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), "list pending transactions failed"})
+		return
+	}
+
+	requestStatus := protocol.RequestStatus(result.ClientResponse.RequestStatus())
+	if asJson {
+		s.writePendingTransactionsJsonResponse(w, requestStatus, toPendingTransactionsResponse(result.ClientResponse), err)
+	} else {
+		s.writePendingTransactionsMembuffResponse(w, requestStatus, result.ClientResponse, err)
+	}
+}
+
+// expects a hex encoded txhash query parameter
+func (s *HttpServer) getPendingTransaction(w http.ResponseWriter, r *http.Request, asJson bool) {
+	param := r.URL.Query().Get("txhash")
+	if param == "" {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, nil, "txhash is missing"})
+		return
+	}
+
+	txHash, err := hex.DecodeString(strings.TrimPrefix(param, "0x"))
+	if err != nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, log.Error(err), "txhash is not a valid hex string"})
+		return
+	}
+
+	s.logger.Info("http HttpServer received get-pending-transaction", log.String("txhash", hex.EncodeToString(txHash)))
+	result, err := s.publicApi.GetPendingTransaction(r.Context(), &publicapi.GetPendingTransactionInput{Txhash: txHash})
+	if err != nil && result == nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), "get pending transaction failed"})
+		return
+	}
+
+	requestStatus := protocol.RequestStatus(result.ClientResponse.RequestStatus())
+	if asJson {
+		s.writePendingTransactionsJsonResponse(w, requestStatus, toPendingTransactionResponse(result.ClientResponse), err)
+	} else {
+		s.writePendingTransactionsMembuffResponse(w, requestStatus, result.ClientResponse, err)
+	}
+}
+
+func readListPendingTransactionsInput(r *http.Request) (*publicapi.ListPendingTransactionsInput, *httpErr) {
+	query := r.URL.Query()
+
+	offset, e := readUint32Param(r, "offset")
+	if e != nil {
+		return nil, e
+	}
+
+	limit, e := readUint32Param(r, "limit")
+	if e != nil {
+		return nil, e
+	}
+
+	signer, err := hex.DecodeString(strings.TrimPrefix(query.Get("signer"), "0x"))
+	if err != nil {
+		return nil, &httpErr{http.StatusBadRequest, log.Error(err), "signer is not a valid hex string"}
+	}
+
+	return &publicapi.ListPendingTransactionsInput{
+		Offset:       offset,
+		Limit:        limit,
+		Signer:       signer,
+		ContractName: primitives.ContractName(query.Get("contract-name")),
+	}, nil
+}
+
+// an absent parameter is zero
+func readUint32Param(r *http.Request, name string) (uint32, *httpErr) {
+	param := r.URL.Query().Get(name)
+	if param == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return 0, &httpErr{http.StatusBadRequest, log.Error(err), name + " is not a valid number"}
+	}
+	return uint32(value), nil
+}
+
+func (s *HttpServer) writePendingTransactionsMembuffResponse(w http.ResponseWriter, requestStatus protocol.RequestStatus, message membuffers.Message, errorForVerbosity error) {
+	w.Header().Set("Content-Type", "application/membuffers")
+	w.Header().Set("X-ORBS-REQUEST-RESULT", requestStatus.String())
+	if errorForVerbosity != nil {
+		w.Header().Set("X-ORBS-ERROR-DETAILS", errorForVerbosity.Error())
+	}
+	w.WriteHeader(translateRequestStatusToHttpCode(requestStatus))
+	_, err := w.Write(message.Raw())
+	if err != nil {
+		s.logger.Info("error writing response", log.Error(err))
+	}
+}
+
+func (s *HttpServer) writePendingTransactionsJsonResponse(w http.ResponseWriter, requestStatus protocol.RequestStatus, response interface{}, errorForVerbosity error) {
+	w.Header().Set("Content-Type", "application/json")
+	if errorForVerbosity != nil {
+		w.Header().Set("X-ORBS-ERROR-DETAILS", errorForVerbosity.Error())
+	}
+	w.WriteHeader(translateRequestStatusToHttpCode(requestStatus))
+	data, _ := json.MarshalIndent(response, "", "  ")
+	_, err := w.Write(data)
+	if err != nil {
+		s.logger.Info("error writing response", log.Error(err))
+	}
+}
+
+func toPendingTransactionsResponse(response *pendingtransactions.ListPendingTransactionsResponse) *PendingTransactionsResponse {
+	out := &PendingTransactionsResponse{
+		RequestStatus:       protocol.RequestStatus(response.RequestStatus()).String(),
+		TotalCount:          response.TotalCount(),
+		PendingTransactions: []PendingTransactionJson{},
+	}
+	for i := response.PendingTransactionsIterator(); i.HasNext(); {
+		out.PendingTransactions = append(out.PendingTransactions, toPendingTransactionJson(i.NextPendingTransactions()))
+	}
+	return out
+}
+
+func toPendingTransactionResponse(response *pendingtransactions.GetPendingTransactionResponse) *PendingTransactionResponse {
+	out := &PendingTransactionResponse{
+		RequestStatus: protocol.RequestStatus(response.RequestStatus()).String(),
+	}
+	if protocol.RequestStatus(response.RequestStatus()) == protocol.REQUEST_STATUS_COMPLETED {
+		ptx := toPendingTransactionJson(response.PendingTransaction())
+		out.PendingTransaction = &ptx
+	}
+	return out
+}
+
+func toPendingTransactionJson(ptx *pendingtransactions.PendingTransaction) PendingTransactionJson {
+	tx := protocol.SignedTransactionReader(ptx.SignedTransaction()).Transaction()
+	return PendingTransactionJson{
+		Txhash:             hex.EncodeToString(ptx.Txhash()),
+		ContractName:       string(tx.ContractName()),
+		MethodName:         string(tx.MethodName()),
+		SignedTransaction:  hex.EncodeToString(ptx.SignedTransaction()),
+		GatewayNodeAddress: hex.EncodeToString(ptx.GatewayNodeAddress()),
+		TimeAdded:          time.Unix(0, int64(ptx.TimeAdded())).UTC().Format(time.RFC3339Nano),
+		AgeMillis:          ptx.AgeMillis(),
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/publicapi/pendingtransactions"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func aListPendingTransactionsOutput() *publicapi.ListPendingTransactionsOutput {
+	tx := builders.TransferTransaction().WithContract("contract").Build()
+	return &publicapi.ListPendingTransactionsOutput{
+		ClientResponse: (&pendingtransactions.ListPendingTransactionsResponseBuilder{
+			RequestStatus: uint32(protocol.REQUEST_STATUS_COMPLETED),
+			TotalCount:    7,
+			PendingTransactions: []*pendingtransactions.PendingTransactionBuilder{
+				{Txhash: []byte{0x01}, SignedTransaction: tx.Raw(), GatewayNodeAddress: []byte{0x02}, AgeMillis: 3},
+			},
+		}).Build(),
+	}
+}
+
+func TestHttpServer_ListPendingTransactions_Membuffers(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			api := h.publicApi
+			output := aListPendingTransactionsOutput()
+			api.When("ListPendingTransactions", mock.Any, mock.Any).Call(func(ctx context.Context, input *publicapi.ListPendingTransactionsInput) (*publicapi.ListPendingTransactionsOutput, error) {
+				require.EqualValues(t, 20, input.Offset)
+				require.EqualValues(t, 10, input.Limit)
+				require.Equal(t, []byte{0xab, 0xcd}, input.Signer)
+				require.EqualValues(t, "contract", input.ContractName)
+				return output, nil
+			}).Times(1)
+
+			rec := h.request(h.server.listPendingTransactionsHandler, "/api/v1/list-pending-transactions?offset=20&limit=10&signer=0xabcd&contract-name=contract")
+
+			require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+			require.Equal(t, "application/membuffers", rec.Header().Get("Content-Type"))
+			require.Equal(t, output.ClientResponse.Raw(), rec.Body.Bytes())
+		})
+	})
+}
+
+func TestHttpServer_ListPendingTransactions_Json(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			api := h.publicApi
+			api.When("ListPendingTransactions", mock.Any, mock.Any).Return(aListPendingTransactionsOutput(), nil).Times(1)
+
+			rec := h.request(h.server.listPendingTransactionsAsJsonHandler, "/api/v1/list-pending-transactions.json")
+
+			require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			response := &PendingTransactionsResponse{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+			require.Equal(t, "REQUEST_STATUS_COMPLETED", response.RequestStatus)
+			require.EqualValues(t, 7, response.TotalCount)
+			require.Len(t, response.PendingTransactions, 1)
+			require.Equal(t, "01", response.PendingTransactions[0].Txhash)
+			require.Equal(t, "02", response.PendingTransactions[0].GatewayNodeAddress)
+			require.Equal(t, "contract", response.PendingTransactions[0].ContractName)
+			require.EqualValues(t, 3, response.PendingTransactions[0].AgeMillis)
+		})
+	})
+}
+
+func TestHttpServer_GetPendingTransaction_NotFound(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			api := h.publicApi
+			api.When("GetPendingTransaction", mock.Any, mock.Any).Return(&publicapi.GetPendingTransactionOutput{
+				ClientResponse: (&pendingtransactions.GetPendingTransactionResponseBuilder{RequestStatus: uint32(protocol.REQUEST_STATUS_NOT_FOUND)}).Build(),
+			}, nil).Times(1)
+
+			rec := h.request(h.server.getPendingTransactionAsJsonHandler, "/api/v1/get-pending-transaction.json?txhash=0x0102")
+
+			require.Equal(t, http.StatusNotFound, rec.Code, "should translate the request status")
+			response := &PendingTransactionResponse{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+			require.Nil(t, response.PendingTransaction)
+		})
+	})
+}
+
+func TestHttpServer_PendingTransactions_BadRequest(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			require.Equal(t, http.StatusBadRequest, h.request(h.server.listPendingTransactionsHandler, "/api/v1/list-pending-transactions?limit=-1").Code, "should fail with a malformed limit")
+			require.Equal(t, http.StatusBadRequest, h.request(h.server.listPendingTransactionsHandler, "/api/v1/list-pending-transactions?signer=xyz").Code, "should fail with a malformed signer")
+			require.Equal(t, http.StatusBadRequest, h.request(h.server.getPendingTransactionHandler, "/api/v1/get-pending-transaction").Code, "should fail without a txhash")
+		})
+	})
+}
+
+func (h *harness) request(handler http.HandlerFunc, url string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
@@ -12,18 +12,21 @@ import (
 	membuffers "github.com/orbs-network/membuffers/go"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/blockstream"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
 	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/client"
-	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/scribe/log"
 )
 
@@ -41,10 +44,16 @@ type HttpServer struct {
 	router     *http.ServeMux
 
 	logger         log.Logger
-	publicApi      services.PublicApi
+	publicApi      publicapi.PublicApi
 	metricRegistry metric.Registry
 	config         config.HttpServerConfig
 
+	blockImporter    blockstream.ImportTarget
+	importInProgress int32
+
+	gossipConnectivity adapter.ConnectivityReporter
+	committee          CommitteeProvider
+
 	port int
 }
 
@@ -120,7 +129,7 @@ func (s *HttpServer) GracefulShutdown(shutdownContext context.Context) {
 
 }
 
-func (s *HttpServer) RegisterPublicApi(publicApi services.PublicApi) {
+func (s *HttpServer) RegisterPublicApi(publicApi publicapi.PublicApi) {
 	s.publicApi = publicApi
 }
 
@@ -170,7 +179,13 @@ func (s *HttpServer) createRouter() *http.ServeMux {
 	s.registerHttpHandler(router, "/api/v1/get-transaction-status", true, s.getTransactionStatusHandler)
 	s.registerHttpHandler(router, "/api/v1/get-transaction-receipt-proof", true, s.getTransactionReceiptProofHandler)
 	s.registerHttpHandler(router, "/api/v1/get-block", true, s.getBlockHandler)
+	s.registerHttpHandler(router, "/api/v1/get-state-proof", true, s.getStateProofHandler)
+	s.registerHttpHandler(router, "/api/v1/list-pending-transactions", true, s.listPendingTransactionsHandler)
+	s.registerHttpHandler(router, "/api/v1/list-pending-transactions.json", true, s.listPendingTransactionsAsJsonHandler)
+	s.registerHttpHandler(router, "/api/v1/get-pending-transaction", true, s.getPendingTransactionHandler)
+	s.registerHttpHandler(router, "/api/v1/get-pending-transaction.json", true, s.getPendingTransactionAsJsonHandler)
 	s.registerHttpHandler(router, "/status", true, s.getStatus)
+	s.registerHttpHandler(router, "/gossip/peers", true, s.getGossipPeers)
 	s.registerHttpHandler(router, "/metrics", true, s.dumpMetricsAsJSON)
 	s.registerHttpHandler(router, "/metrics.json", true, s.dumpMetricsAsJSON)
 	s.registerHttpHandler(router, "/metrics.prometheus", true, s.dumpMetricsAsPrometheus)
@@ -180,6 +195,10 @@ func (s *HttpServer) createRouter() *http.ServeMux {
 
 	router.Handle("/", http.HandlerFunc(wrapHandlerWithCORS(s.Index)))
 
+	if s.config.BlockStorageImportEnabled() {
+		s.registerHttpHandler(router, "/debug/blocks/import", false, s.importBlocksHandler)
+	}
+
 	if s.config.Profiling() {
 		registerPprof(router)
 	}
@@ -199,6 +218,19 @@ func readInput(r *http.Request) ([]byte, *httpErr) {
 	return bytes, nil
 }
 
+// block-height is an optional query parameter, zero means the most recent block
+func readBlockHeightParam(r *http.Request) (primitives.BlockHeight, *httpErr) {
+	param := r.URL.Query().Get("block-height")
+	if param == "" {
+		return 0, nil
+	}
+	height, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, &httpErr{http.StatusBadRequest, log.Error(err), "block-height is not a valid number"}
+	}
+	return primitives.BlockHeight(height), nil
+}
+
 func validate(m membuffers.Message) *httpErr {
 	if !m.IsValid() {
 		return &httpErr{http.StatusBadRequest, log.Stringable("request", m), "http request is not a valid membuffer"}
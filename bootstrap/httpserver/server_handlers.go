@@ -158,8 +158,20 @@ func (s *HttpServer) runQueryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.logger.Info("http HttpServer received run-query", log.Stringable("request", clientRequest))
-	result, err := s.publicApi.RunQuery(r.Context(), &services.RunQueryInput{ClientRequest: clientRequest})
+	blockHeight, e := readBlockHeightParam(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	s.logger.Info("http HttpServer received run-query", log.Stringable("request", clientRequest), log.Uint64("block-height", uint64(blockHeight)))
+	var result *services.RunQueryOutput
+	var err error
+	if blockHeight == 0 {
+		result, err = s.publicApi.RunQuery(r.Context(), &services.RunQueryInput{ClientRequest: clientRequest})
+	} else {
+		result, err = s.publicApi.RunQueryAtBlockHeight(r.Context(), &services.RunQueryInput{ClientRequest: clientRequest}, blockHeight)
+	}
 	if result != nil && result.ClientResponse != nil {
 		s.writeMembuffResponse(w, result.ClientResponse, result.ClientResponse.RequestResult(), err)
 	} else {
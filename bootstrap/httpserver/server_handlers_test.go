@@ -8,11 +8,13 @@ package httpserver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/with"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -108,6 +110,37 @@ func TestHttpServer_RunQuery_Error(t *testing.T) {
 	})
 }
 
+func TestHttpServer_RunQuery_AtBlockHeight(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			api := h.publicApi
+			response := &client.RunQueryResponseBuilder{
+				RequestResult: aCompletedResult(),
+				QueryResult: &protocol.QueryResultBuilder{
+					ExecutionResult: protocol.EXECUTION_RESULT_SUCCESS,
+				},
+			}
+			api.When("RunQueryAtBlockHeight", mock.Any, mock.Any, primitives.BlockHeight(3)).Return(&services.RunQueryOutput{ClientResponse: response.Build()}, nil).Times(1)
+
+			rec := h.runQueryAt("/api/v1/run-query?block-height=3")
+
+			require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+			_, err := api.Verify()
+			require.NoError(t, err)
+		})
+	})
+}
+
+func TestHttpServer_RunQuery_AtBlockHeight_BadRequest(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			rec := h.runQueryAt("/api/v1/run-query?block-height=latest")
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, "should fail with a malformed height")
+		})
+	})
+}
+
 func TestHttpServer_GetTransactionStatus_Basic(t *testing.T) {
 	with.Logging(t, func(parent *with.LoggingHarness) {
 		withServerHarness(parent, func(h *harness) {
@@ -359,9 +392,45 @@ func aCompletedResult() *client.RequestResultBuilder {
 	}
 }
 
+type publicApiMock struct {
+	*services.MockPublicApi
+}
+
+func (m *publicApiMock) ListPendingTransactions(ctx context.Context, input *publicapi.ListPendingTransactionsInput) (*publicapi.ListPendingTransactionsOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.ListPendingTransactionsOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
+func (m *publicApiMock) GetPendingTransaction(ctx context.Context, input *publicapi.GetPendingTransactionInput) (*publicapi.GetPendingTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.GetPendingTransactionOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
+func (m *publicApiMock) RunQueryAtBlockHeight(ctx context.Context, input *services.RunQueryInput, blockHeight primitives.BlockHeight) (*services.RunQueryOutput, error) {
+	ret := m.Called(ctx, input, blockHeight)
+	if out := ret.Get(0); out != nil {
+		return out.(*services.RunQueryOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
+func (m *publicApiMock) GetStateProof(ctx context.Context, input *publicapi.GetStateProofInput) (*publicapi.GetStateProofOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.GetStateProofOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
 type harness struct {
 	*with.LoggingHarness
-	publicApi *services.MockPublicApi
+	publicApi *publicApiMock
 	server    *HttpServer
 }
 
@@ -420,11 +489,15 @@ func (h *harness) sendTransactionAsync(builder *protocol.SignedTransactionBuilde
 }
 
 func (h *harness) runQuery() *httptest.ResponseRecorder {
+	return h.runQueryAt("")
+}
+
+func (h *harness) runQueryAt(url string) *httptest.ResponseRecorder {
 	request := (&client.RunQueryRequestBuilder{
 		SignedQuery: &protocol.SignedQueryBuilder{},
 	}).Build()
 
-	req, _ := http.NewRequest("POST", "", bytes.NewReader(request.Raw()))
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(request.Raw()))
 	rec := httptest.NewRecorder()
 	h.server.runQueryHandler(rec, req)
 	return rec
@@ -468,7 +541,7 @@ func (h *harness) GetRobotsTxtThroughHTTP() (*http.Response, error) {
 }
 
 func withUnregisteredPublicApiServerHarness(parent *with.LoggingHarness, f func(h *harness)) {
-	papiMock := &services.MockPublicApi{}
+	papiMock := &publicApiMock{&services.MockPublicApi{}}
 	h := &harness{
 		LoggingHarness: parent,
 		publicApi:      papiMock,
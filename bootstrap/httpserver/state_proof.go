@@ -0,0 +1,118 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/scribe/log"
+	"net/http"
+	"strings"
+)
+
+// all binary fields are hex encoded, ResultsBlockHeader and ResultsBlockProof hold the raw membuffers
+type StateProofResponse struct {
+	RequestStatus       string
+	BlockHeight         uint64
+	ContractName        string
+	Key                 string
+	Value               string
+	StateMerkleRootHash string
+	Proof               StateProofJson
+	ResultsBlockHeader  string
+	ResultsBlockProof   string
+}
+
+type StateProofJson struct {
+	Nodes          []StateProofNodeJson
+	Path           string
+	ExtraHashLeft  string
+	ExtraHashRight string `json:",omitempty"`
+}
+
+type StateProofNodeJson struct {
+	OtherChildHash string
+	PrefixSize     int
+}
+
+// expects query parameters block-height (optional, defaults to the most recent provable height), contract-name and a hex encoded key
+func (s *HttpServer) getStateProofHandler(w http.ResponseWriter, r *http.Request) {
+	input, e := readStateProofInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	s.logger.Info("http HttpServer received get-state-proof", log.Uint64("block-height", uint64(input.BlockHeight)), log.String("contract", string(input.ContractName)))
+	result, err := s.publicApi.GetStateProof(r.Context(), input)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if result != nil {
+			code = translateRequestStatusToHttpCode(result.RequestStatus)
+		}
+		s.writeErrorResponseAndLog(w, &httpErr{code, log.Error(err), err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	data, _ := json.MarshalIndent(toStateProofResponse(input, result), "", "  ")
+	_, err = w.Write(data)
+	if err != nil {
+		s.logger.Info("error writing response", log.Error(err))
+	}
+}
+
+func readStateProofInput(r *http.Request) (*publicapi.GetStateProofInput, *httpErr) {
+	height, e := readBlockHeightParam(r)
+	if e != nil {
+		return nil, e
+	}
+
+	query := r.URL.Query()
+
+	contract := query.Get("contract-name")
+	if contract == "" {
+		return nil, &httpErr{http.StatusBadRequest, nil, "contract-name is missing"}
+	}
+
+	key, err := hex.DecodeString(strings.TrimPrefix(query.Get("key"), "0x"))
+	if err != nil {
+		return nil, &httpErr{http.StatusBadRequest, log.Error(err), "key is not a valid hex string"}
+	}
+
+	return &publicapi.GetStateProofInput{
+		BlockHeight:  height,
+		ContractName: primitives.ContractName(contract),
+		Key:          key,
+	}, nil
+}
+
+func toStateProofResponse(input *publicapi.GetStateProofInput, output *publicapi.GetStateProofOutput) *StateProofResponse {
+	proof := StateProofJson{
+		Nodes:          make([]StateProofNodeJson, 0, len(output.Proof.Nodes)),
+		Path:           hex.EncodeToString(output.Proof.Path),
+		ExtraHashLeft:  hex.EncodeToString(output.Proof.ExtraHashLeft),
+		ExtraHashRight: hex.EncodeToString(output.Proof.ExtraHashRight),
+	}
+	for _, node := range output.Proof.Nodes {
+		proof.Nodes = append(proof.Nodes, StateProofNodeJson{OtherChildHash: hex.EncodeToString(node.OtherChildHash), PrefixSize: node.PrefixSize})
+	}
+
+	return &StateProofResponse{
+		RequestStatus:       output.RequestStatus.String(),
+		BlockHeight:         uint64(output.BlockHeight),
+		ContractName:        string(input.ContractName),
+		Key:                 hex.EncodeToString(input.Key),
+		Value:               hex.EncodeToString(output.Value),
+		StateMerkleRootHash: hex.EncodeToString(output.StateMerkleRootHash),
+		Proof:               proof,
+		ResultsBlockHeader:  hex.EncodeToString(output.ResultsBlockHeader.Raw()),
+		ResultsBlockProof:   hex.EncodeToString(output.ResultsBlockProof.Raw()),
+	}
+}
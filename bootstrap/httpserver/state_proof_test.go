@@ -0,0 +1,86 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpServer_GetStateProof_Basic(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			api := h.publicApi
+			api.When("GetStateProof", mock.Any, mock.Any).Call(func(ctx context.Context, input *publicapi.GetStateProofInput) (*publicapi.GetStateProofOutput, error) {
+				require.EqualValues(t, 7, input.BlockHeight)
+				require.EqualValues(t, "contract", input.ContractName)
+				require.Equal(t, []byte{0xab, 0xcd}, input.Key)
+				return &publicapi.GetStateProofOutput{
+					RequestStatus:       protocol.REQUEST_STATUS_COMPLETED,
+					BlockHeight:         7,
+					Value:               []byte{0x01},
+					StateMerkleRootHash: []byte{0x02},
+					Proof:               &statestorage.StateProof{Nodes: []*statestorage.StateProofNode{{OtherChildHash: []byte{0x03}, PrefixSize: 4}}, Path: []byte{0x05}, ExtraHashLeft: []byte{0x06}},
+					ResultsBlockHeader:  (&protocol.ResultsBlockHeaderBuilder{BlockHeight: 8}).Build(),
+					ResultsBlockProof:   (&protocol.ResultsBlockProofBuilder{}).Build(),
+				}, nil
+			}).Times(1)
+
+			rec := h.getStateProof("/api/v1/get-state-proof?block-height=7&contract-name=contract&key=0xabcd")
+
+			require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			response := &StateProofResponse{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+			require.EqualValues(t, 7, response.BlockHeight)
+			require.Equal(t, "01", response.Value)
+			require.Equal(t, "02", response.StateMerkleRootHash)
+			require.Equal(t, []StateProofNodeJson{{OtherChildHash: "03", PrefixSize: 4}}, response.Proof.Nodes)
+			require.NotEmpty(t, response.ResultsBlockHeader)
+		})
+	})
+}
+
+func TestHttpServer_GetStateProof_BadRequest(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			require.Equal(t, http.StatusBadRequest, h.getStateProof("/api/v1/get-state-proof?key=abcd").Code, "should fail without a contract")
+			require.Equal(t, http.StatusBadRequest, h.getStateProof("/api/v1/get-state-proof?contract-name=c&key=xyz").Code, "should fail with a malformed key")
+			require.Equal(t, http.StatusBadRequest, h.getStateProof("/api/v1/get-state-proof?contract-name=c&block-height=-1").Code, "should fail with a malformed height")
+		})
+	})
+}
+
+func TestHttpServer_GetStateProof_Error(t *testing.T) {
+	with.Logging(t, func(parent *with.LoggingHarness) {
+		withServerHarness(parent, func(h *harness) {
+			api := h.publicApi
+			api.When("GetStateProof", mock.Any, mock.Any).Return(&publicapi.GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_NOT_FOUND}, errors.New("too old")).Times(1)
+
+			rec := h.getStateProof("/api/v1/get-state-proof?contract-name=contract&key=ab")
+
+			require.Equal(t, http.StatusNotFound, rec.Code, "should translate the request status")
+		})
+	})
+}
+
+func (h *harness) getStateProof(url string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	rec := httptest.NewRecorder()
+	h.server.getStateProofHandler(rec, req)
+	return rec
+}
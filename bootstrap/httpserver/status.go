@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/orbs-network/scribe/log"
@@ -72,6 +73,12 @@ func (s *HttpServer) getStatusWarningMessage() string {
 		}
 	}
 
+	if s.gossipConnectivity != nil {
+		if fraction, err := s.reachableCommitteeFraction(context.Background(), s.gossipConnectivity.PeerConnectivity()); err == nil && fraction < minReachableCommitteeFraction {
+			status += fmt.Sprintf("Only %.0f%% of the committee is reachable through gossip ;", fraction*100)
+		}
+	}
+
 	if status != "" {
 		s.logger.Error("vc status issue ", log.String("status", status))
 		return status
@@ -20,6 +20,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
 	"github.com/orbs-network/orbs-network-go/services/management"
 	nativeProcessorAdapter "github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
 	stateStorageAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
 	stateStorageMemoryAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
 	txPoolAdapter "github.com/orbs-network/orbs-network-go/services/transactionpool/adapter"
@@ -133,22 +134,7 @@ func (n *Network) CreateAndStartNodes(ctx context.Context, numOfNodesToStart int
 	for _, node := range nodes {
 		wg.Add(1)
 
-		nodeLogger := n.Logger.WithTags(log.Node(node.name))
-		node.nodeLogic = bootstrap.NewNodeLogic(
-			ctx,
-			n.Transport,
-			node.blockPersistence,
-			node.statePersistence,
-			node.stateBlockHeightReporter,
-			node.transactionPoolBlockTracker,
-			n.MaybeClock,
-			node.nativeCompiler,
-			n.Management,
-			nodeLogger,
-			node.metricRegistry,
-			node.config,
-			node.ethereumConnection,
-		)
+		nodeLogger := n.startNode(ctx, node)
 		go func(nx *Node) { // nodes should not block each other from executing wait
 			if err := nx.transactionPoolBlockTracker.WaitForBlock(ctx, 1); err != nil {
 				msg := fmt.Sprintf("node %v did not reach block 1: %s", nx.name, err)
@@ -159,13 +145,40 @@ func (n *Network) CreateAndStartNodes(ctx context.Context, numOfNodesToStart int
 			}
 			wg.Done()
 		}(node)
-
-		n.Supervise(node.nodeLogic)
 	}
 
 	wg.Wait()
 }
 
+// CreateAndStartNodesWithoutWaiting is for networks which may not close blocks on their own, such as a node replaying recorded gossip
+func (n *Network) CreateAndStartNodesWithoutWaiting(ctx context.Context, numOfNodesToStart int) {
+	for _, node := range reverse(n.Nodes[:numOfNodesToStart]) {
+		n.startNode(ctx, node)
+	}
+}
+
+func (n *Network) startNode(ctx context.Context, node *Node) log.Logger {
+	nodeLogger := n.Logger.WithTags(log.Node(node.name))
+	node.nodeLogic = bootstrap.NewNodeLogic(
+		ctx,
+		n.Transport,
+		node.blockPersistence,
+		node.statePersistence,
+		nil, // in memory networks do not run in state archive mode
+		node.stateBlockHeightReporter,
+		node.transactionPoolBlockTracker,
+		n.MaybeClock,
+		node.nativeCompiler,
+		n.Management,
+		nodeLogger,
+		node.metricRegistry,
+		node.config,
+		node.ethereumConnection,
+	)
+	n.Supervise(node.nodeLogic)
+	return nodeLogger
+}
+
 func reverse(nodes []*Node) (reversed []*Node) {
 	for i := len(nodes) - 1; i >= 0; i-- {
 		reversed = append(reversed, nodes[i])
@@ -173,7 +186,7 @@ func reverse(nodes []*Node) (reversed []*Node) {
 	return
 }
 
-func (n *Network) PublicApi(nodeIndex int) services.PublicApi {
+func (n *Network) PublicApi(nodeIndex int) publicapi.PublicApi {
 	return n.Nodes[nodeIndex].nodeLogic.PublicApi()
 }
 
@@ -9,18 +9,22 @@ package bootstrap
 import (
 	"context"
 	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
 	"github.com/orbs-network/govnr"
 	"github.com/orbs-network/orbs-network-go/bootstrap/httpserver"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
-	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
 	ethereumAdapter "github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum/adapter"
+	gossipAdapter "github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/recording"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/tcp"
 	"github.com/orbs-network/orbs-network-go/services/management"
 	managementAdapter "github.com/orbs-network/orbs-network-go/services/management/adapter"
 	nativeProcessorAdapter "github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
-	stateStorageAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
+	stateStorageAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	stateStorageFilesystemAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter/filesystem"
+	stateStorageMemoryAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
 	txPoolAdapter "github.com/orbs-network/orbs-network-go/services/transactionpool/adapter"
 	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
 	"github.com/orbs-network/scribe/log"
@@ -29,12 +33,12 @@ import (
 
 type Node struct {
 	govnr.TreeSupervisor
-	logic            NodeLogic
-	cancelFunc       context.CancelFunc
-	httpServer       *httpserver.HttpServer
-	transport        *tcp.DirectTransport
-	logger           log.Logger
-	blockPersistence *filesystem.BlockPersistence
+	logic       NodeLogic
+	cancelFunc  context.CancelFunc
+	httpServer  *httpserver.HttpServer
+	transport   gossipAdapter.Transport
+	logger      log.Logger
+	persistence []supervised.GracefulShutdowner
 }
 
 func GetMetricRegistry(nodeConfig config.NodeConfig) metric.Registry {
@@ -60,7 +64,22 @@ func NewNode(nodeConfig config.NodeConfig, logger log.Logger) *Node {
 
 	httpServer := httpserver.NewHttpServer(nodeConfig, nodeLogger, metricRegistry)
 
-	transport := tcp.NewDirectTransport(ctx, nodeConfig, nodeLogger, metricRegistry)
+	var gossipSigner signer.Signer
+	if nodeConfig.GossipAuthenticationEnabled() {
+		var err error
+		if gossipSigner, err = signer.New(nodeConfig); err != nil {
+			panic(fmt.Sprintf("failed initializing gossip signer, err=%s", err.Error()))
+		}
+	}
+	directTransport := tcp.NewDirectTransport(ctx, nodeConfig, gossipSigner, nodeLogger, metricRegistry)
+	var transport gossipAdapter.Transport = directTransport
+	if nodeConfig.GossipRecordingFilePath() != "" {
+		recordingTransport, err := recording.NewRecordingTransport(ctx, nodeConfig, transport, nodeLogger, metricRegistry)
+		if err != nil {
+			panic(fmt.Sprintf("failed initializing gossip recording, err=%s", err.Error()))
+		}
+		transport = recordingTransport
+	}
 
 	var managementProvider management.Provider
 	if nodeConfig.ManagementFilePath() == "" {
@@ -71,27 +90,56 @@ func NewNode(nodeConfig config.NodeConfig, logger log.Logger) *Node {
 		managementProvider = managementAdapter.NewFileProvider(nodeConfig)
 	}
 
-	blockPersistence, err := filesystem.NewBlockPersistence(nodeConfig, nodeLogger, metricRegistry)
+	blockPersistence, err := NewBlockPersistence(nodeConfig, nodeLogger, metricRegistry)
 	if err != nil {
 		panic(fmt.Sprintf("failed initializing blocks database, err=%s", err.Error()))
 	}
 
-	statePersistence := stateStorageAdapter.NewStatePersistence(metricRegistry)
+	persistence := []supervised.GracefulShutdowner{blockPersistence}
+
+	var statePersistence stateStorageAdapter.StatePersistence
+	if nodeConfig.StateStorageFileSystemDataDir() == "" {
+		statePersistence = stateStorageMemoryAdapter.NewStatePersistence(metricRegistry)
+	} else {
+		filesystemStatePersistence, err := stateStorageFilesystemAdapter.NewStatePersistence(nodeConfig, nodeLogger, metricRegistry)
+		if err != nil {
+			panic(fmt.Sprintf("failed initializing state database, err=%s", err.Error()))
+		}
+		persistence = append(persistence, filesystemStatePersistence)
+		statePersistence = filesystemStatePersistence
+	}
+
+	// the in memory archive grows with every block and is rebuilt from block storage on boot, nodes running in archive mode should set a data dir
+	var stateArchivePersistence stateStorageAdapter.ArchivePersistence
+	if nodeConfig.StateStorageArchiveMode() {
+		if nodeConfig.StateStorageFileSystemDataDir() == "" {
+			stateArchivePersistence = stateStorageMemoryAdapter.NewArchivePersistence()
+		} else {
+			filesystemArchivePersistence, err := stateStorageFilesystemAdapter.NewArchivePersistence(nodeConfig, nodeLogger)
+			if err != nil {
+				panic(fmt.Sprintf("failed initializing state archive database, err=%s", err.Error()))
+			}
+			persistence = append(persistence, filesystemArchivePersistence)
+			stateArchivePersistence = filesystemArchivePersistence
+		}
+	}
 	ethereumConnection := ethereumAdapter.NewEthereumRpcConnection(nodeConfig, logger, metricRegistry)
 	nativeCompiler := nativeProcessorAdapter.NewNativeCompiler(nodeConfig, nodeLogger, metricRegistry)
 	nodeLogic := NewNodeLogic(ctx,
-		transport, blockPersistence, statePersistence, nil, nil, txPoolAdapter.NewSystemClock(), nativeCompiler, managementProvider,
+		transport, blockPersistence, statePersistence, stateArchivePersistence, nil, nil, txPoolAdapter.NewSystemClock(), nativeCompiler, managementProvider,
 		nodeLogger, metricRegistry, nodeConfig, ethereumConnection)
 
 	httpServer.RegisterPublicApi(nodeLogic.PublicApi())
+	httpServer.RegisterBlockImporter(nodeLogic.BlockImportTarget())
+	httpServer.RegisterGossipConnectivity(directTransport, nodeLogic.Management())
 
 	n := &Node{
-		logger:           nodeLogger,
-		cancelFunc:       ctxCancel,
-		logic:            nodeLogic,
-		transport:        transport,
-		httpServer:       httpServer,
-		blockPersistence: blockPersistence,
+		logger:      nodeLogger,
+		cancelFunc:  ctxCancel,
+		logic:       nodeLogic,
+		transport:   transport,
+		httpServer:  httpServer,
+		persistence: persistence,
 	}
 
 	// TODO re-enable Ethereum access (with refTime based finality)
@@ -107,5 +155,6 @@ func NewNode(nodeConfig config.NodeConfig, logger log.Logger) *Node {
 func (n *Node) GracefulShutdown(shutdownContext context.Context) {
 	n.logger.Info("Shutting down")
 	n.cancelFunc()
-	supervised.ShutdownAllGracefully(shutdownContext, n.httpServer, n.transport, n.blockPersistence)
+	supervised.ShutdownAllGracefully(shutdownContext, n.httpServer, n.transport)
+	supervised.ShutdownAllGracefully(shutdownContext, n.persistence...)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage"
 	blockStorageAdapter "github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/blockstream"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/servicesync"
 	"github.com/orbs-network/orbs-network-go/services/consensusalgo/benchmarkconsensus"
 	"github.com/orbs-network/orbs-network-go/services/consensusalgo/leanhelixconsensus"
@@ -43,19 +44,24 @@ import (
 
 type NodeLogic interface {
 	govnr.ShutdownWaiter
-	PublicApi() services.PublicApi
+	PublicApi() publicapi.PublicApi
+	BlockImportTarget() blockstream.ImportTarget
+	Management() services.Management
 }
 
 type nodeLogic struct {
 	govnr.TreeSupervisor
-	publicApi      services.PublicApi
+	publicApi      publicapi.PublicApi
+	blockStorage   *blockstorage.Service
 	consensusAlgos []services.ConsensusAlgo
+	management     services.Management
 }
 
 func NewNodeLogic(parentCtx context.Context,
 	gossipTransport gossipAdapter.Transport,
 	blockPersistence blockStorageAdapter.BlockPersistence,
 	statePersistence stateStorageAdapter.StatePersistence,
+	stateArchivePersistence stateStorageAdapter.ArchivePersistence,
 	stateBlockHeightReporter stateStorageAdapter.BlockHeightReporter,
 	transactionPoolBlockHeightReporter transactionpool.BlockHeightReporter,
 	maybeClock txPoolAdapter.Clock, nativeCompiler nativeProcessorAdapter.Compiler,
@@ -84,14 +90,14 @@ func NewNodeLogic(parentCtx context.Context,
 		panic(fmt.Sprintf("Node logic signer error cannot start: %s", err))
 	}
 
-	gossipService := gossip.NewGossip(ctx, gossipTransport, nodeConfig, logger, metricRegistry)
+	gossipService := gossip.NewGossip(ctx, gossipTransport, signer, nodeConfig, logger, metricRegistry)
 	management := management.NewManagement(ctx, nodeConfig, managementProvider, gossipService, logger, metricRegistry)
-	stateStorageService := statestorage.NewStateStorage(nodeConfig, statePersistence, stateBlockHeightReporter, logger, metricRegistry)
+	stateStorageService := statestorage.NewStateStorage(nodeConfig, statePersistence, stateArchivePersistence, blockPersistence, stateBlockHeightReporter, logger, metricRegistry)
 	virtualMachineService := virtualmachine.NewVirtualMachine(stateStorageService, processors, crosschainConnectors, management, nodeConfig, logger)
 	transactionPoolService := transactionpool.NewTransactionPool(ctx, maybeClock, gossipService, virtualMachineService, signer, transactionPoolBlockHeightReporter, nodeConfig, logger, metricRegistry)
 	serviceSyncCommitters := []servicesync.BlockPairCommitter{servicesync.NewStateStorageCommitter(stateStorageService), servicesync.NewTxPoolCommitter(transactionPoolService)}
 	blockStorageService := blockstorage.NewBlockStorage(ctx, nodeConfig, blockPersistence, gossipService, logger, metricRegistry, serviceSyncCommitters)
-	publicApiService := publicapi.NewPublicApi(nodeConfig, transactionPoolService, virtualMachineService, blockStorageService, logger, metricRegistry)
+	publicApiService := publicapi.NewPublicApi(nodeConfig, transactionPoolService, virtualMachineService, blockStorageService, stateStorageService, logger, metricRegistry)
 	consensusContextService := consensuscontext.NewConsensusContext(transactionPoolService, virtualMachineService, stateStorageService, management, nodeConfig, logger, metricRegistry)
 
 	consensusAlgo := createConsensusAlgo(nodeConfig)(ctx, gossipService, blockStorageService, consensusContextService, management, signer, logger, metricRegistry)
@@ -100,7 +106,9 @@ func NewNodeLogic(parentCtx context.Context,
 
 	node := &nodeLogic{
 		publicApi:      publicApiService,
+		blockStorage:   blockStorageService,
 		consensusAlgos: []services.ConsensusAlgo{consensusAlgo},
+		management:     management,
 	}
 
 	node.Supervise(management)
@@ -152,6 +160,14 @@ func createConsensusAlgo(nodeConfig config.NodeConfig) func(ctx context.Context,
 	}
 }
 
-func (n *nodeLogic) PublicApi() services.PublicApi {
+func (n *nodeLogic) PublicApi() publicapi.PublicApi {
 	return n.publicApi
 }
+
+func (n *nodeLogic) BlockImportTarget() blockstream.ImportTarget {
+	return n.blockStorage
+}
+
+func (n *nodeLogic) Management() services.Management {
+	return n.management
+}
@@ -0,0 +1,191 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package replaygossip
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/bootstrap/inmemory"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	blockStorageMemoryAdapter "github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/blockstream"
+	ethereumAdapter "github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/recording"
+	managementAdapter "github.com/orbs-network/orbs-network-go/services/management/adapter"
+	nativeProcessorAdapter "github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	stateStorageMemoryAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/synchronization"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+const (
+	exitSuccess = 0
+	exitError   = 1
+)
+
+const usage = `usage:
+  replay-gossip --config path/to/config.json [--blocks path/to/stream] [--speed 1] [--linger 10s] path/to/recording...
+
+replays the gossip messages a node received, as recorded to GOSSIP_RECORDING_FILE_PATH, into the same node running in memory.
+the node starts with the blocks of the stream given, e.g. exported by transfer-blocks up to the start of the recording, and
+recordings rotated from one another should be given oldest first. messages keep the timestamps they were recorded with, so
+replaying an old recording may need a config file raising consensus-context-system-timestamp-allowed-jitter.
+node logs are written to stdout, and a summary of the replay to stderr
+`
+
+// Main replays gossip recordings into an in-memory node built from the configuration files of the recording node
+func Main() {
+	os.Exit(Run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func Run(args []string, out io.Writer, messages io.Writer) int {
+	flags := flag.NewFlagSet("replay-gossip", flag.ContinueOnError)
+	flags.SetOutput(messages)
+	flags.Usage = func() { _, _ = fmt.Fprint(messages, usage) }
+	blocks := flags.String("blocks", "", "path to a blocks stream to start the node with")
+	speed := flags.Float64("speed", 1, "how many times faster than recorded to replay, zero replays as fast as the node takes messages")
+	linger := flags.Duration("linger", 10*time.Second, "time to keep the node running after the last message is replayed")
+
+	var filePaths config.FilesPaths
+	flags.Var(&filePaths, "config", "path/to/config.json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() == 0 {
+		_, _ = fmt.Fprint(messages, usage)
+		return exitError
+	}
+
+	cfg, err := config.GetNodeConfigFromFiles(filePaths, "")
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error reading configuration: %s\n", err)
+		return exitError
+	}
+	if cfg.ManagementFilePath() == "" {
+		_, _ = fmt.Fprintln(messages, "configuration has no management-file-path, the node cannot start without it")
+		return exitError
+	}
+
+	logger := log.GetLogger().WithOutput(log.NewFormattingOutput(out, log.NewHumanReadableFormatter()))
+
+	preloadedBlocks, err := readBlocks(*blocks, cfg)
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error reading blocks: %s\n", err)
+		return exitError
+	}
+
+	var blockPersistence *blockStorageMemoryAdapter.InMemoryBlockPersistence
+	provider := func(idx int, nodeConfig config.NodeConfig, logger log.Logger, metricRegistry metric.Registry) *inmemory.NodeDependencies {
+		blockPersistence = blockStorageMemoryAdapter.NewBlockPersistence(logger, metricRegistry, preloadedBlocks...)
+		return &inmemory.NodeDependencies{
+			BlockPersistence:                   blockPersistence,
+			Compiler:                           nativeProcessorAdapter.NewNativeCompiler(nodeConfig, logger, metricRegistry),
+			EtherConnection:                    &ethereumAdapter.NopEthereumAdapter{},
+			StatePersistence:                   stateStorageMemoryAdapter.NewStatePersistence(metricRegistry),
+			StateBlockHeightReporter:           synchronization.NopHeightReporter{},
+			TransactionPoolBlockHeightReporter: synchronization.NewBlockTracker(logger, 0, math.MaxUint16),
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	transport := recording.NewReplayTransport(logger, *speed)
+	network := inmemory.NewNetworkWithNumOfNodes([]primitives.NodeAddress{cfg.NodeAddress()}, []config.NodeConfig{cfg}, logger, transport, managementAdapter.NewFileProvider(cfg), nil, provider)
+	network.CreateAndStartNodesWithoutWaiting(ctx, 1)
+
+	total := &recording.ReplayResult{}
+	for _, path := range flags.Args() {
+		result, err := replayFile(ctx, transport, path, cfg.VirtualChainId())
+		if result != nil {
+			total.Delivered += result.Delivered
+			total.Skipped += result.Skipped
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(messages, "error replaying %s: %s\n", path, err)
+			return exitError
+		}
+	}
+
+	time.Sleep(*linger)
+
+	lastBlockHeight, err := blockPersistence.GetLastBlockHeight()
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error reading last block height: %s\n", err)
+		return exitError
+	}
+	_, _ = fmt.Fprintf(messages, "replayed %d messages received by the node, skipped %d messages it sent, the replaying node sent %d messages, last block height %d\n",
+		total.Delivered, total.Skipped, transport.Sent(), lastBlockHeight)
+
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	network.WaitUntilShutdown(shutdownCtx)
+	return exitSuccess
+}
+
+func replayFile(ctx context.Context, transport *recording.ReplayTransport, path string, vcid primitives.VirtualChainId) (*recording.ReplayResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r, err := recording.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	if r.Header().VirtualChainId != vcid {
+		return nil, errors.Errorf("gossip recording is of virtual chain %d, expected %d", r.Header().VirtualChainId, vcid)
+	}
+	return transport.Replay(ctx, r)
+}
+
+func readBlocks(path string, cfg config.NodeConfig) ([]*protocol.BlockPairContainer, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r, err := blockstream.NewReader(file, cfg.BlockStorageFileSystemMaxBlockSizeInBytes())
+	if err != nil {
+		return nil, err
+	}
+	if r.Header().VirtualChainId != cfg.VirtualChainId() {
+		return nil, errors.Errorf("blocks stream is of virtual chain %d, expected %d", r.Header().VirtualChainId, cfg.VirtualChainId())
+	}
+
+	var blocks []*protocol.BlockPairContainer
+	for {
+		block, err := r.ReadBlock()
+		if err == io.EOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if expected := primitives.BlockHeight(len(blocks) + 1); block.TransactionsBlock.Header.BlockHeight() != expected {
+			return nil, errors.Errorf("blocks stream holds block %d where block %d was expected, the node must start with all blocks from the first", block.TransactionsBlock.Header.BlockHeight(), expected)
+		}
+		blocks = append(blocks, block)
+	}
+}
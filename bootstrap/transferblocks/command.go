@@ -0,0 +1,178 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transferblocks
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/bootstrap"
+	"github.com/orbs-network/orbs-network-go/bootstrap/httpserver"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/blockstream"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/scribe/log"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	exitSuccess = 0
+	exitError   = 1
+)
+
+const importPath = "/debug/blocks/import"
+
+const usage = `usage:
+  transfer-blocks export --config path/to/config.json [--from height] [--to height] [--output path/to/stream]
+  transfer-blocks import --url http://node:8080 [--input path/to/stream]
+`
+
+// Main exports the blocks of a stopped node to a blocks stream, or imports a blocks stream to a running node.
+// the stream is written to stdout and read from stdin unless a file is given, messages are written to stderr
+func Main() {
+	os.Exit(Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func Run(args []string, in io.Reader, out io.Writer, messages io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(messages, usage)
+		return exitError
+	}
+	switch args[0] {
+	case "export":
+		return runExport(args[1:], out, messages)
+	case "import":
+		return runImport(args[1:], in, messages)
+	default:
+		_, _ = fmt.Fprint(messages, usage)
+		return exitError
+	}
+}
+
+func runExport(args []string, out io.Writer, messages io.Writer) int {
+	flags := flag.NewFlagSet("transfer-blocks export", flag.ContinueOnError)
+	flags.SetOutput(messages)
+	from := flags.Uint64("from", 1, "first block height to export")
+	to := flags.Uint64("to", 0, "last block height to export, zero exports up to the last block")
+	output := flags.String("output", "", "path to write the blocks stream to, defaults to stdout")
+	verbose := flags.Bool("verbose", false, "log block storage messages which are not errors")
+
+	var filePaths config.FilesPaths
+	flags.Var(&filePaths, "config", "path/to/config.json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	cfg, err := config.GetNodeConfigFromFiles(filePaths, "")
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error reading configuration: %s\n", err)
+		return exitError
+	}
+
+	logger := log.GetLogger().WithOutput(log.NewFormattingOutput(messages, log.NewHumanReadableFormatter()))
+	if !*verbose {
+		logger = logger.WithFilters(log.OnlyErrors())
+	}
+
+	persistence, err := bootstrap.NewBlockPersistence(cfg, logger, metric.NewRegistry())
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error opening blocks in %s: %s\n", cfg.BlockStorageFileSystemDataDir(), err)
+		return exitError
+	}
+	defer persistence.GracefulShutdown(context.Background())
+
+	var file *os.File
+	if *output != "" {
+		file, err = os.Create(*output)
+		if err != nil {
+			_, _ = fmt.Fprintf(messages, "error creating %s: %s\n", *output, err)
+			return exitError
+		}
+		out = file
+	}
+
+	w, err := blockstream.NewWriter(out, blockstream.Header{VirtualChainId: cfg.VirtualChainId()})
+	if err == nil {
+		var written uint64
+		written, err = blockstream.Export(persistence, primitives.BlockHeight(*from), primitives.BlockHeight(*to), w)
+		_, _ = fmt.Fprintf(messages, "exported %d blocks\n", written)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	// the file is only complete once it is closed, a failed close means a truncated export
+	if file != nil {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close %s: %s", *output, closeErr)
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error exporting blocks: %s\n", err)
+		return exitError
+	}
+	return exitSuccess
+}
+
+func runImport(args []string, in io.Reader, messages io.Writer) int {
+	flags := flag.NewFlagSet("transfer-blocks import", flag.ContinueOnError)
+	flags.SetOutput(messages)
+	url := flags.String("url", "", "http address of a node with blocks import enabled")
+	input := flags.String("input", "", "path to read the blocks stream from, defaults to stdin")
+	timeout := flags.Duration("timeout", time.Hour, "time to wait for the node to commit the imported blocks")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if *url == "" {
+		_, _ = fmt.Fprintln(messages, "missing --url")
+		return exitError
+	}
+
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			_, _ = fmt.Fprintf(messages, "error opening %s: %s\n", *input, err)
+			return exitError
+		}
+		defer file.Close()
+		in = file
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	res, err := client.Post(strings.TrimSuffix(*url, "/")+importPath, "application/octet-stream", in)
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error sending blocks: %s\n", err)
+		return exitError
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		_, _ = fmt.Fprintf(messages, "error reading response: %s\n", err)
+		return exitError
+	}
+
+	response := &httpserver.ImportBlocksResponse{}
+	if res.Header.Get("Content-Type") != "application/json" || json.Unmarshal(body, response) != nil {
+		_, _ = fmt.Fprintf(messages, "node responded with %s: %s\n", res.Status, body)
+		return exitError
+	}
+	_, _ = fmt.Fprintf(messages, "imported %d blocks, skipped %d blocks already committed, last block height %d\n", response.Imported, response.Skipped, response.LastBlockHeight)
+	if response.Error != "" {
+		_, _ = fmt.Fprintf(messages, "error importing blocks: %s\n", response.Error)
+		return exitError
+	}
+	return exitSuccess
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package verifyblocks
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	ethereumDigest "github.com/orbs-network/crypto-lib-go/crypto/ethereum/digest"
+	lhprotocol "github.com/orbs-network/lean-helix-go/spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// VerifyBlockProof checks the block proofs of a block refer to it and are signed by the nodes they list.
+// whether these nodes formed a quorum of the committee depends on the state of the management contracts, and is not checked offline
+func VerifyBlockProof(block *protocol.BlockPairContainer) error {
+	txProof, rxProof := block.TransactionsBlock.BlockProof, block.ResultsBlock.BlockProof
+	switch {
+	case txProof.IsTypeBenchmarkConsensus() && rxProof.IsTypeBenchmarkConsensus():
+		return verifyBenchmarkConsensusBlockProof(block)
+	case txProof.IsTypeLeanHelix() && rxProof.IsTypeLeanHelix():
+		return verifyLeanHelixBlockProof(block)
+	default:
+		return errors.Errorf("unsupported block proof types: transactions block %v, results block %v", txProof.Type(), rxProof.Type())
+	}
+}
+
+func verifyBenchmarkConsensusBlockProof(block *protocol.BlockPairContainer) error {
+	if err := verifyTransactionsBlockHashPtr(block); err != nil {
+		return err
+	}
+
+	blockProof := block.ResultsBlock.BlockProof.BenchmarkConsensus()
+	blockRef := blockProof.BlockRef()
+	if err := verifyBlockRef(block, blockRef.BlockHeight(), blockRef.BlockHash()); err != nil {
+		return err
+	}
+
+	signers := blockProof.NodesIterator()
+	if !signers.HasNext() {
+		return errors.New("benchmark consensus block proof is not signed")
+	}
+	signer := signers.NextNodes()
+	if err := ethereumDigest.VerifyNodeSignature(signer.SenderNodeAddress(), blockRef.Raw(), signer.Signature()); err != nil {
+		return errors.Wrapf(err, "benchmark consensus block proof signature of %s is invalid", signer.SenderNodeAddress())
+	}
+	return nil
+}
+
+func verifyLeanHelixBlockProof(block *protocol.BlockPairContainer) error {
+	if err := verifyTransactionsBlockHashPtr(block); err != nil {
+		return err
+	}
+	if !block.TransactionsBlock.BlockProof.ResultsBlockHash().Equal(digest.CalcResultsBlockHash(block.ResultsBlock)) {
+		return errors.New("transactions block proof does not point to the results block")
+	}
+
+	rawProof := block.TransactionsBlock.BlockProof.LeanHelix()
+	if len(rawProof) == 0 {
+		return errors.New("lean helix block proof is empty")
+	}
+	if !block.ResultsBlock.BlockProof.LeanHelix().Equal(rawProof) {
+		return errors.New("lean helix block proofs of the transactions and results blocks differ")
+	}
+
+	blockProof := lhprotocol.BlockProofReader(rawProof)
+	blockRef := blockProof.BlockRef()
+	if blockRef.MessageType() != lhprotocol.LEAN_HELIX_COMMIT {
+		return errors.Errorf("lean helix block proof refers to a %s message", blockRef.MessageType())
+	}
+	if err := verifyBlockRef(block, primitives.BlockHeight(blockRef.BlockHeight()), primitives.Sha256(blockRef.BlockHash())); err != nil {
+		return err
+	}
+
+	signers := blockProof.NodesIterator()
+	if !signers.HasNext() {
+		return errors.New("lean helix block proof is not signed")
+	}
+	for signers.HasNext() {
+		signer := signers.NextNodes()
+		if err := ethereumDigest.VerifyNodeSignature(primitives.NodeAddress(signer.MemberId()), blockRef.Raw(), primitives.EcdsaSecp256K1Sig(signer.Signature())); err != nil {
+			return errors.Wrapf(err, "lean helix block proof signature of %s is invalid", signer.MemberId())
+		}
+	}
+	return nil
+}
+
+func verifyTransactionsBlockHashPtr(block *protocol.BlockPairContainer) error {
+	if !block.ResultsBlock.BlockProof.TransactionsBlockHash().Equal(digest.CalcTransactionsBlockHash(block.TransactionsBlock)) {
+		return errors.New("results block proof does not point to the transactions block")
+	}
+	return nil
+}
+
+func verifyBlockRef(block *protocol.BlockPairContainer, height primitives.BlockHeight, blockHash primitives.Sha256) error {
+	if expected := block.TransactionsBlock.Header.BlockHeight(); height != expected {
+		return errors.Errorf("block proof refers to block height %d, expected %d", height, expected)
+	}
+	if !blockHash.Equal(digest.CalcBlockHash(block.TransactionsBlock, block.ResultsBlock)) {
+		return errors.New("block proof refers to a different block hash")
+	}
+	return nil
+}
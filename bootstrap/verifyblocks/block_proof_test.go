@@ -0,0 +1,77 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package verifyblocks
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	lhprimitives "github.com/orbs-network/lean-helix-go/spec/types/go/primitives"
+	lhprotocol "github.com/orbs-network/lean-helix-go/spec/types/go/protocol"
+	"github.com/orbs-network/orbs-network-go/services/consensusalgo/leanhelixconsensus"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVerifyBlockProof_AcceptsBenchmarkConsensusBlockProof(t *testing.T) {
+	block := builders.BenchmarkConsensusBlockPair().WithHeight(3).Build()
+	require.NoError(t, VerifyBlockProof(block))
+}
+
+func TestVerifyBlockProof_RejectsInvalidBenchmarkConsensusSignature(t *testing.T) {
+	block := builders.BlockPair().WithHeight(3).WithInvalidBenchmarkConsensusBlockProof(testKeys.EcdsaSecp256K1KeyPairForTests(0)).Build()
+	require.Error(t, VerifyBlockProof(block))
+}
+
+func TestVerifyBlockProof_AcceptsLeanHelixBlockProof(t *testing.T) {
+	block := blockWithLeanHelixBlockProof(t, 3)
+	require.NoError(t, VerifyBlockProof(block))
+}
+
+func TestVerifyBlockProof_RejectsLeanHelixBlockProofOfAnotherBlock(t *testing.T) {
+	block := blockWithLeanHelixBlockProof(t, 4)
+	require.Error(t, VerifyBlockProof(block))
+}
+
+func TestVerifyBlockProof_RejectsEmptyLeanHelixBlockProof(t *testing.T) {
+	block := builders.BlockPair().WithHeight(3).WithEmptyLeanHelixBlockProof().Build()
+	require.Error(t, VerifyBlockProof(block))
+}
+
+// the block is at height 3, its block proof refers to proofHeight
+func blockWithLeanHelixBlockProof(t *testing.T, proofHeight uint64) *protocol.BlockPairContainer {
+	block := builders.BlockPair().WithHeight(3).WithEmptyLeanHelixBlockProof().Build()
+
+	blockRef := (&lhprotocol.BlockRefBuilder{
+		MessageType: lhprotocol.LEAN_HELIX_COMMIT,
+		BlockHeight: lhprimitives.BlockHeight(proofHeight),
+		View:        1,
+		BlockHash:   lhprimitives.BlockHash(digest.CalcBlockHash(block.TransactionsBlock, block.ResultsBlock)),
+	}).Build()
+
+	var nodes []*lhprotocol.SenderSignatureBuilder
+	for i := 0; i < 3; i++ {
+		keyPair := testKeys.EcdsaSecp256K1KeyPairForTests(i)
+		sig, err := signer.NewLocalSigner(keyPair.PrivateKey()).Sign(context.Background(), blockRef.Raw())
+		require.NoError(t, err)
+		nodes = append(nodes, &lhprotocol.SenderSignatureBuilder{
+			MemberId:  lhprimitives.MemberId(keyPair.NodeAddress()),
+			Signature: lhprimitives.Signature(sig),
+		})
+	}
+	blockProof := (&lhprotocol.BlockProofBuilder{
+		BlockRef: lhprotocol.BlockRefBuilderFromRaw(blockRef.Raw()),
+		Nodes:    nodes,
+	}).Build()
+
+	block.TransactionsBlock.BlockProof = leanhelixconsensus.CreateTransactionBlockProof(block, blockProof.Raw())
+	block.ResultsBlock.BlockProof = leanhelixconsensus.CreateResultsBlockProof(block, blockProof.Raw())
+	return block
+}
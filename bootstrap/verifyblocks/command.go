@@ -0,0 +1,104 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package verifyblocks
+
+import (
+	"flag"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
+	"github.com/orbs-network/scribe/log"
+	"io"
+	"os"
+)
+
+const (
+	exitValid   = 0
+	exitError   = 1
+	exitInvalid = 2 // an invalid block was found and the blocks files were not repaired
+)
+
+// Main verifies the blocks files of a stopped node, reading its configuration files like the node does
+func Main() {
+	os.Exit(Run(os.Args[1:], os.Stdout))
+}
+
+func Run(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("verify-blocks", flag.ContinueOnError)
+	flags.SetOutput(out)
+	repair := flags.Bool("repair", false, "truncate the blocks files to the last valid block, keeping a backup of the removed blocks")
+	skipProofs := flags.Bool("skip-proofs", false, "do not verify block proofs")
+	verbose := flags.Bool("verbose", false, "log block storage messages which are not errors")
+
+	var filePaths config.FilesPaths
+	flags.Var(&filePaths, "config", "path/to/config.json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	cfg, err := config.GetNodeConfigFromFiles(filePaths, "")
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "error reading configuration: %s\n", err)
+		return exitError
+	}
+
+	logger := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stderr, log.NewHumanReadableFormatter()))
+	if !*verbose {
+		logger = logger.WithFilters(log.OnlyErrors())
+	}
+
+	var verifyBlock filesystem.VerifyBlockFunc = VerifyBlockProof
+	if *skipProofs {
+		verifyBlock = nil
+	}
+
+	_, _ = fmt.Fprintf(out, "verifying blocks files in %s\n", cfg.BlockStorageFileSystemDataDir())
+	report, err := filesystem.VerifyBlocksFiles(cfg, logger, verifyBlock)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "error verifying blocks files: %s\n", err)
+		return exitError
+	}
+	printReport(out, report)
+
+	if report.FirstInvalid == nil {
+		return exitValid
+	}
+	if !*repair {
+		_, _ = fmt.Fprintf(out, "run with --repair to truncate the blocks files to block %d\n", report.LastBlockHeight)
+		return exitInvalid
+	}
+
+	backups, err := filesystem.RepairBlocksFiles(cfg, logger, report)
+	for _, backup := range backups {
+		_, _ = fmt.Fprintf(out, "backed up %s\n", backup)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "error repairing blocks files: %s\n", err)
+		return exitError
+	}
+	_, _ = fmt.Fprintf(out, "truncated %s to %d bytes, the node will boot at block %d\n", report.FirstInvalid.Filename, report.FirstInvalid.Offset, report.LastBlockHeight)
+	return exitValid
+}
+
+func printReport(out io.Writer, report *filesystem.BlocksFilesReport) {
+	for _, filename := range report.Filenames {
+		_, _ = fmt.Fprintf(out, "read %s\n", filename)
+	}
+	if report.PrunedHeight > 0 {
+		_, _ = fmt.Fprintf(out, "blocks up to height %d were pruned\n", report.PrunedHeight)
+	}
+	_, _ = fmt.Fprintf(out, "found %d valid blocks, last block height %d\n", report.ValidBlocks, report.LastBlockHeight)
+
+	if invalid := report.FirstInvalid; invalid != nil {
+		height := "undecodable block"
+		if invalid.Height > 0 {
+			height = fmt.Sprintf("block %d", invalid.Height)
+		}
+		_, _ = fmt.Fprintf(out, "first invalid record: %s at offset %d of %s: %s\n", height, invalid.Offset, invalid.Filename, invalid.Err)
+	}
+}
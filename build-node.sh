@@ -12,3 +12,9 @@ time go run ./bootstrap/build/artifacts_go_mod.go _bin/
 echo "Build healthckeck binary"
 export BUILD_FLAG="$BUILD_FLAG netgo osusergo" # allows static linking, further reading https://github.com/golang/go/issues/30419
 time go build -o _bin/healthcheck -ldflags "-w -extldflags '-static' -X $CONFIG_PKG.SemanticVersion=$SEMVER -X $CONFIG_PKG.CommitVersion=$GIT_COMMIT" -tags "$BUILD_FLAG" -a bootstrap/healthcheck/main/main.go
+
+echo "Build verify-blocks binary"
+time go build -o _bin/verify-blocks -ldflags "-w -extldflags '-static' -X $CONFIG_PKG.SemanticVersion=$SEMVER -X $CONFIG_PKG.CommitVersion=$GIT_COMMIT" -tags "$BUILD_FLAG" -a bootstrap/verifyblocks/main/main.go
+
+echo "Build transfer-blocks binary"
+time go build -o _bin/transfer-blocks -ldflags "-w -extldflags '-static' -X $CONFIG_PKG.SemanticVersion=$SEMVER -X $CONFIG_PKG.CommitVersion=$GIT_COMMIT" -tags "$BUILD_FLAG" -a bootstrap/transferblocks/main/main.go
@@ -70,30 +70,62 @@ const (
 	BLOCK_SYNC_DESCENDING_ENABLED       = "BLOCK_SYNC_DESCENDING_ENABLED"
 
 	BLOCK_STORAGE_TRANSACTION_RECEIPT_QUERY_TIMESTAMP_GRACE = "BLOCK_STORAGE_TRANSACTION_RECEIPT_QUERY_TIMESTAMP_GRACE"
+	BLOCK_STORAGE_IMPORT_ENABLED                            = "BLOCK_STORAGE_IMPORT_ENABLED"
+	BLOCK_STORAGE_BACKEND                                   = "BLOCK_STORAGE_BACKEND"
 
 	CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK   = "CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK"
 	CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER = "CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER"
 	CONSENSUS_CONTEXT_TRIGGERS_ENABLED                = "CONSENSUS_CONTEXT_TRIGGERS_ENABLED"
 
 	STATE_STORAGE_HISTORY_SNAPSHOT_NUM = "STATE_STORAGE_HISTORY_SNAPSHOT_NUM"
+	STATE_STORAGE_FILE_SYSTEM_DATA_DIR = "STATE_STORAGE_FILE_SYSTEM_DATA_DIR"
+	STATE_STORAGE_SNAPSHOT_INTERVAL    = "STATE_STORAGE_SNAPSHOT_INTERVAL"
+	STATE_STORAGE_SNAPSHOT_FILE_PATH   = "STATE_STORAGE_SNAPSHOT_FILE_PATH"
+	STATE_STORAGE_ARCHIVE_MODE         = "STATE_STORAGE_ARCHIVE_MODE"
 
 	BLOCK_TRACKER_GRACE_DISTANCE = "BLOCK_TRACKER_GRACE_DISTANCE"
 	BLOCK_TRACKER_GRACE_TIMEOUT  = "BLOCK_TRACKER_GRACE_TIMEOUT"
 
-	TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES            = "TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES"
-	TRANSACTION_EXPIRATION_WINDOW                          = "TRANSACTION_EXPIRATION_WINDOW"
-	TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT        = "TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT"
-	TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL   = "TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL"
-	TRANSACTION_POOL_COMMITTED_POOL_CLEAR_EXPIRED_INTERVAL = "TRANSACTION_POOL_COMMITTED_POOL_CLEAR_EXPIRED_INTERVAL"
-	TRANSACTION_POOL_PROPAGATION_BATCH_SIZE                = "TRANSACTION_POOL_PROPAGATION_BATCH_SIZE"
-	TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT          = "TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT"
-	TRANSACTION_POOL_TIME_BETWEEN_EMPTY_BLOCKS             = "TRANSACTION_POOL_TIME_BETWEEN_EMPTY_BLOCKS"
-	TRANSACTION_POOL_NODE_SYNC_REJECT_TIME                 = "TRANSACTION_POOL_NODE_SYNC_REJECT_TIME"
+	TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES                 = "TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES"
+	TRANSACTION_EXPIRATION_WINDOW                               = "TRANSACTION_EXPIRATION_WINDOW"
+	TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT             = "TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT"
+	TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL        = "TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL"
+	TRANSACTION_POOL_COMMITTED_POOL_CLEAR_EXPIRED_INTERVAL      = "TRANSACTION_POOL_COMMITTED_POOL_CLEAR_EXPIRED_INTERVAL"
+	TRANSACTION_POOL_PROPAGATION_BATCH_SIZE                     = "TRANSACTION_POOL_PROPAGATION_BATCH_SIZE"
+	TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT               = "TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT"
+	TRANSACTION_POOL_TIME_BETWEEN_EMPTY_BLOCKS                  = "TRANSACTION_POOL_TIME_BETWEEN_EMPTY_BLOCKS"
+	TRANSACTION_POOL_NODE_SYNC_REJECT_TIME                      = "TRANSACTION_POOL_NODE_SYNC_REJECT_TIME"
+	TRANSACTION_POOL_ORDERING_POLICY                            = "TRANSACTION_POOL_ORDERING_POLICY"
+	TRANSACTION_POOL_PRIORITY_CONTRACTS                         = "TRANSACTION_POOL_PRIORITY_CONTRACTS"
+	TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_SIGNER   = "TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_SIGNER"
+	TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_CONTRACT = "TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_CONTRACT"
+	TRANSACTION_POOL_PENDING_POOL_JOURNAL_FILE_PATH             = "TRANSACTION_POOL_PENDING_POOL_JOURNAL_FILE_PATH"
 
 	GOSSIP_LISTEN_PORT                    = "GOSSIP_LISTEN_PORT"
 	GOSSIP_CONNECTION_KEEP_ALIVE_INTERVAL = "GOSSIP_CONNECTION_KEEP_ALIVE_INTERVAL"
 	GOSSIP_NETWORK_TIMEOUT                = "GOSSIP_NETWORK_TIMEOUT"
 	GOSSIP_RECONNECT_INTERVAL             = "GOSSIP_RECONNECT_INTERVAL"
+	GOSSIP_AUTHENTICATION_ENABLED         = "GOSSIP_AUTHENTICATION_ENABLED"
+	GOSSIP_SIGNED_MESSAGES_ENABLED        = "GOSSIP_SIGNED_MESSAGES_ENABLED"
+	GOSSIP_COMPRESSION_ENABLED            = "GOSSIP_COMPRESSION_ENABLED"
+	GOSSIP_COMPRESSION_THRESHOLD_BYTES    = "GOSSIP_COMPRESSION_THRESHOLD_BYTES"
+
+	GOSSIP_EPIDEMIC_TRANSACTION_RELAY_ENABLED = "GOSSIP_EPIDEMIC_TRANSACTION_RELAY_ENABLED"
+	GOSSIP_EPIDEMIC_BLOCK_SYNC_ENABLED        = "GOSSIP_EPIDEMIC_BLOCK_SYNC_ENABLED"
+	GOSSIP_EPIDEMIC_RELAY_FANOUT              = "GOSSIP_EPIDEMIC_RELAY_FANOUT"
+	GOSSIP_EPIDEMIC_RELAY_TTL                 = "GOSSIP_EPIDEMIC_RELAY_TTL"
+
+	GOSSIP_PEER_SCORE_THROTTLE_THRESHOLD   = "GOSSIP_PEER_SCORE_THROTTLE_THRESHOLD"
+	GOSSIP_PEER_SCORE_DISCONNECT_THRESHOLD = "GOSSIP_PEER_SCORE_DISCONNECT_THRESHOLD"
+	GOSSIP_PEER_DISCONNECT_DURATION        = "GOSSIP_PEER_DISCONNECT_DURATION"
+
+	GOSSIP_PEER_DISCOVERY_ENABLED           = "GOSSIP_PEER_DISCOVERY_ENABLED"
+	GOSSIP_PEER_DISCOVERY_ENDPOINT          = "GOSSIP_PEER_DISCOVERY_ENDPOINT"
+	GOSSIP_PEER_DISCOVERY_ANNOUNCE_INTERVAL = "GOSSIP_PEER_DISCOVERY_ANNOUNCE_INTERVAL"
+
+	GOSSIP_RECORDING_FILE_PATH           = "GOSSIP_RECORDING_FILE_PATH"
+	GOSSIP_RECORDING_MAX_FILE_SIZE_BYTES = "GOSSIP_RECORDING_MAX_FILE_SIZE_BYTES"
+	GOSSIP_RECORDING_MAX_FILES           = "GOSSIP_RECORDING_MAX_FILES"
 
 	PUBLIC_API_SEND_TRANSACTION_TIMEOUT = "PUBLIC_API_SEND_TRANSACTION_TIMEOUT"
 	PUBLIC_API_NODE_SYNC_WARNING_TIME   = "PUBLIC_API_NODE_SYNC_WARNING_TIME"
@@ -111,8 +143,12 @@ const (
 	LOGGER_FILE_TRUNCATION_INTERVAL = "LOGGER_FILE_TRUNCATION_INTERVAL"
 	LOGGER_FULL_LOG                 = "LOGGER_FULL_LOG"
 
-	BLOCK_STORAGE_FILE_SYSTEM_DATA_DIR                = "BLOCK_STORAGE_FILE_SYSTEM_DATA_DIR"
-	BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCK_SIZE_IN_BYTES = "BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCK_SIZE_IN_BYTES"
+	BLOCK_STORAGE_FILE_SYSTEM_DATA_DIR                  = "BLOCK_STORAGE_FILE_SYSTEM_DATA_DIR"
+	BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCK_SIZE_IN_BYTES   = "BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCK_SIZE_IN_BYTES"
+	BLOCK_STORAGE_FILE_SYSTEM_MAX_SEGMENT_SIZE_IN_BYTES = "BLOCK_STORAGE_FILE_SYSTEM_MAX_SEGMENT_SIZE_IN_BYTES"
+	BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCKS_PER_SEGMENT    = "BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCKS_PER_SEGMENT"
+	BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS           = "BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS"
+	BLOCK_STORAGE_FILE_SYSTEM_COMPRESSION_ENABLED       = "BLOCK_STORAGE_FILE_SYSTEM_COMPRESSION_ENABLED"
 
 	PROFILING = "PROFILING"
 
@@ -125,6 +161,20 @@ const (
 	EXPERIMENTAL_EXTERNAL_PROCESSOR_PLUGIN_PATH = "EXPERIMENTAL_EXTERNAL_PROCESSOR_PLUGIN_PATH"
 )
 
+// values of TRANSACTION_POOL_ORDERING_POLICY
+const (
+	TRANSACTION_POOL_ORDERING_POLICY_FIFO               = "fifo"
+	TRANSACTION_POOL_ORDERING_POLICY_CONTRACT_FAIRNESS  = "contract-fairness"
+	TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN = "signer-round-robin"
+	TRANSACTION_POOL_ORDERING_POLICY_PRIORITY           = "priority"
+)
+
+// values of BLOCK_STORAGE_BACKEND
+const (
+	BLOCK_STORAGE_BACKEND_FILESYSTEM = "filesystem"
+	BLOCK_STORAGE_BACKEND_KVSTORE    = "kvstore"
+)
+
 func (c *config) Set(key string, value NodeConfigValue) mutableNodeConfig {
 	c.kv[key] = value
 	return c
@@ -242,6 +292,14 @@ func (c *config) BlockStorageTransactionReceiptQueryTimestampGrace() time.Durati
 	return c.kv[BLOCK_STORAGE_TRANSACTION_RECEIPT_QUERY_TIMESTAMP_GRACE].DurationValue
 }
 
+func (c *config) BlockStorageImportEnabled() bool {
+	return c.kv[BLOCK_STORAGE_IMPORT_ENABLED].BoolValue
+}
+
+func (c *config) BlockStorageBackend() string {
+	return c.kv[BLOCK_STORAGE_BACKEND].StringValue
+}
+
 func (c *config) ConsensusContextMaximumTransactionsInBlock() uint32 {
 	return c.kv[CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK].Uint32Value
 }
@@ -258,6 +316,22 @@ func (c *config) StateStorageHistorySnapshotNum() uint32 {
 	return c.kv[STATE_STORAGE_HISTORY_SNAPSHOT_NUM].Uint32Value
 }
 
+func (c *config) StateStorageFileSystemDataDir() string {
+	return c.kv[STATE_STORAGE_FILE_SYSTEM_DATA_DIR].StringValue
+}
+
+func (c *config) StateStorageSnapshotInterval() uint32 {
+	return c.kv[STATE_STORAGE_SNAPSHOT_INTERVAL].Uint32Value
+}
+
+func (c *config) StateStorageSnapshotFilePath() string {
+	return c.kv[STATE_STORAGE_SNAPSHOT_FILE_PATH].StringValue
+}
+
+func (c *config) StateStorageArchiveMode() bool {
+	return c.kv[STATE_STORAGE_ARCHIVE_MODE].BoolValue
+}
+
 func (c *config) BlockTrackerGraceDistance() uint32 {
 	return c.kv[BLOCK_TRACKER_GRACE_DISTANCE].Uint32Value
 }
@@ -302,6 +376,26 @@ func (c *config) TransactionPoolNodeSyncRejectTime() time.Duration {
 	return c.kv[TRANSACTION_POOL_NODE_SYNC_REJECT_TIME].DurationValue
 }
 
+func (c *config) TransactionPoolOrderingPolicy() string {
+	return c.kv[TRANSACTION_POOL_ORDERING_POLICY].StringValue
+}
+
+func (c *config) TransactionPoolPriorityContracts() string {
+	return c.kv[TRANSACTION_POOL_PRIORITY_CONTRACTS].StringValue
+}
+
+func (c *config) TransactionPoolPendingPoolMaxTransactionsPerSigner() uint32 {
+	return c.kv[TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_SIGNER].Uint32Value
+}
+
+func (c *config) TransactionPoolPendingPoolMaxTransactionsPerContract() uint32 {
+	return c.kv[TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_CONTRACT].Uint32Value
+}
+
+func (c *config) TransactionPoolPendingPoolJournalFilePath() string {
+	return c.kv[TRANSACTION_POOL_PENDING_POOL_JOURNAL_FILE_PATH].StringValue
+}
+
 func (c *config) PublicApiSendTransactionTimeout() time.Duration {
 	return c.kv[PUBLIC_API_SEND_TRANSACTION_TIMEOUT].DurationValue
 }
@@ -346,6 +440,74 @@ func (c *config) GossipReconnectInterval() time.Duration {
 	return c.kv[GOSSIP_RECONNECT_INTERVAL].DurationValue
 }
 
+func (c *config) GossipAuthenticationEnabled() bool {
+	return c.kv[GOSSIP_AUTHENTICATION_ENABLED].BoolValue
+}
+
+func (c *config) GossipSignedMessagesEnabled() bool {
+	return c.kv[GOSSIP_SIGNED_MESSAGES_ENABLED].BoolValue
+}
+
+func (c *config) GossipCompressionEnabled() bool {
+	return c.kv[GOSSIP_COMPRESSION_ENABLED].BoolValue
+}
+
+func (c *config) GossipCompressionThresholdBytes() uint32 {
+	return c.kv[GOSSIP_COMPRESSION_THRESHOLD_BYTES].Uint32Value
+}
+
+func (c *config) GossipEpidemicTransactionRelayEnabled() bool {
+	return c.kv[GOSSIP_EPIDEMIC_TRANSACTION_RELAY_ENABLED].BoolValue
+}
+
+func (c *config) GossipEpidemicBlockSyncEnabled() bool {
+	return c.kv[GOSSIP_EPIDEMIC_BLOCK_SYNC_ENABLED].BoolValue
+}
+
+func (c *config) GossipEpidemicRelayFanout() uint32 {
+	return c.kv[GOSSIP_EPIDEMIC_RELAY_FANOUT].Uint32Value
+}
+
+func (c *config) GossipEpidemicRelayTtl() uint32 {
+	return c.kv[GOSSIP_EPIDEMIC_RELAY_TTL].Uint32Value
+}
+
+func (c *config) GossipPeerScoreThrottleThreshold() uint32 {
+	return c.kv[GOSSIP_PEER_SCORE_THROTTLE_THRESHOLD].Uint32Value
+}
+
+func (c *config) GossipPeerScoreDisconnectThreshold() uint32 {
+	return c.kv[GOSSIP_PEER_SCORE_DISCONNECT_THRESHOLD].Uint32Value
+}
+
+func (c *config) GossipPeerDisconnectDuration() time.Duration {
+	return c.kv[GOSSIP_PEER_DISCONNECT_DURATION].DurationValue
+}
+
+func (c *config) GossipPeerDiscoveryEnabled() bool {
+	return c.kv[GOSSIP_PEER_DISCOVERY_ENABLED].BoolValue
+}
+
+func (c *config) GossipPeerDiscoveryEndpoint() string {
+	return c.kv[GOSSIP_PEER_DISCOVERY_ENDPOINT].StringValue
+}
+
+func (c *config) GossipPeerDiscoveryAnnounceInterval() time.Duration {
+	return c.kv[GOSSIP_PEER_DISCOVERY_ANNOUNCE_INTERVAL].DurationValue
+}
+
+func (c *config) GossipRecordingFilePath() string {
+	return c.kv[GOSSIP_RECORDING_FILE_PATH].StringValue
+}
+
+func (c *config) GossipRecordingMaxFileSizeBytes() uint32 {
+	return c.kv[GOSSIP_RECORDING_MAX_FILE_SIZE_BYTES].Uint32Value
+}
+
+func (c *config) GossipRecordingMaxFiles() uint32 {
+	return c.kv[GOSSIP_RECORDING_MAX_FILES].Uint32Value
+}
+
 func (c *config) BenchmarkConsensusRequiredQuorumPercentage() uint32 {
 	return c.kv[BENCHMARK_CONSENSUS_REQUIRED_QUORUM_PERCENTAGE].Uint32Value
 }
@@ -398,6 +560,22 @@ func (c *config) BlockStorageFileSystemMaxBlockSizeInBytes() uint32 {
 	return c.kv[BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCK_SIZE_IN_BYTES].Uint32Value
 }
 
+func (c *config) BlockStorageFileSystemMaxSegmentSizeInBytes() uint32 {
+	return c.kv[BLOCK_STORAGE_FILE_SYSTEM_MAX_SEGMENT_SIZE_IN_BYTES].Uint32Value
+}
+
+func (c *config) BlockStorageFileSystemMaxBlocksPerSegment() uint32 {
+	return c.kv[BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCKS_PER_SEGMENT].Uint32Value
+}
+
+func (c *config) BlockStorageFileSystemRetainedBlocks() uint32 {
+	return c.kv[BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS].Uint32Value
+}
+
+func (c *config) BlockStorageFileSystemCompressionEnabled() bool {
+	return c.kv[BLOCK_STORAGE_FILE_SYSTEM_COMPRESSION_ENABLED].BoolValue
+}
+
 func (c *config) Profiling() bool {
 	return c.kv[PROFILING].BoolValue
 }
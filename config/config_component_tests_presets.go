@@ -34,6 +34,9 @@ func ForGossipAdapterTests(nodeAddress primitives.NodeAddress) GossipTransportCo
 	cfg.SetDuration(GOSSIP_CONNECTION_KEEP_ALIVE_INTERVAL, 20*time.Millisecond)
 	cfg.SetDuration(GOSSIP_NETWORK_TIMEOUT, 1*time.Second)
 	cfg.SetDuration(GOSSIP_RECONNECT_INTERVAL, 20*time.Millisecond)
+	cfg.SetBool(GOSSIP_AUTHENTICATION_ENABLED, true)
+	cfg.SetBool(GOSSIP_COMPRESSION_ENABLED, true)
+	cfg.SetUint32(GOSSIP_COMPRESSION_THRESHOLD_BYTES, 16)
 	cfg.SetDuration(MANAGEMENT_POLLING_INTERVAL, 1*time.Second)
 
 	return cfg
@@ -72,6 +75,14 @@ func ForStateStorageTest(numOfStateRevisionsToRetain uint32, graceBlockDiff uint
 	return cfg
 }
 
+func ForStateStorageArchiveTest(numOfStateRevisionsToRetain uint32) StateStorageConfig {
+	cfg := emptyConfig()
+
+	cfg.SetUint32(STATE_STORAGE_HISTORY_SNAPSHOT_NUM, numOfStateRevisionsToRetain)
+	cfg.SetBool(STATE_STORAGE_ARCHIVE_MODE, true)
+	return cfg
+}
+
 func ForTransactionPoolTests(sizeLimit uint32, keyPair *testKeys.TestEcdsaSecp256K1KeyPair, timeBetweenEmptyBlocks time.Duration) TransactionPoolConfigForTests {
 	cfg := emptyConfig()
 	cfg.SetNodeAddress(keyPair.NodeAddress())
@@ -89,6 +100,11 @@ func ForTransactionPoolTests(sizeLimit uint32, keyPair *testKeys.TestEcdsaSecp25
 	cfg.SetUint32(TRANSACTION_POOL_PROPAGATION_BATCH_SIZE, 1)
 	cfg.SetDuration(TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT, 50*time.Millisecond)
 	cfg.SetDuration(TRANSACTION_POOL_TIME_BETWEEN_EMPTY_BLOCKS, timeBetweenEmptyBlocks)
+	cfg.SetString(TRANSACTION_POOL_ORDERING_POLICY, TRANSACTION_POOL_ORDERING_POLICY_FIFO)
+	cfg.SetString(TRANSACTION_POOL_PRIORITY_CONTRACTS, "")
+	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_SIGNER, 0)
+	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_CONTRACT, 0)
+	cfg.SetString(TRANSACTION_POOL_PENDING_POOL_JOURNAL_FILE_PATH, "")
 	return cfg
 }
 
@@ -50,11 +50,21 @@ type NodeConfig interface {
 	BlockSyncCollectChunksTimeout() time.Duration
 	BlockSyncDescendingEnabled() bool
 	BlockStorageTransactionReceiptQueryTimestampGrace() time.Duration
+	BlockStorageImportEnabled() bool
+	BlockStorageBackend() string
 	BlockStorageFileSystemDataDir() string
 	BlockStorageFileSystemMaxBlockSizeInBytes() uint32
+	BlockStorageFileSystemMaxSegmentSizeInBytes() uint32
+	BlockStorageFileSystemMaxBlocksPerSegment() uint32
+	BlockStorageFileSystemRetainedBlocks() uint32
+	BlockStorageFileSystemCompressionEnabled() bool
 
 	// state storage
 	StateStorageHistorySnapshotNum() uint32
+	StateStorageFileSystemDataDir() string
+	StateStorageSnapshotInterval() uint32
+	StateStorageSnapshotFilePath() string
+	StateStorageArchiveMode() bool
 
 	// block tracker
 	BlockTrackerGraceDistance() uint32
@@ -74,12 +84,34 @@ type NodeConfig interface {
 	TransactionPoolPropagationBatchingTimeout() time.Duration
 	TransactionPoolTimeBetweenEmptyBlocks() time.Duration
 	TransactionPoolNodeSyncRejectTime() time.Duration
+	TransactionPoolOrderingPolicy() string
+	TransactionPoolPriorityContracts() string
+	TransactionPoolPendingPoolMaxTransactionsPerSigner() uint32
+	TransactionPoolPendingPoolMaxTransactionsPerContract() uint32
+	TransactionPoolPendingPoolJournalFilePath() string
 
 	// gossip
 	GossipListenPort() uint16
 	GossipConnectionKeepAliveInterval() time.Duration
 	GossipNetworkTimeout() time.Duration
 	GossipReconnectInterval() time.Duration
+	GossipAuthenticationEnabled() bool
+	GossipSignedMessagesEnabled() bool
+	GossipCompressionEnabled() bool
+	GossipCompressionThresholdBytes() uint32
+	GossipEpidemicTransactionRelayEnabled() bool
+	GossipEpidemicBlockSyncEnabled() bool
+	GossipEpidemicRelayFanout() uint32
+	GossipEpidemicRelayTtl() uint32
+	GossipPeerScoreThrottleThreshold() uint32
+	GossipPeerScoreDisconnectThreshold() uint32
+	GossipPeerDisconnectDuration() time.Duration
+	GossipPeerDiscoveryEnabled() bool
+	GossipPeerDiscoveryEndpoint() string
+	GossipPeerDiscoveryAnnounceInterval() time.Duration
+	GossipRecordingFilePath() string
+	GossipRecordingMaxFileSizeBytes() uint32
+	GossipRecordingMaxFiles() uint32
 
 	// public api
 	PublicApiSendTransactionTimeout() time.Duration
@@ -150,6 +182,22 @@ type BlockStorageConfig interface {
 type FilesystemBlockPersistenceConfig interface {
 	BlockStorageFileSystemDataDir() string
 	BlockStorageFileSystemMaxBlockSizeInBytes() uint32
+	BlockStorageFileSystemMaxSegmentSizeInBytes() uint32
+	BlockStorageFileSystemMaxBlocksPerSegment() uint32
+	BlockStorageFileSystemRetainedBlocks() uint32
+	BlockStorageFileSystemCompressionEnabled() bool
+	VirtualChainId() primitives.VirtualChainId
+	NetworkType() protocol.SignerNetworkType
+}
+
+type KeyValueStoreBlockPersistenceConfig interface {
+	BlockStorageFileSystemDataDir() string
+	VirtualChainId() primitives.VirtualChainId
+	NetworkType() protocol.SignerNetworkType
+}
+
+type FilesystemStatePersistenceConfig interface {
+	StateStorageFileSystemDataDir() string
 	VirtualChainId() primitives.VirtualChainId
 	NetworkType() protocol.SignerNetworkType
 }
@@ -160,6 +208,12 @@ type GossipTransportConfig interface {
 	GossipConnectionKeepAliveInterval() time.Duration
 	GossipNetworkTimeout() time.Duration
 	GossipReconnectInterval() time.Duration
+	GossipAuthenticationEnabled() bool
+	GossipCompressionEnabled() bool
+	GossipCompressionThresholdBytes() uint32
+	GossipPeerScoreThrottleThreshold() uint32
+	GossipPeerScoreDisconnectThreshold() uint32
+	GossipPeerDisconnectDuration() time.Duration
 }
 
 type ConsensusContextConfig interface {
@@ -180,6 +234,9 @@ type PublicApiConfig interface {
 
 type StateStorageConfig interface {
 	StateStorageHistorySnapshotNum() uint32
+	StateStorageSnapshotInterval() uint32
+	StateStorageSnapshotFilePath() string
+	StateStorageArchiveMode() bool
 	BlockTrackerGraceDistance() uint32
 	BlockTrackerGraceTimeout() time.Duration
 }
@@ -198,6 +255,11 @@ type TransactionPoolConfig interface {
 	TransactionPoolPropagationBatchingTimeout() time.Duration
 	TransactionPoolTimeBetweenEmptyBlocks() time.Duration
 	TransactionPoolNodeSyncRejectTime() time.Duration
+	TransactionPoolOrderingPolicy() string
+	TransactionPoolPriorityContracts() string
+	TransactionPoolPendingPoolMaxTransactionsPerSigner() uint32
+	TransactionPoolPendingPoolMaxTransactionsPerContract() uint32
+	TransactionPoolPendingPoolJournalFilePath() string
 }
 
 type TransactionPoolConfigForTests interface {
@@ -237,8 +299,12 @@ type ValidatorNode interface {
 }
 
 type HttpServerConfig interface {
+	NodeAddress() primitives.NodeAddress
 	HttpAddress() string
 	Profiling() bool
+	VirtualChainId() primitives.VirtualChainId
+	BlockStorageImportEnabled() bool
+	BlockStorageFileSystemMaxBlockSizeInBytes() uint32
 	ManagementFilePath() string
 	ManagementPollingInterval() time.Duration
 	TransactionPoolTimeBetweenEmptyBlocks() time.Duration
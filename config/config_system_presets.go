@@ -55,7 +55,20 @@ func defaultProductionConfig() mutableNodeConfig {
 	// 5 empty blocks
 	cfg.SetDuration(PUBLIC_API_NODE_SYNC_WARNING_TIME, 50*time.Second)
 	cfg.SetDuration(BLOCK_STORAGE_TRANSACTION_RECEIPT_QUERY_TIMESTAMP_GRACE, 5*time.Second)
+	// allows importing exported blocks over http, every imported block is validated like a block received via sync
+	cfg.SetBool(BLOCK_STORAGE_IMPORT_ENABLED, false)
+	// either "filesystem" for append only blocks files or "kvstore" for an embedded key-value store, both kept in the block storage data dir.
+	// switching backends does not migrate blocks, export them from the old backend and import them to the new one
+	cfg.SetString(BLOCK_STORAGE_BACKEND, BLOCK_STORAGE_BACKEND_FILESYSTEM)
 	cfg.SetUint32(STATE_STORAGE_HISTORY_SNAPSHOT_NUM, 5)
+	// empty means state is kept in memory and rebuilt from the blocks file on every boot
+	cfg.SetString(STATE_STORAGE_FILE_SYSTEM_DATA_DIR, "")
+	// number of blocks between state snapshots, 0 disables writing snapshots
+	cfg.SetUint32(STATE_STORAGE_SNAPSHOT_INTERVAL, 0)
+	// snapshots are written to this file and imported from it on boot when ahead of the persisted state
+	cfg.SetString(STATE_STORAGE_SNAPSHOT_FILE_PATH, "")
+	// archive mode indexes every state diff per key so state can be read at any past block height, validators should keep it off
+	cfg.SetBool(STATE_STORAGE_ARCHIVE_MODE, false)
 
 	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES, 20*1024*1024)
 	// roughly 6 leader changes in leanHelix
@@ -65,12 +78,52 @@ func defaultProductionConfig() mutableNodeConfig {
 	cfg.SetDuration(TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT, 1*time.Minute)
 	cfg.SetDuration(TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL, 10*time.Second)
 	cfg.SetDuration(TRANSACTION_POOL_COMMITTED_POOL_CLEAR_EXPIRED_INTERVAL, 30*time.Second)
+	// the order pending transactions are proposed in, the fairness policies take turns between contracts or signers so
+	// one of them flooding the pool cannot starve the others, the priority policy proposes the priority contracts first
+	cfg.SetString(TRANSACTION_POOL_ORDERING_POLICY, TRANSACTION_POOL_ORDERING_POLICY_FIFO)
+	// comma separated contract names, highest priority first. with the priority policy transactions to these contracts
+	// are proposed before all others
+	cfg.SetString(TRANSACTION_POOL_PRIORITY_CONTRACTS, "")
+	// quotas on the pending transactions of a single signer or targeting a single contract so one client cannot fill
+	// the whole pending pool, 0 means no quota
+	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_SIGNER, 0)
+	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_MAX_TRANSACTIONS_PER_CONTRACT, 0)
+	// pending transactions are journaled to this file and restored from it after a restart, empty disables the journal
+	cfg.SetString(TRANSACTION_POOL_PENDING_POOL_JOURNAL_FILE_PATH, "")
 	cfg.SetUint32(TRANSACTION_POOL_PROPAGATION_BATCH_SIZE, 100)
 	cfg.SetDuration(TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT, 100*time.Millisecond)
 
 	cfg.SetDuration(GOSSIP_CONNECTION_KEEP_ALIVE_INTERVAL, 1*time.Second)
 	cfg.SetDuration(GOSSIP_RECONNECT_INTERVAL, 1*time.Minute)
 	cfg.SetDuration(GOSSIP_NETWORK_TIMEOUT, 30*time.Second)
+	// nodes prove ownership of their node address when connecting and encrypt the connection, a node with authentication disabled cannot talk to one with it enabled.
+	// off by default so existing networks keep working after an upgrade, see the README for enabling it on a running network
+	cfg.SetBool(GOSSIP_AUTHENTICATION_ENABLED, false)
+	// every message is signed by its sender and dropped unless signed by a node in the topology, all nodes must agree on this setting
+	cfg.SetBool(GOSSIP_SIGNED_MESSAGES_ENABLED, false)
+	// negotiated per connection, payloads are only compressed when both peers enable it and small payloads (most consensus messages) are never compressed
+	cfg.SetBool(GOSSIP_COMPRESSION_ENABLED, true)
+	cfg.SetUint32(GOSSIP_COMPRESSION_THRESHOLD_BYTES, 2048)
+	// broadcasts of the enabled topics are sent to a random fanout of peers which relay them on until the ttl runs out,
+	// all nodes must agree on these settings. a fanout of 4 and ttl of 5 reaches about a thousand nodes
+	cfg.SetBool(GOSSIP_EPIDEMIC_TRANSACTION_RELAY_ENABLED, false)
+	cfg.SetBool(GOSSIP_EPIDEMIC_BLOCK_SYNC_ENABLED, false)
+	cfg.SetUint32(GOSSIP_EPIDEMIC_RELAY_FANOUT, 4)
+	cfg.SetUint32(GOSSIP_EPIDEMIC_RELAY_TTL, 5)
+	// peers are penalized for sending invalid messages and forgiven one point per second, a threshold of zero disables it.
+	// a single oversized message or a few invalid ones get a peer throttled, a steady stream of them gets it disconnected
+	cfg.SetUint32(GOSSIP_PEER_SCORE_THROTTLE_THRESHOLD, 50)
+	cfg.SetUint32(GOSSIP_PEER_SCORE_DISCONNECT_THRESHOLD, 100)
+	cfg.SetDuration(GOSSIP_PEER_DISCONNECT_DURATION, 5*time.Minute)
+	// nodes announce their gossip endpoint to their peers, which prefer a recent announcement over the endpoint in the
+	// management topology. an empty endpoint announces nothing, announcements expire after 12 intervals without one
+	cfg.SetBool(GOSSIP_PEER_DISCOVERY_ENABLED, false)
+	cfg.SetString(GOSSIP_PEER_DISCOVERY_ENDPOINT, "")
+	cfg.SetDuration(GOSSIP_PEER_DISCOVERY_ANNOUNCE_INTERVAL, 5*time.Second)
+	// an empty path disables recording the gossip traffic of the node, see the replay-gossip tool
+	cfg.SetString(GOSSIP_RECORDING_FILE_PATH, "")
+	cfg.SetUint32(GOSSIP_RECORDING_MAX_FILE_SIZE_BYTES, 64*1024*1024)
+	cfg.SetUint32(GOSSIP_RECORDING_MAX_FILES, 10)
 
 	// TODO: remove with Ethereum connector
 	cfg.SetDuration(ETHEREUM_FINALITY_TIME_COMPONENT, 10*time.Minute)
@@ -85,6 +138,17 @@ func defaultProductionConfig() mutableNodeConfig {
 	cfg.SetString(BLOCK_STORAGE_FILE_SYSTEM_DATA_DIR, "/usr/local/var/orbs") // TODO V1 use build tags to replace with /var/lib/orbs for linux
 	cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCK_SIZE_IN_BYTES, 64*1024*1024)
 
+	// blocks are written to segment files which roll when either limit is reached, zero disables a limit
+	cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_MAX_SEGMENT_SIZE_IN_BYTES, 1024*1024*1024)
+	cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_MAX_BLOCKS_PER_SEGMENT, 0)
+
+	// zero keeps all blocks, otherwise segments holding only blocks older than this many blocks are deleted.
+	// pruning nodes cannot sync peers which are further behind, keep it zero unless disk space is a concern
+	cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS, 0)
+
+	// new segment files hold compressed blocks, existing segment files keep the format they were created with
+	cfg.SetBool(BLOCK_STORAGE_FILE_SYSTEM_COMPRESSION_ENABLED, false)
+
 	// TODO: remove with new logger
 	cfg.SetDuration(LOGGER_FILE_TRUNCATION_INTERVAL, 15*time.Minute)
 	cfg.SetBool(LOGGER_FULL_LOG, false)
@@ -13,6 +13,7 @@ import (
 	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/signature"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/pkg/errors"
+	"strings"
 )
 
 func ValidateNodeLogic(cfg NodeConfig) error {
@@ -27,6 +28,26 @@ func ValidateNodeLogic(cfg NodeConfig) error {
 		return errors.Errorf("node sync timeout must be greater than lean helix round timeout (BlockSyncNoCommitInterval = %s, is greater than LeanHelixConsensusRoundTimeoutInterval %s)",
 			cfg.BlockSyncNoCommitInterval(), cfg.LeanHelixConsensusRoundTimeoutInterval())
 	}
+	switch cfg.TransactionPoolOrderingPolicy() {
+	case TRANSACTION_POOL_ORDERING_POLICY_FIFO, TRANSACTION_POOL_ORDERING_POLICY_CONTRACT_FAIRNESS, TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN:
+	case TRANSACTION_POOL_ORDERING_POLICY_PRIORITY:
+		if strings.Trim(cfg.TransactionPoolPriorityContracts(), ", \t") == "" {
+			return errors.Errorf("transaction pool ordering policy %q requires priority contracts", cfg.TransactionPoolOrderingPolicy())
+		}
+	default:
+		return errors.Errorf("unknown transaction pool ordering policy %q", cfg.TransactionPoolOrderingPolicy())
+	}
+	if cfg.BlockStorageFileSystemRetainedBlocks() > 0 {
+		// pruned blocks cannot be replayed, state must survive a restart instead of being rebuilt from block storage
+		if cfg.StateStorageFileSystemDataDir() == "" {
+			return errors.Errorf("block storage pruning requires state persistence (BlockStorageFileSystemRetainedBlocks = %d, StateStorageFileSystemDataDir is empty)", cfg.BlockStorageFileSystemRetainedBlocks())
+		}
+		// state is persisted this many blocks behind the last committed block, blocks above it are replayed on boot
+		if cfg.BlockStorageFileSystemRetainedBlocks() < cfg.StateStorageHistorySnapshotNum() {
+			return errors.Errorf("retained blocks must not be less than state history snapshots (BlockStorageFileSystemRetainedBlocks = %d, is less than StateStorageHistorySnapshotNum %d)",
+				cfg.BlockStorageFileSystemRetainedBlocks(), cfg.StateStorageHistorySnapshotNum())
+		}
+	}
 	if len(cfg.NodeAddress()) == 0 {
 		return errors.New("node address must not be empty")
 	}
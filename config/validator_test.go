@@ -54,6 +54,50 @@ func TestValidateConfig_ErrorOnInvalidKeys(t *testing.T) {
 	})
 }
 
+func TestValidateConfig_ErrorOnBlockPruningWithoutStatePersistence(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		cfg := defaultProductionConfig()
+		cfg.SetNodeAddress(defaultNodeAddress())
+		cfg.SetNodePrivateKey(defaultPrivateKey())
+		cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS, 100)
+
+		require.Error(t, ValidateNodeLogic(cfg))
+
+		cfg.SetString(STATE_STORAGE_FILE_SYSTEM_DATA_DIR, "/tmp/state")
+		require.NoError(t, ValidateNodeLogic(cfg))
+	})
+}
+
+func TestValidateConfig_ErrorOnRetainingFewerBlocksThanStateHistory(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		cfg := defaultProductionConfig()
+		cfg.SetNodeAddress(defaultNodeAddress())
+		cfg.SetNodePrivateKey(defaultPrivateKey())
+		cfg.SetString(STATE_STORAGE_FILE_SYSTEM_DATA_DIR, "/tmp/state")
+		cfg.SetUint32(STATE_STORAGE_HISTORY_SNAPSHOT_NUM, 10)
+		cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS, 9)
+
+		require.Error(t, ValidateNodeLogic(cfg))
+
+		cfg.SetUint32(BLOCK_STORAGE_FILE_SYSTEM_RETAINED_BLOCKS, 10)
+		require.NoError(t, ValidateNodeLogic(cfg))
+	})
+}
+
+func TestValidateConfig_ErrorOnPriorityOrderingWithoutPriorityContracts(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		cfg := defaultProductionConfig()
+		cfg.SetNodeAddress(defaultNodeAddress())
+		cfg.SetNodePrivateKey(defaultPrivateKey())
+		cfg.SetString(TRANSACTION_POOL_ORDERING_POLICY, TRANSACTION_POOL_ORDERING_POLICY_PRIORITY)
+
+		require.Error(t, ValidateNodeLogic(cfg))
+
+		cfg.SetString(TRANSACTION_POOL_PRIORITY_CONTRACTS, "_Elections,_Committee")
+		require.NoError(t, ValidateNodeLogic(cfg))
+	})
+}
+
 func defaultNodeAddress() primitives.NodeAddress {
 	addr, _ := hex.DecodeString("a328846cd5b4979d68a8c58a9bdfeee657b34de7")
 	return primitives.NodeAddress(addr)
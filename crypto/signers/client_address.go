@@ -0,0 +1,32 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package signers
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/digest"
+	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/keys"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// Of reads a spec signer with the schemes of this package, the spec reader does not know the ecdsa secp256k1 scheme
+func Of(signer *protocol.Signer) *Signer {
+	return SignerReader(signer.Raw())
+}
+
+// the client address of an ecdsa secp256k1 signer is its ethereum address, so the address of a wallet is the same on both chains
+func CalcClientAddressOfEcdsaSecp256K1PublicKey(publicKey primitives.EcdsaSecp256K1PublicKey) (primitives.ClientAddress, error) {
+	if len(publicKey) != keys.ECDSA_SECP256K1_PUBLIC_KEY_SIZE_BYTES {
+		return nil, errors.New("transaction is not signed by a valid Signer")
+	}
+	return primitives.ClientAddress(digest.CalcNodeAddressFromPublicKey(publicKey)), nil
+}
+
+func CalcClientAddressOfEcdsaSecp256K1Signer(signer *Signer) (primitives.ClientAddress, error) {
+	return CalcClientAddressOfEcdsaSecp256K1PublicKey(signer.EcdsaSecp256K1().SignerPublicKey())
+}
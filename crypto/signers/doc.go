@@ -0,0 +1,12 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+// Package signers extends the transaction signer of the spec with the ecdsa secp256k1 scheme of ethereum wallets.
+// its Signer message adds a third scheme to the union of the spec Signer, so any signer of the spec reads the same
+// through it. it should move to the spec once the spec defines the scheme
+package signers
+
+//go:generate membufc --go signers.proto
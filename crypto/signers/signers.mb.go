@@ -0,0 +1,411 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+// AUTO GENERATED FILE (by membufc proto compiler v0.4.0)
+package signers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/orbs-network/membuffers/go"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// message Signer
+
+// reader
+
+type Signer struct {
+	// Scheme SignerScheme
+
+	// internal
+	// implements membuffers.Message
+	_message membuffers.InternalMessage
+}
+
+func (x *Signer) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{Scheme:%s,}", x.StringScheme())
+}
+
+var _Signer_Scheme = []membuffers.FieldType{membuffers.TypeUnion}
+var _Signer_Unions = [][]membuffers.FieldType{{membuffers.TypeBytes, membuffers.TypeBytes, membuffers.TypeMessage}}
+
+func SignerReader(buf []byte) *Signer {
+	x := &Signer{}
+	x._message.Init(buf, membuffers.Offset(len(buf)), _Signer_Scheme, _Signer_Unions)
+	return x
+}
+
+func (x *Signer) IsValid() bool {
+	return x._message.IsValid()
+}
+
+func (x *Signer) Raw() []byte {
+	return x._message.RawBuffer()
+}
+
+func (x *Signer) Equal(y *Signer) bool {
+	if x == nil && y == nil {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	return bytes.Equal(x.Raw(), y.Raw())
+}
+
+type SignerScheme uint16
+
+const (
+	SIGNER_SCHEME_EDDSA                 SignerScheme = 0
+	SIGNER_SCHEME_SMART_CONTRACT_CALLER SignerScheme = 1
+	SIGNER_SCHEME_ECDSA_SECP256K1       SignerScheme = 2
+)
+
+func (x *Signer) Scheme() SignerScheme {
+	return SignerScheme(x._message.GetUnionIndex(0, 0))
+}
+
+func (x *Signer) IsSchemeEddsa() bool {
+	is, _ := x._message.IsUnionIndex(0, 0, 0)
+	return is
+}
+
+func (x *Signer) Eddsa() []byte {
+	is, off := x._message.IsUnionIndex(0, 0, 0)
+	if !is {
+		panic("Accessed union field of incorrect type, did you check which union type it is first?")
+	}
+	return x._message.GetBytesInOffset(off)
+}
+
+func (x *Signer) StringEddsa() string {
+	return fmt.Sprintf("%x", x.Eddsa())
+}
+
+func (x *Signer) MutateEddsa(v []byte) error {
+	is, off := x._message.IsUnionIndex(0, 0, 0)
+	if !is {
+		return &membuffers.ErrInvalidField{}
+	}
+	x._message.SetBytesInOffset(off, v)
+	return nil
+}
+
+func (x *Signer) IsSchemeSmartContractCaller() bool {
+	is, _ := x._message.IsUnionIndex(0, 0, 1)
+	return is
+}
+
+func (x *Signer) SmartContractCaller() []byte {
+	is, off := x._message.IsUnionIndex(0, 0, 1)
+	if !is {
+		panic("Accessed union field of incorrect type, did you check which union type it is first?")
+	}
+	return x._message.GetBytesInOffset(off)
+}
+
+func (x *Signer) StringSmartContractCaller() string {
+	return fmt.Sprintf("%x", x.SmartContractCaller())
+}
+
+func (x *Signer) MutateSmartContractCaller(v []byte) error {
+	is, off := x._message.IsUnionIndex(0, 0, 1)
+	if !is {
+		return &membuffers.ErrInvalidField{}
+	}
+	x._message.SetBytesInOffset(off, v)
+	return nil
+}
+
+func (x *Signer) IsSchemeEcdsaSecp256K1() bool {
+	is, _ := x._message.IsUnionIndex(0, 0, 2)
+	return is
+}
+
+func (x *Signer) EcdsaSecp256K1() *EcdsaSecp256K1Signer {
+	is, off := x._message.IsUnionIndex(0, 0, 2)
+	if !is {
+		panic("Accessed union field of incorrect type, did you check which union type it is first?")
+	}
+	b, s := x._message.GetMessageInOffset(off)
+	return EcdsaSecp256K1SignerReader(b[:s])
+}
+
+func (x *Signer) StringEcdsaSecp256K1() string {
+	return x.EcdsaSecp256K1().String()
+}
+
+func (x *Signer) RawScheme() []byte {
+	return x._message.RawBufferForField(0, 0)
+}
+
+func (x *Signer) RawSchemeWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(0, 0)
+}
+
+func (x *Signer) StringScheme() string {
+	switch x.Scheme() {
+	case SIGNER_SCHEME_EDDSA:
+		return "(Eddsa)" + x.StringEddsa()
+	case SIGNER_SCHEME_SMART_CONTRACT_CALLER:
+		return "(SmartContractCaller)" + x.StringSmartContractCaller()
+	case SIGNER_SCHEME_ECDSA_SECP256K1:
+		return "(EcdsaSecp256K1)" + x.StringEcdsaSecp256K1()
+	}
+	return "(Unknown)"
+}
+
+// builder
+
+type SignerBuilder struct {
+	Scheme              SignerScheme
+	Eddsa               []byte
+	SmartContractCaller []byte
+	EcdsaSecp256K1      *EcdsaSecp256K1SignerBuilder
+
+	// internal
+	// implements membuffers.Builder
+	_builder               membuffers.InternalBuilder
+	_overrideWithRawBuffer []byte
+}
+
+func (w *SignerBuilder) Write(buf []byte) (err error) {
+	if w == nil {
+		return
+	}
+	w._builder.NotifyBuildStart()
+	defer w._builder.NotifyBuildEnd()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	if w._overrideWithRawBuffer != nil {
+		return w._builder.WriteOverrideWithRawBuffer(buf, w._overrideWithRawBuffer)
+	}
+	w._builder.Reset()
+	w._builder.WriteUnionIndex(buf, uint16(w.Scheme))
+	switch w.Scheme {
+	case SIGNER_SCHEME_EDDSA:
+		w._builder.WriteBytes(buf, w.Eddsa)
+	case SIGNER_SCHEME_SMART_CONTRACT_CALLER:
+		w._builder.WriteBytes(buf, w.SmartContractCaller)
+	case SIGNER_SCHEME_ECDSA_SECP256K1:
+		w._builder.WriteMessage(buf, w.EcdsaSecp256K1)
+	}
+	return nil
+}
+
+func (w *SignerBuilder) HexDump(prefix string, offsetFromStart membuffers.Offset) (err error) {
+	if w == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	w._builder.Reset()
+	w._builder.HexDumpUnionIndex(prefix, offsetFromStart, "Signer.Scheme", uint16(w.Scheme))
+	switch w.Scheme {
+	case SIGNER_SCHEME_EDDSA:
+		w._builder.HexDumpBytes(prefix, offsetFromStart, "Signer.Eddsa", w.Eddsa)
+	case SIGNER_SCHEME_SMART_CONTRACT_CALLER:
+		w._builder.HexDumpBytes(prefix, offsetFromStart, "Signer.SmartContractCaller", w.SmartContractCaller)
+	case SIGNER_SCHEME_ECDSA_SECP256K1:
+		w._builder.HexDumpMessage(prefix, offsetFromStart, "Signer.EcdsaSecp256K1", w.EcdsaSecp256K1)
+	}
+	return nil
+}
+
+func (w *SignerBuilder) GetSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	return w._builder.GetSize()
+}
+
+func (w *SignerBuilder) CalcRequiredSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	w.Write(nil)
+	return w._builder.GetSize()
+}
+
+func (w *SignerBuilder) Build() *Signer {
+	buf := make([]byte, w.CalcRequiredSize())
+	if w.Write(buf) != nil {
+		return nil
+	}
+	return SignerReader(buf)
+}
+
+func SignerBuilderFromRaw(raw []byte) *SignerBuilder {
+	return &SignerBuilder{_overrideWithRawBuffer: raw}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// message EcdsaSecp256K1Signer
+
+// reader
+
+type EcdsaSecp256K1Signer struct {
+	// NetworkType uint16
+	// SignerPublicKey []byte
+
+	// internal
+	// implements membuffers.Message
+	_message membuffers.InternalMessage
+}
+
+func (x *EcdsaSecp256K1Signer) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{NetworkType:%s,SignerPublicKey:%s,}", x.StringNetworkType(), x.StringSignerPublicKey())
+}
+
+var _EcdsaSecp256K1Signer_Scheme = []membuffers.FieldType{membuffers.TypeUint16, membuffers.TypeBytes}
+var _EcdsaSecp256K1Signer_Unions = [][]membuffers.FieldType{}
+
+func EcdsaSecp256K1SignerReader(buf []byte) *EcdsaSecp256K1Signer {
+	x := &EcdsaSecp256K1Signer{}
+	x._message.Init(buf, membuffers.Offset(len(buf)), _EcdsaSecp256K1Signer_Scheme, _EcdsaSecp256K1Signer_Unions)
+	return x
+}
+
+func (x *EcdsaSecp256K1Signer) IsValid() bool {
+	return x._message.IsValid()
+}
+
+func (x *EcdsaSecp256K1Signer) Raw() []byte {
+	return x._message.RawBuffer()
+}
+
+func (x *EcdsaSecp256K1Signer) Equal(y *EcdsaSecp256K1Signer) bool {
+	if x == nil && y == nil {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	return bytes.Equal(x.Raw(), y.Raw())
+}
+
+func (x *EcdsaSecp256K1Signer) NetworkType() uint16 {
+	return x._message.GetUint16(0)
+}
+
+func (x *EcdsaSecp256K1Signer) RawNetworkType() []byte {
+	return x._message.RawBufferForField(0, 0)
+}
+
+func (x *EcdsaSecp256K1Signer) MutateNetworkType(v uint16) error {
+	return x._message.SetUint16(0, v)
+}
+
+func (x *EcdsaSecp256K1Signer) StringNetworkType() string {
+	return fmt.Sprintf("%v", x.NetworkType())
+}
+
+func (x *EcdsaSecp256K1Signer) SignerPublicKey() []byte {
+	return x._message.GetBytes(1)
+}
+
+func (x *EcdsaSecp256K1Signer) RawSignerPublicKey() []byte {
+	return x._message.RawBufferForField(1, 0)
+}
+
+func (x *EcdsaSecp256K1Signer) RawSignerPublicKeyWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(1, 0)
+}
+
+func (x *EcdsaSecp256K1Signer) MutateSignerPublicKey(v []byte) error {
+	return x._message.SetBytes(1, v)
+}
+
+func (x *EcdsaSecp256K1Signer) StringSignerPublicKey() string {
+	return fmt.Sprintf("%x", x.SignerPublicKey())
+}
+
+// builder
+
+type EcdsaSecp256K1SignerBuilder struct {
+	NetworkType     uint16
+	SignerPublicKey []byte
+
+	// internal
+	// implements membuffers.Builder
+	_builder               membuffers.InternalBuilder
+	_overrideWithRawBuffer []byte
+}
+
+func (w *EcdsaSecp256K1SignerBuilder) Write(buf []byte) (err error) {
+	if w == nil {
+		return
+	}
+	w._builder.NotifyBuildStart()
+	defer w._builder.NotifyBuildEnd()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	if w._overrideWithRawBuffer != nil {
+		return w._builder.WriteOverrideWithRawBuffer(buf, w._overrideWithRawBuffer)
+	}
+	w._builder.Reset()
+	w._builder.WriteUint16(buf, w.NetworkType)
+	w._builder.WriteBytes(buf, w.SignerPublicKey)
+	return nil
+}
+
+func (w *EcdsaSecp256K1SignerBuilder) HexDump(prefix string, offsetFromStart membuffers.Offset) (err error) {
+	if w == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	w._builder.Reset()
+	w._builder.HexDumpUint16(prefix, offsetFromStart, "EcdsaSecp256K1Signer.NetworkType", w.NetworkType)
+	w._builder.HexDumpBytes(prefix, offsetFromStart, "EcdsaSecp256K1Signer.SignerPublicKey", w.SignerPublicKey)
+	return nil
+}
+
+func (w *EcdsaSecp256K1SignerBuilder) GetSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	return w._builder.GetSize()
+}
+
+func (w *EcdsaSecp256K1SignerBuilder) CalcRequiredSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	w.Write(nil)
+	return w._builder.GetSize()
+}
+
+func (w *EcdsaSecp256K1SignerBuilder) Build() *EcdsaSecp256K1Signer {
+	buf := make([]byte, w.CalcRequiredSize())
+	if w.Write(buf) != nil {
+		return nil
+	}
+	return EcdsaSecp256K1SignerReader(buf)
+}
+
+func EcdsaSecp256K1SignerBuilderFromRaw(raw []byte) *EcdsaSecp256K1SignerBuilder {
+	return &EcdsaSecp256K1SignerBuilder{_overrideWithRawBuffer: raw}
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package signers
+
+import (
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSigner_ReadsEd25519SignerOfTheSpec(t *testing.T) {
+	keyPair := keys.Ed25519KeyPairForTests(1)
+	signer := (&protocol.SignerBuilder{
+		Scheme: protocol.SIGNER_SCHEME_EDDSA,
+		Eddsa: &protocol.EdDSA01SignerBuilder{
+			NetworkType:     protocol.NETWORK_TYPE_TEST_NET,
+			SignerPublicKey: keyPair.PublicKey(),
+		},
+	}).Build()
+
+	extended := Of(signer)
+	require.True(t, extended.IsValid())
+	require.Equal(t, SIGNER_SCHEME_EDDSA, extended.Scheme())
+	require.Equal(t, signer.RawScheme(), extended.RawScheme())
+	require.EqualValues(t, keyPair.PublicKey(), protocol.EdDSA01SignerReader(extended.Eddsa()).SignerPublicKey())
+}
+
+func TestSigner_EcdsaSecp256K1SignerIsUnknownToTheSpec(t *testing.T) {
+	keyPair := keys.EcdsaSecp256K1KeyPairForTests(1)
+	signer := (&SignerBuilder{
+		Scheme: SIGNER_SCHEME_ECDSA_SECP256K1,
+		EcdsaSecp256K1: &EcdsaSecp256K1SignerBuilder{
+			NetworkType:     uint16(protocol.NETWORK_TYPE_TEST_NET),
+			SignerPublicKey: keyPair.PublicKey(),
+		},
+	}).Build()
+
+	spec := protocol.SignerReader(signer.Raw())
+	require.False(t, spec.IsSchemeEddsa())
+	require.False(t, spec.IsSchemeSmartContractCaller())
+
+	extended := Of(spec)
+	require.True(t, extended.IsValid())
+	require.True(t, extended.IsSchemeEcdsaSecp256K1())
+	require.EqualValues(t, protocol.NETWORK_TYPE_TEST_NET, extended.EcdsaSecp256K1().NetworkType())
+	require.EqualValues(t, keyPair.PublicKey(), extended.EcdsaSecp256K1().SignerPublicKey())
+}
+
+func TestCalcClientAddressOfEcdsaSecp256K1PublicKey(t *testing.T) {
+	keyPair := keys.EcdsaSecp256K1KeyPairForTests(1)
+
+	address, err := CalcClientAddressOfEcdsaSecp256K1PublicKey(keyPair.PublicKey())
+	require.NoError(t, err)
+	require.EqualValues(t, keyPair.NodeAddress(), address)
+
+	_, err = CalcClientAddressOfEcdsaSecp256K1PublicKey(keyPair.PublicKey()[1:])
+	require.Error(t, err, "expected a public key of the wrong length to have no address")
+}
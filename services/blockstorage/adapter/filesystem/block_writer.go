@@ -29,6 +29,14 @@ func (bw *blockWriter) Close() error {
 	return bw.ws.Close()
 }
 
+// replace closes the current file, following blocks are written to ws using codec. must be called with the writer locked
+func (bw *blockWriter) replace(ws writerSyncer, codec blockCodec) error {
+	err := bw.ws.Close()
+	bw.ws = ws
+	bw.codec = codec
+	return err
+}
+
 func newBlockWriter(ws writerSyncer, codec blockCodec) *blockWriter {
 	return &blockWriter{
 		ws:    ws,
@@ -7,8 +7,10 @@
 package filesystem
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"github.com/golang/snappy"
 	"github.com/orbs-network/membuffers/go"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/pkg/errors"
@@ -20,14 +22,19 @@ import (
 const blockHeaderSize = int(unsafe.Sizeof(blockHeader{}))
 const checksumSize = int(unsafe.Sizeof(uint32(0)))
 const chunkLengthSize = int(unsafe.Sizeof(uint32(0)))
+const blocksFileHeaderSize = int(unsafe.Sizeof(blocksFileHeader{})) + checksumSize
 
 const orbsFormatMagic = uint32(0x5342524f) // "ORBS"
 const orbsFormatVersion = 0
+const orbsFormatVersionCompressed = 1 // blocks appended to the file are compressed
 const blockMagic = uint32(0x6b4f4c42) // "BLOk"
 const blockVersion = 0
+const blockVersionCompressed = 1 // the dynamic sections are compressed, the fixed section is kept as is so it can be read for indexing
 
+// a codec decodes blocks of every version, and encodes blocks of the version matching the file they are appended to
 type codec struct {
 	maxBlockSize int
+	compress     bool
 }
 
 func newCodec(maxBlockSize uint32) *codec {
@@ -36,6 +43,13 @@ func newCodec(maxBlockSize uint32) *codec {
 	}
 }
 
+func (c *codec) forFileVersion(fileVersion uint32) *codec {
+	return &codec{
+		maxBlockSize: c.maxBlockSize,
+		compress:     fileVersion == orbsFormatVersionCompressed,
+	}
+}
+
 type blocksFileHeader struct {
 	Magic       uint32
 	FileVersion uint32
@@ -101,7 +115,7 @@ func (bh *blockHeader) read(r io.Reader) error {
 		return fmt.Errorf("invalid block magic number %v", bh.Magic)
 	}
 
-	if bh.Version != blockVersion {
+	if bh.Version != blockVersion && bh.Version != blockVersionCompressed {
 		return fmt.Errorf("invalid block version %d", bh.Version)
 	}
 
@@ -117,6 +131,12 @@ func newBlocksFileHeader(networkType, vchainId uint32) *blocksFileHeader {
 	}
 }
 
+func newCompressedBlocksFileHeader(networkType, vchainId uint32) *blocksFileHeader {
+	header := newBlocksFileHeader(networkType, vchainId)
+	header.FileVersion = orbsFormatVersionCompressed
+	return header
+}
+
 func (bfh *blocksFileHeader) read(r io.Reader) error {
 	checkSum := crc32.New(crc32.MakeTable(crc32.Castagnoli))
 	tr := io.TeeReader(r, checkSum)
@@ -139,7 +159,7 @@ func (bfh *blocksFileHeader) read(r io.Reader) error {
 	if bfh.Magic != orbsFormatMagic {
 		return fmt.Errorf("invalid magic number %v", bfh.Magic)
 	}
-	if bfh.FileVersion != orbsFormatVersion {
+	if bfh.FileVersion != orbsFormatVersion && bfh.FileVersion != orbsFormatVersionCompressed {
 		return fmt.Errorf("invalid version %d", bfh.FileVersion)
 	}
 	return nil
@@ -187,14 +207,27 @@ func (c *codec) encode(block *protocol.BlockPairContainer, w io.Writer) (int, er
 	blockHeader.addFixed(rb.Header)
 	blockHeader.addFixed(rb.BlockProof)
 
-	for _, receipt := range rb.TransactionReceipts {
-		blockHeader.addReceipt(receipt)
-	}
-	for _, diff := range rb.ContractStateDiffs {
-		blockHeader.addDiff(diff)
-	}
-	for _, tx := range tb.SignedTransactions {
-		blockHeader.addTx(tx)
+	receipts := transactionReceiptsToMessages(rb.TransactionReceipts)
+	diffs := diffsToMessages(rb.ContractStateDiffs)
+	txs := transactionsToMessages(tb.SignedTransactions)
+
+	var compressedSections [][]byte
+	if c.compress {
+		compressedSections = [][]byte{compressSection(receipts), compressSection(diffs), compressSection(txs)}
+		blockHeader.Version = blockVersionCompressed
+		blockHeader.ReceiptsSize = uint32(len(compressedSections[0]))
+		blockHeader.DiffsSize = uint32(len(compressedSections[1]))
+		blockHeader.TxsSize = uint32(len(compressedSections[2]))
+	} else {
+		for _, receipt := range rb.TransactionReceipts {
+			blockHeader.addReceipt(receipt)
+		}
+		for _, diff := range rb.ContractStateDiffs {
+			blockHeader.addDiff(diff)
+		}
+		for _, tx := range tb.SignedTransactions {
+			blockHeader.addTx(tx)
+		}
 	}
 
 	fullBlockChecksum := crc32.New(crc32.MakeTable(crc32.Castagnoli))
@@ -209,19 +242,20 @@ func (c *codec) encode(block *protocol.BlockPairContainer, w io.Writer) (int, er
 		return 0, err
 	}
 
-	err = c.writeDynamicBlockSectionWithChecksum(fullBlockWriter, transactionReceiptsToMessages(rb.TransactionReceipts))
-	if err != nil {
-		return 0, err
-	}
-
-	err = c.writeDynamicBlockSectionWithChecksum(fullBlockWriter, diffsToMessages(rb.ContractStateDiffs))
-	if err != nil {
-		return 0, err
-	}
-
-	err = c.writeDynamicBlockSectionWithChecksum(fullBlockWriter, transactionsToMessages(tb.SignedTransactions))
-	if err != nil {
-		return 0, err
+	if c.compress {
+		for _, section := range compressedSections {
+			err = writeCompressedBlockSectionWithChecksum(fullBlockWriter, section)
+			if err != nil {
+				return 0, err
+			}
+		}
+	} else {
+		for _, messages := range [][]membuffers.Message{receipts, diffs, txs} {
+			err = c.writeDynamicBlockSectionWithChecksum(fullBlockWriter, messages)
+			if err != nil {
+				return 0, err
+			}
+		}
 	}
 
 	if fullBlockWriter.bytesWritten > c.maxBlockSize { // check if we exceeded budget
@@ -290,6 +324,22 @@ func (c *codec) writeDynamicBlockSectionWithChecksum(w io.Writer, messages []mem
 	return nil
 }
 
+// a compressed section holds the chunks of an uncompressed section, compressed as a single snappy block
+func compressSection(messages []membuffers.Message) []byte {
+	section := new(bytes.Buffer)
+	for _, message := range messages {
+		_ = writeMessage(section, message) // writing to a bytes.Buffer does not fail
+	}
+	return snappy.Encode(nil, section.Bytes())
+}
+
+func writeCompressedBlockSectionWithChecksum(w io.Writer, section []byte) error {
+	return writeWithChecksum(w, func(w io.Writer) error {
+		_, err := w.Write(section)
+		return err
+	})
+}
+
 // TODO V1 see https://tree.taiga.io/project/orbs-network/us/681
 // TODO in case of a truncated file, the error could be either EOF or ErrUnexpectedEOF - consider changing
 //  this behaviour
@@ -316,17 +366,17 @@ func (c *codec) decode(r io.Reader) (*protocol.BlockPairContainer, int, error) {
 		return nil, budget.bytesRead, err
 	}
 
-	receipts, _, err := c.readReceiptsSection(tr, budget, fixed.resultsBlockHeader.NumTransactionReceipts())
+	receipts, _, err := c.readReceiptsSection(tr, budget, serializationHeader, fixed.resultsBlockHeader.NumTransactionReceipts())
 	if err != nil {
 		return nil, budget.bytesRead, err
 	}
 
-	stateDiffs, _, err := c.readStateDiffsSection(tr, budget, fixed.resultsBlockHeader.NumContractStateDiffs())
+	stateDiffs, _, err := c.readStateDiffsSection(tr, budget, serializationHeader, fixed.resultsBlockHeader.NumContractStateDiffs())
 	if err != nil {
 		return nil, budget.bytesRead, err
 	}
 
-	txs, _, err := c.readTransactionsSection(tr, budget, fixed.transactionsBlockHeader.NumSignedTransactions())
+	txs, _, err := c.readTransactionsSection(tr, budget, serializationHeader, fixed.transactionsBlockHeader.NumSignedTransactions())
 	if err != nil {
 		return nil, budget.bytesRead, err
 	}
@@ -413,8 +463,8 @@ func (c *codec) readFixedSection(r io.Reader, budget *readingBudget) (*fixedSize
 	return fixed, checksum, nil
 }
 
-func (c *codec) readReceiptsSection(tr io.Reader, budget *readingBudget, count uint32) ([]*protocol.TransactionReceipt, uint32, error) {
-	chunks, checksum, err := c.readDynamicBlockSection(tr, budget, count)
+func (c *codec) readReceiptsSection(tr io.Reader, budget *readingBudget, bh *blockHeader, count uint32) ([]*protocol.TransactionReceipt, uint32, error) {
+	chunks, checksum, err := c.readSection(tr, budget, bh.Version, bh.ReceiptsSize, count)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -426,8 +476,8 @@ func (c *codec) readReceiptsSection(tr io.Reader, budget *readingBudget, count u
 	return receipts, checksum, err
 }
 
-func (c *codec) readStateDiffsSection(tr io.Reader, budget *readingBudget, count uint32) ([]*protocol.ContractStateDiff, uint32, error) {
-	chunks, checksum, err := c.readDynamicBlockSection(tr, budget, count)
+func (c *codec) readStateDiffsSection(tr io.Reader, budget *readingBudget, bh *blockHeader, count uint32) ([]*protocol.ContractStateDiff, uint32, error) {
+	chunks, checksum, err := c.readSection(tr, budget, bh.Version, bh.DiffsSize, count)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -439,8 +489,8 @@ func (c *codec) readStateDiffsSection(tr io.Reader, budget *readingBudget, count
 	return receipts, checksum, err
 }
 
-func (c *codec) readTransactionsSection(tr io.Reader, budget *readingBudget, count uint32) ([]*protocol.SignedTransaction, uint32, error) {
-	chunks, checksum, err := c.readDynamicBlockSection(tr, budget, count)
+func (c *codec) readTransactionsSection(tr io.Reader, budget *readingBudget, bh *blockHeader, count uint32) ([]*protocol.SignedTransaction, uint32, error) {
+	chunks, checksum, err := c.readSection(tr, budget, bh.Version, bh.TxsSize, count)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -452,26 +502,81 @@ func (c *codec) readTransactionsSection(tr io.Reader, budget *readingBudget, cou
 	return receipts, checksum, err
 }
 
+func (c *codec) readSection(tr io.Reader, budget *readingBudget, version uint32, size uint32, count uint32) ([][]byte, uint32, error) {
+	if version == blockVersionCompressed {
+		return c.readCompressedBlockSection(tr, budget, size, count)
+	}
+	return c.readDynamicBlockSection(tr, budget, count)
+}
+
 func (c *codec) readDynamicBlockSection(tr io.Reader, budget *readingBudget, count uint32) ([][]byte, uint32, error) {
+	chunks, err := readChunks(tr, budget, count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var checksum uint32
+	err = binary.Read(tr, binary.LittleEndian, &checksum)
+	if err != nil {
+		return nil, 0, err
+	}
+	return chunks, checksum, err
+}
+
+func (c *codec) readCompressedBlockSection(tr io.Reader, budget *readingBudget, size uint32, count uint32) ([][]byte, uint32, error) {
+	if budget.limit < budget.bytesRead+int(size) {
+		return nil, 0, fmt.Errorf("invalid block. size exceeds limit (%d)", budget.limit)
+	}
+	compressed := make([]byte, size)
+	n, err := io.ReadFull(tr, compressed)
+	if err != nil {
+		return nil, 0, err
+	}
+	budget.bytesRead += n
+
+	var checksum uint32
+	err = binary.Read(tr, binary.LittleEndian, &checksum)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	decodedLen, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "invalid compressed block section")
+	}
+	if decodedLen > c.maxBlockSize {
+		return nil, 0, fmt.Errorf("decompressed block section size %d exceeds max limit", decodedLen)
+	}
+	section, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "invalid compressed block section")
+	}
+
+	sectionBudget := newReadingBudget(len(section), 0)
+	chunks, err := readChunks(bytes.NewReader(section), sectionBudget, count)
+	if err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+	if sectionBudget.bytesRead != len(section) {
+		return nil, 0, fmt.Errorf("block section size mismatch. expected: %v read: %v", len(section), sectionBudget.bytesRead)
+	}
+	return chunks, checksum, nil
+}
+
+func readChunks(r io.Reader, budget *readingBudget, count uint32) ([][]byte, error) {
 	if uint32(budget.limit) < count {
-		return nil, 0, fmt.Errorf("section element count is invalid. attempting to read %d elements in block section while size budget is only %d", count, budget.limit)
+		return nil, fmt.Errorf("section element count is invalid. attempting to read %d elements in block section while size budget is only %d", count, budget.limit)
 	}
 
 	chunks := make([][]byte, 0, count)
 	for i := 0; i < cap(chunks); i++ {
-		chunk, err := readChunk(tr, budget)
+		chunk, err := readChunk(r, budget)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		chunks = append(chunks, chunk)
 	}
-
-	var checksum uint32
-	err := binary.Read(tr, binary.LittleEndian, &checksum)
-	if err != nil {
-		return nil, 0, err
-	}
-	return chunks, checksum, err
+	return chunks, nil
 }
 
 type checksumWriter struct {
@@ -552,3 +657,34 @@ func transactionsToMessages(txs []*protocol.SignedTransaction) (messages []membu
 	}
 	return messages
 }
+
+func writeWithChecksum(w io.Writer, write func(w io.Writer) error) error {
+	checkSum := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	if err := write(newChecksumWriter(w, checkSum)); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, checkSum.Sum32())
+}
+
+func readWithChecksum(r io.Reader, read func(r io.Reader) error) error {
+	checkSum := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	if err := read(io.TeeReader(r, checkSum)); err != nil {
+		return err
+	}
+	var sum32 uint32
+	if err := binary.Read(r, binary.LittleEndian, &sum32); err != nil {
+		return unexpectedEOF(err)
+	}
+	if sum32 != checkSum.Sum32() {
+		return fmt.Errorf("invalid record, bad checksum")
+	}
+	return nil
+}
+
+// io.EOF is only expected before the first byte of a record
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
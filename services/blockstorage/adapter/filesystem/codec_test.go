@@ -9,11 +9,15 @@ package filesystem
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/stretchr/testify/require"
 	"hash/crc32"
+	"sync"
 	"testing"
 )
 
@@ -77,6 +81,53 @@ func TestCodec_DetectsDataCorruption(t *testing.T) {
 	}
 }
 
+func TestCodec_EncodesAndDecodesCompressedBlocks(t *testing.T) {
+	ctrlRand := rand.NewControlledRand(t)
+	block := builders.RandomizedBlock(1, ctrlRand, nil)
+	rw := new(bytes.Buffer)
+	c := newCodec(1024 * 1024).forFileVersion(orbsFormatVersionCompressed)
+
+	bytesWritten, err := c.encode(block, rw)
+	require.NoError(t, err)
+	require.EqualValues(t, bytesWritten, rw.Len(), "expected to report the number of bytes written")
+
+	decodedBlock, readSize, err := newCodec(1024 * 1024).decode(rw)
+	require.NoError(t, err, "expected to decode compressed block record successfully")
+	require.EqualValues(t, bytesWritten, readSize, "expected to read same number of bytes as written")
+	test.RequireCmpEqual(t, block, decodedBlock, "expected to decode an identical block as encoded")
+}
+
+func TestCodec_CompressesRepetitiveSections(t *testing.T) {
+	block := builders.BlockPair().WithHeight(1).WithTransactions(100).WithReceipts(100).WithStateDiffs(100).Build()
+	uncompressed, compressed := new(bytes.Buffer), new(bytes.Buffer)
+
+	_, err := newCodec(1024*1024).encode(block, uncompressed)
+	require.NoError(t, err)
+	_, err = newCodec(1024*1024).forFileVersion(orbsFormatVersionCompressed).encode(block, compressed)
+	require.NoError(t, err)
+
+	require.Less(t, compressed.Len(), uncompressed.Len()/2, "expected repetitive transactions, receipts and diffs to compress")
+}
+
+func TestCodec_DetectsCompressedDataCorruption(t *testing.T) {
+	ctrlRand := rand.NewControlledRand(t)
+	block := builders.BlockPair().WithHeight(1).WithTransactions(10).WithReceipts(10).WithStateDiffs(10).Build()
+	c := newCodec(1024 * 1024).forFileVersion(orbsFormatVersionCompressed)
+	encodedBlock := new(bytes.Buffer)
+	_, err := c.encode(block, encodedBlock)
+	require.NoError(t, err, "expected to encode block successfully")
+	blockBytes := encodedBlock.Bytes()
+
+	for ri := 0; ri < len(blockBytes); ri += 1 + ctrlRand.Intn(len(blockBytes)/20) {
+		corruptBlock := bytes.NewBuffer(append([]byte{}, blockBytes...))
+		bitFlip := byte(1) << uintptr(ctrlRand.Intn(8))
+		corruptBlock.Bytes()[ri] ^= bitFlip
+
+		_, _, err = c.decode(corruptBlock)
+		require.Error(t, err, "expected codec to detect data corruption when flipping bit %08b in byte %v/%v", bitFlip, ri, len(blockBytes))
+	}
+}
+
 func TestBlockHeaderCodec_EncodeAndDecode(t *testing.T) {
 	rw := new(bytes.Buffer)
 	ctrlRand := rand.NewControlledRand(t)
@@ -123,7 +174,7 @@ func TestBlockHeaderCodec_RejectDecodingWrongMagic(t *testing.T) {
 func TestBlockHeaderCodec_RejectDecodingWrongVersion(t *testing.T) {
 	header := newBlockHeader()
 
-	header.Version++ // fake wrong version
+	header.Version = blockVersionCompressed + 1 // fake wrong version
 
 	rw := new(bytes.Buffer)
 	err := header.write(rw)
@@ -162,7 +213,7 @@ func TestFileHeaderCodec_EncodesAndDecodesHeader(t *testing.T) {
 func TestFileHeaderCodec_RejectDecodingWrongVersion(t *testing.T) {
 	header := newBlocksFileHeader(0, 0)
 
-	header.FileVersion++ // fake wrong version
+	header.FileVersion = orbsFormatVersionCompressed + 1 // fake wrong version
 
 	rw := new(bytes.Buffer)
 	err := header.write(rw)
@@ -248,3 +299,77 @@ func TestFixedSectionChecksum(t *testing.T) {
 	require.EqualValues(t, readChecksum, encodedChecksum, "expected read method to return encoded checksum")
 
 }
+
+// go test -run none -bench Codec ./services/blockstorage/adapter/filesystem/
+// compares the compressed codec to the uncompressed codec, on blocks of the random chain generator and on builder blocks
+// repeating similar transactions. the encode benchmarks log the size of the encoded chain
+func BenchmarkCodec_Encode(b *testing.B) {
+	for _, chain := range benchmarkChains(b) {
+		for _, fileVersion := range []uint32{orbsFormatVersion, orbsFormatVersionCompressed} {
+			c := newCodec(64 * 1024 * 1024).forFileVersion(fileVersion)
+			b.Run(fmt.Sprintf("%s/file-version-%d", chain.name, fileVersion), func(b *testing.B) {
+				w := new(bytes.Buffer)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Reset()
+					for _, block := range chain.blocks {
+						if _, err := c.encode(block, w); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+				b.Logf("%d blocks encoded to %d bytes", len(chain.blocks), w.Len())
+			})
+		}
+	}
+}
+
+func BenchmarkCodec_Decode(b *testing.B) {
+	for _, chain := range benchmarkChains(b) {
+		for _, fileVersion := range []uint32{orbsFormatVersion, orbsFormatVersionCompressed} {
+			c := newCodec(64 * 1024 * 1024).forFileVersion(fileVersion)
+			encoded := new(bytes.Buffer)
+			for _, block := range chain.blocks {
+				if _, err := c.encode(block, encoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.Run(fmt.Sprintf("%s/file-version-%d", chain.name, fileVersion), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					r := bytes.NewReader(encoded.Bytes())
+					for range chain.blocks {
+						if _, _, err := c.decode(r); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+type benchmarkChain struct {
+	name   string
+	blocks []*protocol.BlockPairContainer
+}
+
+var benchmarkChainsOnce sync.Once
+var benchmarkChainsCache []benchmarkChain
+
+func benchmarkChains(b *testing.B) []benchmarkChain {
+	benchmarkChainsOnce.Do(func() {
+		ctrlRand := rand.NewControlledRand(b)
+		var builderBlocks []*protocol.BlockPairContainer
+		for h := 1; h <= 100; h++ {
+			builderBlocks = append(builderBlocks, builders.BlockPair().WithHeight(primitives.BlockHeight(h)).WithTransactions(100).WithReceipts(100).WithStateDiffs(100).Build())
+		}
+		benchmarkChainsCache = []benchmarkChain{
+			{"random-chain", builders.RandomizedBlockChain(100, ctrlRand)},
+			{"builder-chain", builderBlocks},
+		}
+	})
+	return benchmarkChainsCache
+}
@@ -44,7 +44,7 @@ func TestConstructIndexFromReader(t *testing.T) {
 		})
 
 		metrics := generateBlockStorageMetrics(metric.NewRegistry())
-		blockHeightIndex, err := buildIndex(rw, 0, harness.Logger, codec, metrics)
+		blockHeightIndex, err := buildIndex(rw, 0, harness.Logger, codec, metrics, nil)
 
 		require.NoError(t, err, "expected index to construct with no error")
 		require.EqualValues(t, numBlocks, blockHeightIndex.getLastBlockHeight(), "expected index to reach topHeight block height")
@@ -91,7 +91,7 @@ func TestConstructIndexFromReaderInterleavedOrder(t *testing.T) {
 		})
 
 		metrics := generateBlockStorageMetrics(metric.NewRegistry())
-		blockHeightIndex, err := buildIndex(rw, 0, harness.Logger, codec, metrics)
+		blockHeightIndex, err := buildIndex(rw, 0, harness.Logger, codec, metrics, nil)
 
 		require.NoError(t, err, "expected index to construct with no error")
 		require.EqualValues(t, numBlocks, blockHeightIndex.getLastBlockHeight(), "expected index to reach topHeight block height")
@@ -139,7 +139,7 @@ func TestConstructIndexFromReaderInterleavedOrderWithGap(t *testing.T) {
 		})
 
 		metrics := generateBlockStorageMetrics(metric.NewRegistry())
-		blockHeightIndex, err := buildIndex(rw, 0, harness.Logger, codec, metrics)
+		blockHeightIndex, err := buildIndex(rw, 0, harness.Logger, codec, metrics, nil)
 
 		require.NoError(t, err, "expected index to construct with no error")
 		require.EqualValues(t, getBlockHeight(sequentialTopBlock), blockHeightIndex.getLastBlockHeight(), "expected index to reach sequential top height")
@@ -211,7 +211,7 @@ func TestBuildIndexSucceedsIndexingFromReader(t *testing.T) {
 		r, done := newBlockFileReadStream(t, ctrlRand, numBlocks, maxTransactions, maxStateDiffs, codec)
 
 		metrics := generateBlockStorageMetrics(metric.NewRegistry())
-		bhIndex, err := buildIndex(r, 0, harness.Logger, codec, metrics)
+		bhIndex, err := buildIndex(r, 0, harness.Logger, codec, metrics, nil)
 
 		require.NoError(t, err, "expected buildIndex to succeed")
 		require.Equal(t, bhIndex.getLastBlockHeight(), primitives.BlockHeight(numBlocks), "expected block height to match the encoded block count")
@@ -237,7 +237,7 @@ func TestBuildIndexHandlesPartialReads(t *testing.T) {
 
 		rBuffered, done2 := OneByteAtATimeReader(t, r)
 		metrics := generateBlockStorageMetrics(metric.NewRegistry())
-		bhIndex, err := buildIndex(rBuffered, 0, harness.Logger, codec, metrics)
+		bhIndex, err := buildIndex(rBuffered, 0, harness.Logger, codec, metrics, nil)
 
 		require.NoError(t, err, "expected buildIndex to succeed with a buffered reader")
 		require.Equal(t, bhIndex.getLastBlockHeight(), primitives.BlockHeight(numBlocks), "expected block height to match the encoded block count")
@@ -253,7 +253,7 @@ func TestBuildIndexHandlesEmptyFile(t *testing.T) {
 
 		r := bytes.NewReader(make([]byte, 0, 0))
 		metrics := generateBlockStorageMetrics(metric.NewRegistry())
-		bhIndex, err := buildIndex(r, 0, harness.Logger, codec, metrics)
+		bhIndex, err := buildIndex(r, 0, harness.Logger, codec, metrics, nil)
 
 		require.NoError(t, err, "expected buildIndex to succeed")
 		require.Equal(t, bhIndex.getLastBlockHeight(), primitives.BlockHeight(0), "expected block height to be zero")
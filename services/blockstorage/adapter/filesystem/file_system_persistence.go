@@ -8,7 +8,6 @@ package filesystem
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"fmt"
 	"github.com/orbs-network/orbs-network-go/config"
@@ -35,6 +34,8 @@ type metrics struct {
 
 const blocksFilename = "blocks"
 
+var errTxIndexMismatch = errors.New("tx index does not match blocks file")
+
 func generateBlockStorageMetrics(m metric.Factory) *metrics {
 	return &metrics{
 		sizeOnDisk:          m.NewGauge("BlockStorage.FileSystemSize.Bytes"),
@@ -54,7 +55,11 @@ type BlockPersistence struct {
 	blockTracker *synchronization.BlockTracker
 	logger       log.Logger
 	blockWriter  *blockWriter
-	codec        blockCodec
+	codec        *codec
+	txIndex      *txHashIndex
+	lockFile     *os.File
+	manifest     *segmentsManifest
+	segments     []*segment // guarded by the block writer lock, the last one is written to
 }
 
 func (f *BlockPersistence) GetSyncState() internodesync.SyncState {
@@ -69,12 +74,21 @@ func getBlockHeight(block *protocol.BlockPairContainer) primitives.BlockHeight {
 }
 
 func (f *BlockPersistence) GracefulShutdown(shutdownContext context.Context) {
-	logger := f.logger.WithTags(log.String("filename", blocksFileName(f.config)))
-	if err := f.blockWriter.Close(); err != nil {
+	if err := f.txIndex.Close(); err != nil {
+		f.logger.Error("failed to close tx index file", log.Error(err))
+	}
+
+	f.blockWriter.Lock()
+	logger := f.logger.WithTags(log.String("filename", segmentFileName(f.config, f.manifest.lastId())))
+	err := f.blockWriter.Close()
+	f.blockWriter.Unlock()
+	if err != nil {
 		logger.Error("failed to close blocks file")
-		return
+	} else {
+		logger.Info("closed blocks file")
 	}
-	logger.Info("closed blocks file")
+
+	closeSilently(f.lockFile, f.logger)
 }
 
 func NewBlockPersistence(conf config.FilesystemBlockPersistenceConfig, parent log.Logger, metricFactory metric.Factory) (*BlockPersistence, error) {
@@ -82,20 +96,43 @@ func NewBlockPersistence(conf config.FilesystemBlockPersistenceConfig, parent lo
 	metrics := generateBlockStorageMetrics(metricFactory)
 	codec := newCodec(conf.BlockStorageFileSystemMaxBlockSizeInBytes())
 
-	file, blocksOffset, err := openBlocksFile(conf, logger)
+	lockFile, err := lockDataDir(conf, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	bhIndex, err := buildIndex(bufio.NewReaderSize(file, 1024*1024), blocksOffset, logger, codec, metrics)
+	manifest, err := readSegmentsManifest(conf)
 	if err != nil {
-		closeSilently(file, logger)
+		closeSilently(lockFile, logger)
 		return nil, err
 	}
 
-	newTip, err := newFileBlockWriter(file, codec, bhIndex.fetchNextOffset())
+	txIndex, err := openTxHashIndex(conf, logger)
+	if err != nil {
+		closeSilently(lockFile, logger)
+		return nil, err
+	}
+
+	bhIndex, segments, err := buildIndexes(conf, manifest, logger, codec, metrics, txIndex)
+	if err != nil {
+		_ = txIndex.Close()
+		closeSilently(lockFile, logger)
+		return nil, err
+	}
+
+	activeSegment := segments[len(segments)-1]
+	file, _, header, err := openSegmentFile(conf, activeSegment.id, logger)
+	if err != nil {
+		_ = txIndex.Close()
+		closeSilently(lockFile, logger)
+		return nil, err
+	}
+
+	newTip, err := newFileBlockWriter(file, codec.forFileVersion(header.FileVersion), activeSegment.size)
 	if err != nil {
 		closeSilently(file, logger)
+		_ = txIndex.Close()
+		closeSilently(lockFile, logger)
 		return nil, err
 	}
 
@@ -107,12 +144,14 @@ func NewBlockPersistence(conf config.FilesystemBlockPersistenceConfig, parent lo
 		logger:       logger,
 		blockWriter:  newTip,
 		codec:        codec,
+		txIndex:      txIndex,
+		lockFile:     lockFile,
+		manifest:     manifest,
+		segments:     segments,
 	}
 
-	if size, err := getBlockFileSize(file); err != nil {
-		return adapter, err
-	} else {
-		adapter.metrics.sizeOnDisk.Add(size)
+	for _, segment := range segments {
+		adapter.metrics.sizeOnDisk.Add(segment.size)
 	}
 
 	return adapter, nil
@@ -126,79 +165,103 @@ func getBlockFileSize(file *os.File) (int64, error) {
 	}
 }
 
-func openBlocksFile(conf config.FilesystemBlockPersistenceConfig, logger log.Logger) (*os.File, int64, error) {
+// the lock is held on the blocks file, which older versions lock as their single blocks file, so a node of either version
+// never writes a data dir in use by the other. as segment 0 it is truncated rather than deleted when pruned, keeping the lock
+func lockDataDir(conf config.FilesystemBlockPersistenceConfig, logger log.Logger) (*os.File, error) {
 	dir := conf.BlockStorageFileSystemDataDir()
-	filename := blocksFileName(conf)
 	err := os.MkdirAll(dir, os.ModePerm)
 	if err != nil {
-		return nil, 0, errors.Wrapf(err, "failed to verify data directory exists %s", dir)
+		return nil, errors.Wrapf(err, "failed to verify data directory exists %s", dir)
 	}
 
+	filename := blocksFileName(conf)
 	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
-		return nil, 0, errors.Wrapf(err, "failed to open blocks file for writing %s", filename)
+		return nil, errors.Wrapf(err, "failed to open blocks file for locking %s", filename)
 	}
 
 	err = advisoryLockExclusive(file)
 	if err != nil {
 		closeSilently(file, logger)
-		return nil, 0, errors.Wrapf(err, "failed to obtain exclusive lock for writing %s", filename)
+		return nil, errors.Wrapf(err, "failed to obtain exclusive lock for writing %s", dir)
+	}
+
+	return file, nil
+}
+
+// the file header decides the version of blocks appended to the file, so a file is never appended blocks of mixed versions
+func openSegmentFile(conf config.FilesystemBlockPersistenceConfig, id uint32, logger log.Logger) (*os.File, int64, *blocksFileHeader, error) {
+	filename := segmentFileName(conf, id)
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, 0, nil, errors.Wrapf(err, "failed to open blocks file for writing %s", filename)
 	}
 
-	firstBlockOffset, err := validateFileHeader(file, conf, logger)
+	firstBlockOffset, header, err := validateFileHeader(file, conf, logger)
 	if err != nil {
 		closeSilently(file, logger)
-		return nil, 0, errors.Wrapf(err, "failed to obtain exclusive lock for writing %s", filename)
+		return nil, 0, nil, errors.Wrapf(err, "failed to validate blocks file header %s", filename)
 	}
 
-	return file, firstBlockOffset, nil
+	return file, firstBlockOffset, header, nil
 }
 
-func validateFileHeader(file *os.File, conf config.FilesystemBlockPersistenceConfig, logger log.Logger) (int64, error) {
+func validateFileHeader(file *os.File, conf config.FilesystemBlockPersistenceConfig, logger log.Logger) (int64, *blocksFileHeader, error) {
 
 	info, err := file.Stat()
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 	if info.Size() == 0 { // empty file
 		if err := writeNewFileHeader(file, conf, logger); err != nil {
-			return 0, err
+			return 0, nil, err
 		}
 	}
 
 	offset, err := file.Seek(0, io.SeekStart)
 	if err != nil {
-		return 0, errors.Wrapf(err, "error reading blocks file header")
+		return 0, nil, errors.Wrapf(err, "error reading blocks file header")
 	}
 	if offset != 0 {
-		return 0, fmt.Errorf("error reading blocks file header")
+		return 0, nil, fmt.Errorf("error reading blocks file header")
 	}
 
-	header := newBlocksFileHeader(0, 0)
-	err = header.read(file)
+	header, err := readFileHeader(file, conf)
 	if err != nil {
-		return 0, errors.Wrapf(err, "error reading blocks file header")
+		return 0, nil, err
 	}
 
-	if header.NetworkType != uint32(conf.NetworkType()) {
-		return 0, fmt.Errorf("blocks file network type mismatch. found netowrk type %d expected %d", header.NetworkType, conf.NetworkType())
+	offset, err = file.Seek(0, io.SeekCurrent) // read current offset
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "error reading blocks file header")
 	}
 
-	if header.ChainId != uint32(conf.VirtualChainId()) {
-		return 0, fmt.Errorf("blocks file virtual chain id mismatch. found vchain id %d expected %d", header.ChainId, conf.VirtualChainId())
-	}
+	return offset, header, nil
+}
 
-	offset, err = file.Seek(0, io.SeekCurrent) // read current offset
+func readFileHeader(r io.Reader, conf config.FilesystemBlockPersistenceConfig) (*blocksFileHeader, error) {
+	header := newBlocksFileHeader(0, 0)
+	err := header.read(r)
 	if err != nil {
-		return 0, errors.Wrapf(err, "error reading blocks file header")
+		return nil, errors.Wrapf(err, "error reading blocks file header")
 	}
 
-	return offset, nil
+	if header.NetworkType != uint32(conf.NetworkType()) {
+		return nil, fmt.Errorf("blocks file network type mismatch. found netowrk type %d expected %d", header.NetworkType, conf.NetworkType())
+	}
+
+	if header.ChainId != uint32(conf.VirtualChainId()) {
+		return nil, fmt.Errorf("blocks file virtual chain id mismatch. found vchain id %d expected %d", header.ChainId, conf.VirtualChainId())
+	}
+	return header, nil
 }
 
 func writeNewFileHeader(file *os.File, conf config.FilesystemBlockPersistenceConfig, logger log.Logger) error {
 	header := newBlocksFileHeader(uint32(conf.NetworkType()), uint32(conf.VirtualChainId()))
-	logger.Info("creating new blocks file", log.String("filename", file.Name()))
+	if conf.BlockStorageFileSystemCompressionEnabled() {
+		header = newCompressedBlocksFileHeader(uint32(conf.NetworkType()), uint32(conf.VirtualChainId()))
+	}
+	logger.Info("creating new blocks file", log.String("filename", file.Name()), log.Uint32("file-version", header.FileVersion))
 	err := header.write(file)
 	if err != nil {
 		return errors.Wrapf(err, "error writing blocks file header")
@@ -228,9 +291,74 @@ func newFileBlockWriter(file *os.File, codec blockCodec, nextBlockOffset int64)
 	return result, nil
 }
 
-func buildIndex(r io.Reader, firstBlockOffset int64, logger log.Logger, c blockCodec, metrics *metrics) (*blockHeightIndex, error) {
+// the tx index is trusted up to its indexed height, only blocks above it are indexed while building the block height index.
+// it is rebuilt from scratch only when it is missing, corrupt or holds blocks which are no longer in the blocks files,
+// requiring a second pass over the blocks files
+func buildIndexes(conf config.FilesystemBlockPersistenceConfig, manifest *segmentsManifest, logger log.Logger, c blockCodec, metrics *metrics, txIndex *txHashIndex) (*blockHeightIndex, []*segment, error) {
+	bhIndex, segments, err := indexSegments(conf, manifest, logger, c, metrics, txIndex)
+	// every block up to the last block height is in the blocks files, only blocks indexed above it are looked up one by one
+	if err == nil && txIndex.getIndexedHeight() > bhIndex.getLastBlockHeight() {
+		err = errors.Wrap(errTxIndexMismatch, "tx index holds blocks missing from blocks file")
+	}
+	if err == nil {
+		var allExist bool
+		allExist, err = txIndex.allIndexedHeightsAbove(bhIndex.getLastBlockHeight(), func(height primitives.BlockHeight) bool {
+			_, exists := bhIndex.fetchBlockLocation(height)
+			return exists
+		})
+		if err == nil && !allExist {
+			err = errors.Wrap(errTxIndexMismatch, "tx index holds blocks missing from blocks file")
+		}
+	}
+	if err == nil {
+		return bhIndex, segments, txIndex.prune(manifest.prunedHeight)
+	}
+	if errors.Cause(err) != errTxIndexMismatch {
+		return nil, nil, err
+	}
+
+	logger.Info("tx index does not match blocks file, rebuilding tx index", log.Error(err))
+	if err := txIndex.reset(); err != nil {
+		return nil, nil, err
+	}
+	return indexSegments(conf, manifest, logger, c, metrics, txIndex)
+}
+
+func indexSegments(conf config.FilesystemBlockPersistenceConfig, manifest *segmentsManifest, logger log.Logger, c blockCodec, metrics *metrics, txIndex *txHashIndex) (*blockHeightIndex, []*segment, error) {
+	bhIndex := newBlockHeightIndex(logger, 0)
+	bhIndex.prune(manifest.prunedHeight)
+
+	segments := make([]*segment, 0, len(manifest.ids))
+	for _, id := range manifest.ids {
+		file, firstBlockOffset, _, err := openSegmentFile(conf, id, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		segment := newSegment(id, firstBlockOffset)
+		bhIndex.startSegment(id, firstBlockOffset)
+		err = indexSegment(bufio.NewReaderSize(file, 1024*1024), segment, bhIndex, logger, c, metrics, txIndex)
+		closeSilently(file, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		segments = append(segments, segment)
+	}
+	return bhIndex, segments, nil
+}
+
+// txIndex may be nil, in which case transactions are not indexed. blocks already held by txIndex are not indexed again
+func buildIndex(r io.Reader, firstBlockOffset int64, logger log.Logger, c blockCodec, metrics *metrics, txIndex *txHashIndex) (*blockHeightIndex, error) {
 	bhIndex := newBlockHeightIndex(logger, firstBlockOffset)
-	offset := int64(firstBlockOffset)
+	if err := indexSegment(r, newSegment(0, firstBlockOffset), bhIndex, logger, c, metrics, txIndex); err != nil {
+		return nil, err
+	}
+	return bhIndex, nil
+}
+
+// blocks which were pruned but still appear in the segment are skipped
+func indexSegment(r io.Reader, segment *segment, bhIndex *blockHeightIndex, logger log.Logger, c blockCodec, metrics *metrics, txIndex *txHashIndex) error {
+	offset := segment.firstBlockOffset
 	for {
 		aBlock, blockSize, err := c.decode(r)
 		if err != nil {
@@ -241,14 +369,24 @@ func buildIndex(r io.Reader, firstBlockOffset int64, logger log.Logger, c blockC
 			}
 			break // index up to EOF or first invalid record.
 		}
-		err = bhIndex.appendBlock(offset+int64(blockSize), aBlock, nil)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed building block height index")
+		if getBlockHeight(aBlock) > bhIndex.getPrunedHeight() {
+			err = bhIndex.appendBlock(offset+int64(blockSize), aBlock, nil)
+			if err != nil {
+				return errors.Wrap(err, "failed building block height index")
+			}
+			if txIndex != nil && getBlockHeight(aBlock) > txIndex.getIndexedHeight() {
+				if err := txIndex.indexBlock(aBlock); err != nil {
+					return errors.Wrap(errTxIndexMismatch, err.Error())
+				}
+			}
+		} else {
+			bhIndex.skip(offset + int64(blockSize))
 		}
+		segment.addBlock(getBlockHeight(aBlock), blockSize)
 		metrics.indexLastUpdateTime.Update(time.Now().Unix())
 		offset = offset + int64(blockSize)
 	}
-	return bhIndex, nil
+	return nil
 }
 
 func (f *BlockPersistence) WriteNextBlock(blockPair *protocol.BlockPairContainer) (bool, primitives.BlockHeight, error) {
@@ -261,10 +399,15 @@ func (f *BlockPersistence) WriteNextBlock(blockPair *protocol.BlockPairContainer
 		return false, f.bhIndex.getLastBlockHeight(), nil
 	}
 
+	if err := f.rollSegmentIfFull(); err != nil {
+		return false, f.bhIndex.getLastBlockHeight(), err
+	}
+
 	n, err := f.blockWriter.writeBlock(blockPair)
 	if err != nil {
 		return false, f.bhIndex.getLastBlockHeight(), err
 	}
+	f.segments[len(f.segments)-1].addBlock(bh, n)
 
 	startPos := f.bhIndex.fetchNextOffset()
 	err = f.bhIndex.appendBlock(startPos+int64(n), blockPair, f.blockTracker)
@@ -272,25 +415,104 @@ func (f *BlockPersistence) WriteNextBlock(blockPair *protocol.BlockPairContainer
 		return false, f.bhIndex.getLastBlockHeight(), errors.Wrap(err, "failed to update index after writing block")
 	}
 
+	// the block is already durable, a tx index which fails to update is repaired from the blocks file on the next boot
+	if err := f.txIndex.indexBlock(blockPair); err != nil {
+		f.logger.Error("failed to update tx index after writing block", log.Error(err), logfields.BlockHeight(bh))
+	}
+
 	f.metrics.indexLastUpdateTime.Update(time.Now().Unix())
 	f.metrics.sizeOnDisk.Add(int64(n))
+
+	if err := f.pruneSegments(); err != nil {
+		f.logger.Error("failed to prune blocks segments", log.Error(err))
+	}
 	return true, f.bhIndex.getLastBlockHeight(), nil
 }
 
+// must be called with the block writer locked
+func (f *BlockPersistence) rollSegmentIfFull() error {
+	if !f.segments[len(f.segments)-1].isFull(f.config) {
+		return nil
+	}
+
+	id := f.manifest.lastId() + 1
+	file, firstBlockOffset, header, err := openSegmentFile(f.config, id, f.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create blocks segment")
+	}
+	manifest := f.manifest.withSegment(id)
+	if err := manifest.write(f.config); err != nil {
+		closeSilently(file, f.logger)
+		return err
+	}
+
+	if err := f.blockWriter.replace(file, f.codec.forFileVersion(header.FileVersion)); err != nil {
+		f.logger.Error("failed to close previous blocks segment", log.Error(err))
+	}
+	f.manifest = manifest
+	f.segments = append(f.segments, newSegment(id, firstBlockOffset))
+	f.bhIndex.startSegment(id, firstBlockOffset)
+	f.metrics.sizeOnDisk.Add(firstBlockOffset)
+	f.logger.Info("started new blocks segment", log.String("filename", file.Name()), logfields.BlockHeight(f.bhIndex.getLastBlockHeight()))
+	return nil
+}
+
+// segments are pruned oldest first, and only when every block in them is older than the retained blocks.
+// the segment being written to is never pruned, so the sequential top block is always available to sync and the block tracker.
+// must be called with the block writer locked
+func (f *BlockPersistence) pruneSegments() error {
+	retainedBlocks := primitives.BlockHeight(f.config.BlockStorageFileSystemRetainedBlocks())
+	lastBlockHeight := f.bhIndex.getLastBlockHeight()
+	if retainedBlocks == 0 || lastBlockHeight <= retainedBlocks {
+		return nil
+	}
+
+	prunedHeight := f.manifest.prunedHeight
+	n := 0
+	for ; n < len(f.segments)-1 && f.segments[n].maxHeight <= lastBlockHeight-retainedBlocks; n++ {
+		if f.segments[n].maxHeight > prunedHeight {
+			prunedHeight = f.segments[n].maxHeight
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	manifest := f.manifest.withoutFirstSegments(n, prunedHeight)
+	if err := manifest.write(f.config); err != nil {
+		return err
+	}
+	f.bhIndex.prune(prunedHeight)
+	if err := f.txIndex.prune(prunedHeight); err != nil { // left over transactions are pruned on the next boot
+		f.logger.Error("failed to prune tx index", log.Error(err))
+	}
+
+	// a segment file which fails to be deleted is no longer listed in the manifest, and is never read again
+	for _, segment := range f.segments[:n] {
+		if err := removeSegmentFile(f.config, segment.id); err != nil {
+			f.logger.Error("failed to delete pruned blocks segment", log.Error(err))
+		}
+		f.metrics.sizeOnDisk.Add(-segment.size)
+	}
+	f.manifest = manifest
+	f.segments = append([]*segment{}, f.segments[n:]...)
+	f.logger.Info("pruned blocks segments", log.Int("segments", n), logfields.BlockHeight(prunedHeight))
+	return nil
+}
+
 func (f *BlockPersistence) ScanBlocks(from primitives.BlockHeight, pageSize uint8, cursor adapter.CursorFunc) error {
 
 	sequentialHeight := f.bhIndex.getLastBlockHeight()
 	if (sequentialHeight < from) || from == 0 {
 		return fmt.Errorf("requested unsupported block height %d. Supported range for scan is determined by sequence top height (%d)", from, sequentialHeight)
 	}
-
-	file, err := os.Open(f.blockFileName())
-	if err != nil {
-		return errors.Wrap(err, "failed to open blocks file for reading")
+	if prunedHeight := f.bhIndex.getPrunedHeight(); from <= prunedHeight {
+		return fmt.Errorf("requested pruned block height %d. blocks up to height %d were pruned", from, prunedHeight)
 	}
-	defer closeSilently(file, f.logger)
 
-	var offset int64
+	reader := newSegmentReader(f.config, f.codec, f.logger)
+	defer reader.close()
+
 	fromHeight := from
 	wantsMore := true
 	eof := false
@@ -301,7 +523,7 @@ func (f *BlockPersistence) ScanBlocks(from primitives.BlockHeight, pageSize uint
 		}
 		page := make([]*protocol.BlockPairContainer, 0, pageSize)
 		for height := fromHeight; height <= toHeight; height++ {
-			aBlock, nextOffset, err := f.fetchBlockFromFile(height, offset, file)
+			aBlock, err := f.fetchBlock(height, reader)
 			if err != nil {
 				if err == io.EOF || err == io.ErrUnexpectedEOF {
 					eof = true
@@ -309,7 +531,6 @@ func (f *BlockPersistence) ScanBlocks(from primitives.BlockHeight, pageSize uint
 				}
 				return errors.Wrapf(err, "failed to decode block")
 			}
-			offset = nextOffset
 			page = append(page, aBlock)
 		}
 		if len(page) > 0 {
@@ -322,29 +543,12 @@ func (f *BlockPersistence) ScanBlocks(from primitives.BlockHeight, pageSize uint
 	return nil
 }
 
-func (f *BlockPersistence) seekBlockOffset(height primitives.BlockHeight, currentOffset int64, file *os.File) (error, int64) {
-	if expectedOffset, ok := f.bhIndex.fetchBlockOffset(height); ok {
-		if currentOffset != expectedOffset {
-			newOffset, err := file.Seek(expectedOffset, io.SeekStart)
-			if newOffset != expectedOffset || err != nil {
-				return errors.Wrapf(err, "failed to seek in blocks file to position %v", expectedOffset), 0
-			}
-		}
-		return nil, expectedOffset
-	}
-	return fmt.Errorf("failed to find requested block %d", uint64(height)), 0
-}
-
-func (f *BlockPersistence) fetchBlockFromFile(height primitives.BlockHeight, currentOffset int64, file *os.File) (*protocol.BlockPairContainer, int64, error) {
-	err, newOffset := f.seekBlockOffset(height, currentOffset, file)
-	if err != nil {
-		return nil, 0, err
-	}
-	aBlock, blockSize, err := f.codec.decode(file)
-	if err != nil {
-		return nil, 0, err
+func (f *BlockPersistence) fetchBlock(height primitives.BlockHeight, reader *segmentReader) (*protocol.BlockPairContainer, error) {
+	location, ok := f.bhIndex.fetchBlockLocation(height)
+	if !ok {
+		return nil, fmt.Errorf("failed to find requested block %d", uint64(height))
 	}
-	return aBlock, newOffset + int64(blockSize), nil
+	return reader.readBlock(location)
 }
 
 func (f *BlockPersistence) GetLastBlockHeight() (primitives.BlockHeight, error) {
@@ -372,13 +576,10 @@ func (f *BlockPersistence) GetResultsBlock(height primitives.BlockHeight) (*prot
 }
 
 func (f *BlockPersistence) GetBlock(height primitives.BlockHeight) (*protocol.BlockPairContainer, error) {
-	file, err := os.Open(f.blockFileName())
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to open blocks file for reading")
-	}
-	defer closeSilently(file, f.logger)
+	reader := newSegmentReader(f.config, f.codec, f.logger)
+	defer reader.close()
 
-	if aBlock, _, err := f.fetchBlockFromFile(height, 0, file); err != nil {
+	if aBlock, err := f.fetchBlock(height, reader); err != nil {
 		return nil, errors.Wrapf(err, "failed to decode block")
 	} else {
 		return aBlock, nil
@@ -386,44 +587,29 @@ func (f *BlockPersistence) GetBlock(height primitives.BlockHeight) (*protocol.Bl
 }
 
 func (f *BlockPersistence) GetBlockByTx(txHash primitives.Sha256, minBlockTs primitives.TimestampNano, maxBlockTs primitives.TimestampNano) (block *protocol.BlockPairContainer, txIndexInBlock int, err error) {
-	scanFrom, ok := f.bhIndex.getEarliestTxBlockInBucketForTsRange(minBlockTs, maxBlockTs)
-	if !ok {
+	location, ok, err := f.txIndex.lookup(txHash)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to fetch block by txHash")
+	}
+	if !ok || location.height > f.bhIndex.getLastBlockHeight() || location.height <= f.bhIndex.getPrunedHeight() { // ignores blocks which are not fully synced or were pruned
 		return nil, 0, nil
 	}
 
-	err = f.ScanBlocks(scanFrom, 1, func(h primitives.BlockHeight, page []*protocol.BlockPairContainer) (wantsMore bool) {
-		b := page[0]
-		if b.ResultsBlock.Header.Timestamp() > maxBlockTs {
-			return false
-		}
-		if b.ResultsBlock.Header.Timestamp() < minBlockTs {
-			return true
-		}
-
-		for i, receipt := range b.ResultsBlock.TransactionReceipts {
-			if bytes.Equal(receipt.Txhash(), txHash) { // found requested transaction
-				block = b
-				txIndexInBlock = i
-				return false
-			}
-		}
-		return true
-	})
-
+	block, err = f.GetBlock(location.height)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "failed to fetch block by txHash")
 	}
-	return block, txIndexInBlock, nil
+
+	if ts := block.ResultsBlock.Header.Timestamp(); ts < minBlockTs || ts > maxBlockTs {
+		return nil, 0, nil
+	}
+	return block, location.index, nil
 }
 
 func (f *BlockPersistence) GetBlockTracker() *synchronization.BlockTracker {
 	return f.blockTracker
 }
 
-func (f *BlockPersistence) blockFileName() string {
-	return blocksFileName(f.config)
-}
-
 func blocksFileName(config config.FilesystemBlockPersistenceConfig) string {
 	return filepath.Join(config.BlockStorageFileSystemDataDir(), blocksFilename)
 }
@@ -16,26 +16,31 @@ import (
 	"sync"
 )
 
+type blockLocation struct {
+	segment uint32
+	offset  int64
+}
+
 type blockHeightIndex struct {
 	sync.RWMutex
-	heightOffset         map[primitives.BlockHeight]int64
-	firstBlockInTsBucket map[uint32]primitives.BlockHeight
-	nextOffset           int64
-	sequentialTopBlock   *protocol.BlockPairContainer
-	topBlock             *protocol.BlockPairContainer
-	lastWrittenBlock     *protocol.BlockPairContainer
-	logger               log.Logger
+	heightOffset       map[primitives.BlockHeight]blockLocation
+	segment            uint32
+	nextOffset         int64
+	prunedHeight       primitives.BlockHeight // heights up to this one are treated as written, but cannot be read
+	sequentialTopBlock *protocol.BlockPairContainer
+	topBlock           *protocol.BlockPairContainer
+	lastWrittenBlock   *protocol.BlockPairContainer
+	logger             log.Logger
 }
 
 func newBlockHeightIndex(logger log.Logger, firstBlockOffset int64) *blockHeightIndex {
 	return &blockHeightIndex{
-		logger:               logger,
-		heightOffset:         map[primitives.BlockHeight]int64{},
-		firstBlockInTsBucket: map[uint32]primitives.BlockHeight{},
-		nextOffset:           firstBlockOffset,
-		sequentialTopBlock:   nil,
-		topBlock:             nil,
-		lastWrittenBlock:     nil,
+		logger:             logger,
+		heightOffset:       map[primitives.BlockHeight]blockLocation{},
+		nextOffset:         firstBlockOffset,
+		sequentialTopBlock: nil,
+		topBlock:           nil,
+		lastWrittenBlock:   nil,
 	}
 }
 
@@ -56,41 +61,69 @@ func (i *blockHeightIndex) fetchNextOffset() int64 {
 	return i.nextOffset
 }
 
-func (i *blockHeightIndex) fetchBlockOffset(height primitives.BlockHeight) (offset int64, ok bool) {
+func (i *blockHeightIndex) fetchBlockLocation(height primitives.BlockHeight) (location blockLocation, ok bool) {
 	i.RLock()
 	defer i.RUnlock()
 
-	offset, ok = i.heightOffset[height]
+	location, ok = i.heightOffset[height]
 	return
 }
 
-// ignores blocks which are not fully synced (storage is missing blocks with lower height)
-func (i *blockHeightIndex) getEarliestTxBlockInBucketForTsRange(rangeStart primitives.TimestampNano, rangeEnd primitives.TimestampNano) (primitives.BlockHeight, bool) {
+func (i *blockHeightIndex) getPrunedHeight() primitives.BlockHeight {
 	i.RLock()
 	defer i.RUnlock()
 
-	fromBucket := blockTsBucketKey(rangeStart)
-	toBucket := blockTsBucketKey(rangeEnd)
-	sequentialHeight := getBlockHeight(i.sequentialTopBlock)
-	for b := fromBucket; b <= toBucket; b++ {
-		blockHeight, exists := i.firstBlockInTsBucket[b]
-		if blockHeight > sequentialHeight {
-			return 0, false
-		} else if exists {
-			return blockHeight, true
+	return i.prunedHeight
+}
+
+// following blocks are appended to a new segment file
+func (i *blockHeightIndex) startSegment(id uint32, firstBlockOffset int64) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.segment = id
+	i.nextOffset = firstBlockOffset
+}
+
+// skip moves past a block record which is not indexed
+func (i *blockHeightIndex) skip(nextOffset int64) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.nextOffset = nextOffset
+}
+
+// prune forgets the locations of all blocks up to height, which must not be above the sequential top block
+func (i *blockHeightIndex) prune(height primitives.BlockHeight) {
+	i.Lock()
+	defer i.Unlock()
+
+	if height <= i.prunedHeight {
+		return
+	}
+	for h := range i.heightOffset {
+		if h <= height {
+			delete(i.heightOffset, h)
 		}
 	}
-	return 0, false
+	i.prunedHeight = height
+}
 
+// heights of blocks below the pruned height are never lower than it
+func (i *blockHeightIndex) heightOf(block *protocol.BlockPairContainer) primitives.BlockHeight {
+	if height := getBlockHeight(block); height > i.prunedHeight {
+		return height
+	}
+	return i.prunedHeight
 }
 
 func (i *blockHeightIndex) validateCandidateBlockHeight(candidateBlockHeight primitives.BlockHeight) (err error) {
 	i.RLock()
 	defer i.RUnlock()
 
-	topHeight := getBlockHeight(i.topBlock)
-	sequentialHeight := getBlockHeight(i.sequentialTopBlock)
-	lastWrittenHeight := getBlockHeight(i.lastWrittenBlock)
+	topHeight := i.heightOf(i.topBlock)
+	sequentialHeight := i.heightOf(i.sequentialTopBlock)
+	lastWrittenHeight := i.heightOf(i.lastWrittenBlock)
 
 	if lastWrittenHeight > sequentialHeight && candidateBlockHeight != lastWrittenHeight-1 {
 		err = fmt.Errorf("sync session in progress, expected block height %d", lastWrittenHeight-1)
@@ -113,12 +146,10 @@ func (i *blockHeightIndex) appendBlock(newOffset int64, newBlock *protocol.Block
 
 	i.Lock()
 	defer i.Unlock()
-	topHeight := getBlockHeight(i.topBlock)
-	sequentialHeight := getBlockHeight(i.sequentialTopBlock)
-	numTxReceipts := newBlock.ResultsBlock.Header.NumTransactionReceipts()
-	blockTs := newBlock.ResultsBlock.Header.Timestamp()
+	topHeight := i.heightOf(i.topBlock)
+	sequentialHeight := i.heightOf(i.sequentialTopBlock)
 
-	i.heightOffset[newBlockHeight] = i.nextOffset
+	i.heightOffset[newBlockHeight] = blockLocation{segment: i.segment, offset: i.nextOffset}
 	i.nextOffset = newOffset
 	// update indices
 	i.lastWrittenBlock = newBlock
@@ -142,14 +173,6 @@ func (i *blockHeightIndex) appendBlock(newOffset int64, newBlock *protocol.Block
 		i.sequentialTopBlock = i.topBlock
 	}
 
-	if numTxReceipts > 0 {
-		bucketKey := blockTsBucketKey(blockTs)
-		firstBlockHeightInBucket, exists := i.firstBlockInTsBucket[bucketKey]
-		if !exists || newBlockHeight < firstBlockHeightInBucket {
-			i.firstBlockInTsBucket[bucketKey] = newBlockHeight
-		}
-	}
-
 	return nil
 }
 
@@ -162,11 +185,5 @@ func (i *blockHeightIndex) getLastBlock() *protocol.BlockPairContainer {
 func (i *blockHeightIndex) getLastBlockHeight() primitives.BlockHeight {
 	i.RLock()
 	defer i.RUnlock()
-	return getBlockHeight(i.sequentialTopBlock)
-}
-
-const minuteToNanoRatio = 60 * 1000 * 1000 * 1000
-
-func blockTsBucketKey(nano primitives.TimestampNano) uint32 {
-	return uint32(nano / minuteToNanoRatio)
+	return i.heightOf(i.sequentialTopBlock)
 }
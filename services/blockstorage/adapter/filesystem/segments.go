@@ -0,0 +1,250 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const segmentsFilename = "segments"
+const segmentsMagic = uint32(0x53474553) // "SEGS"
+const segmentsVersion = 0
+
+// segment 0 keeps the name of the single blocks file used before blocks were segmented, so existing data dirs are read as is
+func segmentFileName(conf config.FilesystemBlockPersistenceConfig, id uint32) string {
+	if id == 0 {
+		return blocksFileName(conf)
+	}
+	return filepath.Join(conf.BlockStorageFileSystemDataDir(), fmt.Sprintf("%s.%06d", blocksFilename, id))
+}
+
+// segment 0 holds the data dir lock, so it is emptied instead of deleted
+func removeSegmentFile(conf config.FilesystemBlockPersistenceConfig, id uint32) error {
+	if id == 0 {
+		return os.Truncate(segmentFileName(conf, id), 0)
+	}
+	return os.Remove(segmentFileName(conf, id))
+}
+
+func segmentsFileName(conf config.FilesystemBlockPersistenceConfig) string {
+	return filepath.Join(conf.BlockStorageFileSystemDataDir(), segmentsFilename)
+}
+
+// segment holds the in memory summary of a segment file, it is rebuilt on boot while indexing the blocks
+type segment struct {
+	id               uint32
+	firstBlockOffset int64
+	size             int64 // offset following the last valid block record
+	numBlocks        uint32
+	maxHeight        primitives.BlockHeight
+}
+
+func newSegment(id uint32, firstBlockOffset int64) *segment {
+	return &segment{
+		id:               id,
+		firstBlockOffset: firstBlockOffset,
+		size:             firstBlockOffset,
+	}
+}
+
+func (s *segment) addBlock(height primitives.BlockHeight, blockSize int) {
+	s.numBlocks++
+	s.size += int64(blockSize)
+	if height > s.maxHeight {
+		s.maxHeight = height
+	}
+}
+
+func (s *segment) isFull(conf config.FilesystemBlockPersistenceConfig) bool {
+	if s.numBlocks == 0 {
+		return false
+	}
+	maxBlocks, maxSize := conf.BlockStorageFileSystemMaxBlocksPerSegment(), conf.BlockStorageFileSystemMaxSegmentSizeInBytes()
+	return (maxBlocks > 0 && s.numBlocks >= maxBlocks) || (maxSize > 0 && s.size >= int64(maxSize))
+}
+
+// segmentsManifest lists the segment files in the order they were written, the last one being written to.
+// blocks up to prunedHeight were deleted, or are ignored if they still appear in a remaining segment
+type segmentsManifest struct {
+	prunedHeight primitives.BlockHeight
+	ids          []uint32
+}
+
+// a missing manifest means the data dir was written before blocks were segmented (or is new) and holds segment 0 only
+func readSegmentsManifest(conf config.FilesystemBlockPersistenceConfig) (*segmentsManifest, error) {
+	file, err := os.Open(segmentsFileName(conf))
+	if os.IsNotExist(err) {
+		return &segmentsManifest{ids: []uint32{0}}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open segments file")
+	}
+	defer func() { _ = file.Close() }()
+
+	m := &segmentsManifest{}
+	err = readWithChecksum(bufio.NewReader(file), func(r io.Reader) error {
+		var header struct {
+			Magic        uint32
+			Version      uint32
+			PrunedHeight uint64
+			NumSegments  uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return unexpectedEOF(err)
+		}
+		if header.Magic != segmentsMagic {
+			return fmt.Errorf("invalid segments file magic number %v", header.Magic)
+		}
+		if header.Version != segmentsVersion {
+			return fmt.Errorf("invalid segments file version %d", header.Version)
+		}
+		if header.NumSegments == 0 {
+			return fmt.Errorf("segments file lists no segments")
+		}
+		m.prunedHeight = primitives.BlockHeight(header.PrunedHeight)
+		m.ids = make([]uint32, header.NumSegments)
+		return unexpectedEOF(binary.Read(r, binary.LittleEndian, m.ids))
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read segments file")
+	}
+	return m, nil
+}
+
+// the manifest is replaced atomically, a crash leaves either the previous or the new list of segments
+func (m *segmentsManifest) write(conf config.FilesystemBlockPersistenceConfig) error {
+	filename := segmentsFileName(conf)
+	tmpFilename := filename + ".tmp"
+	file, err := os.OpenFile(tmpFilename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create segments file")
+	}
+
+	w := bufio.NewWriter(file)
+	err = writeWithChecksum(w, func(w io.Writer) error {
+		if err := binary.Write(w, binary.LittleEndian, []uint32{segmentsMagic, segmentsVersion}); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint64(m.prunedHeight)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(m.ids))); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.LittleEndian, m.ids)
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write segments file")
+	}
+
+	if err := os.Rename(tmpFilename, filename); err != nil {
+		return errors.Wrap(err, "failed to replace segments file")
+	}
+	return errors.Wrap(syncDir(conf.BlockStorageFileSystemDataDir()), "failed to sync data directory after replacing segments file")
+}
+
+// a rename is only durable once the directory holding it is synced
+func syncDir(dirname string) error {
+	dir, err := os.Open(dirname)
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if closeErr := dir.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (m *segmentsManifest) lastId() uint32 {
+	return m.ids[len(m.ids)-1]
+}
+
+func (m *segmentsManifest) withSegment(id uint32) *segmentsManifest {
+	return &segmentsManifest{
+		prunedHeight: m.prunedHeight,
+		ids:          append(append([]uint32{}, m.ids...), id),
+	}
+}
+
+func (m *segmentsManifest) withoutFirstSegments(n int, prunedHeight primitives.BlockHeight) *segmentsManifest {
+	return &segmentsManifest{
+		prunedHeight: prunedHeight,
+		ids:          append([]uint32{}, m.ids[n:]...),
+	}
+}
+
+// segmentReader reads blocks from segment files, keeping every file it opened open until it is closed
+type segmentReader struct {
+	conf    config.FilesystemBlockPersistenceConfig
+	codec   blockCodec
+	logger  log.Logger
+	files   map[uint32]*os.File
+	offsets map[uint32]int64
+}
+
+func newSegmentReader(conf config.FilesystemBlockPersistenceConfig, codec blockCodec, logger log.Logger) *segmentReader {
+	return &segmentReader{
+		conf:    conf,
+		codec:   codec,
+		logger:  logger,
+		files:   map[uint32]*os.File{},
+		offsets: map[uint32]int64{},
+	}
+}
+
+// errors returned by the codec are returned as is so callers can tell a partially written block (io.EOF, io.ErrUnexpectedEOF)
+func (r *segmentReader) readBlock(location blockLocation) (block *protocol.BlockPairContainer, err error) {
+	file, ok := r.files[location.segment]
+	if !ok {
+		file, err = os.Open(segmentFileName(r.conf, location.segment))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open blocks file for reading")
+		}
+		r.files[location.segment] = file
+	}
+
+	if offset, ok := r.offsets[location.segment]; !ok || offset != location.offset {
+		newOffset, err := file.Seek(location.offset, io.SeekStart)
+		if newOffset != location.offset || err != nil {
+			return nil, errors.Wrapf(err, "failed to seek in blocks file to position %v", location.offset)
+		}
+	}
+
+	block, blockSize, err := r.codec.decode(file)
+	if err != nil {
+		delete(r.offsets, location.segment)
+		return nil, err
+	}
+	r.offsets[location.segment] = location.offset + int64(blockSize)
+	return block, nil
+}
+
+func (r *segmentReader) close() {
+	for _, file := range r.files {
+		closeSilently(file, r.logger)
+	}
+}
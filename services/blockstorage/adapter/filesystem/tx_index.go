@@ -0,0 +1,318 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const txIndexDirname = "txindex.db"
+const legacyTxIndexFilename = "txindex" // the append only index file of older versions, which was loaded into memory on boot
+const txIndexVersion = uint32(1)
+const txHashSize = 32
+
+const txIndexedKeyPrefix = byte('t')
+const txIndexedBlockKeyPrefix = byte('b')
+
+var txIndexVersionKey = []byte("version")
+var txIndexedHeightKey = []byte("indexed-height")
+
+type txLocation struct {
+	height primitives.BlockHeight
+	index  int
+}
+
+// txHashIndex maps the hash of every committed transaction to its block height and receipt index.
+// it is kept in an embedded key-value store, so lookups read from disk and memory does not grow with the chain.
+// each block is written in a single batch with its tx hashes and the indexed height, below which no block is missing.
+// writes are not synced, blocks above the indexed height are indexed (or verified) from the blocks file on boot
+type txHashIndex struct {
+	sync.RWMutex
+	db            *leveldb.DB
+	dirname       string
+	indexedHeight primitives.BlockHeight
+	logger        log.Logger
+}
+
+func txIndexDirName(config config.FilesystemBlockPersistenceConfig) string {
+	return filepath.Join(config.BlockStorageFileSystemDataDir(), txIndexDirname)
+}
+
+// a missing index is created, a corrupt one or one of another version is rebuilt from scratch
+func openTxHashIndex(conf config.FilesystemBlockPersistenceConfig, logger log.Logger) (*txHashIndex, error) {
+	i := &txHashIndex{
+		dirname: txIndexDirName(conf),
+		logger:  logger.WithTags(log.String("dirname", txIndexDirName(conf))),
+	}
+
+	legacyFilename := filepath.Join(conf.BlockStorageFileSystemDataDir(), legacyTxIndexFilename)
+	if err := os.Remove(legacyFilename); err == nil {
+		i.logger.Info("removed tx index file of a previous version", log.String("filename", legacyFilename))
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to remove tx index file %s", legacyFilename)
+	}
+
+	db, err := leveldb.OpenFile(i.dirname, nil)
+	if leveldbErrors.IsCorrupted(err) {
+		i.logger.Error("tx index database is corrupt, index will be rebuilt", log.Error(err))
+		if err := os.RemoveAll(i.dirname); err != nil {
+			return nil, errors.Wrapf(err, "failed to remove tx index database %s", i.dirname)
+		}
+		db, err = leveldb.OpenFile(i.dirname, nil)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open tx index database %s", i.dirname)
+	}
+	i.db = db
+
+	if err := i.load(); err != nil {
+		_ = i.db.Close()
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *txHashIndex) load() error {
+	version, err := i.db.Get(txIndexVersionKey, nil)
+	if err != nil && err != leveldb.ErrNotFound {
+		return errors.Wrap(err, "failed to read tx index version")
+	}
+	if len(version) != 4 || binary.BigEndian.Uint32(version) != txIndexVersion {
+		if err != leveldb.ErrNotFound {
+			i.logger.Error("tx index version is invalid, index will be rebuilt", log.String("version", fmt.Sprintf("%x", version)))
+		}
+		return i.reset()
+	}
+
+	raw, err := i.db.Get(txIndexedHeightKey, nil)
+	if err != nil && err != leveldb.ErrNotFound {
+		return errors.Wrap(err, "failed to read tx index height")
+	}
+	if len(raw) == 8 {
+		i.indexedHeight = primitives.BlockHeight(binary.BigEndian.Uint64(raw))
+	}
+
+	i.logger.Info("loaded tx index", log.Uint64("indexed-block-height", uint64(i.indexedHeight)))
+	return nil
+}
+
+// reset drops all records, it is used when the index cannot be trusted to match the blocks file
+func (i *txHashIndex) reset() error {
+	i.Lock()
+	defer i.Unlock()
+
+	if err := i.db.Close(); err != nil {
+		return errors.Wrap(err, "failed to close tx index database")
+	}
+	if err := os.RemoveAll(i.dirname); err != nil {
+		return errors.Wrapf(err, "failed to remove tx index database %s", i.dirname)
+	}
+	db, err := leveldb.OpenFile(i.dirname, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open tx index database %s", i.dirname)
+	}
+	i.db = db
+	i.indexedHeight = 0
+
+	version := make([]byte, 4)
+	binary.BigEndian.PutUint32(version, txIndexVersion)
+	return errors.Wrap(db.Put(txIndexVersionKey, version, nil), "failed to write tx index version")
+}
+
+// indexBlock adds the receipts of a block to the index, or verifies them if the block height is already indexed
+func (i *txHashIndex) indexBlock(block *protocol.BlockPairContainer) error {
+	height := getBlockHeight(block)
+	receipts := block.ResultsBlock.TransactionReceipts
+
+	i.Lock()
+	defer i.Unlock()
+
+	txHashes := make([]byte, 0, len(receipts)*txHashSize)
+	for _, receipt := range receipts {
+		if len(receipt.Txhash()) != txHashSize {
+			return fmt.Errorf("invalid tx hash size %d in block height %d", len(receipt.Txhash()), height)
+		}
+		txHashes = append(txHashes, receipt.Txhash()...)
+	}
+
+	indexed, err := i.db.Get(txIndexedBlockKey(height), nil)
+	if err == nil {
+		if !bytes.Equal(indexed, txHashes) {
+			return fmt.Errorf("tx index does not match the receipts of block height %d", height)
+		}
+		return nil
+	}
+	if err != leveldb.ErrNotFound {
+		return errors.Wrapf(err, "failed to read tx index record for block height %d", height)
+	}
+
+	batch := &leveldb.Batch{}
+	for index := range receipts {
+		batch.Put(txIndexedKey(txHashes[index*txHashSize:(index+1)*txHashSize]), encodeTxLocation(height, index))
+	}
+	batch.Put(txIndexedBlockKey(height), txHashes)
+	indexedHeight := i.indexedHeight
+	if height == i.indexedHeight+1 { // blocks synced out of order above it may already be indexed
+		if indexedHeight, err = i.contiguousIndexedHeight(height); err != nil {
+			return err
+		}
+		batch.Put(txIndexedHeightKey, encodeTxIndexedHeight(indexedHeight))
+	}
+	if err := i.db.Write(batch, nil); err != nil {
+		return errors.Wrapf(err, "failed to write tx index record for block height %d", height)
+	}
+	i.indexedHeight = indexedHeight
+	return nil
+}
+
+// contiguousIndexedHeight returns the highest block height up to which the blocks following height are indexed
+func (i *txHashIndex) contiguousIndexedHeight(height primitives.BlockHeight) (primitives.BlockHeight, error) {
+	iter := i.db.NewIterator(&util.Range{Start: txIndexedBlockKey(height + 1), Limit: []byte{txIndexedBlockKeyPrefix + 1}}, nil)
+	defer iter.Release()
+	for iter.Next() {
+		next, err := parseTxIndexedBlockKey(iter.Key())
+		if err != nil {
+			return 0, err
+		}
+		if next != height+1 {
+			break
+		}
+		height = next
+	}
+	return height, errors.Wrap(iter.Error(), "failed to read tx index")
+}
+
+// getIndexedHeight returns the block height up to which every block above the pruned height is indexed
+func (i *txHashIndex) getIndexedHeight() primitives.BlockHeight {
+	i.RLock()
+	defer i.RUnlock()
+	return i.indexedHeight
+}
+
+// prune deletes the transactions of blocks up to height. blocks up to it are no longer expected in the index
+func (i *txHashIndex) prune(height primitives.BlockHeight) error {
+	i.Lock()
+	defer i.Unlock()
+
+	iter := i.db.NewIterator(&util.Range{Start: txIndexedBlockKey(0), Limit: txIndexedBlockKey(height + 1)}, nil)
+	batch := &leveldb.Batch{}
+	for iter.Next() {
+		txHashes := iter.Value()
+		for offset := 0; offset+txHashSize <= len(txHashes); offset += txHashSize {
+			batch.Delete(txIndexedKey(txHashes[offset : offset+txHashSize]))
+		}
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "failed to read tx index")
+	}
+
+	indexedHeight := i.indexedHeight
+	if indexedHeight < height {
+		var err error
+		if indexedHeight, err = i.contiguousIndexedHeight(height); err != nil {
+			return err
+		}
+		batch.Put(txIndexedHeightKey, encodeTxIndexedHeight(indexedHeight))
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	if err := i.db.Write(batch, nil); err != nil {
+		return errors.Wrapf(err, "failed to prune tx index up to block height %d", height)
+	}
+	i.indexedHeight = indexedHeight
+	return nil
+}
+
+// allIndexedHeightsAbove returns false if any block height indexed above height is rejected by exists
+func (i *txHashIndex) allIndexedHeightsAbove(height primitives.BlockHeight, exists func(height primitives.BlockHeight) bool) (bool, error) {
+	iter := i.db.NewIterator(&util.Range{Start: txIndexedBlockKey(height + 1), Limit: []byte{txIndexedBlockKeyPrefix + 1}}, nil)
+	defer iter.Release()
+	for iter.Next() {
+		indexed, err := parseTxIndexedBlockKey(iter.Key())
+		if err != nil {
+			return false, err
+		}
+		if !exists(indexed) {
+			return false, nil
+		}
+	}
+	return true, errors.Wrap(iter.Error(), "failed to read tx index")
+}
+
+func (i *txHashIndex) lookup(txHash primitives.Sha256) (txLocation, bool, error) {
+	raw, err := i.db.Get(txIndexedKey(txHash), nil)
+	if err == leveldb.ErrNotFound {
+		return txLocation{}, false, nil
+	}
+	if err != nil {
+		return txLocation{}, false, errors.Wrap(err, "failed to read tx index")
+	}
+	location, err := decodeTxLocation(raw)
+	if err != nil {
+		return txLocation{}, false, err
+	}
+	return location, true, nil
+}
+
+func (i *txHashIndex) Close() error {
+	return i.db.Close()
+}
+
+func txIndexedKey(txHash []byte) []byte {
+	return append([]byte{txIndexedKeyPrefix}, txHash...)
+}
+
+// heights are big endian so blocks are ordered by height
+func txIndexedBlockKey(height primitives.BlockHeight) []byte {
+	key := make([]byte, 9)
+	key[0] = txIndexedBlockKeyPrefix
+	binary.BigEndian.PutUint64(key[1:], uint64(height))
+	return key
+}
+
+func parseTxIndexedBlockKey(raw []byte) (primitives.BlockHeight, error) {
+	if len(raw) != 9 || raw[0] != txIndexedBlockKeyPrefix {
+		return 0, fmt.Errorf("invalid tx index block key %x", raw)
+	}
+	return primitives.BlockHeight(binary.BigEndian.Uint64(raw[1:])), nil
+}
+
+func encodeTxIndexedHeight(height primitives.BlockHeight) []byte {
+	raw := make([]byte, 8)
+	binary.BigEndian.PutUint64(raw, uint64(height))
+	return raw
+}
+
+func encodeTxLocation(height primitives.BlockHeight, index int) []byte {
+	raw := make([]byte, 12)
+	binary.BigEndian.PutUint64(raw[:8], uint64(height))
+	binary.BigEndian.PutUint32(raw[8:], uint32(index))
+	return raw
+}
+
+func decodeTxLocation(raw []byte) (txLocation, error) {
+	if len(raw) != 12 {
+		return txLocation{}, fmt.Errorf("invalid tx index location %x", raw)
+	}
+	return txLocation{height: primitives.BlockHeight(binary.BigEndian.Uint64(raw[:8])), index: int(binary.BigEndian.Uint32(raw[8:]))}, nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"time"
+)
+
+// VerifyBlockFunc checks a block read from the blocks files beyond its encoding, typically its block proof
+type VerifyBlockFunc func(block *protocol.BlockPairContainer) error
+
+// InvalidBlockRecord is the first record of the blocks files which failed verification, every record before it is valid
+type InvalidBlockRecord struct {
+	Filename string
+	Offset   int64                  // the file is valid up to this offset
+	Height   primitives.BlockHeight // zero if the record could not be decoded
+	Err      error
+}
+
+type BlocksFilesReport struct {
+	Filenames       []string // the segment files verified, in the order they were written
+	PrunedHeight    primitives.BlockHeight
+	ValidBlocks     int
+	LastBlockHeight primitives.BlockHeight // the height a node boots with, once the blocks files are repaired
+	FirstInvalid    *InvalidBlockRecord    // nil if all blocks are valid
+	manifest        *segmentsManifest
+	invalidSegment  int // position of the segment holding the first invalid record in the manifest
+}
+
+// VerifyBlocksFiles scans the blocks files of a stopped node, checking checksums, block heights, prev block hash pointers
+// and every block with verifyBlock (may be nil). the scan stops at the first invalid record. the files are not modified
+func VerifyBlocksFiles(conf config.FilesystemBlockPersistenceConfig, logger log.Logger, verifyBlock VerifyBlockFunc) (*BlocksFilesReport, error) {
+	if _, err := os.Stat(conf.BlockStorageFileSystemDataDir()); err != nil {
+		return nil, errors.Wrap(err, "failed to find data directory")
+	}
+
+	lockFile, err := lockDataDir(conf, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSilently(lockFile, logger)
+
+	manifest, err := readSegmentsManifest(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &blocksVerifier{
+		codec:       newCodec(conf.BlockStorageFileSystemMaxBlockSizeInBytes()),
+		bhIndex:     newBlockHeightIndex(logger, 0),
+		links:       newChainLinks(manifest.prunedHeight),
+		verifyBlock: verifyBlock,
+		report:      &BlocksFilesReport{PrunedHeight: manifest.prunedHeight, manifest: manifest},
+	}
+	v.bhIndex.prune(manifest.prunedHeight)
+
+	for i, id := range manifest.ids {
+		filename := segmentFileName(conf, id)
+		v.report.Filenames = append(v.report.Filenames, filename)
+		if invalid := v.verifySegment(conf, filename, id); invalid != nil {
+			v.report.FirstInvalid = invalid
+			v.report.invalidSegment = i
+			break
+		}
+	}
+
+	v.report.LastBlockHeight = v.bhIndex.getLastBlockHeight()
+	return v.report, nil
+}
+
+// RepairBlocksFiles truncates the blocks files to the last valid block of report, which must have been created by VerifyBlocksFiles
+// on the same data directory. the file holding the first invalid record is copied, and the segment files following it are moved,
+// to backup files whose names are returned. the tx index is rebuilt by the node on its next boot
+func RepairBlocksFiles(conf config.FilesystemBlockPersistenceConfig, logger log.Logger, report *BlocksFilesReport) ([]string, error) {
+	if report.FirstInvalid == nil {
+		return nil, nil
+	}
+
+	lockFile, err := lockDataDir(conf, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSilently(lockFile, logger)
+
+	suffix := ".bak." + time.Now().Format("20060102150405")
+	var backups []string
+
+	invalid := report.FirstInvalid
+	if err := copyFile(invalid.Filename, invalid.Filename+suffix); err != nil {
+		return nil, errors.Wrapf(err, "failed to back up blocks file %s", invalid.Filename)
+	}
+	backups = append(backups, invalid.Filename+suffix)
+
+	following := report.manifest.ids[report.invalidSegment+1:]
+	if len(following) > 0 {
+		manifest := &segmentsManifest{prunedHeight: report.manifest.prunedHeight, ids: report.manifest.ids[:report.invalidSegment+1]}
+		if err := manifest.write(conf); err != nil {
+			return backups, err
+		}
+	}
+	for _, id := range following {
+		filename := segmentFileName(conf, id)
+		if err := os.Rename(filename, filename+suffix); err != nil && !os.IsNotExist(err) {
+			return backups, errors.Wrapf(err, "failed to back up blocks file %s", filename)
+		} else if err == nil {
+			backups = append(backups, filename+suffix)
+		}
+	}
+
+	if err := os.Truncate(invalid.Filename, invalid.Offset); err != nil {
+		return backups, errors.Wrapf(err, "failed to truncate blocks file %s", invalid.Filename)
+	}
+	return backups, nil
+}
+
+type blocksVerifier struct {
+	codec       blockCodec
+	bhIndex     *blockHeightIndex
+	links       *chainLinks
+	verifyBlock VerifyBlockFunc
+	report      *BlocksFilesReport
+}
+
+func (v *blocksVerifier) verifySegment(conf config.FilesystemBlockPersistenceConfig, filename string, id uint32) *InvalidBlockRecord {
+	file, err := os.Open(filename)
+	if err != nil {
+		return &InvalidBlockRecord{Filename: filename, Err: errors.Wrap(err, "failed to open blocks file")}
+	}
+	defer func() { _ = file.Close() }()
+
+	r := bufio.NewReaderSize(file, 1024*1024)
+	if _, err := readFileHeader(r, conf); err != nil {
+		return &InvalidBlockRecord{Filename: filename, Err: err}
+	}
+
+	offset := int64(blocksFileHeaderSize)
+	v.bhIndex.startSegment(id, offset)
+	for {
+		block, blockSize, err := v.codec.decode(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &InvalidBlockRecord{Filename: filename, Offset: offset, Err: errors.Wrap(err, "failed to decode block")}
+		}
+
+		height := getBlockHeight(block)
+		if height > v.report.PrunedHeight {
+			if err := v.verifyAndIndex(block, offset+int64(blockSize)); err != nil {
+				return &InvalidBlockRecord{Filename: filename, Offset: offset, Height: height, Err: err}
+			}
+			v.report.ValidBlocks++
+		} else {
+			v.bhIndex.skip(offset + int64(blockSize))
+		}
+		offset += int64(blockSize)
+	}
+}
+
+func (v *blocksVerifier) verifyAndIndex(block *protocol.BlockPairContainer, nextOffset int64) error {
+	if err := v.bhIndex.validateCandidateBlockHeight(getBlockHeight(block)); err != nil {
+		return errors.Wrap(err, "unexpected block height")
+	}
+	if err := v.links.verify(block); err != nil {
+		return err
+	}
+	if v.verifyBlock != nil {
+		if err := v.verifyBlock(block); err != nil {
+			return err
+		}
+	}
+	if err := v.bhIndex.appendBlock(nextOffset, block, nil); err != nil {
+		return errors.Wrap(err, "unexpected block height")
+	}
+	v.links.add(block)
+	return nil
+}
+
+type blockHashes struct {
+	transactionsBlock primitives.Sha256
+	resultsBlock      primitives.Sha256
+}
+
+// chainLinks verifies the prev block hash pointers of blocks read in any order. it only remembers the hashes of blocks whose
+// next block was not read yet, and the pointers of blocks whose previous block was not read yet, so memory is not proportional
+// to the number of blocks
+type chainLinks struct {
+	prunedHeight primitives.BlockHeight
+	hashes       map[primitives.BlockHeight]blockHashes
+	prevPtrs     map[primitives.BlockHeight]blockHashes
+}
+
+func newChainLinks(prunedHeight primitives.BlockHeight) *chainLinks {
+	return &chainLinks{
+		prunedHeight: prunedHeight,
+		hashes:       map[primitives.BlockHeight]blockHashes{},
+		prevPtrs:     map[primitives.BlockHeight]blockHashes{},
+	}
+}
+
+func (c *chainLinks) verify(block *protocol.BlockPairContainer) error {
+	height := getBlockHeight(block)
+	if prev, ok := c.hashes[height-1]; ok && !linked(prev, prevPtrsOf(block)) {
+		return fmt.Errorf("prev block hash pointers of block %d do not match block %d", height, height-1)
+	}
+	if next, ok := c.prevPtrs[height+1]; ok && !linked(hashesOf(block), next) {
+		return fmt.Errorf("prev block hash pointers of block %d do not match block %d", height+1, height)
+	}
+	return nil
+}
+
+func (c *chainLinks) add(block *protocol.BlockPairContainer) {
+	height := getBlockHeight(block)
+	if _, ok := c.hashes[height-1]; ok {
+		delete(c.hashes, height-1)
+	} else if height-1 > c.prunedHeight { // the first block and the block following the pruned ones point to blocks which cannot be read
+		c.prevPtrs[height] = prevPtrsOf(block)
+	}
+	if _, ok := c.prevPtrs[height+1]; ok {
+		delete(c.prevPtrs, height+1)
+	} else {
+		c.hashes[height] = hashesOf(block)
+	}
+}
+
+func hashesOf(block *protocol.BlockPairContainer) blockHashes {
+	return blockHashes{
+		transactionsBlock: digest.CalcTransactionsBlockHash(block.TransactionsBlock),
+		resultsBlock:      digest.CalcResultsBlockHash(block.ResultsBlock),
+	}
+}
+
+func prevPtrsOf(block *protocol.BlockPairContainer) blockHashes {
+	return blockHashes{
+		transactionsBlock: block.TransactionsBlock.Header.PrevBlockHashPtr(),
+		resultsBlock:      block.ResultsBlock.Header.PrevBlockHashPtr(),
+	}
+}
+
+func linked(prev blockHashes, next blockHashes) bool {
+	return prev.transactionsBlock.Equal(next.transactionsBlock) && prev.resultsBlock.Equal(next.resultsBlock)
+}
+
+func copyFile(from string, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
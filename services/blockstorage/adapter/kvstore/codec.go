@@ -0,0 +1,241 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package kvstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/membuffers/go"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"io"
+)
+
+const blocksFormatMagic = uint32(0x564b4c42) // "BLKV"
+const blocksFormatVersion = 0
+
+const blockKeyPrefix = byte('b')
+const txKeyPrefix = byte('t')
+const timestampKeyPrefix = byte('c')
+
+var headerKey = []byte("h")
+var metadataKey = []byte("m")
+
+type blocksDbHeader struct {
+	Magic         uint32
+	FormatVersion uint32
+	NetworkType   uint32
+	ChainId       uint32
+}
+
+func newBlocksDbHeader(networkType, vchainId uint32) *blocksDbHeader {
+	return &blocksDbHeader{
+		Magic:         blocksFormatMagic,
+		FormatVersion: blocksFormatVersion,
+		NetworkType:   networkType,
+		ChainId:       vchainId,
+	}
+}
+
+func (h *blocksDbHeader) encode() []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, h) // writing to a bytes.Buffer never fails
+	return buf.Bytes()
+}
+
+func (h *blocksDbHeader) decode(raw []byte) error {
+	err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, h)
+	if err != nil {
+		return err
+	}
+
+	if h.Magic != blocksFormatMagic {
+		return fmt.Errorf("invalid blocks database magic number %v", h.Magic)
+	}
+
+	if h.FormatVersion != blocksFormatVersion {
+		return fmt.Errorf("invalid blocks database version %d", h.FormatVersion)
+	}
+
+	return nil
+}
+
+// blocksMetadata is written in the same batch as every block, heights above the in order height are sync session leftovers
+type blocksMetadata struct {
+	InOrderHeight     uint64
+	LastWrittenHeight uint64
+}
+
+func (m *blocksMetadata) encode() []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, m)
+	return buf.Bytes()
+}
+
+func (m *blocksMetadata) decode(raw []byte) error {
+	return errors.Wrap(binary.Read(bytes.NewReader(raw), binary.LittleEndian, m), "failed to decode blocks metadata")
+}
+
+type txLocation struct {
+	height primitives.BlockHeight
+	index  int
+}
+
+func (l txLocation) encode() []byte {
+	raw := make([]byte, 12)
+	binary.BigEndian.PutUint64(raw[:8], uint64(l.height))
+	binary.BigEndian.PutUint32(raw[8:], uint32(l.index))
+	return raw
+}
+
+func decodeTxLocation(raw []byte) (txLocation, error) {
+	if len(raw) != 12 {
+		return txLocation{}, fmt.Errorf("invalid tx location %x", raw)
+	}
+	return txLocation{
+		height: primitives.BlockHeight(binary.BigEndian.Uint64(raw[:8])),
+		index:  int(binary.BigEndian.Uint32(raw[8:])),
+	}, nil
+}
+
+// heights are big endian so blocks are iterated in height order
+func blockKey(height primitives.BlockHeight) []byte {
+	key := make([]byte, 9)
+	key[0] = blockKeyPrefix
+	binary.BigEndian.PutUint64(key[1:], uint64(height))
+	return key
+}
+
+func parseBlockKey(key []byte) (primitives.BlockHeight, error) {
+	if len(key) != 9 || key[0] != blockKeyPrefix {
+		return 0, fmt.Errorf("invalid block key %x", key)
+	}
+	return primitives.BlockHeight(binary.BigEndian.Uint64(key[1:])), nil
+}
+
+func txKey(txHash primitives.Sha256) []byte {
+	return append([]byte{txKeyPrefix}, txHash...)
+}
+
+// the height follows the timestamp so blocks sharing a timestamp do not overwrite each other
+func timestampKey(ts primitives.TimestampNano, height primitives.BlockHeight) []byte {
+	key := make([]byte, 17)
+	key[0] = timestampKeyPrefix
+	binary.BigEndian.PutUint64(key[1:9], uint64(ts))
+	binary.BigEndian.PutUint64(key[9:], uint64(height))
+	return key
+}
+
+func parseTimestampKey(key []byte) (primitives.TimestampNano, primitives.BlockHeight, error) {
+	if len(key) != 17 || key[0] != timestampKeyPrefix {
+		return 0, 0, fmt.Errorf("invalid timestamp key %x", key)
+	}
+	return primitives.TimestampNano(binary.BigEndian.Uint64(key[1:9])), primitives.BlockHeight(binary.BigEndian.Uint64(key[9:])), nil
+}
+
+type blockCounts struct {
+	Transactions uint32
+	Receipts     uint32
+	Diffs        uint32
+}
+
+// a block record holds the number of transactions, receipts and diffs followed by the raw messages of the block pair
+func encodeBlock(block *protocol.BlockPairContainer) []byte {
+	tb := block.TransactionsBlock
+	rb := block.ResultsBlock
+
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, &blockCounts{
+		Transactions: uint32(len(tb.SignedTransactions)),
+		Receipts:     uint32(len(rb.TransactionReceipts)),
+		Diffs:        uint32(len(rb.ContractStateDiffs)),
+	})
+
+	writeMessage(buf, tb.Header)
+	writeMessage(buf, tb.Metadata)
+	writeMessage(buf, tb.BlockProof)
+	writeMessage(buf, rb.Header)
+	writeMessage(buf, rb.BlockProof)
+	for _, tx := range tb.SignedTransactions {
+		writeMessage(buf, tx)
+	}
+	for _, receipt := range rb.TransactionReceipts {
+		writeMessage(buf, receipt)
+	}
+	for _, diff := range rb.ContractStateDiffs {
+		writeMessage(buf, diff)
+	}
+	return buf.Bytes()
+}
+
+func decodeBlock(raw []byte) (*protocol.BlockPairContainer, error) {
+	r := bytes.NewReader(raw)
+	counts := &blockCounts{}
+	if err := binary.Read(r, binary.LittleEndian, counts); err != nil {
+		return nil, errors.Wrap(err, "failed to decode block record")
+	}
+
+	chunks, err := readChunks(r, 5+int(counts.Transactions)+int(counts.Receipts)+int(counts.Diffs))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode block record")
+	}
+
+	txs := make([]*protocol.SignedTransaction, 0, counts.Transactions)
+	for _, chunk := range chunks[5 : 5+counts.Transactions] {
+		txs = append(txs, protocol.SignedTransactionReader(chunk))
+	}
+	receipts := make([]*protocol.TransactionReceipt, 0, counts.Receipts)
+	for _, chunk := range chunks[5+counts.Transactions : 5+counts.Transactions+counts.Receipts] {
+		receipts = append(receipts, protocol.TransactionReceiptReader(chunk))
+	}
+	diffs := make([]*protocol.ContractStateDiff, 0, counts.Diffs)
+	for _, chunk := range chunks[5+counts.Transactions+counts.Receipts:] {
+		diffs = append(diffs, protocol.ContractStateDiffReader(chunk))
+	}
+
+	return &protocol.BlockPairContainer{
+		TransactionsBlock: &protocol.TransactionsBlockContainer{
+			Header:             protocol.TransactionsBlockHeaderReader(chunks[0]),
+			Metadata:           protocol.TransactionsBlockMetadataReader(chunks[1]),
+			BlockProof:         protocol.TransactionsBlockProofReader(chunks[2]),
+			SignedTransactions: txs,
+		},
+		ResultsBlock: &protocol.ResultsBlockContainer{
+			Header:              protocol.ResultsBlockHeaderReader(chunks[3]),
+			BlockProof:          protocol.ResultsBlockProofReader(chunks[4]),
+			TransactionReceipts: receipts,
+			ContractStateDiffs:  diffs,
+		},
+	}, nil
+}
+
+func writeMessage(buf *bytes.Buffer, message membuffers.Message) {
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(message.Raw())))
+	buf.Write(message.Raw())
+}
+
+func readChunks(r *bytes.Reader, count int) ([][]byte, error) {
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, err
+		}
+		if int64(size) > int64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		chunk := make([]byte, size)
+		_, _ = io.ReadFull(r, chunk) // the length was checked above
+		chunks = append(chunks, chunk)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d unexpected bytes following block record", r.Len())
+	}
+	return chunks, nil
+}
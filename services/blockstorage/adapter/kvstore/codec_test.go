@@ -0,0 +1,45 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package kvstore
+
+import (
+	"bytes"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCodec_EncodesAndDecodesBlocks(t *testing.T) {
+	block := builders.RandomizedBlock(1, rand.NewControlledRand(t), nil)
+
+	decodedBlock, err := decodeBlock(encodeBlock(block))
+	require.NoError(t, err)
+	test.RequireCmpEqual(t, block, decodedBlock, "expected to decode an identical block as encoded")
+}
+
+func TestCodec_RejectsTruncatedBlockRecords(t *testing.T) {
+	block := builders.BlockPair().WithHeight(1).WithTransactions(3).WithReceiptsForTransactions().Build()
+	record := encodeBlock(block)
+
+	_, err := decodeBlock(record[:len(record)-1])
+	require.Error(t, err, "expected to fail decoding a truncated block record")
+
+	_, err = decodeBlock(append(record, 0))
+	require.Error(t, err, "expected to fail decoding a block record followed by unexpected bytes")
+}
+
+func TestCodec_TimestampKeysSortByTimestampAndHeight(t *testing.T) {
+	require.True(t, bytes.Compare(timestampKey(1, 9), timestampKey(2, 1)) < 0)
+	require.True(t, bytes.Compare(timestampKey(2, 1), timestampKey(2, 2)) < 0)
+
+	ts, height, err := parseTimestampKey(timestampKey(1000, 7))
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, ts)
+	require.EqualValues(t, 7, height)
+}
@@ -0,0 +1,445 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package kvstore
+
+import (
+	"context"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/internodesync"
+	"github.com/orbs-network/orbs-network-go/synchronization"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const blocksDirname = "blocks-kv"
+
+type metrics struct {
+	sizeOnDisk          *metric.Gauge
+	indexLastUpdateTime *metric.Gauge
+}
+
+func newMetrics(m metric.Factory) *metrics {
+	return &metrics{
+		sizeOnDisk:          m.NewGauge("BlockStorage.KeyValueStoreSize.Bytes"),
+		indexLastUpdateTime: m.NewGaugeWithValue("BlockStorage.KeyValueStoreIndex.LastUpdateTime", time.Now().Unix()),
+	}
+}
+
+// BlockPersistence keeps blocks by height in an embedded key-value store, together with the location of every
+// transaction receipt by tx hash and a block timestamp index. Each block is written as a single synced batch with
+// its indexes and the sync state metadata, so a crash never leaves a block without its indexes
+type BlockPersistence struct {
+	config       config.KeyValueStoreBlockPersistenceConfig
+	logger       log.Logger
+	metrics      *metrics
+	blockTracker *synchronization.BlockTracker
+	db           *leveldb.DB
+
+	mutex              sync.RWMutex
+	sequentialTopBlock *protocol.BlockPairContainer
+	topBlock           *protocol.BlockPairContainer
+	lastWrittenBlock   *protocol.BlockPairContainer
+}
+
+func NewBlockPersistence(conf config.KeyValueStoreBlockPersistenceConfig, parent log.Logger, metricFactory metric.Factory) (*BlockPersistence, error) {
+	logger := parent.WithTags(log.String("adapter", "block-storage"))
+
+	db, err := openBlocksDb(conf, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	bp := &BlockPersistence{
+		config:  conf,
+		logger:  logger,
+		metrics: newMetrics(metricFactory),
+		db:      db,
+	}
+
+	if err := bp.loadSyncState(); err != nil {
+		closeSilently(db, logger)
+		return nil, err
+	}
+	bp.blockTracker = synchronization.NewBlockTracker(logger, uint64(getBlockHeight(bp.sequentialTopBlock)), 5)
+	bp.reportSize()
+
+	logger.Info("loaded blocks", logfields.BlockHeight(getBlockHeight(bp.sequentialTopBlock)), log.String("dirname", blocksDirName(conf)))
+	return bp, nil
+}
+
+func openBlocksDb(conf config.KeyValueStoreBlockPersistenceConfig, logger log.Logger) (*leveldb.DB, error) {
+	dir := conf.BlockStorageFileSystemDataDir()
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to verify data directory exists %s", dir)
+	}
+
+	dirname := blocksDirName(conf)
+	db, err := leveldb.OpenFile(dirname, nil) // leveldb holds an exclusive lock on the directory while open
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open blocks database %s", dirname)
+	}
+
+	err = validateHeader(db, conf, logger)
+	if err != nil {
+		closeSilently(db, logger)
+		return nil, errors.Wrapf(err, "failed to validate blocks database %s", dirname)
+	}
+
+	return db, nil
+}
+
+func validateHeader(db *leveldb.DB, conf config.KeyValueStoreBlockPersistenceConfig, logger log.Logger) error {
+	raw, err := db.Get(headerKey, nil)
+	if err == leveldb.ErrNotFound {
+		logger.Info("creating new blocks database", log.String("dirname", blocksDirName(conf)))
+		header := newBlocksDbHeader(uint32(conf.NetworkType()), uint32(conf.VirtualChainId()))
+		return db.Put(headerKey, header.encode(), &opt.WriteOptions{Sync: true})
+	}
+	if err != nil {
+		return errors.Wrap(err, "error reading blocks header")
+	}
+
+	header := &blocksDbHeader{}
+	if err := header.decode(raw); err != nil {
+		return errors.Wrap(err, "error reading blocks header")
+	}
+
+	if header.NetworkType != uint32(conf.NetworkType()) {
+		return fmt.Errorf("blocks network type mismatch. found network type %d expected %d", header.NetworkType, conf.NetworkType())
+	}
+
+	if header.ChainId != uint32(conf.VirtualChainId()) {
+		return fmt.Errorf("blocks virtual chain id mismatch. found vchain id %d expected %d", header.ChainId, conf.VirtualChainId())
+	}
+
+	return nil
+}
+
+// the top block is the highest block in the store, it is above the in order block while a sync session is in progress
+func (bp *BlockPersistence) loadSyncState() error {
+	metadata, err := bp.readMetadata()
+	if err != nil {
+		return err
+	}
+
+	iter := bp.db.NewIterator(util.BytesPrefix([]byte{blockKeyPrefix}), nil)
+	defer iter.Release()
+	topHeight := primitives.BlockHeight(0)
+	if iter.Last() {
+		if topHeight, err = parseBlockKey(iter.Key()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "failed to iterate blocks")
+	}
+
+	if bp.sequentialTopBlock, err = bp.readBlockIfAny(primitives.BlockHeight(metadata.InOrderHeight)); err != nil {
+		return err
+	}
+	if bp.lastWrittenBlock, err = bp.readBlockIfAny(primitives.BlockHeight(metadata.LastWrittenHeight)); err != nil {
+		return err
+	}
+	if bp.topBlock, err = bp.readBlockIfAny(topHeight); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (bp *BlockPersistence) readMetadata() (*blocksMetadata, error) {
+	metadata := &blocksMetadata{}
+	raw, err := bp.db.Get(metadataKey, nil)
+	if err == leveldb.ErrNotFound {
+		return metadata, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading blocks metadata")
+	}
+	if err := metadata.decode(raw); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func (bp *BlockPersistence) readBlockIfAny(height primitives.BlockHeight) (*protocol.BlockPairContainer, error) {
+	if height == 0 {
+		return nil, nil
+	}
+	return bp.GetBlock(height)
+}
+
+// the size of the store is approximate, it excludes blocks which were not compacted to disk yet
+func (bp *BlockPersistence) reportSize() {
+	sizes, err := bp.db.SizeOf([]util.Range{{Start: []byte{0}, Limit: []byte{0xff}}})
+	if err != nil {
+		bp.logger.Info("failed to measure blocks database size", log.Error(err))
+		return
+	}
+	bp.metrics.sizeOnDisk.Update(sizes.Sum())
+}
+
+func getBlockHeight(block *protocol.BlockPairContainer) primitives.BlockHeight {
+	if block == nil {
+		return 0
+	}
+	return block.TransactionsBlock.Header.BlockHeight()
+}
+
+func (bp *BlockPersistence) GetSyncState() internodesync.SyncState {
+	bp.mutex.RLock()
+	defer bp.mutex.RUnlock()
+
+	return internodesync.SyncState{
+		TopBlock:        bp.topBlock,
+		InOrderBlock:    bp.sequentialTopBlock,
+		LastSyncedBlock: bp.lastWrittenBlock,
+	}
+}
+
+func (bp *BlockPersistence) GetBlockTracker() *synchronization.BlockTracker {
+	return bp.blockTracker
+}
+
+func (bp *BlockPersistence) WriteNextBlock(blockPair *protocol.BlockPairContainer) (bool, primitives.BlockHeight, error) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+
+	newBlockHeight := getBlockHeight(blockPair)
+	sequentialHeight := getBlockHeight(bp.sequentialTopBlock)
+	lastWrittenHeight := getBlockHeight(bp.lastWrittenBlock)
+	topHeight := getBlockHeight(bp.topBlock)
+
+	if lastWrittenHeight > sequentialHeight && newBlockHeight != lastWrittenHeight-1 {
+		bp.logger.Info(fmt.Sprintf("sync session in progress, expected block height %d; candidate block height %d", lastWrittenHeight-1, newBlockHeight))
+		return false, sequentialHeight, nil
+	} else if sequentialHeight == topHeight && newBlockHeight <= sequentialHeight {
+		bp.logger.Info(fmt.Sprintf("expected block height higher than current top %d; candidate block height %d", sequentialHeight, newBlockHeight))
+		return false, sequentialHeight, nil
+	}
+
+	topBlock := bp.topBlock
+	if newBlockHeight > topHeight {
+		topBlock = blockPair
+	}
+	lastWrittenBlock := blockPair
+	sequentialTopBlock := bp.sequentialTopBlock
+	if newBlockHeight == sequentialHeight+1 { // the gap is closed, blocks between the new block and the top block were written by the sync session
+		lastWrittenBlock = topBlock
+		sequentialTopBlock = topBlock
+	}
+
+	record := encodeBlock(blockPair)
+	batch := &leveldb.Batch{}
+	batch.Put(blockKey(newBlockHeight), record)
+	batch.Put(timestampKey(blockPair.ResultsBlock.Header.Timestamp(), newBlockHeight), []byte{})
+	for i, receipt := range blockPair.ResultsBlock.TransactionReceipts {
+		batch.Put(txKey(receipt.Txhash()), txLocation{height: newBlockHeight, index: i}.encode())
+	}
+	metadata := &blocksMetadata{
+		InOrderHeight:     uint64(getBlockHeight(sequentialTopBlock)),
+		LastWrittenHeight: uint64(getBlockHeight(lastWrittenBlock)),
+	}
+	batch.Put(metadataKey, metadata.encode())
+
+	if err := bp.db.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return false, sequentialHeight, errors.Wrapf(err, "failed to write block height %d", newBlockHeight)
+	}
+
+	bp.topBlock = topBlock
+	bp.lastWrittenBlock = lastWrittenBlock
+	bp.sequentialTopBlock = sequentialTopBlock
+	for height := sequentialHeight + 1; height <= getBlockHeight(sequentialTopBlock); height++ {
+		bp.blockTracker.IncrementTo(height)
+	}
+
+	bp.metrics.indexLastUpdateTime.Update(time.Now().Unix())
+	bp.metrics.sizeOnDisk.Add(int64(len(record)))
+	return true, getBlockHeight(sequentialTopBlock), nil
+}
+
+// supports the range of blocks up to the sequential top block
+func (bp *BlockPersistence) ScanBlocks(from primitives.BlockHeight, pageSize uint8, cursor adapter.CursorFunc) error {
+	sequentialHeight, _ := bp.GetLastBlockHeight()
+	if (sequentialHeight < from) || from == 0 {
+		return fmt.Errorf("requested unsupported block height %d. Supported range for scan is determined by sequence top height (%d)", from, sequentialHeight)
+	}
+
+	fromHeight := from
+	wantsMore := true
+	for fromHeight <= sequentialHeight && wantsMore {
+		toHeight := fromHeight + primitives.BlockHeight(pageSize) - 1
+		if toHeight > sequentialHeight {
+			toHeight = sequentialHeight
+		}
+		page, err := bp.readBlocks(fromHeight, toHeight)
+		if err != nil {
+			return err
+		}
+		if len(page) > 0 {
+			wantsMore = cursor(fromHeight, page)
+		}
+		sequentialHeight, _ = bp.GetLastBlockHeight()
+		fromHeight = toHeight + 1
+	}
+	return nil
+}
+
+func (bp *BlockPersistence) readBlocks(from primitives.BlockHeight, to primitives.BlockHeight) ([]*protocol.BlockPairContainer, error) {
+	iter := bp.db.NewIterator(&util.Range{Start: blockKey(from), Limit: blockKey(to + 1)}, nil)
+	defer iter.Release()
+
+	page := make([]*protocol.BlockPairContainer, 0, to-from+1)
+	for iter.Next() {
+		block, err := decodeBlock(iter.Value())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode block height %d", from+primitives.BlockHeight(len(page)))
+		}
+		page = append(page, block)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate blocks")
+	}
+	if len(page) != int(to-from+1) {
+		return nil, fmt.Errorf("expected blocks %d to %d to be stored, found %d blocks", from, to, len(page))
+	}
+	return page, nil
+}
+
+func (bp *BlockPersistence) GetLastBlockHeight() (primitives.BlockHeight, error) {
+	bp.mutex.RLock()
+	defer bp.mutex.RUnlock()
+
+	return getBlockHeight(bp.sequentialTopBlock), nil
+}
+
+func (bp *BlockPersistence) GetLastBlock() (*protocol.BlockPairContainer, error) {
+	bp.mutex.RLock()
+	defer bp.mutex.RUnlock()
+
+	return bp.sequentialTopBlock, nil
+}
+
+func (bp *BlockPersistence) GetBlock(height primitives.BlockHeight) (*protocol.BlockPairContainer, error) {
+	raw, err := bp.db.Get(blockKey(height), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, errors.Errorf("block with height %d not found in block persistence", height)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read block height %d", height)
+	}
+	block, err := decodeBlock(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode block height %d", height)
+	}
+	return block, nil
+}
+
+func (bp *BlockPersistence) GetTransactionsBlock(height primitives.BlockHeight) (*protocol.TransactionsBlockContainer, error) {
+	blockPair, err := bp.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	return blockPair.TransactionsBlock, nil
+}
+
+func (bp *BlockPersistence) GetResultsBlock(height primitives.BlockHeight) (*protocol.ResultsBlockContainer, error) {
+	blockPair, err := bp.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	return blockPair.ResultsBlock, nil
+}
+
+// the block holding the receipt is only read when the timestamp index places it within the requested range
+func (bp *BlockPersistence) GetBlockByTx(txHash primitives.Sha256, minBlockTs primitives.TimestampNano, maxBlockTs primitives.TimestampNano) (block *protocol.BlockPairContainer, txIndexInBlock int, err error) {
+	raw, err := bp.db.Get(txKey(txHash), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to read tx location")
+	}
+	location, err := decodeTxLocation(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if lastHeight, _ := bp.GetLastBlockHeight(); location.height > lastHeight { // ignores blocks which are not fully synced
+		return nil, 0, nil
+	}
+
+	inRange, err := bp.isBlockInTimestampRange(location.height, minBlockTs, maxBlockTs)
+	if err != nil || !inRange {
+		return nil, 0, err
+	}
+
+	block, err = bp.GetBlock(location.height)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to fetch block by txHash")
+	}
+	return block, location.index, nil
+}
+
+func (bp *BlockPersistence) isBlockInTimestampRange(height primitives.BlockHeight, minBlockTs primitives.TimestampNano, maxBlockTs primitives.TimestampNano) (bool, error) {
+	if maxBlockTs < minBlockTs {
+		return false, nil
+	}
+	limit := timestampKey(maxBlockTs+1, 0)
+	if maxBlockTs+1 == 0 { // the maximal timestamp wraps around
+		limit = []byte{timestampKeyPrefix + 1}
+	}
+
+	iter := bp.db.NewIterator(&util.Range{Start: timestampKey(minBlockTs, 0), Limit: limit}, nil)
+	defer iter.Release()
+	for iter.Next() {
+		_, h, err := parseTimestampKey(iter.Key())
+		if err != nil {
+			return false, err
+		}
+		if h == height {
+			return true, nil
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return false, errors.Wrap(err, "failed to iterate timestamp index")
+	}
+	return false, nil
+}
+
+func (bp *BlockPersistence) GracefulShutdown(shutdownContext context.Context) {
+	logger := bp.logger.WithTags(log.String("dirname", blocksDirName(bp.config)))
+	if err := bp.db.Close(); err != nil {
+		logger.Error("failed to close blocks database", log.Error(err))
+		return
+	}
+	logger.Info("closed blocks database")
+}
+
+func blocksDirName(conf config.KeyValueStoreBlockPersistenceConfig) string {
+	return filepath.Join(conf.BlockStorageFileSystemDataDir(), blocksDirname)
+}
+
+func closeSilently(db *leveldb.DB, logger log.Logger) {
+	err := db.Close()
+	if err != nil {
+		logger.Error("failed to close blocks database", log.Error(err))
+	}
+}
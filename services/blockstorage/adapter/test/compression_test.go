@@ -0,0 +1,88 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"encoding/binary"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSystemBlockPersistence_WritesCompressedBlocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		blocks := aChainOfBlocksWithTransactions(5)
+
+		uncompressedConf := newTempFileConfig()
+		defer uncompressedConf.cleanDir()
+		writeBlocksRecordingFileSizes(t, harness, uncompressedConf, blocks)
+
+		conf := newTempFileConfig()
+		conf.compress = true
+		defer conf.cleanDir()
+		writeBlocksRecordingFileSizes(t, harness, conf, blocks)
+
+		require.Less(t, getFileSize(t, conf), getFileSize(t, uncompressedConf), "expected compressed blocks file to be smaller")
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+func TestFileSystemBlockPersistence_KeepsFileVersionOfExistingSegments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		conf.maxBlocksPerSegment = 3
+		defer conf.cleanDir()
+
+		blocks := builders.RandomizedBlockChain(8, rand.NewControlledRand(t))
+		writeBlocksRecordingFileSizes(t, harness, conf, blocks[:4])
+
+		conf.compress = true
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		requireBlocksReadable(t, fsa, blocks[:4])
+		writeBlocks(t, fsa, blocks[4:])
+		closeAdapter()
+
+		require.EqualValues(t, 0, readFileVersion(t, conf, "blocks"))
+		require.EqualValues(t, 0, readFileVersion(t, conf, "blocks.000001"), "expected the segment being written to keep its file version")
+		require.EqualValues(t, 1, readFileVersion(t, conf, "blocks.000002"), "expected new segments to be compressed")
+
+		report, err := filesystem.VerifyBlocksFiles(conf, harness.Logger, nil)
+		require.NoError(t, err)
+		require.Nil(t, report.FirstInvalid, "expected blocks of both versions to be valid")
+		require.EqualValues(t, 8, report.LastBlockHeight)
+
+		fsa, closeAdapter, err = NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+// the file version follows the magic number in the blocks file header
+func readFileVersion(t *testing.T, conf *localConfig, filename string) uint32 {
+	data, err := ioutil.ReadFile(filepath.Join(conf.BlockStorageFileSystemDataDir(), filename))
+	require.NoError(t, err)
+	require.True(t, len(data) >= 8, "expected %s to hold a file header", filename)
+	return binary.LittleEndian.Uint32(data[4:8])
+}
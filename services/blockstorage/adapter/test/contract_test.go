@@ -12,6 +12,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/kvstore"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/memory"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
@@ -39,6 +40,14 @@ func withEachAdapter(t *testing.T, testFunc func(t *testing.T, adapter adapter.B
 		})
 	})
 
+	t.Run("Key-Value Store Persistence", func(t *testing.T) {
+		with.Logging(t, func(harness *with.LoggingHarness) {
+			adapter, cleanup := newKeyValueStoreAdapter(harness.Logger)
+			defer cleanup()
+			testFunc(t, adapter)
+		})
+	})
+
 	t.Run("In-Memory Persistence", func(t *testing.T) {
 		with.Logging(t, func(harness *with.LoggingHarness) {
 			testFunc(t, newInMemoryAdapter(harness.Logger))
@@ -324,3 +333,19 @@ func newFilesystemAdapter(logger log.Logger) (adapter.BlockPersistence, func())
 
 	return persistence, cleanup
 }
+
+func newKeyValueStoreAdapter(logger log.Logger) (adapter.BlockPersistence, func()) {
+	conf := newTempFileConfig()
+
+	persistence, err := kvstore.NewBlockPersistence(conf, logger, metric.NewRegistry())
+	if err != nil {
+		panic(err.Error())
+	}
+
+	cleanup := func() {
+		persistence.GracefulShutdown(context.Background())
+		_ = os.RemoveAll(conf.BlockStorageFileSystemDataDir()) // ignore errors - nothing to do
+	}
+
+	return persistence, cleanup
+}
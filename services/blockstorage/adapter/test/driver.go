@@ -12,6 +12,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/kvstore"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/rand"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -44,10 +45,30 @@ func NewFilesystemAdapterDriver(logger log.Logger, conf config.FilesystemBlockPe
 	return persistence, closeAdapter, nil
 }
 
+func NewKeyValueStoreAdapterDriver(logger log.Logger, conf config.KeyValueStoreBlockPersistenceConfig) (adapter.BlockPersistence, func(), error) {
+
+	persistence, err := kvstore.NewBlockPersistence(conf, logger, metric.NewRegistry())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeAdapter := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		persistence.GracefulShutdown(ctx)
+	}
+
+	return persistence, closeAdapter, nil
+}
+
 type localConfig struct {
-	dir         string
-	chainId     primitives.VirtualChainId
-	networkType protocol.SignerNetworkType
+	dir                 string
+	chainId             primitives.VirtualChainId
+	networkType         protocol.SignerNetworkType
+	maxBlocksPerSegment uint32
+	retainedBlocks      uint32
+	compress            bool
 }
 
 func newTempFileConfig() *localConfig {
@@ -69,6 +90,22 @@ func (l *localConfig) BlockStorageFileSystemMaxBlockSizeInBytes() uint32 {
 	return 64 * 1024 * 1024
 }
 
+func (l *localConfig) BlockStorageFileSystemMaxSegmentSizeInBytes() uint32 {
+	return 0
+}
+
+func (l *localConfig) BlockStorageFileSystemMaxBlocksPerSegment() uint32 {
+	return l.maxBlocksPerSegment
+}
+
+func (l *localConfig) BlockStorageFileSystemRetainedBlocks() uint32 {
+	return l.retainedBlocks
+}
+
+func (l *localConfig) BlockStorageFileSystemCompressionEnabled() bool {
+	return l.compress
+}
+
 func (l *localConfig) VirtualChainId() primitives.VirtualChainId {
 	return l.chainId
 }
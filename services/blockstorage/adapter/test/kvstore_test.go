@@ -0,0 +1,84 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKeyValueStoreBlockPersistence_RestoresSyncStateAfterRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+		blocks := aChainOfBlocksWithTransactions(6)
+
+		kva, closeAdapter, err := NewKeyValueStoreAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		writeBlocks(t, kva, blocks[:2])
+		writeBlocks(t, kva, []*protocol.BlockPairContainer{blocks[5], blocks[4]}) // a sync session writing blocks in descending order
+		closeAdapter()
+
+		kva, closeAdapter, err = NewKeyValueStoreAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+
+		syncState := kva.GetSyncState()
+		require.EqualValues(t, 2, syncState.InOrderBlock.TransactionsBlock.Header.BlockHeight())
+		require.EqualValues(t, 5, syncState.LastSyncedBlock.TransactionsBlock.Header.BlockHeight())
+		require.EqualValues(t, 6, syncState.TopBlock.TransactionsBlock.Header.BlockHeight())
+
+		tx := blocks[4].TransactionsBlock.SignedTransactions[0].Transaction()
+		ts := blocks[4].ResultsBlock.Header.Timestamp()
+		block, _, err := kva.GetBlockByTx(digest.CalcTxHash(tx), ts, ts)
+		require.NoError(t, err)
+		require.Nil(t, block, "expected blocks above the in order block not to be found by tx")
+
+		writeBlocks(t, kva, []*protocol.BlockPairContainer{blocks[3], blocks[2]})
+		closeAdapter()
+
+		kva, closeAdapter, err = NewKeyValueStoreAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		lastHeight, err := kva.GetLastBlockHeight()
+		require.NoError(t, err)
+		require.EqualValues(t, 6, lastHeight, "expected the closed gap to survive a restart")
+		requireBlocksReadable(t, kva, blocks)
+
+		block, txIndex, err := kva.GetBlockByTx(digest.CalcTxHash(tx), ts, ts)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, txIndex)
+		test.RequireCmpEqual(t, blocks[4], block)
+	})
+}
+
+func TestKeyValueStoreBlockPersistence_DetectsVirtualChainMismatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		kva, closeAdapter, err := NewKeyValueStoreAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		writeBlocks(t, kva, aChainOfBlocksWithTransactions(1))
+		closeAdapter()
+
+		conf.setVirtualChainId(conf.VirtualChainId() + 1)
+
+		_, _, err = NewKeyValueStoreAdapterDriver(harness.Logger, conf)
+		require.Error(t, err, "expected error when trying to open a blocks database from a different virtual chain")
+	})
+}
@@ -21,11 +21,11 @@ import (
 // the blocks generated here do not go through consensus. they simulate some state diffs, tx and receipts, but will
 // may not be successfully validated as blocks under consensus
 func main() {
-	dir, virtualChain, targetHeight, randomizeEach := parseParams()
+	dir, virtualChain, targetHeight, randomizeEach, compress := parseParams()
 
 	logger := adHocLogger("")
 	rand := testUtils.NewControlledRand(&logger)
-	conf := &randomChainConfig{dir: dir, virtualChainId: virtualChain}
+	conf := &randomChainConfig{dir: dir, virtualChainId: virtualChain, compress: compress}
 
 	start := time.Now()
 	fmt.Printf("\nusing:\noutput directory: %s\nvirtual chain id: %d\n\nloading adapter and building index...\n", conf.BlockStorageFileSystemDataDir(), conf.VirtualChainId())
@@ -74,20 +74,22 @@ func main() {
 	fmt.Printf("\n\nblocks file in %s/ now has %d blocks\n\n", conf.BlockStorageFileSystemDataDir(), currentHeight)
 }
 
-func parseParams() (dir string, vchain primitives.VirtualChainId, height primitives.BlockHeight, randomEach bool) {
+func parseParams() (dir string, vchain primitives.VirtualChainId, height primitives.BlockHeight, randomEach bool, compress bool) {
 	intHeight := flag.Uint64("height", 100, "target height for blocks file")
 	outputDir := flag.String("output", "./gen_data", "target directory for new block file")
 	virtualChain := flag.Uint("vchain", 42, "blocks file virtual chain id")
 	rand := flag.Bool("full_random", false, "generate a different random block for each block height")
+	compressed := flag.Bool("compressed", false, "write compressed blocks to new blocks files")
 	flag.Parse()
-	fmt.Printf("usage: [-output output_folder_name] [-height target_block_height] [-vchain vchain_id] [-full_random]\n\n")
+	fmt.Printf("usage: [-output output_folder_name] [-height target_block_height] [-vchain vchain_id] [-full_random] [-compressed]\n\n")
 	targetHeight := primitives.BlockHeight(*intHeight)
-	return *outputDir, primitives.VirtualChainId(*virtualChain), targetHeight, *rand
+	return *outputDir, primitives.VirtualChainId(*virtualChain), targetHeight, *rand, *compressed
 }
 
 type randomChainConfig struct {
 	dir            string
 	virtualChainId primitives.VirtualChainId
+	compress       bool
 }
 
 func (l *randomChainConfig) VirtualChainId() primitives.VirtualChainId {
@@ -102,6 +104,22 @@ func (l *randomChainConfig) BlockStorageFileSystemMaxBlockSizeInBytes() uint32 {
 	return 1000000000
 }
 
+func (l *randomChainConfig) BlockStorageFileSystemMaxSegmentSizeInBytes() uint32 {
+	return 0
+}
+
+func (l *randomChainConfig) BlockStorageFileSystemMaxBlocksPerSegment() uint32 {
+	return 0
+}
+
+func (l *randomChainConfig) BlockStorageFileSystemRetainedBlocks() uint32 {
+	return 0
+}
+
+func (l *randomChainConfig) BlockStorageFileSystemCompressionEnabled() bool {
+	return l.compress
+}
+
 type adHocLogger string
 
 func (l *adHocLogger) Log(args ...interface{}) {
@@ -47,3 +47,19 @@ func (l *localConfig) BlockStorageFileSystemDataDir() string {
 func (l *localConfig) BlockStorageFileSystemMaxBlockSizeInBytes() uint32 {
 	return 1000000000
 }
+
+func (l *localConfig) BlockStorageFileSystemMaxSegmentSizeInBytes() uint32 {
+	return 0
+}
+
+func (l *localConfig) BlockStorageFileSystemMaxBlocksPerSegment() uint32 {
+	return 0
+}
+
+func (l *localConfig) BlockStorageFileSystemRetainedBlocks() uint32 {
+	return 0
+}
+
+func (l *localConfig) BlockStorageFileSystemCompressionEnabled() bool {
+	return false
+}
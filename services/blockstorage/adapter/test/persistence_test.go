@@ -134,6 +134,14 @@ func TestBlockPersistence_WriteLogic(t *testing.T) {
 		defer closeAdapter()
 		testBlockPersistenceWriteLogicWithAdapter(t, fsa, blocks)
 
+		kvConf := newTempFileConfig()
+		defer kvConf.cleanDir()
+
+		kva, closeKeyValueStoreAdapter, err := NewKeyValueStoreAdapterDriver(harness.Logger, kvConf)
+		require.NoError(t, err)
+		defer closeKeyValueStoreAdapter()
+		testBlockPersistenceWriteLogicWithAdapter(t, kva, blocks)
+
 		fsa = memory.NewBlockPersistence(harness.Logger, metric.NewRegistry())
 		testBlockPersistenceWriteLogicWithAdapter(t, fsa, blocks)
 	})
@@ -0,0 +1,187 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSystemBlockPersistence_RollsSegments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		conf.maxBlocksPerSegment = 3
+		defer conf.cleanDir()
+
+		blocks := aChainOfBlocksWithTransactions(11)
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		writeBlocks(t, fsa, blocks[:10])
+		closeAdapter()
+
+		for _, filename := range []string{"blocks", "blocks.000001", "blocks.000002", "blocks.000003"} {
+			require.FileExists(t, filepath.Join(conf.BlockStorageFileSystemDataDir(), filename))
+		}
+
+		fsa, closeAdapter, err = NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		lastHeight, err := fsa.GetLastBlockHeight()
+		require.NoError(t, err)
+		require.EqualValues(t, 10, lastHeight, "expected blocks of all segments to be indexed after restart")
+		requireBlocksReadable(t, fsa, blocks[:10])
+
+		writeBlocks(t, fsa, blocks[10:])
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+func TestFileSystemBlockPersistence_IndexesOutOfOrderBlocksAcrossSegments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		conf.maxBlocksPerSegment = 2
+		defer conf.cleanDir()
+
+		blocks := aChainOfBlocksWithTransactions(7)
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		writeBlocks(t, fsa, blocks[0:3])
+		writeBlocks(t, fsa, []*protocol.BlockPairContainer{blocks[6], blocks[5], blocks[4]}) // a sync session writing in descending order
+		closeAdapter()
+
+		fsa, closeAdapter, err = NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		writeBlocks(t, fsa, blocks[3:4])
+		lastHeight, err := fsa.GetLastBlockHeight()
+		require.NoError(t, err)
+		require.EqualValues(t, 7, lastHeight, "expected sync session to complete after restart")
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+func TestFileSystemBlockPersistence_PrunesSegmentsOlderThanRetainedBlocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		conf.maxBlocksPerSegment = 3
+		conf.retainedBlocks = 4
+		defer conf.cleanDir()
+
+		blocks := aChainOfBlocksWithTransactions(11)
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		writeBlocks(t, fsa, blocks[:10])
+
+		requirePruned(t, fsa, blocks, 6)
+		require.NoFileExists(t, filepath.Join(conf.BlockStorageFileSystemDataDir(), "blocks.000001"))
+		info, err := os.Stat(filepath.Join(conf.BlockStorageFileSystemDataDir(), "blocks"))
+		require.NoError(t, err, "expected the first segment to be kept as the data dir lock")
+		require.Zero(t, info.Size(), "expected the first segment to be emptied")
+		closeAdapter()
+
+		fsa, closeAdapter, err = NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		lastHeight, err := fsa.GetLastBlockHeight()
+		require.NoError(t, err)
+		require.EqualValues(t, 10, lastHeight, "expected pruning not to lower the last block height")
+		requirePruned(t, fsa, blocks, 6)
+
+		writeBlocks(t, fsa, blocks[10:])
+		requireBlocksReadable(t, fsa, blocks[6:])
+	})
+}
+
+func TestFileSystemBlockPersistence_DoesNotPruneWithoutRetentionPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		conf.maxBlocksPerSegment = 1
+		defer conf.cleanDir()
+
+		blocks := aChainOfBlocksWithTransactions(5)
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		writeBlocks(t, fsa, blocks)
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+func aChainOfBlocksWithTransactions(numBlocks int) []*protocol.BlockPairContainer {
+	blocks := make([]*protocol.BlockPairContainer, 0, numBlocks)
+	for h := 1; h <= numBlocks; h++ {
+		blocks = append(blocks, builders.BlockPair().WithHeight(primitives.BlockHeight(h)).WithTransactions(2).WithReceiptsForTransactions().Build())
+	}
+	return blocks
+}
+
+func writeBlocks(t *testing.T, fsa adapter.BlockPersistence, blocks []*protocol.BlockPairContainer) {
+	for _, block := range blocks {
+		added, _, err := fsa.WriteNextBlock(block)
+		require.NoError(t, err)
+		require.True(t, added, "expected block %d to be written", block.ResultsBlock.Header.BlockHeight())
+	}
+}
+
+func requireBlocksReadable(t *testing.T, fsa adapter.BlockPersistence, blocks []*protocol.BlockPairContainer) {
+	for _, block := range blocks {
+		height := block.ResultsBlock.Header.BlockHeight()
+		readBlock, err := fsa.GetBlock(height)
+		require.NoError(t, err, "expected block %d to be readable", height)
+		test.RequireCmpEqual(t, block, readBlock)
+	}
+
+	var scanned []*protocol.BlockPairContainer
+	err := fsa.ScanBlocks(blocks[0].ResultsBlock.Header.BlockHeight(), 4, func(first primitives.BlockHeight, page []*protocol.BlockPairContainer) bool {
+		scanned = append(scanned, page...)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, scanned, len(blocks), "expected blocks of all segments to be scanned")
+}
+
+func requirePruned(t *testing.T, fsa adapter.BlockPersistence, blocks []*protocol.BlockPairContainer, prunedHeight int) {
+	_, err := fsa.GetBlock(primitives.BlockHeight(prunedHeight))
+	require.Error(t, err, "expected block %d to be pruned", prunedHeight)
+	require.Error(t, fsa.ScanBlocks(1, 1, func(first primitives.BlockHeight, page []*protocol.BlockPairContainer) bool { return true }), "expected scanning pruned blocks to fail")
+
+	prunedTx := blocks[prunedHeight-1].TransactionsBlock.SignedTransactions[0].Transaction()
+	block, _, err := fsa.GetBlockByTx(digest.CalcTxHash(prunedTx), 0, blocks[prunedHeight-1].ResultsBlock.Header.Timestamp())
+	require.NoError(t, err)
+	require.Nil(t, block, "expected transactions of pruned blocks not to be found")
+
+	requireBlocksReadable(t, fsa, blocks[prunedHeight:10])
+}
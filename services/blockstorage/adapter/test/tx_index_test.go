@@ -0,0 +1,216 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const txIndexDirname = "txindex.db"
+
+func TestFileSystemBlockPersistence_TxIndexSurvivesRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := writeBlocksWithTransactionsToFile(t, harness.Logger, conf)
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		requireBlockByTx(t, fsa, blocks, 1, 4)
+		requireBlockByTx(t, fsa, blocks, 2, 0)
+	})
+}
+
+func TestFileSystemBlockPersistence_RebuildsMissingTxIndex(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := writeBlocksWithTransactionsToFile(t, harness.Logger, conf)
+		require.NoError(t, os.RemoveAll(filepath.Join(conf.BlockStorageFileSystemDataDir(), txIndexDirname)))
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		requireBlockByTx(t, fsa, blocks, 1, 4)
+	})
+}
+
+func TestFileSystemBlockPersistence_RebuildsTxIndexOfUnknownVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		harness.AllowErrorsMatching("tx index version is invalid")
+
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := writeBlocksWithTransactionsToFile(t, harness.Logger, conf)
+		db, err := leveldb.OpenFile(filepath.Join(conf.BlockStorageFileSystemDataDir(), txIndexDirname), nil)
+		require.NoError(t, err)
+		require.NoError(t, db.Put([]byte("version"), []byte{0xff, 0xff, 0xff, 0xff}, nil))
+		require.NoError(t, db.Close())
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		requireBlockByTx(t, fsa, blocks, 0, 1)
+		requireBlockByTx(t, fsa, blocks, 2, 0)
+	})
+}
+
+func TestFileSystemBlockPersistence_RebuildsTxIndexAheadOfBlocksFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		harness.AllowErrorsMatching("built index, found and ignoring invalid block records")
+
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := writeBlocksWithTransactionsToFile(t, harness.Logger, conf)
+		truncateFile(t, conf, getFileSize(t, conf)-1) // the last block is lost
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		requireBlockByTx(t, fsa, blocks, 1, 4)
+
+		lostTx := blocks[2].TransactionsBlock.SignedTransactions[0].Transaction()
+		block, _, err := fsa.GetBlockByTx(digest.CalcTxHash(lostTx), 0, blocks[2].ResultsBlock.Header.Timestamp())
+		require.NoError(t, err)
+		require.Nil(t, block, "expected transaction of a lost block not to be found")
+	})
+}
+
+func TestFileSystemBlockPersistence_IndexesBlocksAboveLastTxIndexRecord(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := []*protocol.BlockPairContainer{
+			builders.BlockPair().WithHeight(1).WithTransactions(3).WithReceiptsForTransactions().Build(),
+			builders.BlockPair().WithHeight(2).WithTransactions(5).WithReceiptsForTransactions().Build(),
+		}
+		writeBlocksToFile(t, harness.Logger, conf, blocks[:1])
+		txIndexDir := filepath.Join(conf.BlockStorageFileSystemDataDir(), txIndexDirname)
+		staleTxIndexDir := txIndexDir + ".stale"
+		copyDir(t, txIndexDir, staleTxIndexDir)
+
+		writeBlocksToFile(t, harness.Logger, conf, blocks[1:])
+		require.NoError(t, os.RemoveAll(txIndexDir))
+		require.NoError(t, os.Rename(staleTxIndexDir, txIndexDir), "the tx index lost the last block")
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		requireBlockByTx(t, fsa, blocks, 0, 2)
+		requireBlockByTx(t, fsa, blocks, 1, 4)
+	})
+}
+
+func TestFileSystemBlockPersistence_TxIndexOfBlocksSyncedOutOfOrderSurvivesRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := []*protocol.BlockPairContainer{
+			builders.BlockPair().WithHeight(1).WithTransactions(2).WithReceiptsForTransactions().Build(),
+			builders.BlockPair().WithHeight(2).WithTransactions(2).WithReceiptsForTransactions().Build(),
+			builders.BlockPair().WithHeight(3).WithTransactions(2).WithReceiptsForTransactions().Build(),
+		}
+		writeBlocksToFile(t, harness.Logger, conf, []*protocol.BlockPairContainer{blocks[0], blocks[2], blocks[1]}) // a sync session writes blocks top down
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		for blockIndex := range blocks {
+			requireBlockByTx(t, fsa, blocks, blockIndex, 1)
+		}
+	})
+}
+
+func writeBlocksWithTransactionsToFile(t *testing.T, logger log.Logger, conf *localConfig) []*protocol.BlockPairContainer {
+	blocks := []*protocol.BlockPairContainer{
+		builders.BlockPair().WithHeight(1).WithTransactions(3).WithReceiptsForTransactions().WithTimestampAheadBy(1 * time.Second).Build(),
+		builders.BlockPair().WithHeight(2).WithTransactions(5).WithReceiptsForTransactions().WithTimestampAheadBy(2 * time.Second).Build(),
+		builders.BlockPair().WithHeight(3).WithTransactions(1).WithReceiptsForTransactions().WithTimestampAheadBy(3 * time.Second).Build(),
+	}
+	writeBlocksToFile(t, logger, conf, blocks)
+	return blocks
+}
+
+func writeBlocksToFile(t *testing.T, logger log.Logger, conf *localConfig, blocks []*protocol.BlockPairContainer) {
+	fsa, closeAdapter, err := NewFilesystemAdapterDriver(logger, conf)
+	require.NoError(t, err)
+	defer closeAdapter()
+
+	for _, block := range blocks {
+		_, _, err := fsa.WriteNextBlock(block)
+		require.NoError(t, err)
+	}
+}
+
+func copyDir(t *testing.T, from string, to string) {
+	require.NoError(t, os.MkdirAll(to, 0700))
+	entries, err := ioutil.ReadDir(from)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if entry.Name() == "LOCK" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(from, entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(to, entry.Name()), content, 0600))
+	}
+}
+
+func requireBlockByTx(t *testing.T, fsa adapter.BlockPersistence, blocks []*protocol.BlockPairContainer, blockIndex int, txIndex int) {
+	tx := blocks[blockIndex].TransactionsBlock.SignedTransactions[txIndex].Transaction()
+	ts := blocks[blockIndex].ResultsBlock.Header.Timestamp()
+
+	block, retrievedTxIndex, err := fsa.GetBlockByTx(digest.CalcTxHash(tx), ts, ts)
+	require.NoError(t, err)
+	require.NotNil(t, block, "expected transaction to be found")
+	test.RequireCmpEqual(t, blocks[blockIndex], block, "expected correct block to be retrieved")
+	require.EqualValues(t, txIndex, retrievedTxIndex, "expected correct tx index to be retrieved")
+}
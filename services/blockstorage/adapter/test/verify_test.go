@@ -0,0 +1,186 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/filesystem"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyBlocksFiles_ReportsValidBlocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		writeRandomBlocksToFile(t, harness.Logger, conf, 5, rand.NewControlledRand(t))
+
+		report, err := filesystem.VerifyBlocksFiles(conf, harness.Logger, nil)
+		require.NoError(t, err)
+		require.Nil(t, report.FirstInvalid, "expected all blocks to be valid")
+		require.EqualValues(t, 5, report.ValidBlocks)
+		require.EqualValues(t, 5, report.LastBlockHeight)
+	})
+}
+
+func TestVerifyBlocksFiles_ReportsAndRepairsCorruptBlock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := builders.RandomizedBlockChain(5, rand.NewControlledRand(t))
+		fileSizes := writeBlocksRecordingFileSizes(t, harness, conf, blocks)
+		flipBitInFile(t, conf, fileSizes[1]+10, 0x01) // inside block 3
+
+		report, err := filesystem.VerifyBlocksFiles(conf, harness.Logger, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.FirstInvalid, "expected a corrupt block to be reported")
+		require.EqualValues(t, fileSizes[1], report.FirstInvalid.Offset, "expected the corrupt block record to be reported")
+		require.EqualValues(t, 2, report.LastBlockHeight)
+
+		backups, err := filesystem.RepairBlocksFiles(conf, harness.Logger, report)
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		backup, err := os.Stat(backups[0])
+		require.NoError(t, err)
+		require.EqualValues(t, fileSizes[4], backup.Size(), "expected the backup to hold the blocks file before it was truncated")
+		require.EqualValues(t, fileSizes[1], getFileSize(t, conf), "expected blocks file to be truncated after the last valid block")
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		writeBlocks(t, fsa, blocks[2:])
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+func TestVerifyBlocksFiles_ReportsBrokenPrevBlockHashPointer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		ctrlRand := rand.NewControlledRand(t)
+
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		blocks := builders.RandomizedBlockChain(3, ctrlRand)
+		blocks = append(blocks, builders.RandomizedBlock(4, ctrlRand, nil))
+		writeBlocksRecordingFileSizes(t, harness, conf, blocks)
+
+		report, err := filesystem.VerifyBlocksFiles(conf, harness.Logger, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.FirstInvalid, "expected a block with broken prev block hash pointers to be reported")
+		require.EqualValues(t, 4, report.FirstInvalid.Height)
+		require.Contains(t, report.FirstInvalid.Err.Error(), "prev block hash pointers")
+		require.EqualValues(t, 3, report.LastBlockHeight)
+	})
+}
+
+func TestVerifyBlocksFiles_ReportsBlockRejectedByVerifier(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		defer conf.cleanDir()
+
+		writeRandomBlocksToFile(t, harness.Logger, conf, 3, rand.NewControlledRand(t))
+
+		report, err := filesystem.VerifyBlocksFiles(conf, harness.Logger, func(block *protocol.BlockPairContainer) error {
+			if block.TransactionsBlock.Header.BlockHeight() == 2 {
+				return errors.New("invalid block proof")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.NotNil(t, report.FirstInvalid, "expected a block rejected by the verifier to be reported")
+		require.EqualValues(t, 2, report.FirstInvalid.Height)
+		require.EqualValues(t, 1, report.LastBlockHeight)
+	})
+}
+
+func TestVerifyBlocksFiles_RepairsSegmentedBlocksFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping Integration tests in short mode")
+	}
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		conf := newTempFileConfig()
+		conf.maxBlocksPerSegment = 2
+		defer conf.cleanDir()
+
+		blocks := builders.RandomizedBlockChain(6, rand.NewControlledRand(t))
+		writeBlocksRecordingFileSizes(t, harness, conf, blocks)
+
+		secondSegment := filepath.Join(conf.BlockStorageFileSystemDataDir(), "blocks.000001")
+		info, err := os.Stat(secondSegment)
+		require.NoError(t, err)
+		flipBitInNamedFile(t, secondSegment, info.Size()-1, 0x01) // checksum of block 4
+
+		report, err := filesystem.VerifyBlocksFiles(conf, harness.Logger, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.FirstInvalid, "expected a corrupt block to be reported")
+		require.Equal(t, secondSegment, report.FirstInvalid.Filename)
+		require.EqualValues(t, 3, report.LastBlockHeight)
+
+		backups, err := filesystem.RepairBlocksFiles(conf, harness.Logger, report)
+		require.NoError(t, err)
+		require.Len(t, backups, 2, "expected the corrupt segment and the segment following it to be backed up")
+		require.NoFileExists(t, filepath.Join(conf.BlockStorageFileSystemDataDir(), "blocks.000002"))
+
+		fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+		require.NoError(t, err)
+		defer closeAdapter()
+
+		lastHeight, err := fsa.GetLastBlockHeight()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, lastHeight)
+
+		writeBlocks(t, fsa, blocks[3:])
+		requireBlocksReadable(t, fsa, blocks)
+	})
+}
+
+// returns the size of the blocks file following each block
+func writeBlocksRecordingFileSizes(t *testing.T, harness *with.LoggingHarness, conf *localConfig, blocks []*protocol.BlockPairContainer) []int64 {
+	fsa, closeAdapter, err := NewFilesystemAdapterDriver(harness.Logger, conf)
+	require.NoError(t, err)
+	defer closeAdapter()
+
+	var fileSizes []int64
+	for _, block := range blocks {
+		writeBlocks(t, fsa, []*protocol.BlockPairContainer{block})
+		fileSizes = append(fileSizes, getFileSize(t, conf))
+	}
+	return fileSizes
+}
+
+func flipBitInNamedFile(t *testing.T, filename string, offset int64, bitMask byte) {
+	file, err := os.OpenFile(filename, os.O_RDWR, 0666)
+	require.NoError(t, err)
+	b := make([]byte, 1)
+	_, err = file.ReadAt(b, offset)
+	require.NoError(t, err)
+	b[0] = b[0] ^ bitMask
+	_, err = file.WriteAt(b, offset)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
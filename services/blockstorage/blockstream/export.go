@@ -0,0 +1,60 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package blockstream
+
+import (
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+const exportPageSize = 100
+
+// Export writes the blocks between from and to (inclusive) of the persistence in ascending order, a zero to exports up to the last block.
+// returns the number of blocks written, the writer is not closed
+func Export(persistence adapter.BlockPersistence, from primitives.BlockHeight, to primitives.BlockHeight, w *Writer) (uint64, error) {
+	lastHeight, err := persistence.GetLastBlockHeight()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read last block height")
+	}
+	if to == 0 || to > lastHeight {
+		to = lastHeight
+	}
+	if from == 0 {
+		from = 1
+	}
+	if from > to {
+		return 0, nil
+	}
+
+	var written uint64
+	var writeErr error
+	err = persistence.ScanBlocks(from, exportPageSize, func(first primitives.BlockHeight, page []*protocol.BlockPairContainer) bool {
+		for _, block := range page {
+			height := block.TransactionsBlock.Header.BlockHeight()
+			if height > to {
+				return false
+			}
+			if writeErr = w.WriteBlock(block); writeErr != nil {
+				return false
+			}
+			written++
+		}
+		return first+primitives.BlockHeight(len(page)) <= to
+	})
+	if err != nil {
+		return written, errors.Wrapf(err, "failed to scan blocks from height %d", from)
+	}
+	if writeErr != nil {
+		return written, writeErr
+	}
+	if expected := uint64(to - from + 1); written != expected {
+		return written, errors.Errorf("exported %d blocks, expected %d", written, expected)
+	}
+	return written, nil
+}
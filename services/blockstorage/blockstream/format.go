@@ -0,0 +1,303 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+// Package blockstream reads and writes blocks in a portable stream format, used to move blocks between nodes and environments.
+// a stream is a header followed by one record per block and an end record. every record holds its own checksum, and a stream
+// missing its end record is reported as truncated. unlike the blocks files of the filesystem adapter, a stream does not depend
+// on the network type, and may hold any range of blocks
+package blockstream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/membuffers/go"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"io"
+)
+
+const streamMagic = uint32(0x54534b42) // "BKST"
+const streamVersion = 0
+
+const (
+	recordTypeBlock = uint32(1)
+	recordTypeEnd   = uint32(2)
+)
+
+var crc32Table = crc32.MakeTable(crc32.Castagnoli)
+
+var ErrTruncatedStream = errors.New("blocks stream ended without an end record")
+
+type Header struct {
+	VirtualChainId primitives.VirtualChainId
+}
+
+type Writer struct {
+	w         *bufio.Writer
+	numBlocks uint64
+}
+
+// NewWriter writes the stream header, blocks must be written in the order they should be imported, and the writer closed
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	sw := &Writer{w: bufio.NewWriter(w)}
+	if err := writeRecord(sw.w, []uint32{streamMagic, streamVersion, uint32(header.VirtualChainId)}); err != nil {
+		return nil, errors.Wrap(err, "failed to write blocks stream header")
+	}
+	return sw, nil
+}
+
+func (sw *Writer) WriteBlock(block *protocol.BlockPairContainer) error {
+	tb, rb := block.TransactionsBlock, block.ResultsBlock
+	messages := []membuffers.Message{tb.Header, tb.Metadata, tb.BlockProof, rb.Header, rb.BlockProof}
+	for _, receipt := range rb.TransactionReceipts {
+		messages = append(messages, receipt)
+	}
+	for _, diff := range rb.ContractStateDiffs {
+		messages = append(messages, diff)
+	}
+	for _, tx := range tb.SignedTransactions {
+		messages = append(messages, tx)
+	}
+
+	payload := make([]byte, 0, payloadSize(messages))
+	payload = appendUint32(payload, recordTypeBlock)
+	payload = appendUint32(payload, uint32(len(rb.TransactionReceipts)))
+	payload = appendUint32(payload, uint32(len(rb.ContractStateDiffs)))
+	payload = appendUint32(payload, uint32(len(tb.SignedTransactions)))
+	for _, message := range messages {
+		payload = appendUint32(payload, uint32(len(message.Raw())))
+		payload = append(payload, message.Raw()...)
+	}
+
+	if err := writeRecord(sw.w, payload); err != nil {
+		return errors.Wrapf(err, "failed to write block %d to blocks stream", tb.Header.BlockHeight())
+	}
+	sw.numBlocks++
+	return nil
+}
+
+// Close writes the end record and flushes the stream, it does not close the underlying writer
+func (sw *Writer) Close() error {
+	payload := appendUint32(nil, recordTypeEnd)
+	payload = append(payload, make([]byte, 8)...)
+	binary.LittleEndian.PutUint64(payload[4:], sw.numBlocks)
+	if err := writeRecord(sw.w, payload); err != nil {
+		return errors.Wrap(err, "failed to write blocks stream end record")
+	}
+	return sw.w.Flush()
+}
+
+type Reader struct {
+	r            *bufio.Reader
+	maxBlockSize uint32
+	header       Header
+	numBlocks    uint64
+	done         bool
+}
+
+// NewReader reads the stream header, records larger than maxBlockSize are rejected before they are read
+func NewReader(r io.Reader, maxBlockSize uint32) (*Reader, error) {
+	sr := &Reader{r: bufio.NewReader(r), maxBlockSize: maxBlockSize}
+
+	var header [3]uint32
+	payload, err := sr.readRecord()
+	if err == nil && len(payload) != len(header)*4 {
+		err = fmt.Errorf("invalid header size %d", len(payload))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read blocks stream header")
+	}
+	for i := range header {
+		header[i] = binary.LittleEndian.Uint32(payload[i*4:])
+	}
+	if header[0] != streamMagic {
+		return nil, fmt.Errorf("invalid blocks stream magic number %v", header[0])
+	}
+	if header[1] != streamVersion {
+		return nil, fmt.Errorf("unsupported blocks stream version %d", header[1])
+	}
+	sr.header = Header{VirtualChainId: primitives.VirtualChainId(header[2])}
+	return sr, nil
+}
+
+func (sr *Reader) Header() Header {
+	return sr.header
+}
+
+// ReadBlock returns io.EOF after the end record, and ErrTruncatedStream if the stream ends before it
+func (sr *Reader) ReadBlock() (*protocol.BlockPairContainer, error) {
+	if sr.done {
+		return nil, io.EOF
+	}
+
+	payload, err := sr.readRecord()
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrTruncatedStream
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read record %d of blocks stream", sr.numBlocks+1)
+	}
+
+	p := &payloadReader{buf: payload}
+	switch recordType := p.uint32(); recordType {
+	case recordTypeEnd:
+		if numBlocks := p.uint64(); p.err == nil && numBlocks != sr.numBlocks {
+			return nil, fmt.Errorf("blocks stream end record counts %d blocks, read %d", numBlocks, sr.numBlocks)
+		}
+		if p.err != nil {
+			return nil, errors.Wrap(p.err, "invalid blocks stream end record")
+		}
+		sr.done = true
+		return nil, io.EOF
+	case recordTypeBlock:
+		block, err := p.block()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid record %d of blocks stream", sr.numBlocks+1)
+		}
+		sr.numBlocks++
+		return block, nil
+	default:
+		return nil, fmt.Errorf("invalid blocks stream record type %d", recordType)
+	}
+}
+
+// a record is its payload size, the payload and a checksum of the payload
+func writeRecord(w io.Writer, payload interface{}) error {
+	size := binary.Size(payload)
+	if err := binary.Write(w, binary.LittleEndian, uint32(size)); err != nil {
+		return err
+	}
+	checksum := crc32.New(crc32Table)
+	if err := binary.Write(io.MultiWriter(w, checksum), binary.LittleEndian, payload); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, checksum.Sum32())
+}
+
+func (sr *Reader) readRecord() ([]byte, error) {
+	var size uint32
+	if err := binary.Read(sr.r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > sr.maxBlockSize {
+		return nil, fmt.Errorf("record size %d exceeds max block size %d", size, sr.maxBlockSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(sr.r, payload); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	var checksum uint32
+	if err := binary.Read(sr.r, binary.LittleEndian, &checksum); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if computed := crc32.Checksum(payload, crc32Table); computed != checksum {
+		return nil, fmt.Errorf("record checksum mismatch. computed: %v recorded: %v", computed, checksum)
+	}
+	return payload, nil
+}
+
+type payloadReader struct {
+	buf []byte
+	err error
+}
+
+func (p *payloadReader) next(size int) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if size > len(p.buf) {
+		p.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	chunk := p.buf[:size]
+	p.buf = p.buf[size:]
+	return chunk
+}
+
+func (p *payloadReader) uint32() uint32 {
+	if chunk := p.next(4); chunk != nil {
+		return binary.LittleEndian.Uint32(chunk)
+	}
+	return 0
+}
+
+func (p *payloadReader) uint64() uint64 {
+	if chunk := p.next(8); chunk != nil {
+		return binary.LittleEndian.Uint64(chunk)
+	}
+	return 0
+}
+
+func (p *payloadReader) message() []byte {
+	return p.next(int(p.uint32()))
+}
+
+func (p *payloadReader) block() (*protocol.BlockPairContainer, error) {
+	numReceipts, numDiffs, numTxs := p.uint32(), p.uint32(), p.uint32()
+	if p.err == nil && uint64(numReceipts)+uint64(numDiffs)+uint64(numTxs) > uint64(len(p.buf)/4) {
+		return nil, fmt.Errorf("record counts more messages than it holds")
+	}
+
+	block := &protocol.BlockPairContainer{
+		TransactionsBlock: &protocol.TransactionsBlockContainer{
+			Header:     protocol.TransactionsBlockHeaderReader(p.message()),
+			Metadata:   protocol.TransactionsBlockMetadataReader(p.message()),
+			BlockProof: protocol.TransactionsBlockProofReader(p.message()),
+		},
+		ResultsBlock: &protocol.ResultsBlockContainer{
+			Header:     protocol.ResultsBlockHeaderReader(p.message()),
+			BlockProof: protocol.ResultsBlockProofReader(p.message()),
+		},
+	}
+	for i := uint32(0); i < numReceipts; i++ {
+		block.ResultsBlock.TransactionReceipts = append(block.ResultsBlock.TransactionReceipts, protocol.TransactionReceiptReader(p.message()))
+	}
+	for i := uint32(0); i < numDiffs; i++ {
+		block.ResultsBlock.ContractStateDiffs = append(block.ResultsBlock.ContractStateDiffs, protocol.ContractStateDiffReader(p.message()))
+	}
+	for i := uint32(0); i < numTxs; i++ {
+		block.TransactionsBlock.SignedTransactions = append(block.TransactionsBlock.SignedTransactions, protocol.SignedTransactionReader(p.message()))
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.buf) > 0 {
+		return nil, fmt.Errorf("record holds %d unexpected bytes", len(p.buf))
+	}
+	if !block.TransactionsBlock.Header.IsValid() || !block.ResultsBlock.Header.IsValid() {
+		return nil, fmt.Errorf("record holds invalid block headers")
+	}
+	if block.TransactionsBlock.Header.BlockHeight() != block.ResultsBlock.Header.BlockHeight() {
+		return nil, fmt.Errorf("record holds a transactions block of height %d and a results block of height %d", block.TransactionsBlock.Header.BlockHeight(), block.ResultsBlock.Header.BlockHeight())
+	}
+	return block, nil
+}
+
+func payloadSize(messages []membuffers.Message) int {
+	size := 4 * 4
+	for _, message := range messages {
+		size += 4 + len(message.Raw())
+	}
+	return size
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
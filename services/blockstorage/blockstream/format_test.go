@@ -0,0 +1,128 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package blockstream
+
+import (
+	"bytes"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+const maxBlockSize = 64 * 1024 * 1024
+
+func TestStream_WritesAndReadsBlocks(t *testing.T) {
+	blocks := builders.RandomizedBlockChain(5, rand.NewControlledRand(t))
+	stream := aStreamOf(t, 42, blocks)
+
+	r, err := NewReader(stream, maxBlockSize)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, r.Header().VirtualChainId)
+
+	test.RequireCmpEqual(t, blocks, readAllBlocks(t, r), "expected to read the blocks written")
+}
+
+func TestStream_DetectsDataCorruption(t *testing.T) {
+	blocks := builders.RandomizedBlockChain(3, rand.NewControlledRand(t))
+	stream := aStreamOf(t, 42, blocks).Bytes()
+	stream[len(stream)/2] ^= 0x01
+
+	r, err := NewReader(bytes.NewReader(stream), maxBlockSize)
+	require.NoError(t, err)
+
+	for {
+		_, err = r.ReadBlock()
+		if err != nil {
+			break
+		}
+	}
+	require.Error(t, err)
+	require.NotEqual(t, io.EOF, err, "expected corruption to be reported")
+	require.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestStream_DetectsTruncatedStream(t *testing.T) {
+	blocks := builders.RandomizedBlockChain(3, rand.NewControlledRand(t))
+	stream := aStreamOf(t, 42, blocks).Bytes()
+
+	for _, size := range []int{len(stream) - 1, len(stream) - 20} {
+		r, err := NewReader(bytes.NewReader(stream[:size]), maxBlockSize)
+		require.NoError(t, err)
+
+		for {
+			_, err = r.ReadBlock()
+			if err != nil {
+				break
+			}
+		}
+		require.Equal(t, ErrTruncatedStream, err, "expected a stream of %d bytes out of %d to be reported truncated", size, len(stream))
+	}
+}
+
+func TestStream_EnforcesBlockSizeLimit(t *testing.T) {
+	blocks := []*protocol.BlockPairContainer{builders.BlockPair().WithHeight(1).WithTransactions(6).Build()}
+	r, err := NewReader(aStreamOf(t, 42, blocks), 1024)
+	require.NoError(t, err)
+
+	_, err = r.ReadBlock()
+	require.Error(t, err, "expected to fail reading a block larger than maxBlockSize")
+}
+
+func TestStream_RejectsUnknownFormat(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a blocks stream, just some text")), maxBlockSize)
+	require.Error(t, err)
+}
+
+func TestExport_WritesRequestedRange(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		blocks := builders.RandomizedBlockChain(7, rand.NewControlledRand(t))
+		persistence := memory.NewBlockPersistence(harness.Logger, metric.NewRegistry(), blocks...)
+
+		stream := new(bytes.Buffer)
+		w, err := NewWriter(stream, Header{VirtualChainId: 42})
+		require.NoError(t, err)
+		written, err := Export(persistence, 2, 5, w)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.EqualValues(t, 4, written)
+
+		r, err := NewReader(stream, maxBlockSize)
+		require.NoError(t, err)
+		test.RequireCmpEqual(t, blocks[1:5], readAllBlocks(t, r), "expected to read the exported blocks")
+	})
+}
+
+func aStreamOf(t *testing.T, vcid primitives.VirtualChainId, blocks []*protocol.BlockPairContainer) *bytes.Buffer {
+	stream := new(bytes.Buffer)
+	w, err := NewWriter(stream, Header{VirtualChainId: vcid})
+	require.NoError(t, err)
+	for _, block := range blocks {
+		require.NoError(t, w.WriteBlock(block))
+	}
+	require.NoError(t, w.Close())
+	return stream
+}
+
+func readAllBlocks(t *testing.T, r *Reader) []*protocol.BlockPairContainer {
+	var blocks []*protocol.BlockPairContainer
+	for {
+		block, err := r.ReadBlock()
+		if err == io.EOF {
+			return blocks
+		}
+		require.NoError(t, err)
+		blocks = append(blocks, block)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package blockstream
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"io"
+	"time"
+)
+
+// ImportTarget is the part of block storage blocks are imported into, every block is validated and committed exactly like a block received via sync
+type ImportTarget interface {
+	GetLastCommittedBlockHeight(ctx context.Context, input *services.GetLastCommittedBlockHeightInput) (*services.GetLastCommittedBlockHeightOutput, error)
+	GetBlock(height primitives.BlockHeight) (*protocol.BlockPairContainer, error)
+	ValidateBlockForCommit(ctx context.Context, input *services.ValidateBlockForCommitInput) (*services.ValidateBlockForCommitOutput, error)
+	NodeSyncCommitBlock(ctx context.Context, input *services.CommitBlockInput) (*services.CommitBlockOutput, error)
+	UpdateConsensusAlgosAboutLastCommittedBlockInLocalPersistence(ctx context.Context)
+}
+
+type ImportResult struct {
+	Imported        uint64
+	Skipped         uint64
+	LastBlockHeight primitives.BlockHeight
+}
+
+// Import commits the blocks of the stream following the last committed block of the target, blocks already committed are skipped.
+// the import stops at the first block failing to read, validate or commit, blocks committed before it remain committed
+func Import(ctx context.Context, r *Reader, vcid primitives.VirtualChainId, target ImportTarget, logger log.Logger) (*ImportResult, error) {
+	if r.Header().VirtualChainId != vcid {
+		return nil, errors.Errorf("blocks stream is of virtual chain %d, expected %d", r.Header().VirtualChainId, vcid)
+	}
+
+	out, err := target.GetLastCommittedBlockHeight(ctx, &services.GetLastCommittedBlockHeightInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read last committed block height")
+	}
+	result := &ImportResult{LastBlockHeight: out.LastCommittedBlockHeight}
+
+	var prevBlockPair *protocol.BlockPairContainer
+	if result.LastBlockHeight > 0 {
+		if prevBlockPair, err = target.GetBlock(result.LastBlockHeight); err != nil {
+			return result, errors.Wrapf(err, "failed to read last committed block %d", result.LastBlockHeight)
+		}
+	}
+	defer func() {
+		if result.Imported > 0 {
+			shortCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			target.UpdateConsensusAlgosAboutLastCommittedBlockInLocalPersistence(shortCtx)
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		blockPair, err := r.ReadBlock()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+
+		blockHeight := blockPair.TransactionsBlock.Header.BlockHeight()
+		if blockHeight <= result.LastBlockHeight {
+			result.Skipped++
+			continue
+		}
+		if blockHeight != result.LastBlockHeight+1 {
+			return result, errors.Errorf("blocks stream skips from block %d to block %d", result.LastBlockHeight, blockHeight)
+		}
+
+		if _, err := target.ValidateBlockForCommit(ctx, &services.ValidateBlockForCommitInput{BlockPair: blockPair, PrevBlockPair: prevBlockPair}); err != nil {
+			return result, errors.Wrapf(err, "failed to validate imported block %d", blockHeight)
+		}
+		if _, err := target.NodeSyncCommitBlock(ctx, &services.CommitBlockInput{BlockPair: blockPair}); err != nil {
+			return result, errors.Wrapf(err, "failed to commit imported block %d", blockHeight)
+		}
+		logger.Info("committed imported block", logfields.BlockHeight(blockHeight))
+
+		result.Imported++
+		result.LastBlockHeight = blockHeight
+		prevBlockPair = blockPair
+	}
+}
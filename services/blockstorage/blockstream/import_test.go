@@ -0,0 +1,133 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package blockstream
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/rand"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestImport_CommitsBlocksFollowingLastCommittedBlock(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(harness *with.LoggingHarness) {
+			blocks := builders.RandomizedBlockChain(6, rand.NewControlledRand(t))
+			target := newFakeImportTarget(harness, blocks[:2]...)
+
+			r, err := NewReader(aStreamOf(t, 42, blocks), maxBlockSize)
+			require.NoError(t, err)
+			result, err := Import(ctx, r, 42, target, harness.Logger)
+			require.NoError(t, err)
+
+			require.EqualValues(t, 4, result.Imported)
+			require.EqualValues(t, 2, result.Skipped)
+			require.EqualValues(t, 6, result.LastBlockHeight)
+			require.EqualValues(t, []primitives.BlockHeight{3, 4, 5, 6}, target.validated)
+			require.Equal(t, blocks[1], target.prevBlockPairs[0], "expected the first imported block to be validated against the last committed block")
+			require.Equal(t, 1, target.consensusUpdates)
+		})
+	})
+}
+
+func TestImport_StopsAtBlockFailingValidation(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(harness *with.LoggingHarness) {
+			blocks := builders.RandomizedBlockChain(5, rand.NewControlledRand(t))
+			target := newFakeImportTarget(harness)
+			target.invalidHeight = 3
+
+			r, err := NewReader(aStreamOf(t, 42, blocks), maxBlockSize)
+			require.NoError(t, err)
+			result, err := Import(ctx, r, 42, target, harness.Logger)
+			require.Error(t, err)
+
+			require.EqualValues(t, 2, result.Imported)
+			require.EqualValues(t, 2, result.LastBlockHeight)
+			lastHeight, _ := target.persistence.GetLastBlockHeight()
+			require.EqualValues(t, 2, lastHeight, "expected blocks following the invalid block not to be committed")
+		})
+	})
+}
+
+func TestImport_RejectsGapInBlocks(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(harness *with.LoggingHarness) {
+			blocks := builders.RandomizedBlockChain(5, rand.NewControlledRand(t))
+			target := newFakeImportTarget(harness)
+
+			r, err := NewReader(aStreamOf(t, 42, blocks[2:]), maxBlockSize)
+			require.NoError(t, err)
+			result, err := Import(ctx, r, 42, target, harness.Logger)
+			require.Error(t, err)
+			require.Zero(t, result.Imported)
+		})
+	})
+}
+
+func TestImport_RejectsStreamOfAnotherVirtualChain(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(harness *with.LoggingHarness) {
+			blocks := builders.RandomizedBlockChain(2, rand.NewControlledRand(t))
+			target := newFakeImportTarget(harness)
+
+			r, err := NewReader(aStreamOf(t, 43, blocks), maxBlockSize)
+			require.NoError(t, err)
+			_, err = Import(ctx, r, 42, target, harness.Logger)
+			require.Error(t, err)
+			require.Empty(t, target.validated)
+		})
+	})
+}
+
+type fakeImportTarget struct {
+	persistence      *memory.InMemoryBlockPersistence
+	invalidHeight    primitives.BlockHeight
+	validated        []primitives.BlockHeight
+	prevBlockPairs   []*protocol.BlockPairContainer
+	consensusUpdates int
+}
+
+func newFakeImportTarget(harness *with.LoggingHarness, blocks ...*protocol.BlockPairContainer) *fakeImportTarget {
+	return &fakeImportTarget{persistence: memory.NewBlockPersistence(harness.Logger, metric.NewRegistry(), blocks...)}
+}
+
+func (f *fakeImportTarget) GetLastCommittedBlockHeight(ctx context.Context, input *services.GetLastCommittedBlockHeightInput) (*services.GetLastCommittedBlockHeightOutput, error) {
+	height, err := f.persistence.GetLastBlockHeight()
+	return &services.GetLastCommittedBlockHeightOutput{LastCommittedBlockHeight: height}, err
+}
+
+func (f *fakeImportTarget) GetBlock(height primitives.BlockHeight) (*protocol.BlockPairContainer, error) {
+	return f.persistence.GetBlock(height)
+}
+
+func (f *fakeImportTarget) ValidateBlockForCommit(ctx context.Context, input *services.ValidateBlockForCommitInput) (*services.ValidateBlockForCommitOutput, error) {
+	height := input.BlockPair.TransactionsBlock.Header.BlockHeight()
+	f.validated = append(f.validated, height)
+	f.prevBlockPairs = append(f.prevBlockPairs, input.PrevBlockPair)
+	if height == f.invalidHeight {
+		return nil, errors.New("invalid block proof")
+	}
+	return &services.ValidateBlockForCommitOutput{}, nil
+}
+
+func (f *fakeImportTarget) NodeSyncCommitBlock(ctx context.Context, input *services.CommitBlockInput) (*services.CommitBlockOutput, error) {
+	_, _, err := f.persistence.WriteNextBlock(input.BlockPair)
+	return &services.CommitBlockOutput{}, err
+}
+
+func (f *fakeImportTarget) UpdateConsensusAlgosAboutLastCommittedBlockInLocalPersistence(ctx context.Context) {
+	f.consensusUpdates++
+}
@@ -0,0 +1,30 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package adapter
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+)
+
+type authenticatedPeerKey struct{}
+
+// ContextWithAuthenticatedPeer is used by transports to tell their listener which node proved it sent a received message
+func ContextWithAuthenticatedPeer(ctx context.Context, peerNodeAddress primitives.NodeAddress) context.Context {
+	return context.WithValue(ctx, authenticatedPeerKey{}, peerNodeAddress)
+}
+
+// ContextWithoutAuthenticatedPeer is used when the peer which delivered a message is known not to be its sender, e.g. a relay
+func ContextWithoutAuthenticatedPeer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authenticatedPeerKey{}, primitives.NodeAddress(nil))
+}
+
+// AuthenticatedPeerFromContext returns false when the transport did not authenticate the peer a message was received from
+func AuthenticatedPeerFromContext(ctx context.Context) (primitives.NodeAddress, bool) {
+	peerNodeAddress, ok := ctx.Value(authenticatedPeerKey{}).(primitives.NodeAddress)
+	return peerNodeAddress, ok && peerNodeAddress != nil
+}
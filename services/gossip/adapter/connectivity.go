@@ -0,0 +1,42 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package adapter
+
+import (
+	"time"
+)
+
+type PeerConnectionState string
+
+const (
+	PeerConnecting   PeerConnectionState = "connecting" // never connected since the peer joined the topology
+	PeerConnected    PeerConnectionState = "connected"
+	PeerDisconnected PeerConnectionState = "disconnected" // lost its connection and is reconnecting
+)
+
+// PeerConnectivity is a snapshot of the connection to a single topology peer, zero times mean it never happened
+type PeerConnectivity struct {
+	NodeAddress          string // hex
+	Endpoint             string
+	State                PeerConnectionState
+	LastSuccessfulSend   time.Time
+	LastKeepAlive        time.Time
+	QueueDepth           int               // messages waiting to be sent
+	BytesSentByTopic     map[string]uint64 // as written to the connection, after compression
+	BytesReceivedByTopic map[string]uint64 // only known for authenticated peers
+	Reconnects           uint64
+	LastError            string `json:",omitempty"`
+}
+
+func (p *PeerConnectivity) IsReachable() bool {
+	return p.State == PeerConnected
+}
+
+// implemented by transports which can report the connection to each of their topology peers
+type ConnectivityReporter interface {
+	PeerConnectivity() []*PeerConnectivity
+}
@@ -31,6 +31,7 @@ var LogTag = log.String("adapter", "gossip")
 type message struct {
 	payloads     [][]byte
 	traceContext *trace.Context
+	sender       primitives.NodeAddress // peers are trusted to send under their own address, like an authenticated tcp connection
 }
 
 type memoryTransport struct {
@@ -170,7 +171,7 @@ func (p *peer) send(ctx context.Context, data *adapter.TransportData) {
 	before := time.Now()
 	tracingContext, _ := trace.FromContext(ctx)
 	select {
-	case p.socket <- message{payloads: data.Payloads, traceContext: tracingContext}:
+	case p.socket <- message{payloads: data.Payloads, traceContext: tracingContext, sender: data.SenderNodeAddress}:
 		p.logger.Info("deposited message into peer queue", trace.LogFieldFrom(ctx), log.Stringable("duration", time.Since(before)))
 		return
 	case <-ctx.Done():
@@ -199,6 +200,9 @@ func receive(bgCtx context.Context, listener adapter.TransportListener, message
 	ctx, cancel := context.WithTimeout(bgCtx, LISTENER_HANDLE_TIMEOUT)
 	defer cancel()
 	traceContext := contextFrom(ctx, message)
+	if message.sender != nil {
+		traceContext = adapter.ContextWithAuthenticatedPeer(traceContext, message.sender)
+	}
 	listener.OnTransportMessageReceived(traceContext, message.payloads)
 }
 
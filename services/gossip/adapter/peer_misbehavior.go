@@ -0,0 +1,58 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package adapter
+
+import (
+	"context"
+)
+
+type Misbehavior int
+
+const (
+	MisbehaviorInvalidHeader Misbehavior = iota
+	MisbehaviorUndecodableMessage
+	MisbehaviorOversizedMessage
+	MisbehaviorFailedHandshake
+)
+
+func (m Misbehavior) String() string {
+	switch m {
+	case MisbehaviorInvalidHeader:
+		return "invalid-header"
+	case MisbehaviorUndecodableMessage:
+		return "undecodable-message"
+	case MisbehaviorOversizedMessage:
+		return "oversized-message"
+	case MisbehaviorFailedHandshake:
+		return "failed-handshake"
+	default:
+		return "unknown"
+	}
+}
+
+// MisbehaviorReporter penalizes the peer a received message came from
+type MisbehaviorReporter func(misbehavior Misbehavior)
+
+type misbehaviorReporterKey struct{}
+
+// ContextWithMisbehaviorReporter is used by transports which score peers to let their listener report the peer a received message came from,
+// a nil reporter stops reports from reaching the transport, e.g. when the delivering peer is not to blame for the message
+func ContextWithMisbehaviorReporter(ctx context.Context, reporter MisbehaviorReporter) context.Context {
+	return context.WithValue(ctx, misbehaviorReporterKey{}, reporter)
+}
+
+// MisbehaviorReporterFromContext returns nil when the transport does not score peers
+func MisbehaviorReporterFromContext(ctx context.Context) MisbehaviorReporter {
+	reporter, _ := ctx.Value(misbehaviorReporterKey{}).(MisbehaviorReporter)
+	return reporter
+}
+
+func ReportMisbehavior(ctx context.Context, misbehavior Misbehavior) {
+	if reporter := MisbehaviorReporterFromContext(ctx); reporter != nil {
+		reporter(misbehavior)
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package proxy
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/orbs-network/govnr"
+	"github.com/orbs-network/membuffers/go"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/tcp"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/testkit"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var LogTag = log.String("adapter", "gossip-proxy")
+
+const proxyHost = "127.0.0.1"
+const relayTimeout = 5 * time.Second
+
+// the tcp transport framing of a compression proposal, which the proxy declines so payloads are relayed uncompressed
+const compressionProposal = uint32(0xfffffff0)
+const compressionCodecNone = uint32(0)
+
+// TamperingProxy sits between the gossip ports of real nodes and tampers with the messages they send with the same
+// Tamperer the in-memory TamperingTransport offers. every node is given a topology (PeersOf) where each of its peers is
+// a proxy port of its own, so the proxy knows the sender and recipient of every connection it relays. messages are
+// read as they are framed by the tcp transport, which is only possible when gossip authentication is disabled
+type TamperingProxy struct {
+	govnr.TreeSupervisor
+	testkit.Tamperer
+
+	logger    log.Logger
+	tampering *testkit.TamperingTransport
+	nodes     adapter.TransportPeers
+	routes    map[string]*route // by sender and recipient
+	cancel    context.CancelFunc
+}
+
+// a route relays the connections of a single sender to a single recipient
+type route struct {
+	sender        primitives.NodeAddress
+	recipient     primitives.NodeAddress
+	recipientPeer adapter.TransportPeer
+	listener      net.Listener
+	keepAlives    uint64 // relayed, once the sender sends keep alives it is ready to send messages
+
+	upstream struct {
+		sync.Mutex
+		conn net.Conn // the latest connection to the recipient, nil when the sender is not connected
+	}
+}
+
+// nodes are the real gossip endpoints of the nodes keyed by node address as NewGossipPeers does, the proxy listens on a
+// port for every pair of them
+func NewTamperingProxy(parent context.Context, logger log.Logger, nodes adapter.TransportPeers) (*TamperingProxy, error) {
+	ctx, cancel := context.WithCancel(parent)
+	p := &TamperingProxy{
+		logger: logger.WithTags(LogTag),
+		nodes:  nodes,
+		routes: make(map[string]*route),
+		cancel: cancel,
+	}
+	p.tampering = testkit.NewTamperingTransport(p.logger, &forwarder{proxy: p})
+	p.Tamperer = p.tampering
+
+	for senderKey, sender := range nodes {
+		for recipientKey, recipient := range nodes {
+			if senderKey == recipientKey {
+				continue
+			}
+			r, err := newRoute(sender, recipient)
+			if err != nil {
+				p.closeListeners()
+				cancel()
+				return nil, err
+			}
+			p.routes[routeKey(r.sender, r.recipient)] = r
+		}
+	}
+
+	for _, r := range p.routes {
+		r := r
+		p.Supervise(govnr.Forever(ctx, fmt.Sprintf("gossip proxy from %s to %s", r.sender, r.recipient), logfields.GovnrErrorer(p.logger), func() {
+			p.acceptConnections(ctx, r)
+		}))
+	}
+	govnr.Once(logfields.GovnrErrorer(p.logger), func() {
+		<-ctx.Done()
+		p.closeListeners()
+	})
+
+	return p, nil
+}
+
+func newRoute(sender adapter.TransportPeer, recipient adapter.TransportPeer) (*route, error) {
+	senderAddress, err := hex.DecodeString(sender.HexOrbsAddress())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid node address %s", sender.HexOrbsAddress())
+	}
+	recipientAddress, err := hex.DecodeString(recipient.HexOrbsAddress())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid node address %s", recipient.HexOrbsAddress())
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(proxyHost, "0"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "gossip proxy failed to listen for messages from %s to %s", sender.HexOrbsAddress(), recipient.HexOrbsAddress())
+	}
+
+	return &route{
+		sender:        senderAddress,
+		recipient:     recipientAddress,
+		recipientPeer: recipient,
+		listener:      listener,
+	}, nil
+}
+
+func routeKey(sender primitives.NodeAddress, recipient primitives.NodeAddress) string {
+	return sender.KeyForMap() + recipient.KeyForMap()
+}
+
+// PeersOf returns the topology the sender should connect to its peers with, which routes its messages through the proxy
+func (p *TamperingProxy) PeersOf(sender primitives.NodeAddress) adapter.TransportPeers {
+	peers := make(adapter.TransportPeers, len(p.nodes))
+	for key, node := range p.nodes {
+		if r, ok := p.routes[routeKey(sender, primitives.NodeAddress(key))]; ok {
+			peers[key] = adapter.NewGossipPeer(r.listener.Addr().(*net.TCPAddr).Port, proxyHost, node.HexOrbsAddress())
+		} else {
+			peers[key] = node // the sender itself
+		}
+	}
+	return peers
+}
+
+func (p *TamperingProxy) GracefulShutdown(shutdownContext context.Context) {
+	p.cancel()
+}
+
+func (p *TamperingProxy) closeListeners() {
+	for _, r := range p.routes {
+		_ = r.listener.Close()
+	}
+}
+
+func (p *TamperingProxy) acceptConnections(ctx context.Context, r *route) {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				p.logger.Info("gossip proxy failed accepting a connection", log.Error(err), log.Stringable("sender", r.sender), log.Stringable("recipient", r.recipient))
+			}
+			return
+		}
+
+		govnr.Once(logfields.GovnrErrorer(p.logger), func() {
+			p.relay(ctx, r, conn)
+		})
+	}
+}
+
+// relays the messages of a sender's connection to a new connection to the recipient, until either is closed
+func (p *TamperingProxy) relay(parent context.Context, r *route, conn net.Conn) {
+	logger := p.logger.WithTags(log.Stringable("sender", r.sender), log.Stringable("recipient", r.recipient))
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	upstream, err := net.DialTimeout("tcp", net.JoinHostPort(r.recipientPeer.Endpoint(), fmt.Sprintf("%d", r.recipientPeer.Port())), relayTimeout)
+	if err != nil {
+		logger.Info("gossip proxy failed connecting to the recipient", log.Error(err))
+		_ = conn.Close()
+		return
+	}
+	r.connected(upstream)
+	defer r.disconnected(upstream)
+
+	govnr.Once(logfields.GovnrErrorer(logger), func() {
+		_, _ = io.Copy(ioutil.Discard, upstream) // the recipient never writes once compression is declined, this only notices it closing
+		cancel()
+	})
+	govnr.Once(logfields.GovnrErrorer(logger), func() {
+		<-ctx.Done()
+		_ = conn.Close()
+		_ = upstream.Close()
+	})
+
+	for {
+		payloads, err := p.readMessage(conn)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Info("gossip proxy stopped relaying a connection", log.Error(err))
+			}
+			return
+		}
+
+		if len(payloads) == 0 { // keep alives are not tampered with
+			if err := r.write(nil); err != nil {
+				logger.Info("gossip proxy failed relaying a keep alive", log.Error(err))
+			} else {
+				atomic.AddUint64(&r.keepAlives, 1)
+			}
+			continue
+		}
+
+		if err := p.tampering.Send(ctx, &adapter.TransportData{
+			SenderNodeAddress:      r.sender,
+			RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
+			RecipientNodeAddresses: []primitives.NodeAddress{r.recipient},
+			Payloads:               payloads,
+		}); err != nil {
+			logger.Info("gossip proxy dropped a message", log.Error(err))
+		}
+	}
+}
+
+// reads a message framed by the tcp transport, a compression proposal is declined and returns no payloads like a keep alive
+func (p *TamperingProxy) readMessage(conn net.Conn) ([][]byte, error) {
+	buffer, err := readTotal(conn, 4)
+	if err != nil {
+		return nil, err
+	}
+	numPayloads := membuffers.GetUint32(buffer)
+
+	if numPayloads == compressionProposal {
+		if _, err := readTotal(conn, 4); err != nil {
+			return nil, err
+		}
+		reply := make([]byte, 4)
+		membuffers.WriteUint32(reply, compressionCodecNone)
+		return nil, write(conn, reply)
+	}
+
+	if numPayloads > tcp.MAX_PAYLOADS_IN_MESSAGE {
+		return nil, errors.Errorf("received message with too many payloads: %d", numPayloads)
+	}
+
+	payloads := make([][]byte, 0, numPayloads)
+	for i := uint32(0); i < numPayloads; i++ {
+		buffer, err := readTotal(conn, 4)
+		if err != nil {
+			return nil, err
+		}
+		payloadSize := membuffers.GetUint32(buffer)
+		if payloadSize > tcp.MAX_PAYLOAD_SIZE_BYTES {
+			return nil, errors.Errorf("received message with a payload too big: %d bytes", payloadSize)
+		}
+
+		payload, err := readTotal(conn, payloadSize+paddingSize(payloadSize))
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload[:payloadSize])
+	}
+	return payloads, nil
+}
+
+func (p *TamperingProxy) transmit(ctx context.Context, recipient primitives.NodeAddress, data *adapter.TransportData) {
+	r, ok := p.routes[routeKey(data.SenderNodeAddress, recipient)]
+	if !ok {
+		p.logger.Info("gossip proxy has no route for a message", log.Stringable("sender", data.SenderNodeAddress), log.Stringable("recipient", recipient))
+		return
+	}
+
+	if err := r.write(data.Payloads); err != nil {
+		p.logger.Info("gossip proxy failed relaying a message", log.Error(err), log.Stringable("sender", r.sender), log.Stringable("recipient", r.recipient))
+	}
+}
+
+func (r *route) connected(conn net.Conn) {
+	r.upstream.Lock()
+	defer r.upstream.Unlock()
+	r.upstream.conn = conn
+}
+
+func (r *route) disconnected(conn net.Conn) {
+	r.upstream.Lock()
+	defer r.upstream.Unlock()
+	if r.upstream.conn == conn {
+		r.upstream.conn = nil
+	}
+}
+
+// writes a message to the recipient framed as the tcp transport does, no payloads make a keep alive
+func (r *route) write(payloads [][]byte) error {
+	size := 4
+	for _, payload := range payloads {
+		size += 4 + len(payload) + int(paddingSize(uint32(len(payload))))
+	}
+	buffer := make([]byte, size)
+	membuffers.WriteUint32(buffer, uint32(len(payloads)))
+	offset := 4
+	for _, payload := range payloads {
+		membuffers.WriteUint32(buffer[offset:], uint32(len(payload)))
+		copy(buffer[offset+4:], payload)
+		offset += 4 + len(payload) + int(paddingSize(uint32(len(payload))))
+	}
+
+	r.upstream.Lock()
+	defer r.upstream.Unlock()
+	if r.upstream.conn == nil {
+		return errors.New("the sender is not connected to the recipient")
+	}
+	return write(r.upstream.conn, buffer)
+}
+
+func readTotal(conn net.Conn, size uint32) ([]byte, error) {
+	buffer := make([]byte, size)
+	_, err := io.ReadFull(conn, buffer)
+	return buffer, err
+}
+
+func write(conn net.Conn, buffer []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(relayTimeout)); err != nil {
+		return err
+	}
+	_, err := conn.Write(buffer)
+	return err
+}
+
+func paddingSize(size uint32) uint32 {
+	const contentAlignment = 4
+	return (contentAlignment - size%contentAlignment) % contentAlignment
+}
+
+// forwarder is the transport the tampering transport intercepts, each message it is given holds the single recipient of
+// the connection the message was read from
+type forwarder struct {
+	proxy *TamperingProxy
+}
+
+func (f *forwarder) SendWithInterceptor(ctx context.Context, data *adapter.TransportData, intercept adapter.InterceptorFunc) error {
+	for _, recipient := range data.RecipientNodeAddresses {
+		if err := intercept(ctx, recipient, data, f.proxy.transmit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *forwarder) Send(ctx context.Context, data *adapter.TransportData) error {
+	return f.SendWithInterceptor(ctx, data, func(ctx context.Context, peerAddress primitives.NodeAddress, data *adapter.TransportData, transmit adapter.TransmitFunc) error {
+		transmit(ctx, peerAddress, data)
+		return nil
+	})
+}
+
+func (f *forwarder) RegisterListener(listener adapter.TransportListener, listenerNodeAddress primitives.NodeAddress) {
+}
+
+func (f *forwarder) UpdateTopology(bgCtx context.Context, newPeers adapter.TransportPeers) {
+}
+
+func (f *forwarder) GracefulShutdown(shutdownContext context.Context) {
+}
+
+func (f *forwarder) WaitUntilShutdown(shutdownContext context.Context) {
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package proxy
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/tcp"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/testkit"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type proxiedNode struct {
+	transport *tcp.DirectTransport
+	address   primitives.NodeAddress
+	listener  *testkit.MockTransportListener
+}
+
+// proposes compression to its peers, which the proxy declines
+type compressingConfig struct {
+	config.GossipTransportConfig
+}
+
+func (c *compressingConfig) GossipCompressionEnabled() bool {
+	return true
+}
+
+func aProxiedNode(ctx context.Context, harness *with.ConcurrencyHarness, keyIndex int) *proxiedNode {
+	address := keys.EcdsaSecp256K1KeyPairForTests(keyIndex).NodeAddress()
+	cfg := &compressingConfig{config.ForDirectTransportTests(address, 100*time.Millisecond, 1*time.Second)}
+	transport := tcp.NewDirectTransport(ctx, cfg, nil, harness.Logger, metric.NewRegistry())
+	harness.Supervise(transport)
+	listener := &testkit.MockTransportListener{}
+	transport.RegisterListener(listener, address)
+	require.True(harness.T, test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, transport.IsServerListening), "server did not start")
+	return &proxiedNode{transport: transport, address: address, listener: listener}
+}
+
+func aProxiedNetwork(ctx context.Context, harness *with.ConcurrencyHarness) (*TamperingProxy, *proxiedNode, *proxiedNode) {
+	node1 := aProxiedNode(ctx, harness, 1)
+	node2 := aProxiedNode(ctx, harness, 2)
+
+	nodes := make(adapter.TransportPeers)
+	for _, node := range []*proxiedNode{node1, node2} {
+		nodes[node.address.KeyForMap()] = adapter.NewGossipPeer(node.transport.GetServerPort(), "127.0.0.1", hex.EncodeToString(node.address))
+	}
+	proxy, err := NewTamperingProxy(ctx, harness.Logger, nodes)
+	require.NoError(harness.T, err)
+	harness.Supervise(proxy)
+
+	node1.transport.UpdateTopology(ctx, proxy.PeersOf(node1.address))
+	node2.transport.UpdateTopology(ctx, proxy.PeersOf(node2.address))
+	require.True(harness.T, test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, proxy.allSendersReady), "expected nodes to connect through the proxy")
+	return proxy, node1, node2
+}
+
+func (p *TamperingProxy) allSendersReady() bool {
+	for _, r := range p.routes {
+		if atomic.LoadUint64(&r.keepAlives) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func shutdown(ctx context.Context, proxy *TamperingProxy, nodes ...*proxiedNode) {
+	for _, node := range nodes {
+		node.transport.GracefulShutdown(ctx)
+	}
+	proxy.GracefulShutdown(ctx)
+}
+
+func (n *proxiedNode) send(t *testing.T, ctx context.Context, payloads [][]byte, recipient *proxiedNode) {
+	require.NoError(t, n.transport.Send(ctx, &adapter.TransportData{
+		SenderNodeAddress:      n.address,
+		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
+		RecipientNodeAddresses: []primitives.NodeAddress{recipient.address},
+		Payloads:               payloads,
+	}))
+}
+
+func aMessage(topic gossipmessages.HeaderTopic, content ...byte) [][]byte {
+	header := (&gossipmessages.HeaderBuilder{
+		Topic:         topic,
+		RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_LIST,
+	}).Build()
+	return [][]byte{header.Raw(), append(content, make([]byte, 100)...)} // large enough to be compressed if compression was accepted
+}
+
+func TestTamperingProxy_RelaysMessagesBetweenNodes(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		proxy, node1, node2 := aProxiedNetwork(ctx, harness)
+		defer shutdown(ctx, proxy, node1, node2)
+
+		message := aMessage(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x01, 0x02, 0x03)
+		node2.listener.ExpectReceive(message)
+		node1.send(t, ctx, message, node2)
+		require.NoError(t, test.EventuallyVerify(test.EVENTUALLY_ADAPTER_TIMEOUT, node2.listener), "expected the message to be relayed untouched")
+
+		message = aMessage(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x04)
+		node1.listener.ExpectReceive(message)
+		node2.send(t, ctx, message, node1)
+		require.NoError(t, test.EventuallyVerify(test.EVENTUALLY_ADAPTER_TIMEOUT, node1.listener), "expected the message to be relayed untouched")
+	})
+}
+
+func TestTamperingProxy_PartitionsNodesWithAFailingTamperer(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		proxy, node1, node2 := aProxiedNetwork(ctx, harness)
+		defer shutdown(ctx, proxy, node1, node2)
+
+		partition := proxy.Fail(testkit.HasHeader(testkit.AConsensusMessage).And(testkit.SentBy(node1.address)).And(testkit.SentTo(node2.address)))
+
+		dropped := aMessage(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x01)
+		node2.listener.ExpectNotReceive()
+		node1.send(t, ctx, dropped, node2)
+
+		relayed := aMessage(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x02)
+		node1.listener.ExpectReceive(relayed)
+		node2.send(t, ctx, relayed, node1)
+
+		require.NoError(t, test.EventuallyVerify(test.EVENTUALLY_ADAPTER_TIMEOUT, node1.listener), "expected the message of a node outside the partition to be relayed")
+		require.NoError(t, test.ConsistentlyVerify(test.EVENTUALLY_ADAPTER_TIMEOUT, node2.listener), "expected the message of a partitioned node to be dropped")
+
+		partition.StopTampering(ctx)
+
+		healed := aMessage(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x03)
+		node2.listener.ExpectReceive(healed)
+		node1.send(t, ctx, healed, node2)
+		require.NoError(t, test.EventuallyVerify(test.EVENTUALLY_ADAPTER_TIMEOUT, node2.listener), "expected messages to be relayed once the partition is healed")
+	})
+}
+
+func TestTamperingProxy_DelaysMessages(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		proxy, node1, node2 := aProxiedNetwork(ctx, harness)
+		defer shutdown(ctx, proxy, node1, node2)
+
+		delay := 300 * time.Millisecond
+		proxy.Delay(func() time.Duration { return delay }, testkit.HasHeader(func(header *gossipmessages.Header) bool { return header.IsTopicBlockSync() }))
+
+		message := aMessage(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x01)
+		received := make(chan time.Time, 1)
+		node2.listener.WhenOnTransportMessageReceived(message).Call(func(ctx context.Context, payloads [][]byte) {
+			received <- time.Now()
+		}).Times(1)
+
+		sent := time.Now()
+		node1.send(t, ctx, message, node2)
+
+		select {
+		case at := <-received:
+			require.True(t, at.Sub(sent) >= delay, "expected the message to be delayed")
+		case <-time.After(delay + test.EVENTUALLY_ADAPTER_TIMEOUT):
+			require.Fail(t, "expected the delayed message to be relayed")
+		}
+	})
+}
@@ -0,0 +1,298 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+// Package recording records the gossip traffic of a node to files and replays it, to reproduce locally what a node saw in production.
+// a recording is a header naming the recording node followed by one record per message sent or received. every record holds its
+// own checksum, and since a node may stop at any moment a recording has no end record, a partial last record reads as truncated
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+const recordingMagic = uint32(0x43525347) // "GSRC"
+const recordingVersion = 0
+
+const maxRecordSize = 256 * 1024 * 1024
+
+// TopicUnknown is the topic of recorded messages without a valid header
+const TopicUnknown = gossipmessages.HeaderTopic(0xffff)
+
+type Direction uint32
+
+const (
+	DirectionSent     Direction = 1
+	DirectionReceived Direction = 2
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionSent:
+		return "sent"
+	case DirectionReceived:
+		return "received"
+	default:
+		return fmt.Sprintf("direction-%d", uint32(d))
+	}
+}
+
+var crc32Table = crc32.MakeTable(crc32.Castagnoli)
+
+type Header struct {
+	VirtualChainId primitives.VirtualChainId
+	NodeAddress    primitives.NodeAddress
+}
+
+// Record is a message sent or received by the recording node. the peers of a sent message are its recipients, and are empty
+// for a broadcast. the peer of a received message is its sender, when the transport authenticated it
+type Record struct {
+	Direction     Direction
+	Timestamp     time.Time
+	Topic         gossipmessages.HeaderTopic
+	RecipientMode gossipmessages.RecipientsListMode
+	Peers         []primitives.NodeAddress
+	Payloads      [][]byte
+}
+
+func topicOf(payloads [][]byte) gossipmessages.HeaderTopic {
+	if len(payloads) == 0 {
+		return TopicUnknown
+	}
+	header := gossipmessages.HeaderReader(payloads[0])
+	if !header.IsValid() {
+		return TopicUnknown
+	}
+	return header.Topic()
+}
+
+type Writer struct {
+	w       *bufio.Writer
+	written int64
+}
+
+// NewWriter writes the recording header, Flush must be called for records to reach the underlying writer
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	rw := &Writer{w: bufio.NewWriter(w)}
+	payload := appendUint32(nil, recordingMagic)
+	payload = appendUint32(payload, recordingVersion)
+	payload = appendUint32(payload, uint32(header.VirtualChainId))
+	payload = appendBytes(payload, header.NodeAddress)
+	if err := rw.writeRecord(payload); err != nil {
+		return nil, errors.Wrap(err, "failed to write gossip recording header")
+	}
+	return rw, nil
+}
+
+func (rw *Writer) WriteRecord(record *Record) error {
+	payload := appendUint32(nil, uint32(record.Direction))
+	payload = appendUint64(payload, uint64(record.Timestamp.UnixNano()))
+	payload = appendUint32(payload, uint32(record.Topic))
+	payload = appendUint32(payload, uint32(record.RecipientMode))
+	payload = appendUint32(payload, uint32(len(record.Peers)))
+	for _, peer := range record.Peers {
+		payload = appendBytes(payload, peer)
+	}
+	payload = appendUint32(payload, uint32(len(record.Payloads)))
+	for _, p := range record.Payloads {
+		payload = appendBytes(payload, p)
+	}
+
+	return errors.Wrap(rw.writeRecord(payload), "failed to write gossip recording record")
+}
+
+// Written returns the number of bytes written so far, including the header
+func (rw *Writer) Written() int64 {
+	return rw.written
+}
+
+// Flush does not close the underlying writer
+func (rw *Writer) Flush() error {
+	return rw.w.Flush()
+}
+
+// a record is its payload size, the payload and a checksum of the payload
+func (rw *Writer) writeRecord(payload []byte) error {
+	if len(payload) > maxRecordSize {
+		return errors.Errorf("record size %d exceeds max record size %d", len(payload), maxRecordSize)
+	}
+	record := appendUint32(nil, uint32(len(payload)))
+	record = append(record, payload...)
+	record = appendUint32(record, crc32.Checksum(payload, crc32Table))
+	n, err := rw.w.Write(record)
+	rw.written += int64(n)
+	return err
+}
+
+type Reader struct {
+	r          *bufio.Reader
+	header     Header
+	numRecords uint64
+}
+
+// NewReader reads the recording header
+func NewReader(r io.Reader) (*Reader, error) {
+	rr := &Reader{r: bufio.NewReader(r)}
+
+	payload, err := rr.readRecord()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read gossip recording header")
+	}
+
+	p := &payloadReader{buf: payload}
+	magic, version, vcid, nodeAddress := p.uint32(), p.uint32(), p.uint32(), p.bytes()
+	if p.err != nil || len(p.buf) > 0 {
+		return nil, errors.Errorf("invalid gossip recording header size %d", len(payload))
+	}
+	if magic != recordingMagic {
+		return nil, errors.Errorf("invalid gossip recording magic number %v", magic)
+	}
+	if version != recordingVersion {
+		return nil, errors.Errorf("unsupported gossip recording version %d", version)
+	}
+	rr.header = Header{VirtualChainId: primitives.VirtualChainId(vcid), NodeAddress: nodeAddress}
+	return rr, nil
+}
+
+func (rr *Reader) Header() Header {
+	return rr.header
+}
+
+// ReadRecord returns io.EOF after the last complete record, and an error caused by io.ErrUnexpectedEOF if the recording ends in the middle of one
+func (rr *Reader) ReadRecord() (*Record, error) {
+	payload, err := rr.readRecord()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read record %d of gossip recording", rr.numRecords+1)
+	}
+
+	p := &payloadReader{buf: payload}
+	record := &Record{
+		Direction:     Direction(p.uint32()),
+		Timestamp:     time.Unix(0, int64(p.uint64())),
+		Topic:         gossipmessages.HeaderTopic(p.uint32()),
+		RecipientMode: gossipmessages.RecipientsListMode(p.uint32()),
+	}
+	for i, numPeers := uint32(0), p.count(); i < numPeers; i++ {
+		record.Peers = append(record.Peers, p.bytes())
+	}
+	for i, numPayloads := uint32(0), p.count(); i < numPayloads; i++ {
+		record.Payloads = append(record.Payloads, p.bytes())
+	}
+
+	if p.err == nil && len(p.buf) > 0 {
+		p.err = errors.Errorf("record holds %d unexpected bytes", len(p.buf))
+	}
+	if p.err != nil {
+		return nil, errors.Wrapf(p.err, "invalid record %d of gossip recording", rr.numRecords+1)
+	}
+	rr.numRecords++
+	return record, nil
+}
+
+func (rr *Reader) readRecord() ([]byte, error) {
+	var size uint32
+	if err := binary.Read(rr.r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxRecordSize {
+		return nil, errors.Errorf("record size %d exceeds max record size %d", size, maxRecordSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, payload); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	var checksum uint32
+	if err := binary.Read(rr.r, binary.LittleEndian, &checksum); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if computed := crc32.Checksum(payload, crc32Table); computed != checksum {
+		return nil, errors.Errorf("record checksum mismatch. computed: %v recorded: %v", computed, checksum)
+	}
+	return payload, nil
+}
+
+type payloadReader struct {
+	buf []byte
+	err error
+}
+
+func (p *payloadReader) next(size int) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if size > len(p.buf) {
+		p.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	chunk := p.buf[:size]
+	p.buf = p.buf[size:]
+	return chunk
+}
+
+func (p *payloadReader) uint32() uint32 {
+	if chunk := p.next(4); chunk != nil {
+		return binary.LittleEndian.Uint32(chunk)
+	}
+	return 0
+}
+
+func (p *payloadReader) uint64() uint64 {
+	if chunk := p.next(8); chunk != nil {
+		return binary.LittleEndian.Uint64(chunk)
+	}
+	return 0
+}
+
+// a count of length prefixed fields, each taking at least 4 bytes
+func (p *payloadReader) count() uint32 {
+	count := p.uint32()
+	if p.err == nil && uint64(count) > uint64(len(p.buf)/4) {
+		p.err = errors.Errorf("record counts %d fields but holds %d bytes", count, len(p.buf))
+		return 0
+	}
+	return count
+}
+
+func (p *payloadReader) bytes() []byte {
+	return p.next(int(p.uint32()))
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendBytes(buf []byte, v []byte) []byte {
+	return append(appendUint32(buf, uint32(len(v))), v...)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
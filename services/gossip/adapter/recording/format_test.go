@@ -0,0 +1,106 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package recording
+
+import (
+	"bytes"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+func aLeanHelixHeader() []byte {
+	return (&gossipmessages.HeaderBuilder{
+		Topic:         gossipmessages.HEADER_TOPIC_LEAN_HELIX,
+		RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
+	}).Build().Raw()
+}
+
+func aRecording(t *testing.T, records ...*Record) []byte {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, Header{VirtualChainId: 42, NodeAddress: primitives.NodeAddress{0x01}})
+	require.NoError(t, err)
+	for _, record := range records {
+		require.NoError(t, w.WriteRecord(record))
+	}
+	require.NoError(t, w.Flush())
+	require.EqualValues(t, buf.Len(), w.Written())
+	return buf.Bytes()
+}
+
+func TestRecording_ReadsWhatWasWritten(t *testing.T) {
+	sent := &Record{
+		Direction:     DirectionSent,
+		Timestamp:     time.Unix(1000, 1),
+		Topic:         gossipmessages.HEADER_TOPIC_LEAN_HELIX,
+		RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_LIST,
+		Peers:         []primitives.NodeAddress{{0x02}, {0x03}},
+		Payloads:      [][]byte{aLeanHelixHeader(), {0xaa, 0xbb}},
+	}
+	received := &Record{
+		Direction:     DirectionReceived,
+		Timestamp:     time.Unix(1001, 2),
+		Topic:         TopicUnknown,
+		RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_LIST,
+		Payloads:      [][]byte{{0x01}},
+	}
+
+	r, err := NewReader(bytes.NewReader(aRecording(t, sent, received)))
+	require.NoError(t, err)
+	require.Equal(t, Header{VirtualChainId: 42, NodeAddress: primitives.NodeAddress{0x01}}, r.Header())
+
+	record, err := r.ReadRecord()
+	require.NoError(t, err)
+	require.Equal(t, sent, record)
+
+	record, err = r.ReadRecord()
+	require.NoError(t, err)
+	require.Equal(t, received, record)
+
+	_, err = r.ReadRecord()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestRecording_ReportsATruncatedLastRecord(t *testing.T) {
+	recording := aRecording(t, &Record{Direction: DirectionSent, Payloads: [][]byte{{0x01, 0x02}}})
+
+	r, err := NewReader(bytes.NewReader(recording[:len(recording)-3]))
+	require.NoError(t, err)
+
+	_, err = r.ReadRecord()
+	require.Equal(t, io.ErrUnexpectedEOF, errors.Cause(err))
+}
+
+func TestRecording_RejectsACorruptRecord(t *testing.T) {
+	recording := aRecording(t, &Record{Direction: DirectionSent, Payloads: [][]byte{{0x01, 0x02}}})
+	recording[len(recording)-6] ^= 0xff // a payload byte
+
+	r, err := NewReader(bytes.NewReader(recording))
+	require.NoError(t, err)
+
+	_, err = r.ReadRecord()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checksum")
+}
+
+func TestRecording_RejectsWhatIsNotARecording(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte{}))
+	require.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader(aRecording(t)[1:]))
+	require.Error(t, err)
+}
+
+func TestRecording_TopicOfMessagesWithoutAValidHeaderIsUnknown(t *testing.T) {
+	require.Equal(t, gossipmessages.HEADER_TOPIC_LEAN_HELIX, topicOf([][]byte{aLeanHelixHeader()}))
+	require.Equal(t, TopicUnknown, topicOf(nil))
+	require.Equal(t, TopicUnknown, topicOf([][]byte{{0x01}}))
+}
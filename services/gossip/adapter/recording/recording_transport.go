@@ -0,0 +1,238 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package recording
+
+import (
+	"context"
+	"fmt"
+	"github.com/orbs-network/govnr"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"os"
+	"time"
+)
+
+const RECORDING_QUEUE_MAX_RECORDS = 10000
+
+var LogTag = log.String("adapter", "gossip-recording")
+
+type Config interface {
+	NodeAddress() primitives.NodeAddress
+	VirtualChainId() primitives.VirtualChainId
+	GossipRecordingFilePath() string
+	GossipRecordingMaxFileSizeBytes() uint32
+	GossipRecordingMaxFiles() uint32
+}
+
+// RecordingTransport decorates a transport, recording every message sent through it and received from it to a file.
+// records are written in the background and dropped when the writer falls behind, so recording never slows gossip down.
+// once the file grows beyond its max size it is rotated: the file at path is renamed to path.1, path.1 to path.2 and so on,
+// keeping the configured number of files. every file starts with its own header so it can be replayed on its own
+type RecordingTransport struct {
+	govnr.TreeSupervisor
+	nested  adapter.Transport
+	config  Config
+	logger  log.Logger
+	records chan *Record
+
+	file   *os.File
+	writer *Writer
+
+	metrics struct {
+		records        *metric.Gauge
+		droppedRecords *metric.Gauge
+	}
+}
+
+type recordingListener struct {
+	adapter.TransportListener
+	transport *RecordingTransport
+}
+
+func NewRecordingTransport(ctx context.Context, config Config, nested adapter.Transport, parentLogger log.Logger, registry metric.Registry) (*RecordingTransport, error) {
+	t := &RecordingTransport{
+		nested:  nested,
+		config:  config,
+		logger:  parentLogger.WithTags(LogTag),
+		records: make(chan *Record, RECORDING_QUEUE_MAX_RECORDS),
+	}
+	t.metrics.records = registry.NewGauge("Gossip.Recording.Records.Count")
+	t.metrics.droppedRecords = registry.NewGauge("Gossip.Recording.DroppedRecords.Count")
+
+	if err := t.openFile(); err != nil {
+		return nil, err
+	}
+
+	t.Supervise(nested)
+	t.Supervise(govnr.Forever(ctx, "gossip recording writer", logfields.GovnrErrorer(t.logger), func() {
+		t.writeRecords(ctx)
+	}))
+
+	return t, nil
+}
+
+func (t *RecordingTransport) RegisterListener(listener adapter.TransportListener, listenerNodeAddress primitives.NodeAddress) {
+	t.nested.RegisterListener(&recordingListener{TransportListener: listener, transport: t}, listenerNodeAddress)
+}
+
+func (t *RecordingTransport) Send(ctx context.Context, data *adapter.TransportData) error {
+	t.record(&Record{
+		Direction:     DirectionSent,
+		Timestamp:     time.Now(),
+		Topic:         topicOf(data.Payloads),
+		RecipientMode: data.RecipientMode,
+		Peers:         data.RecipientNodeAddresses,
+		Payloads:      data.Payloads,
+	})
+	return t.nested.Send(ctx, data)
+}
+
+func (t *RecordingTransport) UpdateTopology(bgCtx context.Context, newPeers adapter.TransportPeers) {
+	t.nested.UpdateTopology(bgCtx, newPeers)
+}
+
+// the records still queued are written once the context given to NewRecordingTransport is cancelled
+func (t *RecordingTransport) GracefulShutdown(shutdownContext context.Context) {
+	t.nested.GracefulShutdown(shutdownContext)
+}
+
+func (l *recordingListener) OnTransportMessageReceived(ctx context.Context, payloads [][]byte) {
+	var peers []primitives.NodeAddress
+	if peer, ok := adapter.AuthenticatedPeerFromContext(ctx); ok {
+		peers = []primitives.NodeAddress{peer}
+	}
+	l.transport.record(&Record{
+		Direction:     DirectionReceived,
+		Timestamp:     time.Now(),
+		Topic:         topicOf(payloads),
+		RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_LIST,
+		Peers:         peers,
+		Payloads:      payloads,
+	})
+	l.TransportListener.OnTransportMessageReceived(ctx, payloads)
+}
+
+func (t *RecordingTransport) record(record *Record) {
+	select {
+	case t.records <- record:
+	default:
+		t.metrics.droppedRecords.Inc()
+	}
+}
+
+func (t *RecordingTransport) writeRecords(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			t.drain()
+			t.closeFile()
+			return
+		case record := <-t.records:
+			t.write(record)
+			if len(t.records) == 0 {
+				t.flush()
+			}
+		}
+	}
+}
+
+func (t *RecordingTransport) drain() {
+	for {
+		select {
+		case record := <-t.records:
+			t.write(record)
+		default:
+			return
+		}
+	}
+}
+
+func (t *RecordingTransport) write(record *Record) {
+	if t.writer == nil { // recording stopped after failing to rotate
+		t.metrics.droppedRecords.Inc()
+		return
+	}
+
+	if err := t.writer.WriteRecord(record); err != nil {
+		t.metrics.droppedRecords.Inc()
+		t.logger.Error("failed to record gossip message", log.Error(err))
+		return
+	}
+	t.metrics.records.Inc()
+
+	if t.writer.Written() >= int64(t.config.GossipRecordingMaxFileSizeBytes()) {
+		t.closeFile()
+		if err := t.openFile(); err != nil {
+			t.logger.Error("failed to rotate gossip recording, recording stopped", log.Error(err))
+		}
+	}
+}
+
+func (t *RecordingTransport) flush() {
+	if t.writer == nil {
+		return
+	}
+	if err := t.writer.Flush(); err != nil {
+		t.logger.Error("failed to flush gossip recording", log.Error(err))
+	}
+}
+
+// rotates the files of earlier recordings so none is overwritten, including those of an earlier run of the node
+func (t *RecordingTransport) openFile() error {
+	path := t.config.GossipRecordingFilePath()
+	if err := rotateFiles(path, int(t.config.GossipRecordingMaxFiles())); err != nil {
+		return errors.Wrapf(err, "failed to rotate gossip recording files of %s", path)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create gossip recording file %s", path)
+	}
+
+	writer, err := NewWriter(file, Header{VirtualChainId: t.config.VirtualChainId(), NodeAddress: t.config.NodeAddress()})
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	t.file = file
+	t.writer = writer
+	return nil
+}
+
+func (t *RecordingTransport) closeFile() {
+	if t.writer == nil {
+		return
+	}
+	t.flush()
+	if err := t.file.Close(); err != nil {
+		t.logger.Error("failed to close gossip recording file", log.Error(err))
+	}
+	t.file = nil
+	t.writer = nil
+}
+
+func rotatedFilePath(path string, index int) string {
+	if index == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+func rotateFiles(path string, maxFiles int) error {
+	for i := maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(rotatedFilePath(path, i-1), rotatedFilePath(path, i)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package recording
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/testkit"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var recordingNode = primitives.NodeAddress{0x01}
+var otherNode = primitives.NodeAddress{0x02}
+
+type recordingConfig struct {
+	path             string
+	maxFileSizeBytes uint32
+}
+
+func (c *recordingConfig) NodeAddress() primitives.NodeAddress {
+	return recordingNode
+}
+
+func (c *recordingConfig) VirtualChainId() primitives.VirtualChainId {
+	return 42
+}
+
+func (c *recordingConfig) GossipRecordingFilePath() string {
+	return c.path
+}
+
+func (c *recordingConfig) GossipRecordingMaxFileSizeBytes() uint32 {
+	return c.maxFileSizeBytes
+}
+
+func (c *recordingConfig) GossipRecordingMaxFiles() uint32 {
+	return 3
+}
+
+func aRecordingPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "gossip_recording")
+	require.NoError(t, err)
+	return filepath.Join(dir, "gossip.rec"), func() { _ = os.RemoveAll(dir) }
+}
+
+func readRecords(t *testing.T, path string) []*Record {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	r, err := NewReader(file)
+	require.NoError(t, err)
+	require.Equal(t, Header{VirtualChainId: 42, NodeAddress: recordingNode}, r.Header())
+
+	var records []*Record
+	for {
+		record, err := r.ReadRecord()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func aMessageTo(recipient primitives.NodeAddress, sender primitives.NodeAddress, payload byte) *adapter.TransportData {
+	return &adapter.TransportData{
+		SenderNodeAddress:      sender,
+		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
+		RecipientNodeAddresses: []primitives.NodeAddress{recipient},
+		Payloads:               [][]byte{aLeanHelixHeader(), {payload}},
+	}
+}
+
+func TestRecordingTransport_RecordsSentAndReceivedMessages(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		path, cleanup := aRecordingPath(t)
+		defer cleanup()
+
+		recordingCtx, cancel := context.WithCancel(ctx)
+		nested := memory.NewTransport(recordingCtx, harness.Logger, []primitives.NodeAddress{recordingNode, otherNode})
+		transport, err := NewRecordingTransport(recordingCtx, &recordingConfig{path: path, maxFileSizeBytes: 1024 * 1024}, nested, harness.Logger, metric.NewRegistry())
+		require.NoError(t, err)
+
+		listener := &testkit.MockTransportListener{}
+		listener.WhenOnTransportMessageReceived([][]byte{aLeanHelixHeader(), {0xbb}}).Return().Times(1)
+		transport.RegisterListener(listener, recordingNode)
+		otherListener := testkit.ListenTo(nested, otherNode)
+		otherListener.WhenOnTransportMessageReceived([][]byte{aLeanHelixHeader(), {0xaa}}).Return().Times(1)
+
+		require.NoError(t, transport.Send(ctx, aMessageTo(otherNode, recordingNode, 0xaa)))
+		require.NoError(t, nested.Send(ctx, aMessageTo(recordingNode, otherNode, 0xbb)))
+		require.NoError(t, test.EventuallyVerify(test.EVENTUALLY_ADAPTER_TIMEOUT, listener, otherListener), "expected messages to be delivered through the recording transport")
+
+		cancel()
+		transport.WaitUntilShutdown(ctx)
+
+		records := readRecords(t, path)
+		require.Len(t, records, 2)
+
+		require.Equal(t, DirectionSent, records[0].Direction)
+		require.Equal(t, gossipmessages.HEADER_TOPIC_LEAN_HELIX, records[0].Topic)
+		require.Equal(t, []primitives.NodeAddress{otherNode}, records[0].Peers)
+		require.Equal(t, [][]byte{aLeanHelixHeader(), {0xaa}}, records[0].Payloads)
+
+		require.Equal(t, DirectionReceived, records[1].Direction)
+		require.Equal(t, gossipmessages.HEADER_TOPIC_LEAN_HELIX, records[1].Topic)
+		require.Equal(t, []primitives.NodeAddress{otherNode}, records[1].Peers, "expected the authenticated sender to be recorded")
+		require.Equal(t, [][]byte{aLeanHelixHeader(), {0xbb}}, records[1].Payloads)
+		require.False(t, records[1].Timestamp.Before(records[0].Timestamp))
+	})
+}
+
+func TestRecordingTransport_RotatesFilesKeepingTheConfiguredNumber(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		path, cleanup := aRecordingPath(t)
+		defer cleanup()
+		require.NoError(t, ioutil.WriteFile(path, []byte("an earlier recording"), 0644))
+
+		recordingCtx, cancel := context.WithCancel(ctx)
+		nested := memory.NewTransport(recordingCtx, harness.Logger, []primitives.NodeAddress{recordingNode, otherNode})
+		transport, err := NewRecordingTransport(recordingCtx, &recordingConfig{path: path, maxFileSizeBytes: 1}, nested, harness.Logger, metric.NewRegistry()) // every record fills a file
+		require.NoError(t, err)
+
+		earlier, err := ioutil.ReadFile(path + ".1")
+		require.NoError(t, err, "expected the recording of an earlier run to be kept")
+		require.Equal(t, "an earlier recording", string(earlier))
+
+		for i := byte(1); i <= 4; i++ {
+			require.NoError(t, transport.Send(ctx, aMessageTo(otherNode, recordingNode, i)))
+		}
+		require.True(t, test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, func() bool {
+			return transport.metrics.records.IntValue() == 4
+		}))
+
+		cancel()
+		transport.WaitUntilShutdown(ctx)
+
+		require.Empty(t, readRecords(t, path))
+		require.Equal(t, [][]byte{aLeanHelixHeader(), {4}}, readRecords(t, path+".1")[0].Payloads)
+		require.Equal(t, [][]byte{aLeanHelixHeader(), {3}}, readRecords(t, path+".2")[0].Payloads)
+		_, err = os.Stat(path + ".3")
+		require.True(t, os.IsNotExist(err), "expected only 3 files to be kept")
+	})
+}
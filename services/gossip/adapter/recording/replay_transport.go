@@ -0,0 +1,161 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package recording
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplayTransport is a transport of a single node which only receives the messages of recordings made by that node.
+// messages are delivered with the timing they were received at, divided by the speed, and messages sent by the node go nowhere
+type ReplayTransport struct {
+	logger log.Logger
+	speed  float64
+
+	listener struct {
+		sync.Mutex
+		listener    adapter.TransportListener
+		nodeAddress primitives.NodeAddress
+		registered  chan struct{}
+	}
+
+	// the first replayed message and when it was delivered, later messages are timed relative to it across recordings
+	firstRecorded  time.Time
+	firstDelivered time.Time
+
+	sent uint64
+}
+
+type ReplayResult struct {
+	Delivered uint64
+	Skipped   uint64 // messages the node sent when it was recorded
+}
+
+// a speed of zero or less delivers messages as fast as the node takes them
+func NewReplayTransport(logger log.Logger, speed float64) *ReplayTransport {
+	t := &ReplayTransport{
+		logger: logger.WithTags(LogTag),
+		speed:  speed,
+	}
+	t.listener.registered = make(chan struct{})
+	return t
+}
+
+func (t *ReplayTransport) RegisterListener(listener adapter.TransportListener, listenerNodeAddress primitives.NodeAddress) {
+	t.listener.Lock()
+	defer t.listener.Unlock()
+
+	if t.listener.listener == nil {
+		close(t.listener.registered)
+	}
+	t.listener.listener = listener
+	t.listener.nodeAddress = listenerNodeAddress
+}
+
+func (t *ReplayTransport) Send(ctx context.Context, data *adapter.TransportData) error {
+	atomic.AddUint64(&t.sent, 1)
+	return nil
+}
+
+// Sent returns the number of messages the replaying node sent
+func (t *ReplayTransport) Sent() uint64 {
+	return atomic.LoadUint64(&t.sent)
+}
+
+func (t *ReplayTransport) UpdateTopology(bgCtx context.Context, newPeers adapter.TransportPeers) {
+}
+
+func (t *ReplayTransport) GracefulShutdown(shutdownContext context.Context) {
+}
+
+func (t *ReplayTransport) WaitUntilShutdown(shutdownContext context.Context) {
+}
+
+// Replay waits for the node to register its listener and delivers it the received messages of the recording, recordings
+// rotated from one another should be replayed oldest first
+func (t *ReplayTransport) Replay(ctx context.Context, r *Reader) (*ReplayResult, error) {
+	listener, nodeAddress, err := t.waitForListener(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !r.Header().NodeAddress.Equal(nodeAddress) {
+		return nil, errors.Errorf("gossip recording was made by node %s but the replaying node is %s", r.Header().NodeAddress, nodeAddress)
+	}
+
+	result := &ReplayResult{}
+	for {
+		record, err := r.ReadRecord()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if record.Direction != DirectionReceived {
+			result.Skipped++
+			continue
+		}
+
+		if err := t.waitUntilDue(ctx, record.Timestamp); err != nil {
+			return result, err
+		}
+
+		deliveryCtx := ctx
+		if len(record.Peers) == 1 {
+			deliveryCtx = adapter.ContextWithAuthenticatedPeer(ctx, record.Peers[0])
+		}
+		listener.OnTransportMessageReceived(deliveryCtx, record.Payloads)
+		result.Delivered++
+	}
+}
+
+func (t *ReplayTransport) waitForListener(ctx context.Context) (adapter.TransportListener, primitives.NodeAddress, error) {
+	select {
+	case <-t.listener.registered:
+	case <-ctx.Done():
+		return nil, nil, errors.Wrap(ctx.Err(), "node did not register a gossip listener")
+	}
+
+	t.listener.Lock()
+	defer t.listener.Unlock()
+	return t.listener.listener, t.listener.nodeAddress, nil
+}
+
+func (t *ReplayTransport) waitUntilDue(ctx context.Context, recorded time.Time) error {
+	if t.firstDelivered.IsZero() {
+		t.firstRecorded = recorded
+		t.firstDelivered = time.Now()
+		return nil
+	}
+	if t.speed <= 0 {
+		return ctx.Err()
+	}
+
+	due := t.firstDelivered.Add(time.Duration(float64(recorded.Sub(t.firstRecorded)) / t.speed))
+	wait := time.Until(due)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
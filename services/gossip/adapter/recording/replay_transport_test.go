@@ -0,0 +1,80 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package recording
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/testkit"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestReplayTransport_DeliversReceivedMessagesFromTheirSenders(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		start := time.Now()
+		r, err := NewReader(bytes.NewReader(aRecording(t,
+			&Record{Direction: DirectionReceived, Timestamp: start, Peers: []primitives.NodeAddress{otherNode}, Payloads: [][]byte{{0x01}}},
+			&Record{Direction: DirectionSent, Timestamp: start.Add(10 * time.Millisecond), Payloads: [][]byte{{0x02}}},
+			&Record{Direction: DirectionReceived, Timestamp: start.Add(50 * time.Millisecond), Payloads: [][]byte{{0x03}}},
+		)))
+		require.NoError(t, err)
+
+		transport := NewReplayTransport(harness.Logger, 1)
+		var delivered []time.Time
+		listener := testkit.ListenTo(transport, recordingNode)
+		listener.WhenOnTransportMessageReceived([][]byte{{0x01}}).Call(func(ctx context.Context, payloads [][]byte) {
+			peer, ok := adapter.AuthenticatedPeerFromContext(ctx)
+			require.True(t, ok, "expected a message to be delivered from its recorded sender")
+			require.Equal(t, otherNode, peer)
+			delivered = append(delivered, time.Now())
+		}).Times(1)
+		listener.WhenOnTransportMessageReceived([][]byte{{0x03}}).Call(func(ctx context.Context, payloads [][]byte) {
+			_, ok := adapter.AuthenticatedPeerFromContext(ctx)
+			require.False(t, ok, "expected a message recorded without a sender to be delivered without one")
+			delivered = append(delivered, time.Now())
+		}).Times(1)
+		listener.Never("OnTransportMessageReceived", mock.Any, [][]byte{{0x02}})
+
+		result, err := transport.Replay(ctx, r)
+		require.NoError(t, err)
+		require.Equal(t, &ReplayResult{Delivered: 2, Skipped: 1}, result)
+		_, err = listener.Verify()
+		require.NoError(t, err)
+		// the first message is delivered after the replay timing starts, so the second is only due 50ms after that start
+		due := transport.firstDelivered.Add(50 * time.Millisecond)
+		require.False(t, delivered[0].Before(transport.firstDelivered), "expected the first message to start the replay timing")
+		require.False(t, delivered[1].Before(due), "expected messages to be replayed with their recorded timing (delivered at %s, due at %s)", delivered[1], due)
+	})
+}
+
+func TestReplayTransport_RejectsARecordingOfAnotherNode(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		r, err := NewReader(bytes.NewReader(aRecording(t)))
+		require.NoError(t, err)
+
+		transport := NewReplayTransport(harness.Logger, 0)
+		testkit.ListenTo(transport, otherNode)
+
+		_, err = transport.Replay(ctx, r)
+		require.Error(t, err)
+	})
+}
+
+func TestReplayTransport_DiscardsMessagesSentByTheNode(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		transport := NewReplayTransport(harness.Logger, 0)
+
+		require.NoError(t, transport.Send(ctx, aMessageTo(otherNode, recordingNode, 0x01)))
+		require.EqualValues(t, 1, transport.Sent())
+	})
+}
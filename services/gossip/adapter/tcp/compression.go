@@ -0,0 +1,134 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/snappy"
+	"github.com/orbs-network/membuffers/go"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/pkg/errors"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// a client which enables compression proposes it right after connecting by sending the proposal in place of the number
+// of payloads of a message followed by the codec it supports, the server replies with the codec it accepted. once accepted
+// the client may compress any payload it sends, marking it with a flag in the payload size
+const compressionProposal = uint32(0xfffffff0) // larger than MAX_PAYLOADS_IN_MESSAGE so never a valid number of payloads
+const compressedPayloadFlag = uint32(1 << 31)  // larger than MAX_PAYLOAD_SIZE_BYTES so never part of a valid payload size
+
+const (
+	compressionCodecNone   = uint32(0)
+	compressionCodecSnappy = uint32(1)
+)
+
+// returns true if the server accepted compressing the payloads sent on this connection
+func proposeCompression(ctx context.Context, conn net.Conn, timeout time.Duration) (bool, error) {
+	proposal := make([]byte, 8)
+	membuffers.WriteUint32(proposal, compressionProposal)
+	membuffers.WriteUint32(proposal[4:], compressionCodecSnappy)
+	if err := write(ctx, conn, proposal, timeout); err != nil {
+		return false, errors.Wrap(err, "failed sending compression proposal")
+	}
+
+	reply, err := readTotal(ctx, conn, 4, timeout)
+	if err != nil {
+		return false, errors.Wrap(err, "failed receiving compression proposal reply")
+	}
+	return membuffers.GetUint32(reply) == compressionCodecSnappy, nil
+}
+
+// called by the server once it received a compression proposal, returns true if the received payloads may be compressed
+func replyToCompressionProposal(ctx context.Context, conn net.Conn, enabled bool, timeout time.Duration) (bool, error) {
+	codec, err := readTotal(ctx, conn, 4, timeout)
+	if err != nil {
+		return false, errors.Wrap(err, "failed receiving compression proposal")
+	}
+
+	accepted := enabled && membuffers.GetUint32(codec) == compressionCodecSnappy
+	reply := make([]byte, 4)
+	membuffers.WriteUint32(reply, compressionCodecNone)
+	if accepted {
+		membuffers.WriteUint32(reply, compressionCodecSnappy)
+	}
+	if err := write(ctx, conn, reply, timeout); err != nil {
+		return false, errors.Wrap(err, "failed sending compression proposal reply")
+	}
+	return accepted, nil
+}
+
+func decompressPayload(compressed []byte) ([]byte, error) {
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, errors.Wrap(err, "received a corrupt compressed payload")
+	}
+	if size > MAX_PAYLOAD_SIZE_BYTES {
+		return nil, errors.Wrapf(errOversizedMessage, "received a compressed payload too big: %d bytes", size)
+	}
+
+	payload, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, errors.Wrap(err, "received a corrupt compressed payload")
+	}
+	return payload, nil
+}
+
+// compresses the payloads sent on connections which negotiated compression, shared by all outgoing connections
+type payloadCompressor struct {
+	thresholdBytes int
+	topics         []*topicCompressionMetrics // by queue lane
+}
+
+type topicCompressionMetrics struct {
+	rawBytes  int64
+	sentBytes int64
+	percent   *metric.Gauge
+}
+
+func newPayloadCompressor(thresholdBytes uint32, registry metric.Registry) *payloadCompressor {
+	c := &payloadCompressor{thresholdBytes: int(thresholdBytes)}
+	for _, spec := range queueLaneSpecs {
+		c.topics = append(c.topics, &topicCompressionMetrics{
+			percent: registry.NewGauge(fmt.Sprintf("Gossip.OutgoingConnection.Compression.%s.Percent", spec.name)),
+		})
+	}
+	return c
+}
+
+// returns the payloads to send and whether each of them was compressed, payloads below the threshold or which do not
+// get smaller are sent as is
+func (c *payloadCompressor) compress(data *adapter.TransportData) ([][]byte, []bool) {
+	payloads := make([][]byte, len(data.Payloads))
+	compressed := make([]bool, len(data.Payloads))
+	sentBytes := 0
+	for i, payload := range data.Payloads {
+		payloads[i] = payload
+		if len(payload) >= c.thresholdBytes {
+			if encoded := snappy.Encode(nil, payload); len(encoded) < len(payload) {
+				payloads[i] = encoded
+				compressed[i] = true
+			}
+		}
+		sentBytes += len(payloads[i])
+	}
+
+	c.topics[laneOf(data)].record(data.TotalSize(), sentBytes)
+	return payloads, compressed
+}
+
+// the percent is of all bytes sent by the topic on compressing connections, so 100 means nothing was saved
+func (m *topicCompressionMetrics) record(rawBytes int, sentBytes int) {
+	raw := atomic.AddInt64(&m.rawBytes, int64(rawBytes))
+	sent := atomic.AddInt64(&m.sentBytes, int64(sentBytes))
+	if raw > 0 {
+		m.percent.Update(sent * 100 / raw)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/snappy"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+type compressionHarness struct {
+	registry metric.Registry
+	client   *outgoingConnection
+	server   *transportServer
+}
+
+func newCompressionHarness(harness *with.LoggingHarness, serverCompressionEnabled bool) *compressionHarness {
+	registry := metric.NewRegistry()
+	return &compressionHarness{
+		registry: registry,
+		client: &outgoingConnection{
+			config:       &timeouts{},
+			compressor:   newPayloadCompressor(16, registry),
+			connectivity: newPeerConnectivity(),
+		},
+		server: newServer(&serverCfg{compressionEnabled: serverCompressionEnabled}, harness.Logger, registry),
+	}
+}
+
+// negotiates compression and sends data from the client to the server, returning what the server received
+func (h *compressionHarness) negotiateAndSend(ctx context.Context, t *testing.T, data *adapter.TransportData) (bool, [][]byte) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	type received struct {
+		payloads [][]byte
+		err      error
+	}
+	serverDone := make(chan received)
+	go func() {
+		compressed := false
+		_, err := h.server.receiveTransportData(ctx, serverConn, &compressed) // the proposal
+		if err != nil {
+			serverDone <- received{err: err}
+			return
+		}
+		payloads, err := h.server.receiveTransportData(ctx, serverConn, &compressed)
+		serverDone <- received{payloads: payloads, err: err}
+	}()
+
+	compressed, err := proposeCompression(ctx, clientConn, TEST_NETWORK_TIMEOUT)
+	require.NoError(t, err)
+	require.NoError(t, h.client.sendToSocket(ctx, clientConn, data, compressed))
+
+	res := <-serverDone
+	require.NoError(t, res.err)
+	return compressed, res.payloads
+}
+
+func aCompressibleBlockSyncMessage() *adapter.TransportData {
+	header := (&gossipmessages.HeaderBuilder{Topic: gossipmessages.HEADER_TOPIC_BLOCK_SYNC}).Build()
+	return &adapter.TransportData{Payloads: [][]byte{header.Raw(), bytes.Repeat([]byte{0x17}, 10000), {0x01, 0x02, 0x03}}}
+}
+
+func TestCompression_PayloadsArriveIntactWhenBothPeersEnableIt(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			h := newCompressionHarness(parent, true)
+
+			data := aCompressibleBlockSyncMessage()
+			compressed, payloads := h.negotiateAndSend(ctx, t, data)
+			require.True(t, compressed, "expected the server to accept compression")
+			require.Equal(t, data.Payloads, payloads)
+
+			percent := h.registry.Get("Gossip.OutgoingConnection.Compression.BlockSync.Percent").(*metric.Gauge).IntValue()
+			require.True(t, percent > 0 && percent < 10, "expected the repetitive payload to compress well but got %d%%", percent)
+		})
+	})
+}
+
+func TestCompression_PayloadsAreSentAsIsWhenServerDisablesIt(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			h := newCompressionHarness(parent, false)
+
+			data := aCompressibleBlockSyncMessage()
+			compressed, payloads := h.negotiateAndSend(ctx, t, data)
+			require.False(t, compressed, "expected the server to decline compression")
+			require.Equal(t, data.Payloads, payloads)
+			require.EqualValues(t, 0, h.registry.Get("Gossip.OutgoingConnection.Compression.BlockSync.Percent").(*metric.Gauge).IntValue())
+		})
+	})
+}
+
+func TestCompression_PayloadsBelowThresholdAreNotCompressed(t *testing.T) {
+	compressor := newPayloadCompressor(16, metric.NewRegistry())
+
+	small := bytes.Repeat([]byte{0x17}, 15)
+	large := bytes.Repeat([]byte{0x17}, 64)
+	payloads, compressed := compressor.compress(&adapter.TransportData{Payloads: [][]byte{small, large}})
+
+	require.Equal(t, []bool{false, true}, compressed)
+	require.Equal(t, small, payloads[0])
+	require.True(t, len(payloads[1]) < len(large))
+}
+
+func TestCompression_ServerRejectsCompressedPayloadsWithoutNegotiation(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			h := newCompressionHarness(parent, true)
+			h.client.compressor.thresholdBytes = 0
+
+			clientConn, serverConn := net.Pipe()
+			defer serverConn.Close()
+			go func() {
+				_ = h.client.sendToSocket(ctx, clientConn, aCompressibleBlockSyncMessage(), true)
+				_ = clientConn.Close()
+			}()
+
+			compressed := false
+			_, err := h.server.receiveTransportData(ctx, serverConn, &compressed)
+			require.Error(t, err)
+		})
+	})
+}
+
+func TestCompression_DecompressRejectsPayloadsExpandingBeyondMaxSize(t *testing.T) {
+	_, err := decompressPayload(snappy.Encode(nil, make([]byte, MAX_PAYLOAD_SIZE_BYTES+1)))
+	require.Error(t, err)
+
+	_, err = decompressPayload([]byte{0xff, 0xff, 0xff})
+	require.Error(t, err, "expected a corrupt payload to be rejected")
+}
@@ -8,6 +8,7 @@ package tcp
 
 import (
 	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
 	"github.com/orbs-network/govnr"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
@@ -32,13 +33,25 @@ type DirectTransport struct {
 	server              *transportServer
 }
 
-func NewDirectTransport(parentCtx context.Context, config config.GossipTransportConfig, parentLogger log.Logger, registry metric.Registry) *DirectTransport {
+// nodeSigner proves ownership of the node address to peers, it is only used when gossip authentication is enabled and may be nil otherwise
+func NewDirectTransport(parentCtx context.Context, config config.GossipTransportConfig, nodeSigner signer.Signer, parentLogger log.Logger, registry metric.Registry) *DirectTransport {
 	logger := parentLogger.WithTags(LogTag)
+
+	var peerHandshake *handshake
+	if config.GossipAuthenticationEnabled() {
+		if nodeSigner == nil {
+			panic("gossip authentication is enabled but no signer was given to the direct transport")
+		}
+		peerHandshake = newHandshake(nodeSigner, config.NodeAddress(), config.GossipNetworkTimeout())
+	}
+
 	t := &DirectTransport{
 		logger:              logger,
-		outgoingConnections: newOutgoingConnections(logger, registry, config),
+		outgoingConnections: newOutgoingConnections(logger, registry, config, peerHandshake),
 		server:              newServer(config, parentLogger.WithTags(log.String("component", "tcp-transport-server")), registry),
 	}
+	t.server.handshake = peerHandshake
+	t.server.isPeerAllowed = t.outgoingConnections.isInTopology
 
 	t.Supervise(t.server)
 	t.Supervise(t.outgoingConnections)
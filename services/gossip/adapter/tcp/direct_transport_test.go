@@ -21,6 +21,7 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/scribe/log"
 	"github.com/stretchr/testify/require"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -29,7 +30,7 @@ func TestDirectTransport_HandlesStartupWithEmptyPeerList(t *testing.T) {
 	address := keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress()
 	cfg := config.ForDirectTransportTests(address, 20*time.Hour /*disable keep alive*/, 1*time.Second)
 	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
-		transport := NewDirectTransport(ctx, cfg, harness.Logger, metric.NewRegistry())
+		transport := NewDirectTransport(ctx, cfg, nil, harness.Logger, metric.NewRegistry())
 		harness.Supervise(transport)
 		defer transport.GracefulShutdown(ctx)
 
@@ -220,6 +221,39 @@ func TestDirectTransport_FailsGracefullyIfCapacityExceeded(t *testing.T) {
 	})
 }
 
+func TestDirectTransport_ReportsConnectivityOfTopologyPeers(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		node1 := aNode(ctx, harness.Logger)
+		node2 := aNode(ctx, harness.Logger)
+		superviseAll(harness, node1, node2)
+		defer shutdownAll(ctx, node1, node2)
+
+		waitForAllNodesToSatisfy(t, "server did not start", func(node *nodeHarness) bool { return node.transport.IsServerListening() }, node1, node2)
+
+		topology := aTopologyContaining(node1, node2)
+		node1.updateTopology(ctx, topology)
+		node2.updateTopology(ctx, topology)
+		waitForAllNodesToSatisfy(t,
+			"expected all outgoing queues to become enabled after topology change",
+			func(node *nodeHarness) bool { return node.transport.allOutgoingQueuesEnabled() },
+			node1, node2)
+
+		node1.requireSendsSuccessfullyTo(t, ctx, node2)
+		require.True(t, test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, func() bool {
+			return !node1.transport.PeerConnectivity()[0].LastSuccessfulSend.IsZero()
+		}), "expected the send to be reported")
+
+		peers := node1.transport.PeerConnectivity()
+		require.Len(t, peers, 1, "expected only the other topology peer to be reported")
+		require.Equal(t, hex.EncodeToString(node2.address), peers[0].NodeAddress)
+		require.Equal(t, node2.toGossipPeer().Endpoint()+":"+strconv.Itoa(node2.transport.GetServerPort()), peers[0].Endpoint)
+		require.Equal(t, adapter.PeerConnected, peers[0].State)
+		require.NotZero(t, peers[0].BytesSentByTopic["LeanHelixConsensus"])
+		require.Zero(t, peers[0].BytesSentByTopic["BlockSync"])
+		require.Zero(t, peers[0].QueueDepth)
+	})
+}
+
 type nodeHarness struct {
 	transport *DirectTransport
 	address   primitives.NodeAddress
@@ -285,7 +319,7 @@ func aVeryLargeMessage() [][]byte {
 func aNode(ctx context.Context, logger log.Logger) *nodeHarness {
 	address := aKey()
 	cfg := config.ForDirectTransportTests(address, 20*time.Hour /*disable keep alive*/, 1*time.Second)
-	transport := NewDirectTransport(ctx, cfg, logger, metric.NewRegistry())
+	transport := NewDirectTransport(ctx, cfg, nil, logger, metric.NewRegistry())
 	listener := &testkit.MockTransportListener{}
 	transport.RegisterListener(listener, address)
 	return &nodeHarness{transport, address, listener}
@@ -0,0 +1,230 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/digest"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"net"
+	"time"
+)
+
+const handshakeMagic = uint32(0x4f524853) // "ORHS"
+const handshakeVersion = uint32(1)
+const handshakeHelloSize = 4 + 4 + digest.NODE_ADDRESS_SIZE_BYTES + curve25519.PointSize
+const maxHandshakeSignatureSize = 1024
+
+const clientRole = byte('c')
+const serverRole = byte('s')
+
+var handshakeSignatureDomain = []byte("orbs-gossip-handshake")
+var sessionKeysInfo = []byte("orbs-gossip-session-keys")
+
+// the handshake proves each side owns its node address and agrees on the keys encrypting the rest of the session:
+// both sides send a hello with their node address and an ephemeral key, then each signs the hellos with its node key
+type handshake struct {
+	signer      signer.Signer
+	nodeAddress primitives.NodeAddress
+	timeout     time.Duration
+}
+
+type handshakeHello struct {
+	nodeAddress primitives.NodeAddress
+	publicKey   []byte
+}
+
+func newHandshake(nodeSigner signer.Signer, nodeAddress primitives.NodeAddress, timeout time.Duration) *handshake {
+	return &handshake{
+		signer:      nodeSigner,
+		nodeAddress: nodeAddress,
+		timeout:     timeout,
+	}
+}
+
+// returns a connection encrypting all traffic to a peer which proved it owns peerNodeAddress
+func (h *handshake) client(ctx context.Context, conn net.Conn, peerNodeAddress primitives.NodeAddress) (net.Conn, error) {
+	privateKey, hello, err := h.newHello()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := write(ctx, conn, hello.encode(), h.timeout); err != nil {
+		return nil, errors.Wrap(err, "failed sending handshake hello")
+	}
+
+	peerHello, err := h.readHello(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if !peerHello.nodeAddress.Equal(peerNodeAddress) {
+		return nil, errors.Errorf("handshake peer claims node address %s but %s was expected", peerHello.nodeAddress, peerNodeAddress)
+	}
+
+	transcript := transcriptOf(hello, peerHello)
+	if err := h.sendSignature(ctx, conn, clientRole, transcript); err != nil {
+		return nil, err
+	}
+	if err := h.verifySignature(ctx, conn, serverRole, transcript, peerHello.nodeAddress); err != nil {
+		return nil, err
+	}
+
+	clientKey, serverKey, err := sessionKeys(privateKey, peerHello.publicKey, transcript)
+	if err != nil {
+		return nil, err
+	}
+	return newSecureConn(conn, clientKey, serverKey)
+}
+
+// returns a connection encrypting all traffic to a peer which proved it owns the returned node address, isAllowed rejects peers before they are authenticated
+func (h *handshake) server(ctx context.Context, conn net.Conn, isAllowed func(primitives.NodeAddress) bool) (net.Conn, primitives.NodeAddress, error) {
+	peerHello, err := h.readHello(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isAllowed(peerHello.nodeAddress) {
+		return nil, nil, errors.Errorf("handshake peer %s is not in the topology", peerHello.nodeAddress)
+	}
+
+	privateKey, hello, err := h.newHello()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := write(ctx, conn, hello.encode(), h.timeout); err != nil {
+		return nil, nil, errors.Wrap(err, "failed sending handshake hello")
+	}
+
+	transcript := transcriptOf(peerHello, hello)
+	if err := h.verifySignature(ctx, conn, clientRole, transcript, peerHello.nodeAddress); err != nil {
+		return nil, nil, err
+	}
+	if err := h.sendSignature(ctx, conn, serverRole, transcript); err != nil {
+		return nil, nil, err
+	}
+
+	clientKey, serverKey, err := sessionKeys(privateKey, peerHello.publicKey, transcript)
+	if err != nil {
+		return nil, nil, err
+	}
+	secured, err := newSecureConn(conn, serverKey, clientKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secured, peerHello.nodeAddress, nil
+}
+
+func (h *handshake) newHello() ([]byte, *handshakeHello, error) {
+	privateKey := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, privateKey); err != nil {
+		return nil, nil, errors.Wrap(err, "failed generating handshake key")
+	}
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed generating handshake key")
+	}
+	return privateKey, &handshakeHello{nodeAddress: h.nodeAddress, publicKey: publicKey}, nil
+}
+
+func (h *handshake) readHello(ctx context.Context, conn net.Conn) (*handshakeHello, error) {
+	raw, err := readTotal(ctx, conn, handshakeHelloSize, h.timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading handshake hello")
+	}
+	return decodeHandshakeHello(raw)
+}
+
+func (h *handshake) sendSignature(ctx context.Context, conn net.Conn, role byte, transcript []byte) error {
+	sig, err := h.signer.Sign(ctx, signedHandshakeData(role, transcript))
+	if err != nil {
+		return errors.Wrap(err, "failed signing handshake")
+	}
+
+	buf := make([]byte, 4+len(sig))
+	binary.LittleEndian.PutUint32(buf, uint32(len(sig)))
+	copy(buf[4:], sig)
+	return errors.Wrap(write(ctx, conn, buf, h.timeout), "failed sending handshake signature")
+}
+
+func (h *handshake) verifySignature(ctx context.Context, conn net.Conn, role byte, transcript []byte, peerNodeAddress primitives.NodeAddress) error {
+	sizeBuffer, err := readTotal(ctx, conn, 4, h.timeout)
+	if err != nil {
+		return errors.Wrap(err, "failed reading handshake signature")
+	}
+	size := binary.LittleEndian.Uint32(sizeBuffer)
+	if size > maxHandshakeSignatureSize {
+		return errors.Errorf("received handshake signature too big: %d bytes", size)
+	}
+	sig, err := readTotal(ctx, conn, size, h.timeout)
+	if err != nil {
+		return errors.Wrap(err, "failed reading handshake signature")
+	}
+
+	if err := digest.VerifyNodeSignature(peerNodeAddress, signedHandshakeData(role, transcript), sig); err != nil {
+		return errors.Wrapf(err, "handshake peer failed to prove it owns node address %s", peerNodeAddress)
+	}
+	return nil
+}
+
+func (h *handshakeHello) encode() []byte {
+	raw := make([]byte, handshakeHelloSize)
+	binary.LittleEndian.PutUint32(raw[0:4], handshakeMagic)
+	binary.LittleEndian.PutUint32(raw[4:8], handshakeVersion)
+	copy(raw[8:8+digest.NODE_ADDRESS_SIZE_BYTES], h.nodeAddress)
+	copy(raw[8+digest.NODE_ADDRESS_SIZE_BYTES:], h.publicKey)
+	return raw
+}
+
+func decodeHandshakeHello(raw []byte) (*handshakeHello, error) {
+	if len(raw) != handshakeHelloSize {
+		return nil, errors.Errorf("invalid handshake hello size %d", len(raw))
+	}
+	if magic := binary.LittleEndian.Uint32(raw[0:4]); magic != handshakeMagic {
+		return nil, errors.Errorf("invalid handshake magic number %x, is the peer authenticating its connections?", magic)
+	}
+	if version := binary.LittleEndian.Uint32(raw[4:8]); version != handshakeVersion {
+		return nil, errors.Errorf("unsupported handshake version %d", version)
+	}
+	return &handshakeHello{
+		nodeAddress: primitives.NodeAddress(append([]byte{}, raw[8:8+digest.NODE_ADDRESS_SIZE_BYTES]...)),
+		publicKey:   append([]byte{}, raw[8+digest.NODE_ADDRESS_SIZE_BYTES:]...),
+	}, nil
+}
+
+func transcriptOf(clientHello *handshakeHello, serverHello *handshakeHello) []byte {
+	transcript := sha256.New()
+	transcript.Write(clientHello.encode())
+	transcript.Write(serverHello.encode())
+	return transcript.Sum(nil)
+}
+
+// the role is signed so a signature cannot be reflected back to the peer which made it
+func signedHandshakeData(role byte, transcript []byte) []byte {
+	data := append([]byte{}, handshakeSignatureDomain...)
+	data = append(data, role)
+	return append(data, transcript...)
+}
+
+func sessionKeys(privateKey []byte, peerPublicKey []byte, transcript []byte) (clientKey []byte, serverKey []byte, err error) {
+	shared, err := curve25519.X25519(privateKey, peerPublicKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed agreeing on session keys")
+	}
+
+	keys := make([]byte, 2*sessionKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, transcript, sessionKeysInfo), keys); err != nil {
+		return nil, nil, errors.Wrap(err, "failed deriving session keys")
+	}
+	return keys[:sessionKeySize], keys[sessionKeySize:], nil
+}
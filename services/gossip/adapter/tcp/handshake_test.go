@@ -0,0 +1,147 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+const TEST_HANDSHAKE_TIMEOUT = 1 * time.Second
+
+type handshakeResult struct {
+	conn            net.Conn
+	peerNodeAddress primitives.NodeAddress
+	err             error
+}
+
+func aHandshakeOfNode(nodeIndex int) *handshake {
+	key := keys.EcdsaSecp256K1KeyPairForTests(nodeIndex)
+	return newHandshake(signer.NewLocalSigner(key.PrivateKey()), key.NodeAddress(), TEST_HANDSHAKE_TIMEOUT)
+}
+
+func allowAll(primitives.NodeAddress) bool {
+	return true
+}
+
+func runHandshake(ctx context.Context, client *handshake, server *handshake, expectedServer primitives.NodeAddress, isAllowed func(primitives.NodeAddress) bool) (clientResult handshakeResult, serverResult handshakeResult) {
+	clientConn, serverConn := net.Pipe()
+
+	serverDone := make(chan handshakeResult)
+	go func() {
+		conn, peerNodeAddress, err := server.server(ctx, serverConn, isAllowed)
+		if err != nil {
+			_ = serverConn.Close() // unblocks the client
+		}
+		serverDone <- handshakeResult{conn: conn, peerNodeAddress: peerNodeAddress, err: err}
+	}()
+
+	conn, err := client.client(ctx, clientConn, expectedServer)
+	if err != nil {
+		_ = clientConn.Close() // unblocks the server
+	}
+	return handshakeResult{conn: conn, err: err}, <-serverDone
+}
+
+func TestHandshake_AuthenticatesBothSidesAndEncryptsTraffic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, server := aHandshakeOfNode(0), aHandshakeOfNode(1)
+	clientResult, serverResult := runHandshake(ctx, client, server, server.nodeAddress, allowAll)
+	require.NoError(t, clientResult.err)
+	require.NoError(t, serverResult.err)
+	defer clientResult.conn.Close()
+	defer serverResult.conn.Close()
+	require.EqualValues(t, client.nodeAddress, serverResult.peerNodeAddress, "expected the server to learn the authenticated client address")
+
+	payload := exampleWireProtocolEncoding_Payloads_0x11_0x2233()
+	go func() {
+		_ = write(ctx, clientResult.conn, payload, TEST_HANDSHAKE_TIMEOUT)
+	}()
+	received, err := readTotal(ctx, serverResult.conn, uint32(len(payload)), TEST_HANDSHAKE_TIMEOUT)
+	require.NoError(t, err)
+	require.Equal(t, payload, received)
+
+	big := make([]byte, 3*maxRecordPlaintextSize+5)
+	for i := range big {
+		big[i] = byte(i)
+	}
+	go func() {
+		_ = write(ctx, serverResult.conn, big, TEST_HANDSHAKE_TIMEOUT)
+	}()
+	received, err = readTotal(ctx, clientResult.conn, uint32(len(big)), TEST_HANDSHAKE_TIMEOUT)
+	require.NoError(t, err)
+	require.Equal(t, big, received, "expected writes larger than a record to arrive intact")
+}
+
+func TestHandshake_ClientRejectsServerWithUnexpectedAddress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, server := aHandshakeOfNode(0), aHandshakeOfNode(1)
+	clientResult, serverResult := runHandshake(ctx, client, server, keys.EcdsaSecp256K1KeyPairForTests(2).NodeAddress(), allowAll)
+	require.Error(t, clientResult.err)
+	require.Error(t, serverResult.err)
+}
+
+func TestHandshake_RejectsPeerClaimingAnAddressItDoesNotOwn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	impersonated := keys.EcdsaSecp256K1KeyPairForTests(2).NodeAddress()
+	spoofingClient := newHandshake(signer.NewLocalSigner(keys.EcdsaSecp256K1KeyPairForTests(0).PrivateKey()), impersonated, TEST_HANDSHAKE_TIMEOUT)
+	server := aHandshakeOfNode(1)
+
+	_, serverResult := runHandshake(ctx, spoofingClient, server, server.nodeAddress, allowAll)
+	require.Error(t, serverResult.err, "expected the server to reject a client signing with a key of another node")
+
+	spoofingServer := newHandshake(signer.NewLocalSigner(keys.EcdsaSecp256K1KeyPairForTests(1).PrivateKey()), impersonated, TEST_HANDSHAKE_TIMEOUT)
+	clientResult, _ := runHandshake(ctx, aHandshakeOfNode(0), spoofingServer, impersonated, allowAll)
+	require.Error(t, clientResult.err, "expected the client to reject a server signing with a key of another node")
+}
+
+func TestHandshake_ServerRejectsPeerNotInTopology(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, server := aHandshakeOfNode(0), aHandshakeOfNode(1)
+	clientResult, serverResult := runHandshake(ctx, client, server, server.nodeAddress, func(primitives.NodeAddress) bool {
+		return false
+	})
+	require.Error(t, serverResult.err)
+	require.Error(t, clientResult.err)
+}
+
+func TestSecureConn_RejectsTamperedRecords(t *testing.T) {
+	key := make([]byte, sessionKeySize)
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	sender, err := newSecureConn(clientConn, key, key)
+	require.NoError(t, err)
+	receiver, err := newSecureConn(serverConn, key, key)
+	require.NoError(t, err)
+
+	go func() {
+		record := make([]byte, 4, 4+3+sender.sealer.Overhead())
+		record = sender.sealer.Seal(record, nonce(0), []byte{0x11, 0x22, 0x33}, nil)
+		record[len(record)-1] ^= 0x55
+		record[0] = byte(len(record) - 4)
+		_, _ = clientConn.Write(record)
+	}()
+
+	_, err = io.ReadFull(receiver, make([]byte, 3))
+	require.Error(t, err, "expected a tampered record to fail decryption")
+}
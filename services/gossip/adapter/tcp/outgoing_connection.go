@@ -8,6 +8,7 @@ package tcp
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"github.com/orbs-network/govnr"
 	"github.com/orbs-network/membuffers/go"
@@ -34,6 +35,9 @@ type outgoingConnection struct {
 	sharedMetrics  *outgoingConnectionMetrics // TODO this is smelly, see how we can restructure metrics so that an outgoing connection doesn't have to share the parent metrics
 	queue          *transportQueue
 	peerHexAddress string
+	handshake      *handshake         // nil when gossip authentication is disabled
+	compressor     *payloadCompressor // nil when gossip compression is disabled
+	connectivity   *peerConnectivity
 	cancel         context.CancelFunc
 
 	sendErrors      *metric.Gauge
@@ -43,7 +47,7 @@ type outgoingConnection struct {
 }
 
 func newOutgoingConnection(peer adapter.TransportPeer, parentLogger log.Logger, metricFactory metric.Registry, sharedMetrics *outgoingConnectionMetrics, transportConfig timingsConfig) *outgoingConnection {
-	networkAddress := fmt.Sprintf("%s:%d", peer.Endpoint(), peer.Port())
+	networkAddress := networkAddressOf(peer)
 	peerHexAddress := peer.HexOrbsAddress()
 
 	logger := parentLogger.WithTags(log.String("peer-node-address", peerHexAddress[:6]), log.String("peer-network-address", networkAddress))
@@ -61,6 +65,7 @@ func newOutgoingConnection(peer adapter.TransportPeer, parentLogger log.Logger,
 		config:          transportConfig,
 		queue:           queue,
 		peerHexAddress:  peerHexAddress,
+		connectivity:    newPeerConnectivity(),
 		sendErrors:      sendErrors,
 		sendQueueErrors: sendQueueErrors,
 	}
@@ -68,6 +73,10 @@ func newOutgoingConnection(peer adapter.TransportPeer, parentLogger log.Logger,
 	return client
 }
 
+func networkAddressOf(peer adapter.TransportPeer) string {
+	return fmt.Sprintf("%s:%d", peer.Endpoint(), peer.Port())
+}
+
 // This is a round-about way to clean up these metrics that can be left over from previous connection
 func generateMetrics(peerHexAddress string, metricFactory metric.Registry, logger log.Logger) (*metric.Gauge, *metric.Gauge) {
 	sendErrorName := fmt.Sprintf("Gossip.OutgoingConnection.SendError.%s.Count", peerHexAddress)
@@ -104,10 +113,11 @@ func (c *outgoingConnection) disconnect() chan struct{} {
 }
 
 func (c *outgoingConnection) connectionMainLoop(parentCtx context.Context) {
-	for {
+	for attempt := 0; ; attempt++ {
 		if parentCtx.Err() != nil {
 			return // because otherwise the continue statement below could prevent us from ever shutting down
 		}
+		c.connectivity.connecting(attempt > 0)
 		ctx := trace.NewContext(parentCtx, fmt.Sprintf("Gossip.Transport.TCP.Client.%s", c.peerHexAddress[:6]))
 		logger := c.logger.WithTags(trace.LogFieldFrom(ctx))
 
@@ -116,23 +126,58 @@ func (c *outgoingConnection) connectionMainLoop(parentCtx context.Context) {
 
 		if err != nil {
 			logger.Info("cannot connect to gossip peer endpoint", log.Error(err))
+			c.connectivity.failed(err)
 			time.Sleep(c.config.GossipReconnectInterval())
 			continue
 		}
 
-		if !c.handleOutgoingConnection(ctx, conn) {
+		if c.handshake != nil {
+			secureConn, err := c.authenticate(ctx, conn)
+			if err != nil {
+				c.sharedMetrics.handshakeErrors.Inc()
+				logger.Info("failed authenticating gossip peer, reconnecting", log.Error(err))
+				c.connectivity.failed(err)
+				_ = conn.Close()
+				time.Sleep(c.config.GossipReconnectInterval())
+				continue
+			}
+			conn = secureConn
+		}
+
+		compressed := false
+		if c.compressor != nil {
+			compressed, err = proposeCompression(ctx, conn, c.config.GossipNetworkTimeout())
+			if err != nil {
+				logger.Info("failed negotiating compression with gossip peer, reconnecting", log.Error(err))
+				c.connectivity.failed(err)
+				_ = conn.Close()
+				time.Sleep(c.config.GossipReconnectInterval())
+				continue
+			}
+		}
+
+		if !c.handleOutgoingConnection(ctx, conn, compressed) {
 			return
 		}
 	}
 }
 
+func (c *outgoingConnection) authenticate(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	peerNodeAddress, err := hex.DecodeString(c.peerHexAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid peer node address %s", c.peerHexAddress)
+	}
+	return c.handshake.client(ctx, conn, peerNodeAddress)
+}
+
 // returns true if should attempt reconnect on error
-func (c *outgoingConnection) handleOutgoingConnection(ctx context.Context, conn net.Conn) bool {
+func (c *outgoingConnection) handleOutgoingConnection(ctx context.Context, conn net.Conn, compressed bool) bool {
 	logger := c.logger.WithTags(trace.LogFieldFrom(ctx), log.Stringable("local-address", conn.LocalAddr()))
-	logger.Info("successful outgoing gossip transport connection")
+	logger.Info("successful outgoing gossip transport connection", log.String("compressed", fmt.Sprintf("%t", compressed)))
 
 	c.sharedMetrics.activeCount.Inc()
 	defer c.sharedMetrics.activeCount.Dec()
+	c.connectivity.connected()
 
 	c.queue.OnNewConnection(ctx)
 	defer c.queue.Disable()
@@ -142,7 +187,7 @@ func (c *outgoingConnection) handleOutgoingConnection(ctx context.Context, conn
 	for {
 		if data := c.popMessageFromQueue(ctx); data != nil {
 			// got data from queue
-			err := c.sendToSocket(ctx, conn, data)
+			err := c.sendToSocket(ctx, conn, data, compressed)
 			if err != nil {
 				logger.Info("connection closing due to socket error")
 				return c.reconnectAfterSocketError(logger, err)
@@ -179,12 +224,15 @@ func (c *outgoingConnection) onDisconnect(logger log.Logger) bool {
 	logger.Info("client loop stopped since a disconnect was requested (topology change or system shutdown)")
 	c.metricRegistry.Remove(c.sendErrors)
 	c.metricRegistry.Remove(c.sendQueueErrors)
-	c.metricRegistry.Remove(c.queue.usagePercentageMetric)
+	for _, queueMetric := range c.queue.metrics() {
+		c.metricRegistry.Remove(queueMetric)
+	}
 	return false
 }
 
 func (c *outgoingConnection) reconnectAfterKeepAliveFailure(logger log.Logger, err error) bool {
 	c.sharedMetrics.KeepaliveErrors.Inc()
+	c.connectivity.failed(err)
 	logger.Info("failed sending keepalive, reconnecting", log.Error(err))
 	return true
 }
@@ -192,6 +240,7 @@ func (c *outgoingConnection) reconnectAfterKeepAliveFailure(logger log.Logger, e
 func (c *outgoingConnection) reconnectAfterSocketError(logger log.Logger, err error) bool {
 	c.sharedMetrics.sendErrors.Inc() //TODO remove, replaced by following metric
 	c.sendErrors.Inc()
+	c.connectivity.failed(err)
 	logger.Info("failed sending transport data, reconnecting", log.Error(err))
 	return true
 }
@@ -205,21 +254,31 @@ func (c *outgoingConnection) addDataToOutgoingPeerQueue(ctx context.Context, dat
 	}
 }
 
-func (c *outgoingConnection) sendToSocket(ctx context.Context, conn net.Conn, data *adapter.TransportData) error {
+func (c *outgoingConnection) sendToSocket(ctx context.Context, conn net.Conn, data *adapter.TransportData, compressed bool) error {
 	timeout := c.config.GossipNetworkTimeout()
 	zeroBuffer := make([]byte, 4)
 	sizeBuffer := make([]byte, 4)
 
+	payloads, isCompressed := data.Payloads, make([]bool, len(data.Payloads))
+	if compressed {
+		payloads, isCompressed = c.compressor.compress(data)
+	}
+	written := 4
+
 	// send num payloads
-	membuffers.WriteUint32(sizeBuffer, uint32(len(data.Payloads)))
+	membuffers.WriteUint32(sizeBuffer, uint32(len(payloads)))
 	err := write(ctx, conn, sizeBuffer, timeout)
 	if err != nil {
 		return err
 	}
 
-	for _, payload := range data.Payloads {
+	for i, payload := range payloads {
 		// send payload size
-		membuffers.WriteUint32(sizeBuffer, uint32(len(payload)))
+		payloadSize := uint32(len(payload))
+		if isCompressed[i] {
+			payloadSize |= compressedPayloadFlag
+		}
+		membuffers.WriteUint32(sizeBuffer, payloadSize)
 		err := write(ctx, conn, sizeBuffer, timeout)
 		if err != nil {
 			return err
@@ -239,8 +298,10 @@ func (c *outgoingConnection) sendToSocket(ctx context.Context, conn net.Conn, da
 				return err
 			}
 		}
+		written += 4 + len(payload) + int(paddingSize)
 	}
 
+	c.connectivity.sent(laneOf(data), written)
 	return nil
 }
 
@@ -254,6 +315,7 @@ func (c *outgoingConnection) sendKeepAlive(ctx context.Context, conn net.Conn) e
 		return err
 	}
 
+	c.connectivity.keptAlive()
 	return nil
 }
 
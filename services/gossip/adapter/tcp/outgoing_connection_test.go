@@ -62,6 +62,41 @@ func TestOutgoingConnection_ReconnectsWhenServerDisconnects_AndSendKeepAlive(t *
 	})
 }
 
+func TestOutgoingConnection_ReportsConnectivityAcrossReconnects(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			server := newServerStub(t)
+			defer server.Close()
+
+			client := server.createClientAndConnect(ctx, t, parent.Logger, 10*time.Millisecond)
+			peer := adapter.NewGossipPeer(server.port, "127.0.0.1", "012345")
+			waitForQueueEnabled(t, client)
+			require.NotZero(t, server.readSomeBytes(), "client didn't send keep alive")
+
+			require.True(t, test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, func() bool {
+				return !client.connectivity.snapshot(peer, 0, nil).LastKeepAlive.IsZero()
+			}), "expected the keep alive to be reported")
+			connectivity := client.connectivity.snapshot(peer, 0, nil)
+			require.Equal(t, adapter.PeerConnected, connectivity.State)
+			require.Zero(t, connectivity.Reconnects)
+
+			server.forceDisconnect(t)
+			require.True(t, test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, func() bool {
+				return client.connectivity.snapshot(peer, 0, nil).Reconnects == 1
+			}), "expected the lost connection to be reported")
+			require.NotEmpty(t, client.connectivity.snapshot(peer, 0, nil).LastError)
+
+			server.acceptClientConnection(t)
+			waitForQueueEnabled(t, client)
+			connectivity = client.connectivity.snapshot(peer, 0, nil)
+			require.Equal(t, adapter.PeerConnected, connectivity.State)
+			require.EqualValues(t, 1, connectivity.Reconnects)
+
+			<-client.disconnect()
+		})
+	})
+}
+
 type timeouts struct {
 	keepAliveInterval time.Duration
 }
@@ -17,6 +17,7 @@ type outgoingConnectionMetrics struct {
 	KeepaliveErrors *metric.Gauge
 	sendQueueErrors *metric.Gauge
 	activeCount     *metric.Gauge
+	handshakeErrors *metric.Gauge
 
 	messageSize *metric.Histogram
 }
@@ -30,9 +31,11 @@ type outgoingConnections struct {
 	config            timingsConfig
 	metricRegistry    metric.Registry
 	nodeAddress       primitives.NodeAddress
+	handshake         *handshake         // nil when gossip authentication is disabled
+	compressor        *payloadCompressor // nil when gossip compression is disabled
 }
 
-func newOutgoingConnections(logger log.Logger, registry metric.Registry, config config.GossipTransportConfig) *outgoingConnections {
+func newOutgoingConnections(logger log.Logger, registry metric.Registry, config config.GossipTransportConfig, peerHandshake *handshake) *outgoingConnections {
 	c := &outgoingConnections{
 		logger:            logger,
 		activeConnections: make(map[string]*outgoingConnection),
@@ -41,6 +44,10 @@ func newOutgoingConnections(logger log.Logger, registry metric.Registry, config
 		metricRegistry:    registry,
 		nodeAddress:       config.NodeAddress(),
 		config:            config,
+		handshake:         peerHandshake,
+	}
+	if config.GossipCompressionEnabled() {
+		c.compressor = newPayloadCompressor(config.GossipCompressionThresholdBytes(), registry)
 	}
 
 	return c
@@ -52,6 +59,7 @@ func createOutgoingConnectionMetrics(registry metric.Registry) *outgoingConnecti
 		KeepaliveErrors: registry.NewGauge("Gossip.OutgoingConnection.KeepaliveErrors.Count"),
 		sendQueueErrors: registry.NewGauge("Gossip.OutgoingConnection.SendQueueErrors.Count"),
 		activeCount:     registry.NewGauge("Gossip.OutgoingConnection.Active.Count"),
+		handshakeErrors: registry.NewGauge("Gossip.OutgoingConnection.HandshakeErrors.Count"),
 		messageSize:     registry.NewHistogram("Gossip.OutgoingConnection.MessageSize.Bytes", MAX_PAYLOAD_SIZE_BYTES),
 	}
 }
@@ -100,6 +108,8 @@ func (c *outgoingConnections) connectForeverUnderLock(bgCtx context.Context, pee
 	if c.nodeAddress.KeyForMap() != peerNodeAddress {
 		c.peerTopology[peerNodeAddress] = peer
 		client := newOutgoingConnection(peer, c.logger, c.metricRegistry, c.metrics, c.config)
+		client.handshake = c.handshake
+		client.compressor = c.compressor
 		c.activeConnections[peerNodeAddress] = client
 		client.connect(bgCtx)
 	}
@@ -122,6 +132,15 @@ func (c *outgoingConnections) disconnectAllUnderLock(ctx context.Context, peersT
 	}
 }
 
+// incoming connections are only accepted from peers in the topology
+func (c *outgoingConnections) isInTopology(nodeAddress primitives.NodeAddress) bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	_, found := c.peerTopology[nodeAddress.KeyForMap()]
+	return found
+}
+
 var DataExceedsCapacityError = errors.Errorf("Data exceeds allowed size %d", SEND_QUEUE_MAX_BYTES)
 
 // TODO(https://github.com/orbs-network/orbs-network-go/issues/182): we are not currently respecting any intents given in ctx (added in context refactor)
@@ -0,0 +1,148 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"sort"
+	"sync"
+	"time"
+)
+
+// the state of the outgoing connection to a single peer, as reported by the connectivity endpoint. traffic is counted
+// per queue lane, which is per topic
+type peerConnectivity struct {
+	sync.Mutex
+	state              adapter.PeerConnectionState
+	lastSuccessfulSend time.Time
+	lastKeepAlive      time.Time
+	bytesSent          []uint64 // by lane
+	reconnects         uint64
+	lastError          string
+}
+
+func newPeerConnectivity() *peerConnectivity {
+	return &peerConnectivity{
+		state:     adapter.PeerConnecting,
+		bytesSent: make([]uint64, len(queueLaneSpecs)),
+	}
+}
+
+func (c *peerConnectivity) connecting(isReconnect bool) {
+	c.Lock()
+	defer c.Unlock()
+	if isReconnect {
+		c.reconnects++
+	}
+}
+
+func (c *peerConnectivity) connected() {
+	c.Lock()
+	defer c.Unlock()
+	c.state = adapter.PeerConnected
+}
+
+func (c *peerConnectivity) failed(err error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.state == adapter.PeerConnected {
+		c.state = adapter.PeerDisconnected
+	}
+	c.lastError = err.Error()
+}
+
+func (c *peerConnectivity) sent(lane int, bytes int) {
+	c.Lock()
+	defer c.Unlock()
+	c.lastSuccessfulSend = time.Now()
+	c.bytesSent[lane] += uint64(bytes)
+}
+
+func (c *peerConnectivity) keptAlive() {
+	c.Lock()
+	defer c.Unlock()
+	c.lastKeepAlive = time.Now()
+}
+
+func (c *peerConnectivity) snapshot(peer adapter.TransportPeer, queueDepth int, bytesReceived map[string]uint64) *adapter.PeerConnectivity {
+	c.Lock()
+	defer c.Unlock()
+	return &adapter.PeerConnectivity{
+		NodeAddress:          peer.HexOrbsAddress(),
+		Endpoint:             networkAddressOf(peer),
+		State:                c.state,
+		LastSuccessfulSend:   c.lastSuccessfulSend,
+		LastKeepAlive:        c.lastKeepAlive,
+		QueueDepth:           queueDepth,
+		BytesSentByTopic:     byLaneName(c.bytesSent),
+		BytesReceivedByTopic: bytesReceived,
+		Reconnects:           c.reconnects,
+		LastError:            c.lastError,
+	}
+}
+
+// payload bytes received from each peer, by lane. peers are only known on authenticated connections
+type receivedTraffic struct {
+	sync.Mutex
+	bytes map[string][]uint64
+}
+
+func newReceivedTraffic() *receivedTraffic {
+	return &receivedTraffic{bytes: make(map[string][]uint64)}
+}
+
+func (t *receivedTraffic) received(peer primitives.NodeAddress, payloads [][]byte) {
+	size := 0
+	for _, payload := range payloads {
+		size += len(payload)
+	}
+	lane := laneOf(&adapter.TransportData{Payloads: payloads})
+
+	t.Lock()
+	defer t.Unlock()
+	bytes, found := t.bytes[peer.KeyForMap()]
+	if !found {
+		bytes = make([]uint64, len(queueLaneSpecs))
+		t.bytes[peer.KeyForMap()] = bytes
+	}
+	bytes[lane] += uint64(size)
+}
+
+func (t *receivedTraffic) of(peerKey string) map[string]uint64 {
+	t.Lock()
+	defer t.Unlock()
+	return byLaneName(t.bytes[peerKey])
+}
+
+func byLaneName(bytes []uint64) map[string]uint64 {
+	res := make(map[string]uint64, len(queueLaneSpecs))
+	for i, spec := range queueLaneSpecs {
+		if i < len(bytes) {
+			res[spec.name] = bytes[i]
+		} else {
+			res[spec.name] = 0
+		}
+	}
+	return res
+}
+
+// PeerConnectivity reports the outgoing connection to every topology peer, ordered by node address
+func (t *DirectTransport) PeerConnectivity() []*adapter.PeerConnectivity {
+	t.outgoingConnections.RLock()
+	defer t.outgoingConnections.RUnlock()
+
+	var res []*adapter.PeerConnectivity
+	for key, client := range t.outgoingConnections.activeConnections {
+		peer := t.outgoingConnections.peerTopology[key]
+		res = append(res, client.connectivity.snapshot(peer, client.queue.depth(), t.server.traffic.of(key)))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].NodeAddress < res[j].NodeAddress
+	})
+	return res
+}
@@ -0,0 +1,210 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"context"
+	"github.com/orbs-network/govnr"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/synchronization"
+	"github.com/orbs-network/scribe/log"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+const peerScoreDecayPerSecond = 1.0
+const peerThrottleDelay = 100 * time.Millisecond
+const peerScoresExpiryInterval = 10 * time.Second
+const maxScoredPeers = 10000
+
+// every peer may open a burst of connections, after which it is refused until its connection count decays
+const peerConnectionBurst = 10.0
+const peerConnectionDecayPerSecond = 1.0
+
+var misbehaviorPenalties = map[adapter.Misbehavior]float64{
+	adapter.MisbehaviorInvalidHeader:      10,
+	adapter.MisbehaviorUndecodableMessage: 10,
+	adapter.MisbehaviorOversizedMessage:   50,
+	adapter.MisbehaviorFailedHandshake:    20,
+}
+
+type peerScoresConfig interface {
+	GossipPeerScoreThrottleThreshold() uint32
+	GossipPeerScoreDisconnectThreshold() uint32
+	GossipPeerDisconnectDuration() time.Duration
+}
+
+// peerScores tracks the misbehavior and the connection rate of the peers connecting to the server by their IP, so a
+// disconnected peer cannot come back on a new connection. every misbehavior adds a penalty to the score of the peer and
+// the score decays over time, a peer is throttled while its score is above the throttle threshold and is disconnected for
+// a while once it reaches the disconnect threshold. peers are forgotten once both decay away, and the table is capped so
+// connecting from many addresses cannot grow it without bound
+type peerScores struct {
+	govnr.TreeSupervisor
+	config peerScoresConfig
+	logger log.Logger
+
+	mu struct {
+		sync.Mutex
+		peers map[string]*peerScore
+	}
+
+	throttledMessages  *metric.Gauge
+	disconnectedPeers  *metric.Gauge
+	refusedConnections *metric.Gauge
+	scoredPeers        *metric.Gauge
+}
+
+type peerScore struct {
+	score             float64
+	connections       float64
+	updated           time.Time
+	disconnectedUntil time.Time
+}
+
+func newPeerScores(config peerScoresConfig, logger log.Logger, registry metric.Registry) *peerScores {
+	s := &peerScores{
+		config:             config,
+		logger:             logger,
+		throttledMessages:  registry.NewGauge("Gossip.IncomingConnection.ThrottledMessages.Count"),
+		disconnectedPeers:  registry.NewGauge("Gossip.IncomingConnection.DisconnectedPeers.Count"),
+		refusedConnections: registry.NewGauge("Gossip.IncomingConnection.RefusedConnections.Count"),
+		scoredPeers:        registry.NewGauge("Gossip.IncomingConnection.ScoredPeers.Count"),
+	}
+	s.mu.peers = make(map[string]*peerScore)
+	return s
+}
+
+func (s *peerScores) startExpiringPeers(ctx context.Context) {
+	s.Supervise(synchronization.NewPeriodicalTrigger(ctx, "Gossip peer scores expiry", synchronization.NewTimeTicker(peerScoresExpiryInterval), s.logger, func() {
+		s.forgetExpiredPeers(time.Now())
+	}, nil))
+}
+
+func peerOf(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
+
+func (s *peerScores) penalize(peer string, misbehavior adapter.Misbehavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	p := s.peerLocked(peer, now)
+	p.score += misbehaviorPenalties[misbehavior]
+	s.logger.Info("penalized gossip peer", log.String("peer", peer), log.Stringable("misbehavior", misbehavior), log.Int("score", int(p.score)))
+
+	if threshold := s.config.GossipPeerScoreDisconnectThreshold(); threshold > 0 && p.score >= float64(threshold) && now.After(p.disconnectedUntil) {
+		p.disconnectedUntil = now.Add(s.config.GossipPeerDisconnectDuration())
+		s.disconnectedPeers.Inc()
+		s.logger.Info("disconnecting misbehaving gossip peer", log.String("peer", peer), log.Stringable("until", p.disconnectedUntil))
+	}
+}
+
+// admitConnection counts a new connection of the peer, refusing it while the peer is disconnected or connects too often
+func (s *peerScores) admitConnection(peer string) (bool, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	p := s.peerLocked(peer, now)
+	if now.Before(p.disconnectedUntil) {
+		return false, "disconnected misbehaving peer"
+	}
+	if p.connections+1 > peerConnectionBurst {
+		return false, "peer connecting too often"
+	}
+	p.connections++
+	return true, ""
+}
+
+// a disconnected peer has its connections closed and new ones refused
+func (s *peerScores) isDisconnected(peer string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, found := s.mu.peers[peer]
+	return found && time.Now().Before(p.disconnectedUntil)
+}
+
+func (s *peerScores) isThrottled(peer string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, found := s.mu.peers[peer]
+	if !found {
+		return false
+	}
+	p.decay(time.Now())
+
+	threshold := s.config.GossipPeerScoreThrottleThreshold()
+	return threshold > 0 && p.score >= float64(threshold)
+}
+
+// forgetExpiredPeers drops the peers whose score and connection count decayed away and who are not disconnected
+func (s *peerScores) forgetExpiredPeers(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for peer, p := range s.mu.peers {
+		p.decay(now)
+		if p.isExpired(now) {
+			delete(s.mu.peers, peer)
+		}
+	}
+	s.scoredPeers.Update(int64(len(s.mu.peers)))
+}
+
+// peerLocked returns the decayed score of the peer, tracking it if it is new. when the table is full, expired peers are
+// forgotten and if none are, the peer with the lowest score is
+func (s *peerScores) peerLocked(peer string, now time.Time) *peerScore {
+	if p, found := s.mu.peers[peer]; found {
+		p.decay(now)
+		return p
+	}
+
+	if len(s.mu.peers) >= maxScoredPeers {
+		var lowest string
+		for other, p := range s.mu.peers {
+			p.decay(now)
+			if p.isExpired(now) {
+				delete(s.mu.peers, other)
+			} else if lowest == "" || p.score < s.mu.peers[lowest].score {
+				lowest = other
+			}
+		}
+		if len(s.mu.peers) >= maxScoredPeers {
+			delete(s.mu.peers, lowest)
+		}
+	}
+
+	p := &peerScore{updated: now}
+	s.mu.peers[peer] = p
+	s.scoredPeers.Update(int64(len(s.mu.peers)))
+	return p
+}
+
+func (p *peerScore) decay(now time.Time) {
+	elapsed := now.Sub(p.updated).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	p.score = math.Max(0, p.score-elapsed*peerScoreDecayPerSecond)
+	p.connections = math.Max(0, p.connections-elapsed*peerConnectionDecayPerSecond)
+	p.updated = now
+}
+
+func (p *peerScore) isExpired(now time.Time) bool {
+	return p.score == 0 && p.connections == 0 && !now.Before(p.disconnectedUntil)
+}
@@ -0,0 +1,152 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/scribe/log"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type peerScoresCfg struct {
+	throttleThreshold   uint32
+	disconnectThreshold uint32
+}
+
+func (c *peerScoresCfg) GossipPeerScoreThrottleThreshold() uint32 {
+	return c.throttleThreshold
+}
+
+func (c *peerScoresCfg) GossipPeerScoreDisconnectThreshold() uint32 {
+	return c.disconnectThreshold
+}
+
+func (c *peerScoresCfg) GossipPeerDisconnectDuration() time.Duration {
+	return 1 * time.Minute
+}
+
+func aPeerScores(logger log.Logger, throttleThreshold uint32, disconnectThreshold uint32) *peerScores {
+	return newPeerScores(&peerScoresCfg{throttleThreshold: throttleThreshold, disconnectThreshold: disconnectThreshold}, logger, metric.NewRegistry())
+}
+
+func TestPeerScores_ThrottlesAndThenDisconnectsAMisbehavingPeer(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 15, 35) // with room for the score decaying between penalties
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorInvalidHeader)
+		require.False(t, s.isThrottled("10.0.0.1"), "expected a peer not to be throttled below the threshold")
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorUndecodableMessage)
+		require.True(t, s.isThrottled("10.0.0.1"), "expected a peer to be throttled at the threshold")
+		require.False(t, s.isDisconnected("10.0.0.1"))
+		require.False(t, s.isThrottled("10.0.0.2"), "expected other peers not to be throttled")
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorFailedHandshake)
+		require.True(t, s.isDisconnected("10.0.0.1"), "expected a peer to be disconnected at the threshold")
+		require.False(t, s.isDisconnected("10.0.0.2"), "expected other peers not to be disconnected")
+		require.EqualValues(t, 1, s.disconnectedPeers.IntValue())
+	})
+}
+
+func TestPeerScores_ForgivesPeersAsTheirScoreDecays(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 20, 0)
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorOversizedMessage)
+		require.True(t, s.isThrottled("10.0.0.1"))
+
+		s.mu.Lock()
+		s.mu.peers["10.0.0.1"].updated = time.Now().Add(-40 * time.Second)
+		s.mu.Unlock()
+		require.False(t, s.isThrottled("10.0.0.1"), "expected a peer not to be throttled once its score decays below the threshold")
+
+		s.forgetExpiredPeers(time.Now())
+		require.EqualValues(t, 1, s.scoredPeers.IntValue(), "expected a peer not to be forgotten before its score decays away")
+
+		s.forgetExpiredPeers(time.Now().Add(1 * time.Minute))
+		require.EqualValues(t, 0, s.scoredPeers.IntValue(), "expected a peer to be forgotten once its score decays away")
+	})
+}
+
+func TestPeerScores_DoesNotForgetDisconnectedPeers(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 0, 20)
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorOversizedMessage)
+		s.forgetExpiredPeers(time.Now().Add(50 * time.Second))
+		require.True(t, s.isDisconnected("10.0.0.1"), "expected a peer to stay disconnected after its score decays away")
+
+		s.forgetExpiredPeers(time.Now().Add(2 * time.Minute))
+		require.EqualValues(t, 0, s.scoredPeers.IntValue(), "expected a peer to be forgotten once its disconnection ends")
+	})
+}
+
+func TestPeerScores_CapsTheNumberOfScoredPeers(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 0, 0)
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorOversizedMessage)
+		for i := 0; i < maxScoredPeers+10; i++ {
+			s.penalize(fmt.Sprintf("11.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff), adapter.MisbehaviorInvalidHeader)
+		}
+
+		require.EqualValues(t, maxScoredPeers, s.scoredPeers.IntValue(), "expected the peer table not to grow beyond its cap")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		require.Contains(t, s.mu.peers, "10.0.0.1", "expected the peers with the lowest score to be evicted first")
+	})
+}
+
+func TestPeerScores_RefusesPeersConnectingTooOften(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 0, 0)
+
+		for i := 0; i < peerConnectionBurst; i++ {
+			admitted, _ := s.admitConnection("10.0.0.1")
+			require.True(t, admitted, "expected a peer to be admitted within its connection burst")
+		}
+		admitted, _ := s.admitConnection("10.0.0.1")
+		require.False(t, admitted, "expected a peer to be refused beyond its connection burst")
+		admitted, _ = s.admitConnection("10.0.0.2")
+		require.True(t, admitted, "expected other peers to be admitted")
+
+		s.mu.Lock()
+		s.mu.peers["10.0.0.1"].updated = time.Now().Add(-2 * time.Second)
+		s.mu.Unlock()
+		admitted, _ = s.admitConnection("10.0.0.1")
+		require.True(t, admitted, "expected a peer to be admitted once its connection count decays")
+	})
+}
+
+func TestPeerScores_RefusesConnectionsOfDisconnectedPeers(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 0, 20)
+
+		s.penalize("10.0.0.1", adapter.MisbehaviorOversizedMessage)
+		admitted, reason := s.admitConnection("10.0.0.1")
+		require.False(t, admitted, "expected a disconnected peer to be refused")
+		require.Equal(t, "disconnected misbehaving peer", reason)
+	})
+}
+
+func TestPeerScores_DoesNotThrottleOrDisconnectWhenThresholdsAreZero(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		s := aPeerScores(harness.Logger, 0, 0)
+
+		for i := 0; i < 10; i++ {
+			s.penalize("10.0.0.1", adapter.MisbehaviorOversizedMessage)
+		}
+
+		require.False(t, s.isThrottled("10.0.0.1"))
+		require.False(t, s.isDisconnected("10.0.0.1"))
+	})
+}
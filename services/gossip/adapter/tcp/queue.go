@@ -11,32 +11,84 @@ import (
 	"fmt"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/scribe/log"
 	"github.com/pkg/errors"
 	"sync"
 )
 
+// each topic is queued in its own lane so a burst of one topic does not delay or crowd out the others.
+// lanes without a weight are strictly prioritized and always drained first (consensus), lanes with a weight
+// share the remaining bandwidth in proportion to their weights, each lane holds up to the max bytes and messages
+// given to the queue on its own
+type queueLaneSpec struct {
+	name   string
+	weight int
+}
+
+var queueLaneSpecs = []queueLaneSpec{
+	{name: "LeanHelixConsensus"},
+	{name: "BenchmarkConsensus"},
+	{name: "TransactionRelay", weight: 4},
+	{name: "BlockSync", weight: 1}, // block sync chunks are large so a single message takes a while to send
+}
+
+const (
+	leanHelixLane = iota
+	benchmarkConsensusLane
+	transactionRelayLane
+	blockSyncLane
+)
+
+type queueLane struct {
+	queueLaneSpec
+	index   int
+	channel chan *adapter.TransportData
+	credits int // messages left for this lane in the current weighted round, only touched by the popping goroutine
+
+	depthMetric *metric.Gauge
+	dropsMetric *metric.Gauge
+}
+
 type transportQueue struct {
-	channel        chan *adapter.TransportData // replace this buffered channel with github.com/phf/go-queue if we don't want maxSizeMessages (and its pre allocation)
+	lanes          []*queueLane
+	weightedLanes  []*queueLane
+	currentLane    int           // index in weightedLanes, only touched by the popping goroutine
+	pushed         chan struct{} // wakes a pop waiting on an empty queue
 	networkAddress string
 	maxBytes       int
 	maxMessages    int
 
 	protected struct {
 		sync.Mutex
-		bytesLeft int
-		disabled  bool // not under mutex on purpose
+		bytesLeft []int // per lane
+		disabled  bool  // not under mutex on purpose
 	}
 	usagePercentageMetric *metric.Gauge
 }
 
 func NewTransportQueue(maxSizeBytes int, maxSizeMessages int, metricFactory metric.Registry, peerNodeAddress string, logger log.Logger) *transportQueue {
 	q := &transportQueue{
-		channel:     make(chan *adapter.TransportData, maxSizeMessages),
+		pushed:      make(chan struct{}, 1),
 		maxBytes:    maxSizeBytes,
 		maxMessages: maxSizeMessages,
 	}
-	q.protected.bytesLeft = maxSizeBytes
+
+	for i, spec := range queueLaneSpecs {
+		lane := &queueLane{
+			queueLaneSpec: spec,
+			index:         i,
+			channel:       make(chan *adapter.TransportData, maxSizeMessages),
+			credits:       spec.weight,
+			depthMetric:   newPeerGauge(metricFactory, fmt.Sprintf("Gossip.OutgoingConnection.QueueDepth.%s.%s.Count", spec.name, peerNodeAddress), logger),
+			dropsMetric:   newPeerGauge(metricFactory, fmt.Sprintf("Gossip.OutgoingConnection.QueueDrops.%s.%s.Count", spec.name, peerNodeAddress), logger),
+		}
+		q.lanes = append(q.lanes, lane)
+		if spec.weight > 0 {
+			q.weightedLanes = append(q.weightedLanes, lane)
+		}
+		q.protected.bytesLeft = append(q.protected.bytesLeft, maxSizeBytes)
+	}
 
 	// round-about way to remove old queue metric if exists
 	queueUsageName := fmt.Sprintf("Gossip.OutgoingConnection.QueueUsage.%s.Percent", peerNodeAddress)
@@ -50,39 +102,114 @@ func NewTransportQueue(maxSizeBytes int, maxSizeMessages int, metricFactory metr
 	return q
 }
 
+// round-about way to remove a metric left over from a previous connection to the same peer
+func newPeerGauge(metricFactory metric.Registry, name string, logger log.Logger) *metric.Gauge {
+	existing := metricFactory.Get(name)
+	if existing != nil {
+		logger.Info("TransportQueue ctor issue", log.Error(errors.Errorf("Metric %s still existed when new connection created", name)))
+	}
+	metricFactory.Remove(existing)
+	return metricFactory.NewGauge(name)
+}
+
 func (q *transportQueue) Push(data *adapter.TransportData) error {
-	err := q.consumeBytes(data)
+	laneIndex := laneOf(data)
+	lane := q.lanes[laneIndex]
+
+	err := q.consumeBytes(laneIndex, data)
 	if err != nil {
+		lane.dropsMetric.Inc()
 		return err
 	}
 
 	select {
-	case q.channel <- data:
+	case lane.channel <- data:
+		lane.depthMetric.Inc()
+		q.notifyPushed()
 		return nil
 	default:
-		return errors.Errorf("failed to push to queue - full with %d messages", q.maxMessages)
+		q.releaseBytes(laneIndex, data)
+		lane.dropsMetric.Inc()
+		return errors.Errorf("failed to push to %s queue - full with %d messages", lane.name, q.maxMessages)
 	}
 }
 
-func (q *transportQueue) Pop(ctx context.Context) *adapter.TransportData {
+// the number of messages waiting in all lanes
+func (q *transportQueue) depth() int {
+	depth := 0
+	for _, lane := range q.lanes {
+		depth += len(lane.channel)
+	}
+	return depth
+}
+
+func (q *transportQueue) notifyPushed() {
 	select {
-	case <-ctx.Done():
-		return nil
-	case res := <-q.channel:
-		q.releaseBytes(res)
-		return res
+	case q.pushed <- struct{}{}:
+	default: // a wake up is already pending
 	}
 }
 
-func (q *transportQueue) Clear(ctx context.Context) {
+// must only be called from a single goroutine
+func (q *transportQueue) Pop(ctx context.Context) *adapter.TransportData {
 	for {
+		if res := q.tryPop(); res != nil {
+			return res
+		}
+
 		select {
 		case <-ctx.Done():
-			return
-		case res := <-q.channel:
-			q.releaseBytes(res)
-		default:
-			return
+			return nil
+		case <-q.pushed:
+		}
+	}
+}
+
+func (q *transportQueue) tryPop() *adapter.TransportData {
+	for laneIndex, lane := range q.lanes {
+		if lane.weight == 0 {
+			if res := q.tryPopLane(laneIndex); res != nil {
+				return res
+			}
+		}
+	}
+
+	// weighted round robin, a lane is drained until it is empty or used up its weight and then the next lane gets its turn
+	for attempts := 0; attempts <= len(q.weightedLanes); attempts++ {
+		lane := q.weightedLanes[q.currentLane]
+		if lane.credits > 0 {
+			if res := q.tryPopLane(lane.index); res != nil {
+				lane.credits--
+				return res
+			}
+		}
+		lane.credits = lane.weight
+		q.currentLane = (q.currentLane + 1) % len(q.weightedLanes)
+	}
+	return nil
+}
+
+func (q *transportQueue) tryPopLane(laneIndex int) *adapter.TransportData {
+	lane := q.lanes[laneIndex]
+	select {
+	case res := <-lane.channel:
+		lane.depthMetric.Dec()
+		q.releaseBytes(laneIndex, res)
+		return res
+	default:
+		return nil
+	}
+}
+
+func (q *transportQueue) Clear(ctx context.Context) {
+	for laneIndex := range q.lanes {
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			if res := q.tryPopLane(laneIndex); res == nil {
+				break
+			}
 		}
 	}
 }
@@ -110,11 +237,41 @@ func (q *transportQueue) OnNewConnection(ctx context.Context) {
 	q.Enable()
 }
 
+func (q *transportQueue) metrics() []*metric.Gauge {
+	metrics := []*metric.Gauge{q.usagePercentageMetric}
+	for _, lane := range q.lanes {
+		metrics = append(metrics, lane.depthMetric, lane.dropsMetric)
+	}
+	return metrics
+}
+
 func NewQueueFullError(bytesAttempted int, bytesInQueue int, queueSize int) error {
 	return errors.Errorf("failed to push %d bytes to queue - full with %d bytes out of %d bytes", bytesAttempted, bytesInQueue, queueSize)
 }
 
-func (q *transportQueue) consumeBytes(data *adapter.TransportData) error {
+// messages without a readable header are sent with the lowest priority
+func laneOf(data *adapter.TransportData) int {
+	if len(data.Payloads) == 0 {
+		return blockSyncLane
+	}
+	header := gossipmessages.HeaderReader(data.Payloads[0])
+	if !header.IsValid() {
+		return blockSyncLane
+	}
+
+	switch header.Topic() {
+	case gossipmessages.HEADER_TOPIC_LEAN_HELIX:
+		return leanHelixLane
+	case gossipmessages.HEADER_TOPIC_BENCHMARK_CONSENSUS:
+		return benchmarkConsensusLane
+	case gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY:
+		return transactionRelayLane
+	default:
+		return blockSyncLane
+	}
+}
+
+func (q *transportQueue) consumeBytes(laneIndex int, data *adapter.TransportData) error {
 	q.protected.Lock()
 	defer q.protected.Unlock()
 
@@ -123,24 +280,30 @@ func (q *transportQueue) consumeBytes(data *adapter.TransportData) error {
 	}
 
 	dataSize := data.TotalSize()
-	if dataSize > q.protected.bytesLeft {
-		return NewQueueFullError(dataSize, q.maxBytes-q.protected.bytesLeft, q.maxBytes)
+	if dataSize > q.protected.bytesLeft[laneIndex] {
+		return NewQueueFullError(dataSize, q.maxBytes-q.protected.bytesLeft[laneIndex], q.maxBytes)
 	}
 
-	q.protected.bytesLeft -= dataSize
+	q.protected.bytesLeft[laneIndex] -= dataSize
 	q.updateUsageMetric()
 	return nil
 }
 
-func (q *transportQueue) releaseBytes(data *adapter.TransportData) {
+func (q *transportQueue) releaseBytes(laneIndex int, data *adapter.TransportData) {
 	q.protected.Lock()
 	defer q.protected.Unlock()
 
-	q.protected.bytesLeft += data.TotalSize()
+	q.protected.bytesLeft[laneIndex] += data.TotalSize()
 	q.updateUsageMetric()
 }
 
+// reports the fullest lane since that is the one closest to dropping messages
 func (q *transportQueue) updateUsageMetric() {
-	bytesUsed := q.maxBytes - q.protected.bytesLeft
+	bytesUsed := 0
+	for _, bytesLeft := range q.protected.bytesLeft {
+		if q.maxBytes-bytesLeft > bytesUsed {
+			bytesUsed = q.maxBytes - bytesLeft
+		}
+	}
 	q.usagePercentageMetric.Update(int64(bytesUsed * 100 / q.maxBytes))
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
 	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/scribe/log"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -185,6 +186,68 @@ func TestQueue_DisableThenEnable(t *testing.T) {
 	})
 }
 
+func TestQueue_ConsensusMessagesArePoppedBeforeOtherTopics(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			q := aQueue(t, 1000, 1000, parent.Logger)
+
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x01)))
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY, 0x02)))
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x03)))
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x04)))
+
+			require.EqualValues(t, []byte{0x03}, q.Pop(ctx).SenderNodeAddress)
+			require.EqualValues(t, []byte{0x04}, q.Pop(ctx).SenderNodeAddress)
+		})
+	})
+}
+
+func TestQueue_WeightedTopicsShareTheRemainingBandwidth(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			q := aQueue(t, 1000, 1000, parent.Logger)
+
+			for i := 0; i < 10; i++ {
+				require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x01)))
+				require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY, 0x02)))
+			}
+
+			popped := map[byte]int{}
+			for i := 0; i < 10; i++ {
+				popped[q.Pop(ctx).SenderNodeAddress[0]]++
+			}
+			require.Equal(t, 8, popped[0x02], "expected transaction relay to get 4 messages for every block sync message")
+			require.Equal(t, 2, popped[0x01], "expected block sync not to be starved")
+		})
+	})
+}
+
+func TestQueue_TopicsAreLimitedAndMeteredSeparately(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			registry := metric.NewRegistry()
+			q := NewTransportQueue(1000, 2, registry, someAddress, parent.Logger)
+
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x01)))
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x02)))
+			require.Error(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_BLOCK_SYNC, 0x03)), "block sync queue should be full")
+			require.NoError(t, q.Push(aMessageOfTopic(gossipmessages.HEADER_TOPIC_LEAN_HELIX, 0x04)), "expected a full block sync queue not to affect consensus")
+
+			require.EqualValues(t, 2, registry.Get("Gossip.OutgoingConnection.QueueDepth.BlockSync..Count").(*metric.Gauge).IntValue())
+			require.EqualValues(t, 1, registry.Get("Gossip.OutgoingConnection.QueueDrops.BlockSync..Count").(*metric.Gauge).IntValue())
+			require.EqualValues(t, 1, registry.Get("Gossip.OutgoingConnection.QueueDepth.LeanHelixConsensus..Count").(*metric.Gauge).IntValue())
+
+			q.Pop(ctx)
+			require.EqualValues(t, 0, registry.Get("Gossip.OutgoingConnection.QueueDepth.LeanHelixConsensus..Count").(*metric.Gauge).IntValue())
+		})
+	})
+}
+
+func aMessageOfTopic(topic gossipmessages.HeaderTopic, sender byte) *adapter.TransportData {
+	header := (&gossipmessages.HeaderBuilder{Topic: topic}).Build()
+	return &adapter.TransportData{SenderNodeAddress: []byte{sender}, Payloads: [][]byte{header.Raw()}}
+}
+
 func buf(len int) []byte {
 	return make([]byte, len)
 }
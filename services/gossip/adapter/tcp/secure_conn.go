@@ -0,0 +1,119 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package tcp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+)
+
+const sessionKeySize = 32
+const maxRecordPlaintextSize = 64 * 1024
+
+// secureConn encrypts every write as records of a length followed by the AES-GCM sealed data, nonces count the records
+// sent in each direction so records cannot be replayed or reordered
+type secureConn struct {
+	net.Conn
+
+	sealer    cipher.AEAD
+	sendCount uint64
+
+	opener       cipher.AEAD
+	receiveCount uint64
+	pending      []byte // opened data which was not read yet
+}
+
+func newSecureConn(conn net.Conn, sendKey []byte, receiveKey []byte) (*secureConn, error) {
+	sealer, err := newAead(sendKey)
+	if err != nil {
+		return nil, err
+	}
+	opener, err := newAead(receiveKey)
+	if err != nil {
+		return nil, err
+	}
+	return &secureConn{
+		Conn:   conn,
+		sealer: sealer,
+		opener: opener,
+	}, nil
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating session cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Wrap(err, "failed creating session cipher")
+}
+
+func (c *secureConn) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		size := len(b) - written
+		if size > maxRecordPlaintextSize {
+			size = maxRecordPlaintextSize
+		}
+
+		record := make([]byte, 4, 4+size+c.sealer.Overhead())
+		record = c.sealer.Seal(record, nonce(c.sendCount), b[written:written+size], nil)
+		binary.LittleEndian.PutUint32(record[:4], uint32(len(record)-4))
+		c.sendCount++
+
+		if _, err := c.Conn.Write(record); err != nil {
+			return written, err
+		}
+		written += size
+	}
+	return written, nil
+}
+
+func (c *secureConn) Read(b []byte) (int, error) {
+	if len(c.pending) == 0 {
+		if err := c.readRecord(); err != nil {
+			return 0, err
+		}
+	}
+	read := copy(b, c.pending)
+	c.pending = c.pending[read:]
+	return read, nil
+}
+
+func (c *secureConn) readRecord() error {
+	sizeBuffer := make([]byte, 4)
+	if _, err := io.ReadFull(c.Conn, sizeBuffer); err != nil {
+		return err
+	}
+	size := binary.LittleEndian.Uint32(sizeBuffer)
+	if size < uint32(c.opener.Overhead()) || size > uint32(maxRecordPlaintextSize+c.opener.Overhead()) {
+		return errors.Errorf("received an encrypted record of invalid size %d", size)
+	}
+
+	record := make([]byte, size)
+	if _, err := io.ReadFull(c.Conn, record); err != nil {
+		return err
+	}
+
+	opened, err := c.opener.Open(record[:0], nonce(c.receiveCount), record, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed decrypting record")
+	}
+	c.receiveCount++
+	c.pending = opened
+	return nil
+}
+
+func nonce(count uint64) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[4:], count)
+	return n
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/scribe/log"
 	"github.com/pkg/errors"
 	"net"
@@ -24,8 +25,10 @@ import (
 )
 
 type serverConfig interface {
+	peerScoresConfig
 	GossipListenPort() uint16
 	GossipNetworkTimeout() time.Duration
+	GossipCompressionEnabled() bool
 }
 
 type transportServer struct {
@@ -39,7 +42,12 @@ type transportServer struct {
 	logger         log.Logger
 	metrics        incomingConnectionMetrics
 	config         serverConfig
+	peerScores     *peerScores
+	traffic        *receivedTraffic
 	shutdownServer context.CancelFunc
+
+	handshake     *handshake // nil when gossip authentication is disabled
+	isPeerAllowed func(primitives.NodeAddress) bool
 }
 
 type incomingConnectionMetrics struct {
@@ -47,13 +55,16 @@ type incomingConnectionMetrics struct {
 	acceptErrors      *metric.Gauge
 	transportErrors   *metric.Gauge
 	activeConnections *metric.Gauge
+	handshakeErrors   *metric.Gauge
 }
 
 func newServer(config serverConfig, logger log.Logger, registry metric.Registry) *transportServer {
 	server := &transportServer{
-		config:  config,
-		logger:  logger,
-		metrics: createServerMetrics(registry),
+		config:     config,
+		logger:     logger,
+		metrics:    createServerMetrics(registry),
+		peerScores: newPeerScores(config, logger, registry),
+		traffic:    newReceivedTraffic(),
 	}
 
 	return server
@@ -65,6 +76,7 @@ func createServerMetrics(registry metric.Registry) incomingConnectionMetrics {
 		acceptErrors:      registry.NewGauge("Gossip.IncomingConnection.ListeningOnTCPPortErrors.Count"),
 		transportErrors:   registry.NewGauge("Gossip.IncomingConnection.TransportErrors.Count"),
 		activeConnections: registry.NewGauge("Gossip.IncomingConnection.Active.Count"),
+		handshakeErrors:   registry.NewGauge("Gossip.IncomingConnection.HandshakeErrors.Count"),
 	}
 }
 
@@ -131,6 +143,13 @@ func (t *transportServer) mainLoop(parentCtx context.Context, listener net.Liste
 			continue
 		}
 
+		if admitted, reason := t.peerScores.admitConnection(peerOf(conn)); !admitted {
+			t.peerScores.refusedConnections.Inc()
+			logger.Info("refused incoming connection", log.String("reason", reason), log.Stringable("remote-address", conn.RemoteAddr()))
+			_ = conn.Close()
+			continue
+		}
+
 		logger.Info("got incoming connection", log.Stringable("remote-address", conn.RemoteAddr()))
 		t.metrics.acceptSuccesses.Inc()
 
@@ -155,24 +174,74 @@ func (t *transportServer) handleIncomingConnection(ctx context.Context, conn net
 	defer t.metrics.activeConnections.Dec()
 
 	defer func() { _ = conn.Close() }()
+
+	peer := peerOf(conn)
+	reportMisbehavior := func(misbehavior adapter.Misbehavior) {
+		t.peerScores.penalize(peer, misbehavior)
+	}
+
+	var peerNodeAddress primitives.NodeAddress
+	if t.handshake != nil {
+		secureConn, authenticatedPeer, err := t.handshake.server(ctx, conn, t.isPeerAllowed)
+		if err != nil {
+			t.metrics.handshakeErrors.Inc()
+			reportMisbehavior(adapter.MisbehaviorFailedHandshake)
+			t.logger.Info("incoming connection failed to authenticate, disconnecting", log.Error(err), log.String("peer", conn.RemoteAddr().String()), trace.LogFieldFrom(ctx))
+
+			return
+		}
+		conn = secureConn
+		peerNodeAddress = authenticatedPeer
+	}
+
+	compressed := false // until the peer proposes compression and it is accepted
 	for {
-		payloads, err := t.receiveTransportData(ctx, conn)
+		payloads, err := t.receiveTransportData(ctx, conn, &compressed)
 		if err != nil {
 			t.metrics.transportErrors.Inc()
+			if errors.Cause(err) == errOversizedMessage {
+				reportMisbehavior(adapter.MisbehaviorOversizedMessage)
+			}
 			t.logger.Info("failed receiving transport data, disconnecting", log.Error(err), log.String("peer", conn.RemoteAddr().String()), trace.LogFieldFrom(ctx))
 
 			return
 		}
 
+		if peerNodeAddress != nil && !t.isPeerAllowed(peerNodeAddress) {
+			t.logger.Info("peer was removed from the topology, disconnecting", log.Stringable("peer-node-address", peerNodeAddress), trace.LogFieldFrom(ctx))
+
+			return
+		}
+
 		// notify if not keepalive
 		if len(payloads) > 0 {
 			ctxWithPeer := context.WithValue(ctx, "peer-ip", conn.RemoteAddr().String())
+			ctxWithPeer = adapter.ContextWithMisbehaviorReporter(ctxWithPeer, reportMisbehavior)
+			if peerNodeAddress != nil {
+				ctxWithPeer = adapter.ContextWithAuthenticatedPeer(ctxWithPeer, peerNodeAddress)
+				t.traffic.received(peerNodeAddress, payloads)
+			}
 			t.notifyListener(ctxWithPeer, payloads)
 		}
+
+		if t.peerScores.isDisconnected(peer) {
+			t.logger.Info("peer is misbehaving, disconnecting", log.String("peer", conn.RemoteAddr().String()), trace.LogFieldFrom(ctx))
+
+			return
+		}
+
+		if t.peerScores.isThrottled(peer) {
+			t.peerScores.throttledMessages.Inc()
+			select {
+			case <-ctx.Done():
+			case <-time.After(peerThrottleDelay):
+			}
+		}
 	}
 }
 
-func (t *transportServer) receiveTransportData(ctx context.Context, conn net.Conn) ([][]byte, error) {
+// a compression proposal is answered and returns no payloads, like a keep alive
+func (t *transportServer) receiveTransportData(ctx context.Context, conn net.Conn, compressed *bool) ([][]byte, error) {
 	// TODO(https://github.com/orbs-network/orbs-network-go/issues/182): think about timeout policy on receive, we might not want it
 	timeout := t.config.GossipNetworkTimeout()
 	var res [][]byte
@@ -184,8 +253,13 @@ func (t *transportServer) receiveTransportData(ctx context.Context, conn net.Con
 	}
 	numPayloads := membuffers.GetUint32(sizeBuffer)
 
+	if numPayloads == compressionProposal {
+		*compressed, err = replyToCompressionProposal(ctx, conn, t.config.GossipCompressionEnabled(), timeout)
+		return nil, err
+	}
+
 	if numPayloads > MAX_PAYLOADS_IN_MESSAGE {
-		return nil, errors.Errorf("received message with too many payloads: %d", numPayloads)
+		return nil, errors.Wrapf(errOversizedMessage, "received message with too many payloads: %d", numPayloads)
 	}
 
 	for i := uint32(0); i < numPayloads; i++ {
@@ -195,8 +269,13 @@ func (t *transportServer) receiveTransportData(ctx context.Context, conn net.Con
 			return nil, err
 		}
 		payloadSize := membuffers.GetUint32(sizeBuffer)
+		isCompressed := payloadSize&compressedPayloadFlag != 0
+		if isCompressed && !*compressed {
+			return nil, errors.New("received a compressed payload on a connection which did not negotiate compression")
+		}
+		payloadSize &^= compressedPayloadFlag
 		if payloadSize > MAX_PAYLOAD_SIZE_BYTES {
-			return nil, errors.Errorf("received message with a payload too big: %d bytes", payloadSize)
+			return nil, errors.Wrapf(errOversizedMessage, "received message with a payload too big: %d bytes", payloadSize)
 		}
 
 		// receive payload data
@@ -204,7 +283,6 @@ func (t *transportServer) receiveTransportData(ctx context.Context, conn net.Con
 		if err != nil {
 			return nil, err
 		}
-		res = append(res, payload)
 
 		// receive padding
 		paddingSize := calcPaddingSize(uint32(len(payload)))
@@ -214,11 +292,21 @@ func (t *transportServer) receiveTransportData(ctx context.Context, conn net.Con
 				return nil, err
 			}
 		}
+
+		if isCompressed {
+			payload, err = decompressPayload(payload)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, payload)
 	}
 
 	return res, nil
 }
 
+var errOversizedMessage = errors.New("message exceeds the allowed size")
+
 func (t *transportServer) notifyListener(ctx context.Context, payloads [][]byte) {
 	listener := t.getListener()
 
@@ -247,6 +335,8 @@ func (t *transportServer) startSupervisedMainLoop(parent context.Context) {
 	t.Supervise(govnr.Forever(ctx, "TCP server", logfields.GovnrErrorer(t.logger), func() {
 		t.mainLoop(ctx, listener)
 	}))
+	t.peerScores.startExpiringPeers(ctx)
+	t.Supervise(t.peerScores)
 }
 
 func (t *transportServer) GracefulShutdown(shutdownContext context.Context) {
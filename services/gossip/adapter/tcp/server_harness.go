@@ -61,7 +61,7 @@ func newDirectHarnessWithConnectedPeers(t *testing.T, ctx context.Context, paren
 func makeTransport(ctx context.Context, logger log.Logger, cfg config.GossipTransportConfig) *DirectTransport {
 	registry := metric.NewRegistry()
 
-	transport := NewDirectTransport(ctx, cfg, logger, registry)
+	transport := NewDirectTransport(ctx, cfg, nil, logger, registry)
 	// to synchronize tests, wait until server is ready
 	test.Eventually(test.EVENTUALLY_ADAPTER_TIMEOUT, func() bool {
 		return transport.IsServerListening()
@@ -169,7 +169,9 @@ func TestDirectIncoming_TimeoutDuringReceiveCausesDisconnect(t *testing.T) {
 }
 
 type serverCfg struct {
-	port uint16
+	port                uint16
+	compressionEnabled  bool
+	disconnectThreshold uint32
 }
 
 func (s *serverCfg) GossipListenPort() uint16 {
@@ -180,6 +182,22 @@ func (s *serverCfg) GossipNetworkTimeout() time.Duration {
 	return 100 * time.Millisecond
 }
 
+func (s *serverCfg) GossipCompressionEnabled() bool {
+	return s.compressionEnabled
+}
+
+func (s *serverCfg) GossipPeerScoreThrottleThreshold() uint32 {
+	return 0
+}
+
+func (s *serverCfg) GossipPeerScoreDisconnectThreshold() uint32 {
+	return s.disconnectThreshold
+}
+
+func (s *serverCfg) GossipPeerDisconnectDuration() time.Duration {
+	return 1 * time.Minute
+}
+
 func TestDirectServer_PanicsOnPortAlreadyInUse(t *testing.T) {
 	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
 
@@ -283,3 +301,36 @@ func TestDirectServer_DoesNotAcceptNewConnections_AfterShutdown(t *testing.T) {
 		require.Error(t, err, "should not have succeeded connecting to server")
 	})
 }
+
+func TestDirectServer_RefusesConnectionsFromAPeerDisconnectedForMisbehaving(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		cfg := &serverCfg{disconnectThreshold: 50}
+
+		server := newServer(cfg, harness.Logger, metric.NewRegistry())
+		harness.Supervise(server)
+		server.startSupervisedMainLoop(ctx)
+		defer server.GracefulShutdown(context.Background())
+
+		require.True(t, test.Eventually(100*time.Millisecond, func() bool {
+			return server.IsListening()
+		}))
+
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", server.getPort()))
+		require.NoError(t, err, "failed connecting to server")
+		defer conn.Close()
+
+		_, err = conn.Write(exampleWireProtocolEncoding_CorruptNumPayloads())
+		require.NoError(t, err, "test peer could not write to server")
+		_, err = conn.Read([]byte{0})
+		require.Error(t, err, "test peer should be disconnected from server")
+		require.True(t, server.peerScores.isDisconnected("127.0.0.1"), "expected an oversized message to disconnect the peer")
+
+		conn, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", server.getPort()))
+		require.NoError(t, err, "failed connecting to server")
+		defer conn.Close()
+
+		_, err = conn.Read([]byte{0})
+		require.Error(t, err, "expected the server to close new connections from a disconnected peer")
+		require.EqualValues(t, 1, server.peerScores.refusedConnections.IntValue())
+	})
+}
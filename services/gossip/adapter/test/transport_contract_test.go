@@ -9,6 +9,7 @@ package test
 import (
 	"context"
 	"encoding/hex"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
@@ -177,10 +178,10 @@ func aDirectTransport(ctx context.Context, harness *with.ConcurrencyHarness) *tr
 
 
 	transports := []*tcp.DirectTransport{
-		tcp.NewDirectTransport(ctx, configs[0], logger, metric.NewRegistry()),
-		tcp.NewDirectTransport(ctx, configs[1], logger, metric.NewRegistry()),
-		tcp.NewDirectTransport(ctx, configs[2], logger, metric.NewRegistry()),
-		tcp.NewDirectTransport(ctx, configs[3], logger, metric.NewRegistry()),
+		tcp.NewDirectTransport(ctx, configs[0], signer.NewLocalSigner(keys.EcdsaSecp256K1KeyPairForTests(0).PrivateKey()), logger, metric.NewRegistry()),
+		tcp.NewDirectTransport(ctx, configs[1], signer.NewLocalSigner(keys.EcdsaSecp256K1KeyPairForTests(1).PrivateKey()), logger, metric.NewRegistry()),
+		tcp.NewDirectTransport(ctx, configs[2], signer.NewLocalSigner(keys.EcdsaSecp256K1KeyPairForTests(2).PrivateKey()), logger, metric.NewRegistry()),
+		tcp.NewDirectTransport(ctx, configs[3], signer.NewLocalSigner(keys.EcdsaSecp256K1KeyPairForTests(3).PrivateKey()), logger, metric.NewRegistry()),
 	}
 
 	test.Eventually(1*time.Second, func() bool {
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 )
 
 func ExampleMessagePredicate_sender() {
@@ -62,3 +63,19 @@ func ExampleMessagePredicate_payloadSize() {
 	// Output: got message smaller than 100 bytes
 	// got message larger than 100 bytes
 }
+
+func ExampleSentTo() {
+	node1, node2, node3 := primitives.NodeAddress{0x01}, primitives.NodeAddress{0x02}, primitives.NodeAddress{0x03}
+	pred := SentTo(node2)
+
+	fmt.Println(pred(&adapter.TransportData{SenderNodeAddress: node1, RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_LIST, RecipientNodeAddresses: []primitives.NodeAddress{node2}}))
+	fmt.Println(pred(&adapter.TransportData{SenderNodeAddress: node1, RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_LIST, RecipientNodeAddresses: []primitives.NodeAddress{node3}}))
+	fmt.Println(pred(&adapter.TransportData{SenderNodeAddress: node1, RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_BROADCAST}))
+	fmt.Println(pred(&adapter.TransportData{SenderNodeAddress: node2, RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_BROADCAST}))
+	fmt.Println(pred(&adapter.TransportData{SenderNodeAddress: node1, RecipientMode: gossipmessages.RECIPIENT_LIST_MODE_ALL_BUT_LIST, RecipientNodeAddresses: []primitives.NodeAddress{node2}}))
+	// Output: true
+	// false
+	// true
+	// false
+	// false
+}
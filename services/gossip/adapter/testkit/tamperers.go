@@ -7,6 +7,7 @@
 package testkit
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/orbs-network/govnr"
@@ -111,6 +112,26 @@ func (o *corruptingTamperer) StopTampering(ctx context.Context) {
 	o.transport.removeOngoingTamperer(o)
 }
 
+type spoofingTamperer struct {
+	predicate     MessagePredicate
+	transport     *TamperingTransport
+	asNodeAddress primitives.NodeAddress
+}
+
+// the sender address is replaced wherever it appears in the message body, the header does not hold it
+func (o *spoofingTamperer) maybeTamper(ctx context.Context, data *adapter.TransportData, peerAddress primitives.NodeAddress, transmit adapter.TransmitFunc) (error, bool) {
+	if o.predicate(data) && len(data.SenderNodeAddress) == len(o.asNodeAddress) {
+		for i := 1; i < len(data.Payloads); i++ {
+			data.Payloads[i] = bytes.Replace(data.Payloads[i], data.SenderNodeAddress, o.asNodeAddress, -1)
+		}
+	}
+	return nil, false
+}
+
+func (o *spoofingTamperer) StopTampering(ctx context.Context) {
+	o.transport.removeOngoingTamperer(o)
+}
+
 type pausedTransmission struct {
 	data        *adapter.TransportData
 	peerAddress primitives.NodeAddress
@@ -8,6 +8,7 @@ package testkit
 
 import (
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 )
@@ -84,3 +85,34 @@ func parseHeader(data *adapter.TransportData) (*gossipmessages.Header, bool) {
 
 	return header, true
 }
+
+// a MessagePredicate for capturing messages sent by the given node
+func SentBy(sender primitives.NodeAddress) MessagePredicate {
+	return func(data *adapter.TransportData) bool {
+		return data.SenderNodeAddress.Equal(sender)
+	}
+}
+
+// a MessagePredicate for capturing messages which reach the given node, broadcasts reach every node but their sender
+func SentTo(recipient primitives.NodeAddress) MessagePredicate {
+	return func(data *adapter.TransportData) bool {
+		switch data.RecipientMode {
+		case gossipmessages.RECIPIENT_LIST_MODE_BROADCAST:
+			return !data.SenderNodeAddress.Equal(recipient)
+		case gossipmessages.RECIPIENT_LIST_MODE_LIST:
+			return containsNodeAddress(data.RecipientNodeAddresses, recipient)
+		case gossipmessages.RECIPIENT_LIST_MODE_ALL_BUT_LIST:
+			return !data.SenderNodeAddress.Equal(recipient) && !containsNodeAddress(data.RecipientNodeAddresses, recipient)
+		}
+		return false
+	}
+}
+
+func containsNodeAddress(addresses []primitives.NodeAddress, address primitives.NodeAddress) bool {
+	for _, a := range addresses {
+		if a.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
@@ -43,6 +43,10 @@ type Tamperer interface {
 
 	// Creates an ongoing tamper which delays (reshuffles) messages matching the given predicate for the specified duration
 	Delay(duration func() time.Duration, predicate MessagePredicate) OngoingTamper
+
+	// Creates an ongoing tamper which makes messages matching the given predicate claim they were sent by another node,
+	// while the transport still delivers them from the real sender. This is useful to verify spoofed messages are dropped
+	Spoof(predicate MessagePredicate, asNodeAddress primitives.NodeAddress) OngoingTamper
 }
 
 // A predicate for matching messages with a certain property
@@ -143,6 +147,10 @@ func (t *TamperingTransport) Delay(duration func() time.Duration, predicate Mess
 	return t.addTamperer(&delayingTamperer{predicate: predicate, transport: t, duration: duration})
 }
 
+func (t *TamperingTransport) Spoof(predicate MessagePredicate, asNodeAddress primitives.NodeAddress) OngoingTamper {
+	return t.addTamperer(&spoofingTamperer{predicate: predicate, transport: t, asNodeAddress: asNodeAddress})
+}
+
 func (t *TamperingTransport) LatchOn(predicate MessagePredicate) LatchingTamper {
 	tamperer := &latchingTamperer{predicate: predicate, transport: t, cond: sync.NewCond(&sync.Mutex{})}
 	t.tamperers.Lock()
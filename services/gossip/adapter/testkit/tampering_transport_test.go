@@ -270,6 +270,29 @@ func TestLatchingTamperer(t *testing.T) {
 	})
 }
 
+func TestSpoofingTamperer_ClaimsAnotherSenderWhileDeliveredFromRealSender(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, parent *with.ConcurrencyHarness) {
+		withTamperingHarness(ctx, t, parent, 1, func(c *tamperingHarness) {
+			received := make(chan [][]byte, 1)
+			authenticatedPeers := make(chan primitives.NodeAddress, 1)
+
+			c.transport.Spoof(anyMessage(), primitives.NodeAddress("victim"))
+			c.listeners[0].WhenOnTransportMessageReceived(mock.Any).Call(func(ctx context.Context, payloads [][]byte) {
+				peer, _ := adapter.AuthenticatedPeerFromContext(ctx)
+				authenticatedPeers <- peer
+				received <- payloads
+			}).Times(1)
+
+			payloads := [][]byte{{0x1}, []byte("signed by sender")}
+			c.send(ctx, payloads)
+
+			require.Equal(t, []byte("signed by victim"), (<-received)[1], "expected the sender address in the message body to be spoofed")
+			require.EqualValues(t, c.senderKey, <-authenticatedPeers, "expected the transport to deliver the message from the real sender")
+			require.Equal(t, []byte("signed by sender"), payloads[1], "expected input payload to preserve its original value")
+		})
+	})
+}
+
 func oddNumbers() MessagePredicate {
 	return func(data *adapter.TransportData) bool {
 		return data.Payloads[0][0]%2 == 1
@@ -0,0 +1,80 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package codec
+
+import (
+	"encoding/binary"
+	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/digest"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/pkg/errors"
+)
+
+const peerAnnouncementMaxEndpointSize = 255
+const peerAnnouncementFieldsSize = 2 + 8 // port followed by timestamp
+
+// PeerAnnouncement is the gossip endpoint a node announces for itself, signed by the node
+type PeerAnnouncement struct {
+	NodeAddress primitives.NodeAddress
+	Endpoint    string
+	Port        uint16
+	Timestamp   primitives.TimestampNano
+	Signature   primitives.EcdsaSecp256K1Sig
+}
+
+// SignedPayloads are the payloads the signature of the announcement is over
+func (a *PeerAnnouncement) SignedPayloads() [][]byte {
+	fields := make([]byte, peerAnnouncementFieldsSize)
+	binary.LittleEndian.PutUint16(fields, a.Port)
+	binary.LittleEndian.PutUint64(fields[2:], uint64(a.Timestamp))
+	return [][]byte{a.NodeAddress, []byte(a.Endpoint), fields}
+}
+
+func EncodePeerAnnouncement(header *gossipmessages.Header, announcement *PeerAnnouncement) ([][]byte, error) {
+	if len(announcement.NodeAddress) != digest.NODE_ADDRESS_SIZE_BYTES {
+		return nil, errors.Errorf("NodeAddress is %d bytes but should be %d", len(announcement.NodeAddress), digest.NODE_ADDRESS_SIZE_BYTES)
+	}
+	if announcement.Endpoint == "" || len(announcement.Endpoint) > peerAnnouncementMaxEndpointSize {
+		return nil, errors.Errorf("Endpoint must be 1 to %d bytes", peerAnnouncementMaxEndpointSize)
+	}
+	if len(announcement.Signature) == 0 {
+		return nil, errors.New("missing Signature")
+	}
+
+	payloads := make([][]byte, 0, 5)
+	payloads = append(payloads, header.Raw())
+	payloads = append(payloads, announcement.SignedPayloads()...)
+	return append(payloads, announcement.Signature), nil
+}
+
+func DecodePeerAnnouncement(payloads [][]byte) (*PeerAnnouncement, error) {
+	if len(payloads) != 4 {
+		return nil, errors.New("wrong num of payloads")
+	}
+
+	nodeAddress, endpoint, fields, signature := payloads[0], payloads[1], payloads[2], payloads[3]
+	if len(nodeAddress) != digest.NODE_ADDRESS_SIZE_BYTES {
+		return nil, errors.Errorf("NodeAddress is %d bytes but should be %d", len(nodeAddress), digest.NODE_ADDRESS_SIZE_BYTES)
+	}
+	if len(endpoint) == 0 || len(endpoint) > peerAnnouncementMaxEndpointSize {
+		return nil, errors.Errorf("Endpoint must be 1 to %d bytes", peerAnnouncementMaxEndpointSize)
+	}
+	if len(fields) != peerAnnouncementFieldsSize {
+		return nil, errors.Errorf("announcement fields are %d bytes but should be %d", len(fields), peerAnnouncementFieldsSize)
+	}
+	if len(signature) == 0 {
+		return nil, errors.New("missing Signature")
+	}
+
+	return &PeerAnnouncement{
+		NodeAddress: nodeAddress,
+		Endpoint:    string(endpoint),
+		Port:        binary.LittleEndian.Uint16(fields),
+		Timestamp:   primitives.TimestampNano(binary.LittleEndian.Uint64(fields[2:])),
+		Signature:   signature,
+	}, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package codec
+
+import (
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPeerDiscovery_PeerAnnouncement(t *testing.T) {
+	announcement := &PeerAnnouncement{
+		NodeAddress: keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress(),
+		Endpoint:    "10.0.0.1",
+		Port:        4400,
+		Timestamp:   1584608736000000000,
+		Signature:   []byte{0x01, 0x02, 0x03},
+	}
+
+	payloads, err := EncodePeerAnnouncement((&gossipmessages.HeaderBuilder{}).Build(), announcement)
+	require.NoError(t, err, "encode should not fail")
+	decoded, err := DecodePeerAnnouncement(payloads[1:])
+	require.NoError(t, err, "decode should not fail")
+	test.RequireCmpEqual(t, announcement, decoded, "decoded encoded should equal to original")
+}
+
+func TestPeerDiscovery_EmptyPeerAnnouncement(t *testing.T) {
+	_, err := DecodePeerAnnouncement(builders.EmptyPayloads(4))
+	require.Error(t, err, "decode should fail and return error")
+}
+
+func TestPeerDiscovery_PeerAnnouncementWithoutSignature(t *testing.T) {
+	_, err := EncodePeerAnnouncement((&gossipmessages.HeaderBuilder{}).Build(), &PeerAnnouncement{
+		NodeAddress: keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress(),
+		Endpoint:    "10.0.0.1",
+		Port:        4400,
+	})
+	require.Error(t, err, "encode should fail and return error")
+}
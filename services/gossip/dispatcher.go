@@ -13,6 +13,8 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/scribe/log"
 	"github.com/pkg/errors"
@@ -21,9 +23,11 @@ import (
 type handlerFunc func(ctx context.Context, header *gossipmessages.Header, payloads [][]byte)
 
 type gossipMessage struct {
-	header         *gossipmessages.Header
-	payloads       [][]byte
-	tracingContext *trace.Context
+	header            *gossipmessages.Header
+	payloads          [][]byte
+	tracingContext    *trace.Context
+	authenticatedPeer primitives.NodeAddress
+	reportMisbehavior adapter.MisbehaviorReporter
 }
 
 type meteredTopicChannel struct {
@@ -39,12 +43,14 @@ func (c *meteredTopicChannel) send(ctx context.Context, header *gossipmessages.H
 	logger := c.logger.WithTags(trace.LogFieldFrom(ctx))
 	logger.Info("transport message received", log.Stringable("header", header), log.Int("topic-size", len(c.ch)))
 	tracingContext, _ := trace.FromContext(ctx)
+	authenticatedPeer, _ := adapter.AuthenticatedPeerFromContext(ctx)
+	reportMisbehavior := adapter.MisbehaviorReporterFromContext(ctx)
 
 	select {
 	default:
 		c.droppedMessages.Inc()
 		return errors.Errorf("buffer full")
-	case c.ch <- gossipMessage{header: header, payloads: payloads, tracingContext: tracingContext}: //TODO should the channel have *gossipMessage as type?
+	case c.ch <- gossipMessage{header: header, payloads: payloads, tracingContext: tracingContext, authenticatedPeer: authenticatedPeer, reportMisbehavior: reportMisbehavior}: //TODO should the channel have *gossipMessage as type?
 		c.updateMetrics()
 		return nil
 	}
@@ -63,6 +69,12 @@ func (c *meteredTopicChannel) run(ctx context.Context, logger log.Logger, handle
 				return
 			case message := <-c.ch:
 				ctxWithTrace := trace.PropagateContext(ctx, message.tracingContext)
+				if message.authenticatedPeer != nil {
+					ctxWithTrace = adapter.ContextWithAuthenticatedPeer(ctxWithTrace, message.authenticatedPeer)
+				}
+				if message.reportMisbehavior != nil {
+					ctxWithTrace = adapter.ContextWithMisbehaviorReporter(ctxWithTrace, message.reportMisbehavior)
+				}
 				handler(ctxWithTrace, message.header, message.payloads)
 				c.updateMetrics()
 			}
@@ -0,0 +1,186 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+	"math/rand"
+	"sync"
+)
+
+const relaySeenMessagesCapacity = 20000
+const relayTrailerSize = 2 // ttl followed by hops
+
+var relayMessageHashDomain = []byte("orbs-gossip-relay")
+
+type relayConfig interface {
+	NodeAddress() primitives.NodeAddress
+	GossipEpidemicTransactionRelayEnabled() bool
+	GossipEpidemicBlockSyncEnabled() bool
+	GossipEpidemicRelayFanout() uint32
+	GossipEpidemicRelayTtl() uint32
+}
+
+// epidemicRelay sends broadcasts of the enabled topics to a random fanout of peers instead of to the whole topology.
+// every node receiving such a message for the first time delivers it and relays it to a random fanout of its own peers
+// until its ttl runs out, so bandwidth scales with the fanout rather than the network size.
+// the relay state is appended to the message as its last payload: the ttl left and the number of hops it was relayed
+type epidemicRelay struct {
+	config relayConfig
+
+	topology struct {
+		sync.RWMutex
+		nodeAddresses []primitives.NodeAddress
+	}
+
+	seen struct {
+		sync.Mutex
+		hashes map[string]bool
+		order  []string // oldest first, bounds the memory of the seen hashes
+	}
+
+	relayedMessages   *metric.Gauge
+	duplicateMessages *metric.Gauge
+}
+
+type relayTrailer struct {
+	ttl  uint8
+	hops uint8
+}
+
+func newEpidemicRelay(config relayConfig, registry metric.Registry) *epidemicRelay {
+	r := &epidemicRelay{
+		config:            config,
+		relayedMessages:   registry.NewGauge("Gossip.EpidemicRelay.RelayedMessages.Count"),
+		duplicateMessages: registry.NewGauge("Gossip.EpidemicRelay.DuplicateMessages.Count"),
+	}
+	r.seen.hashes = make(map[string]bool)
+	return r
+}
+
+func isEpidemicRelayEnabled(config relayConfig) bool {
+	return config.GossipEpidemicTransactionRelayEnabled() || config.GossipEpidemicBlockSyncEnabled()
+}
+
+func (r *epidemicRelay) isRelayed(header *gossipmessages.Header) bool {
+	switch header.Topic() {
+	case gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY:
+		return r.config.GossipEpidemicTransactionRelayEnabled() && header.TransactionRelay() == gossipmessages.TRANSACTION_RELAY_FORWARDED_TRANSACTIONS
+	case gossipmessages.HEADER_TOPIC_BLOCK_SYNC:
+		return r.config.GossipEpidemicBlockSyncEnabled() && header.BlockSync() == gossipmessages.BLOCK_SYNC_AVAILABILITY_REQUEST
+	default:
+		return false
+	}
+}
+
+func (r *epidemicRelay) updateTopology(peers []*services.GossipPeer) {
+	var nodeAddresses []primitives.NodeAddress
+	for _, peer := range peers {
+		if !peer.Address.Equal(r.config.NodeAddress()) {
+			nodeAddresses = append(nodeAddresses, peer.Address)
+		}
+	}
+
+	r.topology.Lock()
+	defer r.topology.Unlock()
+	r.topology.nodeAddresses = nodeAddresses
+}
+
+// returns the data to send for a message originating at this node
+func (r *epidemicRelay) originate(payloads [][]byte) *adapter.TransportData {
+	r.markSeen(payloads) // so it is not relayed again when a peer relays it back
+	return r.relayData(payloads, relayTrailer{ttl: uint8(r.config.GossipEpidemicRelayTtl()), hops: 0}, nil)
+}
+
+// returns the message without its relay state
+func (r *epidemicRelay) open(payloads [][]byte) ([][]byte, relayTrailer, error) {
+	if len(payloads) < 2 {
+		return nil, relayTrailer{}, errors.New("relayed message has no relay state")
+	}
+
+	trailer := payloads[len(payloads)-1]
+	if len(trailer) != relayTrailerSize {
+		return nil, relayTrailer{}, errors.Errorf("relay state is %d bytes but should be %d", len(trailer), relayTrailerSize)
+	}
+	return payloads[:len(payloads)-1], relayTrailer{ttl: trailer[0], hops: trailer[1]}, nil
+}
+
+// returns false if the message was already seen
+func (r *epidemicRelay) markSeen(message [][]byte) bool {
+	hash := string(hashPayloads(relayMessageHashDomain, message))
+
+	r.seen.Lock()
+	defer r.seen.Unlock()
+	if r.seen.hashes[hash] {
+		r.duplicateMessages.Inc()
+		return false
+	}
+
+	r.seen.hashes[hash] = true
+	r.seen.order = append(r.seen.order, hash)
+	if len(r.seen.order) > relaySeenMessagesCapacity {
+		delete(r.seen.hashes, r.seen.order[0])
+		r.seen.order = r.seen.order[1:]
+	}
+	return true
+}
+
+// returns the data relaying a received message on, or nil if its ttl ran out
+func (r *epidemicRelay) relay(message [][]byte, trailer relayTrailer, receivedFrom primitives.NodeAddress) *adapter.TransportData {
+	if trailer.ttl <= 1 {
+		return nil
+	}
+
+	data := r.relayData(message, relayTrailer{ttl: trailer.ttl - 1, hops: trailer.hops + 1}, receivedFrom)
+	if data != nil {
+		r.relayedMessages.Inc()
+	}
+	return data
+}
+
+func (r *epidemicRelay) relayData(message [][]byte, trailer relayTrailer, exclude primitives.NodeAddress) *adapter.TransportData {
+	recipients := r.randomPeers(int(r.config.GossipEpidemicRelayFanout()), exclude)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	payloads := make([][]byte, 0, len(message)+1)
+	payloads = append(payloads, message...)
+	payloads = append(payloads, []byte{trailer.ttl, trailer.hops})
+
+	// the header still says broadcast, only the transport is told to send to the chosen recipients
+	return &adapter.TransportData{
+		SenderNodeAddress:      r.config.NodeAddress(),
+		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
+		RecipientNodeAddresses: recipients,
+		Payloads:               payloads,
+	}
+}
+
+func (r *epidemicRelay) randomPeers(count int, exclude primitives.NodeAddress) []primitives.NodeAddress {
+	r.topology.RLock()
+	candidates := make([]primitives.NodeAddress, 0, len(r.topology.nodeAddresses))
+	for _, nodeAddress := range r.topology.nodeAddresses {
+		if !nodeAddress.Equal(exclude) {
+			candidates = append(candidates, nodeAddress)
+		}
+	}
+	r.topology.RUnlock()
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	return candidates
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type relayConf struct {
+	transactionRelay bool
+	blockSync        bool
+}
+
+func (c *relayConf) NodeAddress() primitives.NodeAddress {
+	return []byte{0x01}
+}
+
+func (c *relayConf) GossipEpidemicTransactionRelayEnabled() bool {
+	return c.transactionRelay
+}
+
+func (c *relayConf) GossipEpidemicBlockSyncEnabled() bool {
+	return c.blockSync
+}
+
+func (c *relayConf) GossipEpidemicRelayFanout() uint32 {
+	return 2
+}
+
+func (c *relayConf) GossipEpidemicRelayTtl() uint32 {
+	return 3
+}
+
+func aRelayWithPeers(peers ...byte) *epidemicRelay {
+	r := newEpidemicRelay(&relayConf{transactionRelay: true}, metric.NewRegistry())
+	var topology []*services.GossipPeer
+	for _, peer := range peers {
+		topology = append(topology, &services.GossipPeer{Address: []byte{peer}})
+	}
+	r.updateTopology(topology)
+	return r
+}
+
+func TestEpidemicRelay_RelaysOnlyTheEnabledBroadcasts(t *testing.T) {
+	r := newEpidemicRelay(&relayConf{transactionRelay: true}, metric.NewRegistry())
+
+	require.True(t, r.isRelayed((&gossipmessages.HeaderBuilder{
+		Topic:            gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY,
+		TransactionRelay: gossipmessages.TRANSACTION_RELAY_FORWARDED_TRANSACTIONS,
+	}).Build()))
+	require.False(t, r.isRelayed((&gossipmessages.HeaderBuilder{
+		Topic:     gossipmessages.HEADER_TOPIC_BLOCK_SYNC,
+		BlockSync: gossipmessages.BLOCK_SYNC_AVAILABILITY_REQUEST,
+	}).Build()), "expected block sync not to be relayed when it is not enabled")
+	require.False(t, r.isRelayed((&gossipmessages.HeaderBuilder{
+		Topic: gossipmessages.HEADER_TOPIC_LEAN_HELIX,
+	}).Build()))
+}
+
+func TestEpidemicRelay_OriginatesToARandomFanoutOfOtherPeers(t *testing.T) {
+	r := aRelayWithPeers(0x01, 0x02, 0x03, 0x04, 0x05)
+
+	data := r.originate([][]byte{{0xaa}, {0xbb}})
+	require.Len(t, data.RecipientNodeAddresses, 2)
+	require.Equal(t, gossipmessages.RECIPIENT_LIST_MODE_LIST, data.RecipientMode)
+	for _, recipient := range data.RecipientNodeAddresses {
+		require.NotEqual(t, primitives.NodeAddress{0x01}, recipient, "expected a node not to send to itself")
+	}
+
+	message, trailer, err := r.open(data.Payloads)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0xaa}, {0xbb}}, message)
+	require.Equal(t, relayTrailer{ttl: 3, hops: 0}, trailer)
+	require.False(t, r.markSeen(message), "expected an originated message to be seen so it is not relayed back")
+}
+
+func TestEpidemicRelay_RelaysUntilTtlRunsOut(t *testing.T) {
+	r := aRelayWithPeers(0x01, 0x02, 0x03)
+
+	data := r.relay([][]byte{{0xaa}}, relayTrailer{ttl: 2, hops: 4}, []byte{0x02})
+	require.Equal(t, []primitives.NodeAddress{{0x03}}, data.RecipientNodeAddresses, "expected the peer a message was received from to be excluded")
+	_, trailer, err := r.open(data.Payloads)
+	require.NoError(t, err)
+	require.Equal(t, relayTrailer{ttl: 1, hops: 5}, trailer)
+
+	require.Nil(t, r.relay([][]byte{{0xaa}}, relayTrailer{ttl: 1, hops: 5}, []byte{0x02}))
+}
+
+func TestEpidemicRelay_DeduplicatesMessages(t *testing.T) {
+	r := aRelayWithPeers(0x01, 0x02)
+
+	require.True(t, r.markSeen([][]byte{{0xaa}, {0xbb}}))
+	require.False(t, r.markSeen([][]byte{{0xaa}, {0xbb}}))
+	require.True(t, r.markSeen([][]byte{{0xaa, 0xbb}}), "expected a different split of the same bytes to be a different message")
+	require.EqualValues(t, 1, r.duplicateMessages.IntValue())
+}
+
+func TestEpidemicRelay_RejectsMessagesWithoutRelayState(t *testing.T) {
+	r := aRelayWithPeers(0x01, 0x02)
+
+	_, _, err := r.open([][]byte{{0xaa}})
+	require.Error(t, err)
+
+	_, _, err = r.open([][]byte{{0xaa}, {0x01, 0x02, 0x03}})
+	require.Error(t, err)
+}
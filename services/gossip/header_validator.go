@@ -7,6 +7,8 @@
 package gossip
 
 import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/scribe/log"
@@ -43,6 +45,20 @@ func (v *headerValidator) validateMessageHeader(header *gossipmessages.Header) e
 	return nil
 }
 
+// messages received from an authenticated peer must be signed by it, otherwise a peer could impersonate another node
+func (v *headerValidator) validateMessageSender(ctx context.Context, sender *gossipmessages.SenderSignature) error {
+	peerNodeAddress, authenticated := adapter.AuthenticatedPeerFromContext(ctx)
+	if !authenticated {
+		return nil
+	}
+
+	if !peerNodeAddress.Equal(sender.SenderNodeAddress()) {
+		return errors.Errorf("message claims to be sent by %s but was received from %s", sender.SenderNodeAddress(), peerNodeAddress)
+	}
+
+	return nil
+}
+
 func isInRecipientList(me primitives.NodeAddress, recipientIterator *gossipmessages.HeaderRecipientNodeAddressesIterator) bool {
 	for recipientIterator.HasNext() {
 		if me.Equal(recipientIterator.NextRecipientNodeAddresses()) {
@@ -7,6 +7,8 @@
 package gossip
 
 import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
 	"github.com/orbs-network/orbs-network-go/test/with"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
@@ -70,6 +72,36 @@ func TestValidateMessageHeader_NodeIsARecipient_AllButListMode(t *testing.T) {
 	})
 }
 
+func TestValidateMessageSender_SenderIsTheAuthenticatedPeer(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		v := newValidator(harness.Logger)
+		ctx := adapter.ContextWithAuthenticatedPeer(context.Background(), primitives.NodeAddress{0x2})
+
+		require.NoError(t, v.validateMessageSender(ctx, aSenderSignature(primitives.NodeAddress{0x2})))
+	})
+}
+
+func TestValidateMessageSender_SenderIsNotTheAuthenticatedPeer(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		v := newValidator(harness.Logger)
+		ctx := adapter.ContextWithAuthenticatedPeer(context.Background(), primitives.NodeAddress{0x2})
+
+		require.Error(t, v.validateMessageSender(ctx, aSenderSignature(primitives.NodeAddress{0x3})))
+	})
+}
+
+func TestValidateMessageSender_TransportDidNotAuthenticatePeer(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		v := newValidator(harness.Logger)
+
+		require.NoError(t, v.validateMessageSender(context.Background(), aSenderSignature(primitives.NodeAddress{0x3})))
+	})
+}
+
+func aSenderSignature(nodeAddress primitives.NodeAddress) *gossipmessages.SenderSignature {
+	return (&gossipmessages.SenderSignatureBuilder{SenderNodeAddress: nodeAddress}).Build()
+}
+
 func newValidator(logger log.Logger) *headerValidator {
 	cfg := &hardcodedValidatorConfig{virtualChainId: 42, nodeAddress: []byte{0x1}}
 	v := newHeaderValidator(cfg, logger)
@@ -0,0 +1,128 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/digest"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+var envelopeSignatureDomain = []byte("orbs-gossip-message")
+
+// messageEnvelopes signs every sent message and verifies every received one was signed by a node in the topology.
+// the envelope is appended to the message as its last payload: the sender node address followed by its signature
+// over the header and all other payloads
+type messageEnvelopes struct {
+	signer      signer.Signer
+	nodeAddress primitives.NodeAddress
+
+	topology struct {
+		sync.RWMutex
+		nodeAddresses map[string]bool
+	}
+
+	forgedMessages map[gossipmessages.HeaderTopic]*metric.Gauge
+}
+
+func newMessageEnvelopes(nodeSigner signer.Signer, nodeAddress primitives.NodeAddress, registry metric.Registry) *messageEnvelopes {
+	e := &messageEnvelopes{
+		signer:      nodeSigner,
+		nodeAddress: nodeAddress,
+		forgedMessages: map[gossipmessages.HeaderTopic]*metric.Gauge{
+			gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY:   registry.NewGauge("Gossip.Topic.TransactionRelay.ForgedMessages.Count"),
+			gossipmessages.HEADER_TOPIC_BLOCK_SYNC:          registry.NewGauge("Gossip.Topic.BlockSync.ForgedMessages.Count"),
+			gossipmessages.HEADER_TOPIC_LEAN_HELIX:          registry.NewGauge("Gossip.Topic.LeanHelixConsensus.ForgedMessages.Count"),
+			gossipmessages.HEADER_TOPIC_BENCHMARK_CONSENSUS: registry.NewGauge("Gossip.Topic.BenchmarkConsensus.ForgedMessages.Count"),
+		},
+	}
+	e.topology.nodeAddresses = make(map[string]bool)
+	return e
+}
+
+func (e *messageEnvelopes) updateTopology(peers []*services.GossipPeer) {
+	nodeAddresses := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		nodeAddresses[peer.Address.KeyForMap()] = true
+	}
+
+	e.topology.Lock()
+	defer e.topology.Unlock()
+	e.topology.nodeAddresses = nodeAddresses
+}
+
+func (e *messageEnvelopes) isInTopology(nodeAddress primitives.NodeAddress) bool {
+	e.topology.RLock()
+	defer e.topology.RUnlock()
+	return e.topology.nodeAddresses[nodeAddress.KeyForMap()]
+}
+
+func (e *messageEnvelopes) seal(ctx context.Context, payloads [][]byte) ([][]byte, error) {
+	sig, err := e.signer.Sign(ctx, hashPayloads(envelopeSignatureDomain, payloads))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed signing gossip message")
+	}
+
+	envelope := make([]byte, 0, len(e.nodeAddress)+len(sig))
+	envelope = append(envelope, e.nodeAddress...)
+	envelope = append(envelope, sig...)
+
+	sealed := make([][]byte, 0, len(payloads)+1)
+	sealed = append(sealed, payloads...)
+	return append(sealed, envelope), nil
+}
+
+// returns the node which signed the message and the message without its envelope
+func (e *messageEnvelopes) open(payloads [][]byte) (primitives.NodeAddress, [][]byte, error) {
+	if len(payloads) < 2 {
+		return nil, nil, errors.New("message is not signed")
+	}
+
+	opened := payloads[:len(payloads)-1]
+	envelope := payloads[len(payloads)-1]
+	if len(envelope) <= digest.NODE_ADDRESS_SIZE_BYTES {
+		return nil, nil, errors.Errorf("message envelope is too short: %d bytes", len(envelope))
+	}
+
+	sender := primitives.NodeAddress(envelope[:digest.NODE_ADDRESS_SIZE_BYTES])
+	if !e.isInTopology(sender) {
+		return nil, nil, errors.Errorf("message is signed by %s which is not in the topology", sender)
+	}
+
+	if err := digest.VerifyNodeSignature(sender, hashPayloads(envelopeSignatureDomain, opened), envelope[digest.NODE_ADDRESS_SIZE_BYTES:]); err != nil {
+		return nil, nil, errors.Wrapf(err, "message signature of %s is invalid", sender)
+	}
+
+	return sender, opened, nil
+}
+
+func (e *messageEnvelopes) countForgedMessage(header *gossipmessages.Header) {
+	if forged, found := e.forgedMessages[header.Topic()]; found {
+		forged.Inc()
+	}
+}
+
+// payloads are length prefixed so moving bytes between payloads changes the hash
+func hashPayloads(domain []byte, payloads [][]byte) []byte {
+	hash := sha256.New()
+	hash.Write(domain)
+	sizeBuffer := make([]byte, 4)
+	for _, payload := range payloads {
+		binary.LittleEndian.PutUint32(sizeBuffer, uint32(len(payload)))
+		hash.Write(sizeBuffer)
+		hash.Write(payload)
+	}
+	return hash.Sum(nil)
+}
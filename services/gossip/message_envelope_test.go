@@ -0,0 +1,94 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newEnvelopesOfNode(nodeIndex int, topology ...int) *messageEnvelopes {
+	key := keys.EcdsaSecp256K1KeyPairForTests(nodeIndex)
+	e := newMessageEnvelopes(signer.NewLocalSigner(key.PrivateKey()), key.NodeAddress(), metric.NewRegistry())
+
+	var peers []*services.GossipPeer
+	for _, i := range topology {
+		peers = append(peers, &services.GossipPeer{Address: keys.EcdsaSecp256K1KeyPairForTests(i).NodeAddress()})
+	}
+	e.updateTopology(peers)
+	return e
+}
+
+func TestMessageEnvelopes_OpenReturnsSignerAndOriginalPayloads(t *testing.T) {
+	sender := newEnvelopesOfNode(0)
+	receiver := newEnvelopesOfNode(1, 0, 1)
+
+	payloads := [][]byte{{0x01, 0x02}, {0x03}}
+	sealed, err := sender.seal(context.Background(), payloads)
+	require.NoError(t, err)
+	require.Len(t, sealed, 3, "expected the envelope to be appended as the last payload")
+
+	signer, opened, err := receiver.open(sealed)
+	require.NoError(t, err)
+	require.EqualValues(t, keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress(), signer)
+	require.Equal(t, payloads, opened)
+}
+
+func TestMessageEnvelopes_RejectsTamperedPayloads(t *testing.T) {
+	sender := newEnvelopesOfNode(0)
+	receiver := newEnvelopesOfNode(1, 0, 1)
+
+	sealed, err := sender.seal(context.Background(), [][]byte{{0x01, 0x02}, {0x03}})
+	require.NoError(t, err)
+
+	moved := [][]byte{{0x01}, {0x02, 0x03}, sealed[2]}
+	_, _, err = receiver.open(moved)
+	require.Error(t, err, "expected moving bytes between payloads to invalidate the signature")
+
+	sealed[1][0] ^= 0x55
+	_, _, err = receiver.open(sealed)
+	require.Error(t, err, "expected a modified payload to invalidate the signature")
+}
+
+func TestMessageEnvelopes_RejectsSignersNotInTopology(t *testing.T) {
+	sender := newEnvelopesOfNode(0)
+	receiver := newEnvelopesOfNode(1, 1, 2)
+
+	sealed, err := sender.seal(context.Background(), [][]byte{{0x01}})
+	require.NoError(t, err)
+
+	_, _, err = receiver.open(sealed)
+	require.Error(t, err)
+
+	receiver.updateTopology([]*services.GossipPeer{{Address: keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress()}})
+	_, _, err = receiver.open(sealed)
+	require.NoError(t, err, "expected the signer to be accepted once it joins the topology")
+}
+
+func TestMessageEnvelopes_RejectsUnsignedAndImpersonatingMessages(t *testing.T) {
+	receiver := newEnvelopesOfNode(1, 0, 1, 2)
+
+	_, _, err := receiver.open([][]byte{{0x01}})
+	require.Error(t, err, "expected a message without an envelope to be rejected")
+
+	_, _, err = receiver.open([][]byte{{0x01}, keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress()})
+	require.Error(t, err, "expected an envelope without a signature to be rejected")
+
+	impersonator := newEnvelopesOfNode(2)
+	impersonator.nodeAddress = primitives.NodeAddress(keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress())
+	sealed, err := impersonator.seal(context.Background(), [][]byte{{0x01}})
+	require.NoError(t, err)
+
+	_, _, err = receiver.open(sealed)
+	require.Error(t, err, "expected an envelope signed with the key of another node to be rejected")
+}
@@ -0,0 +1,179 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/orbs-network/crypto-lib-go/crypto/ethereum/digest"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/codec"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+const peerAnnouncementTtlIntervals = 12
+const peerAnnouncementMaxClockSkew = 1 * time.Minute
+
+var peerAnnouncementSignatureDomain = []byte("orbs-gossip-peer-announcement")
+
+type peerDiscoveryConfig interface {
+	NodeAddress() primitives.NodeAddress
+	GossipListenPort() uint16
+	GossipPeerDiscoveryEndpoint() string
+	GossipPeerDiscoveryAnnounceInterval() time.Duration
+}
+
+// peerDiscovery merges the endpoints nodes announce for themselves into the management topology, so a node changing
+// its endpoint is reached again within an announce interval instead of when the management topology is regenerated.
+// membership still comes from management only: announcements of nodes outside the management topology are ignored
+type peerDiscovery struct {
+	config    peerDiscoveryConfig
+	signer    signer.Signer
+	transport adapter.Transport
+
+	sync.Mutex
+	managementTopology adapter.TransportPeers
+	announcements      map[string]*receivedPeerAnnouncement
+	topology           adapter.TransportPeers // as last given to the transport
+
+	acceptedAnnouncements *metric.Gauge
+	rejectedAnnouncements *metric.Gauge
+	topologyUpdates       *metric.Gauge
+}
+
+type receivedPeerAnnouncement struct {
+	announcement *codec.PeerAnnouncement
+	receivedAt   time.Time
+}
+
+func newPeerDiscovery(config peerDiscoveryConfig, nodeSigner signer.Signer, transport adapter.Transport, registry metric.Registry) *peerDiscovery {
+	return &peerDiscovery{
+		config:                config,
+		signer:                nodeSigner,
+		transport:             transport,
+		managementTopology:    make(adapter.TransportPeers),
+		announcements:         make(map[string]*receivedPeerAnnouncement),
+		topology:              make(adapter.TransportPeers),
+		acceptedAnnouncements: registry.NewGauge("Gossip.PeerDiscovery.AcceptedAnnouncements.Count"),
+		rejectedAnnouncements: registry.NewGauge("Gossip.PeerDiscovery.RejectedAnnouncements.Count"),
+		topologyUpdates:       registry.NewGauge("Gossip.PeerDiscovery.TopologyUpdates.Count"),
+	}
+}
+
+func (d *peerDiscovery) announcementTtl() time.Duration {
+	return peerAnnouncementTtlIntervals * d.config.GossipPeerDiscoveryAnnounceInterval()
+}
+
+func (d *peerDiscovery) updateManagementTopology(ctx context.Context, peers adapter.TransportPeers) {
+	d.Lock()
+	defer d.Unlock()
+	d.managementTopology = peers
+	d.updateTransport(ctx, time.Now(), true)
+}
+
+// returns nil when this node has no endpoint to announce
+func (d *peerDiscovery) announcement(ctx context.Context) (*codec.PeerAnnouncement, error) {
+	if d.config.GossipPeerDiscoveryEndpoint() == "" {
+		return nil, nil
+	}
+
+	announcement := &codec.PeerAnnouncement{
+		NodeAddress: d.config.NodeAddress(),
+		Endpoint:    d.config.GossipPeerDiscoveryEndpoint(),
+		Port:        d.config.GossipListenPort(),
+		Timestamp:   primitives.TimestampNano(time.Now().UnixNano()),
+	}
+	sig, err := d.signer.Sign(ctx, hashPayloads(peerAnnouncementSignatureDomain, announcement.SignedPayloads()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed signing peer announcement")
+	}
+	announcement.Signature = sig
+	return announcement, nil
+}
+
+func (d *peerDiscovery) accept(ctx context.Context, announcement *codec.PeerAnnouncement) error {
+	if err := d.verify(announcement); err != nil {
+		d.rejectedAnnouncements.Inc()
+		return err
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	key := announcement.NodeAddress.KeyForMap()
+	if _, isMember := d.managementTopology[key]; !isMember {
+		d.rejectedAnnouncements.Inc()
+		return errors.Errorf("announcement of %s which is not in the management topology", announcement.NodeAddress)
+	}
+	if previous, found := d.announcements[key]; found && previous.announcement.Timestamp >= announcement.Timestamp {
+		return nil // a duplicate or an older announcement received out of order
+	}
+
+	d.announcements[key] = &receivedPeerAnnouncement{announcement: announcement, receivedAt: time.Now()}
+	d.acceptedAnnouncements.Inc()
+	d.updateTransport(ctx, time.Now(), false)
+	return nil
+}
+
+func (d *peerDiscovery) verify(announcement *codec.PeerAnnouncement) error {
+	if announcement.NodeAddress.Equal(d.config.NodeAddress()) {
+		return errors.New("announcement of this node")
+	}
+
+	announcedAt := time.Unix(0, int64(announcement.Timestamp))
+	if now := time.Now(); announcedAt.After(now.Add(peerAnnouncementMaxClockSkew)) || announcedAt.Before(now.Add(-d.announcementTtl()-peerAnnouncementMaxClockSkew)) {
+		return errors.Errorf("announcement of %s is timestamped %s which is too far from now", announcement.NodeAddress, announcedAt)
+	}
+
+	if err := digest.VerifyNodeSignature(announcement.NodeAddress, hashPayloads(peerAnnouncementSignatureDomain, announcement.SignedPayloads()), announcement.Signature); err != nil {
+		return errors.Wrapf(err, "announcement signature of %s is invalid", announcement.NodeAddress)
+	}
+	return nil
+}
+
+// drops announcements not renewed in time, their nodes fall back to their management endpoint
+func (d *peerDiscovery) expireAnnouncements(ctx context.Context) {
+	d.Lock()
+	defer d.Unlock()
+	d.updateTransport(ctx, time.Now(), false)
+}
+
+// must be called under lock, management topology updates are always passed on like they were before discovery
+func (d *peerDiscovery) updateTransport(ctx context.Context, now time.Time, force bool) {
+	merged := d.mergedTopology(now)
+	peersToRemove, peersToAdd := adapter.PeerDiff(d.topology, merged)
+	if !force && len(peersToRemove) == 0 && len(peersToAdd) == 0 {
+		return
+	}
+
+	d.topology = merged
+	d.topologyUpdates.Inc()
+	d.transport.UpdateTopology(ctx, merged)
+}
+
+func (d *peerDiscovery) mergedTopology(now time.Time) adapter.TransportPeers {
+	merged := make(adapter.TransportPeers, len(d.managementTopology))
+	for key, peer := range d.managementTopology {
+		merged[key] = peer
+	}
+
+	for key, received := range d.announcements {
+		if now.Sub(received.receivedAt) > d.announcementTtl() {
+			delete(d.announcements, key)
+			continue
+		}
+		if _, isMember := merged[key]; isMember {
+			announcement := received.announcement
+			merged[key] = adapter.NewGossipPeer(int(announcement.Port), announcement.Endpoint, hex.EncodeToString(announcement.NodeAddress))
+		}
+	}
+	return merged
+}
@@ -0,0 +1,141 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type peerDiscoveryConf struct {
+	nodeAddress primitives.NodeAddress
+	endpoint    string
+}
+
+func (c *peerDiscoveryConf) NodeAddress() primitives.NodeAddress {
+	return c.nodeAddress
+}
+
+func (c *peerDiscoveryConf) GossipListenPort() uint16 {
+	return 4400
+}
+
+func (c *peerDiscoveryConf) GossipPeerDiscoveryEndpoint() string {
+	return c.endpoint
+}
+
+func (c *peerDiscoveryConf) GossipPeerDiscoveryAnnounceInterval() time.Duration {
+	return 1 * time.Second
+}
+
+type topologyRecorder struct {
+	adapter.Transport
+	topologies []adapter.TransportPeers
+}
+
+func (r *topologyRecorder) UpdateTopology(bgCtx context.Context, newPeers adapter.TransportPeers) {
+	r.topologies = append(r.topologies, newPeers)
+}
+
+func (r *topologyRecorder) lastEndpointOf(nodeIndex int) string {
+	return r.topologies[len(r.topologies)-1][keys.EcdsaSecp256K1KeyPairForTests(nodeIndex).NodeAddress().KeyForMap()].Endpoint()
+}
+
+func newPeerDiscoveryOfNode(nodeIndex int, endpoint string) (*peerDiscovery, *topologyRecorder) {
+	key := keys.EcdsaSecp256K1KeyPairForTests(nodeIndex)
+	transport := &topologyRecorder{}
+	d := newPeerDiscovery(&peerDiscoveryConf{nodeAddress: key.NodeAddress(), endpoint: endpoint}, signer.NewLocalSigner(key.PrivateKey()), transport, metric.NewRegistry())
+	return d, transport
+}
+
+func aManagementTopology(nodeIndexes ...int) adapter.TransportPeers {
+	peers := make(adapter.TransportPeers)
+	for _, i := range nodeIndexes {
+		nodeAddress := keys.EcdsaSecp256K1KeyPairForTests(i).NodeAddress()
+		peers[nodeAddress.KeyForMap()] = adapter.NewGossipPeer(4400, "management-endpoint", hex.EncodeToString(nodeAddress))
+	}
+	return peers
+}
+
+func TestPeerDiscovery_AnnouncedEndpointReplacesManagementEndpoint(t *testing.T) {
+	ctx := context.Background()
+	announcer, _ := newPeerDiscoveryOfNode(0, "announced-endpoint")
+	receiver, transport := newPeerDiscoveryOfNode(1, "")
+	receiver.updateManagementTopology(ctx, aManagementTopology(0, 1))
+	require.Equal(t, "management-endpoint", transport.lastEndpointOf(0))
+
+	announcement, err := announcer.announcement(ctx)
+	require.NoError(t, err)
+	require.NoError(t, receiver.accept(ctx, announcement))
+
+	require.Len(t, transport.topologies, 2, "expected the announcement to update the transport topology")
+	require.Equal(t, "announced-endpoint", transport.lastEndpointOf(0))
+
+	require.NoError(t, receiver.accept(ctx, announcement))
+	require.Len(t, transport.topologies, 2, "expected a duplicate announcement to leave the transport topology as is")
+
+	receiver.updateManagementTopology(ctx, aManagementTopology(0, 1, 2))
+	require.Equal(t, "announced-endpoint", transport.lastEndpointOf(0), "expected the announced endpoint to survive management topology updates")
+	require.Equal(t, "management-endpoint", transport.lastEndpointOf(2))
+}
+
+func TestPeerDiscovery_RejectsForgedAnnouncementsAndAnnouncementsOfNonMembers(t *testing.T) {
+	ctx := context.Background()
+	announcer, _ := newPeerDiscoveryOfNode(0, "announced-endpoint")
+	receiver, transport := newPeerDiscoveryOfNode(1, "")
+	receiver.updateManagementTopology(ctx, aManagementTopology(1))
+
+	announcement, err := announcer.announcement(ctx)
+	require.NoError(t, err)
+	require.Error(t, receiver.accept(ctx, announcement), "expected an announcement of a node outside the management topology to be rejected")
+
+	receiver.updateManagementTopology(ctx, aManagementTopology(0, 1))
+	announcement.Endpoint = "hijacked-endpoint"
+	require.Error(t, receiver.accept(ctx, announcement), "expected a tampered announcement to be rejected")
+	require.Equal(t, "management-endpoint", transport.lastEndpointOf(0))
+}
+
+func TestPeerDiscovery_IgnoresOlderAnnouncements(t *testing.T) {
+	ctx := context.Background()
+	receiver, transport := newPeerDiscoveryOfNode(1, "")
+	receiver.updateManagementTopology(ctx, aManagementTopology(0, 1))
+
+	olderAnnouncer, _ := newPeerDiscoveryOfNode(0, "older-endpoint")
+	older, err := olderAnnouncer.announcement(ctx)
+	require.NoError(t, err)
+	newerAnnouncer, _ := newPeerDiscoveryOfNode(0, "newer-endpoint")
+	newer, err := newerAnnouncer.announcement(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, receiver.accept(ctx, newer))
+	require.NoError(t, receiver.accept(ctx, older))
+	require.Equal(t, "newer-endpoint", transport.lastEndpointOf(0), "expected an announcement received out of order to be ignored")
+}
+
+func TestPeerDiscovery_ExpiredAnnouncementFallsBackToManagementEndpoint(t *testing.T) {
+	ctx := context.Background()
+	announcer, _ := newPeerDiscoveryOfNode(0, "announced-endpoint")
+	receiver, transport := newPeerDiscoveryOfNode(1, "")
+	receiver.updateManagementTopology(ctx, aManagementTopology(0, 1))
+
+	announcement, err := announcer.announcement(ctx)
+	require.NoError(t, err)
+	require.NoError(t, receiver.accept(ctx, announcement))
+
+	receiver.announcements[announcement.NodeAddress.KeyForMap()].receivedAt = time.Now().Add(-receiver.announcementTtl() - time.Second)
+	receiver.expireAnnouncements(ctx)
+
+	require.Equal(t, "management-endpoint", transport.lastEndpointOf(0))
+}
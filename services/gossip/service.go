@@ -9,6 +9,7 @@ package gossip
 import (
 	"context"
 	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
 	"github.com/orbs-network/govnr"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
@@ -19,6 +20,7 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/services/gossiptopics"
 	"github.com/orbs-network/scribe/log"
 	"sync"
+	"time"
 )
 
 var LogTag = log.Service("gossip")
@@ -26,6 +28,15 @@ var LogTag = log.Service("gossip")
 type Config interface {
 	NodeAddress() primitives.NodeAddress
 	VirtualChainId() primitives.VirtualChainId
+	GossipSignedMessagesEnabled() bool
+	GossipEpidemicTransactionRelayEnabled() bool
+	GossipEpidemicBlockSyncEnabled() bool
+	GossipEpidemicRelayFanout() uint32
+	GossipEpidemicRelayTtl() uint32
+	GossipListenPort() uint16
+	GossipPeerDiscoveryEnabled() bool
+	GossipPeerDiscoveryEndpoint() string
+	GossipPeerDiscoveryAnnounceInterval() time.Duration
 }
 
 type gossipListeners struct {
@@ -44,12 +55,17 @@ type service struct {
 	transport       adapter.Transport
 	handlers        gossipListeners
 	headerValidator *headerValidator
+	envelopes       *messageEnvelopes // nil when signed messages are disabled
+	relay           *epidemicRelay    // nil when epidemic relay is disabled for all topics
+	discovery       *peerDiscovery    // nil when peer discovery is disabled
 
 	messageDispatcher             *gossipMessageDispatcher
 	forwarededTransactionFailures *metric.Gauge
 }
 
-func NewGossip(ctx context.Context, transport adapter.Transport, config Config, parent log.Logger, metricRegistry metric.Registry) *service {
+// nodeSigner signs sent messages and peer announcements, it is only used when signed messages or peer discovery are
+// enabled and may be nil otherwise
+func NewGossip(ctx context.Context, transport adapter.Transport, nodeSigner signer.Signer, config Config, parent log.Logger, metricRegistry metric.Registry) *service {
 	logger := parent.WithTags(LogTag)
 	dispatcher := newMessageDispatcher(metricRegistry, logger)
 	s := &service{
@@ -62,6 +78,22 @@ func NewGossip(ctx context.Context, transport adapter.Transport, config Config,
 		messageDispatcher:             dispatcher,
 		forwarededTransactionFailures: metricRegistry.NewGauge("Gossip.Topic.TransactionRelay.Errors.Count"),
 	}
+	if config.GossipSignedMessagesEnabled() {
+		if nodeSigner == nil {
+			panic("gossip signed messages are enabled but no signer was given to the gossip service")
+		}
+		s.envelopes = newMessageEnvelopes(nodeSigner, config.NodeAddress(), metricRegistry)
+	}
+	if isEpidemicRelayEnabled(config) {
+		s.relay = newEpidemicRelay(config, metricRegistry)
+	}
+	if config.GossipPeerDiscoveryEnabled() {
+		if nodeSigner == nil {
+			panic("gossip peer discovery is enabled but no signer was given to the gossip service")
+		}
+		s.discovery = newPeerDiscovery(config, nodeSigner, transport, metricRegistry)
+		s.Supervise(s.startPeerDiscovery(ctx))
+	}
 	transport.RegisterListener(s, s.config.NodeAddress())
 	s.Supervise(dispatcher.runHandler(ctx, logger, gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY, s.receivedTransactionRelayMessage))
 	s.Supervise(dispatcher.runHandler(ctx, logger, gossipmessages.HEADER_TOPIC_BLOCK_SYNC, s.receivedBlockSyncMessage))
@@ -72,7 +104,17 @@ func NewGossip(ctx context.Context, transport adapter.Transport, config Config,
 }
 
 func (s *service) UpdateTopology(bgCtx context.Context, input *services.UpdateTopologyInput) (*services.UpdateTopologyOutput, error)  {
-	s.transport.UpdateTopology(bgCtx, adapter.NewGossipPeers(input.Peers))
+	if s.envelopes != nil {
+		s.envelopes.updateTopology(input.Peers)
+	}
+	if s.relay != nil {
+		s.relay.updateTopology(input.Peers)
+	}
+	if s.discovery != nil {
+		s.discovery.updateManagementTopology(bgCtx, adapter.NewGossipPeers(input.Peers))
+	} else {
+		s.transport.UpdateTopology(bgCtx, adapter.NewGossipPeers(input.Peers))
+	}
 	return &services.UpdateTopologyOutput{}, nil
 }
 
@@ -89,17 +131,98 @@ func (s *service) OnTransportMessageReceived(ctx context.Context, payloads [][]b
 	header := gossipmessages.HeaderReader(payloads[0])
 	if !header.IsValid() {
 		logger.Error("transport header is corrupt", log.Bytes("header", payloads[0]))
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorInvalidHeader)
 		return
 	}
 
 	if err := s.headerValidator.validateMessageHeader(header); err != nil {
 		logger.Error("dropping a received message that isn't valid", log.Error(err), log.Stringable("message-header", header))
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorInvalidHeader)
 		return
 	}
 
+	if s.envelopes != nil {
+		sender, opened, err := s.envelopes.open(payloads)
+		if err != nil {
+			s.envelopes.countForgedMessage(header)
+			logger.Info("dropping a received message which is not signed by a node in the topology", log.Error(err), log.Stringable("message-header", header))
+			return
+		}
+		ctx = adapter.ContextWithAuthenticatedPeer(ctx, sender) // the signer proved it sent the message, whichever peer delivered it
+		payloads = opened
+	}
+
+	if s.relay != nil && s.relay.isRelayed(header) {
+		var ok bool
+		if ctx, payloads, ok = s.receivedRelayedMessage(ctx, logger, header, payloads); !ok {
+			return
+		}
+	}
+
 	s.messageDispatcher.dispatch(ctx, logger, header, payloads[1:])
 }
 
+// relays the message on and returns it without its relay state, returns false if it should not be delivered
+func (s *service) receivedRelayedMessage(ctx context.Context, logger log.Logger, header *gossipmessages.Header, payloads [][]byte) (context.Context, [][]byte, bool) {
+	message, trailer, err := s.relay.open(payloads)
+	if err != nil {
+		logger.Info("dropping a received message with invalid relay state", log.Error(err), log.Stringable("message-header", header))
+		return ctx, nil, false
+	}
+	if !s.relay.markSeen(message) {
+		return ctx, nil, false
+	}
+
+	receivedFrom, _ := adapter.AuthenticatedPeerFromContext(ctx)
+	if data := s.relay.relay(message, trailer, receivedFrom); data != nil {
+		if err := s.send(ctx, data); err != nil {
+			logger.Info("failed relaying a received message", log.Error(err), log.Stringable("message-header", header))
+		}
+	}
+
+	if trailer.hops > 0 { // the peer delivering a relayed message is not its sender and is not to blame for its content
+		ctx = adapter.ContextWithoutAuthenticatedPeer(ctx)
+		ctx = adapter.ContextWithMisbehaviorReporter(ctx, nil)
+	}
+	return ctx, message, true
+}
+
+// sends a message to all peers, or to a random fanout of them if its topic is relayed
+func (s *service) broadcast(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) error {
+	if s.relay != nil && s.relay.isRelayed(header) {
+		data := s.relay.originate(payloads)
+		if data == nil {
+			return nil // no peers
+		}
+		return s.send(ctx, data)
+	}
+
+	return s.send(ctx, &adapter.TransportData{
+		SenderNodeAddress: s.config.NodeAddress(),
+		RecipientMode:     gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
+		Payloads:          payloads,
+	})
+}
+
+func (s *service) send(ctx context.Context, data *adapter.TransportData) error {
+	if s.envelopes != nil {
+		sealed, err := s.envelopes.seal(ctx, data.Payloads)
+		if err != nil {
+			return err
+		}
+		data.Payloads = sealed
+	}
+	return s.transport.Send(ctx, data)
+}
+
+func (s *service) isSentByAuthenticatedPeer(ctx context.Context, sender *gossipmessages.SenderSignature) bool {
+	if err := s.headerValidator.validateMessageSender(ctx, sender); err != nil {
+		s.logger.Info("dropping a received message with a spoofed sender", log.Error(err), trace.LogFieldFrom(ctx))
+		return false
+	}
+	return true
+}
+
 func (s *service) String() string {
 	return fmt.Sprintf("Gossip service for node %s: %p", s.config.NodeAddress(), s)
 }
@@ -0,0 +1,94 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/orbs-spec/types/go/services/gossiptopics"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type epidemicRelayConf struct {
+	conf
+	nodeAddress primitives.NodeAddress
+}
+
+func (c *epidemicRelayConf) NodeAddress() primitives.NodeAddress {
+	return c.nodeAddress
+}
+
+func (c *epidemicRelayConf) GossipEpidemicTransactionRelayEnabled() bool {
+	return true
+}
+
+func (c *epidemicRelayConf) GossipEpidemicRelayFanout() uint32 {
+	return 2
+}
+
+func (c *epidemicRelayConf) GossipEpidemicRelayTtl() uint32 {
+	return 3
+}
+
+// with 4 nodes and a fanout of 2 every relaying node sends to both peers other than the one it received from,
+// so all nodes are reached whichever peers are picked at random
+func TestEpidemicRelay_BroadcastReachesAllNodesOnceThroughRelays(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		nodeAddresses := []primitives.NodeAddress{{0x01}, {0x02}, {0x03}, {0x04}}
+		var peers []*services.GossipPeer
+		for _, nodeAddress := range nodeAddresses {
+			peers = append(peers, &services.GossipPeer{Address: nodeAddress})
+		}
+
+		transport := memory.NewTransport(ctx, harness.Logger, nodeAddresses)
+		defer transport.GracefulShutdown(ctx)
+		harness.Supervise(transport)
+
+		var origin gossiptopics.TransactionRelay
+		var handlers []*gossiptopics.MockTransactionRelayHandler
+		for i, nodeAddress := range nodeAddresses {
+			g := gossip.NewGossip(ctx, transport, nil, &epidemicRelayConf{nodeAddress: nodeAddress}, harness.Logger, metric.NewRegistry())
+			harness.Supervise(g)
+			_, _ = g.UpdateTopology(ctx, &services.UpdateTopologyInput{Peers: peers})
+
+			handler := &gossiptopics.MockTransactionRelayHandler{}
+			g.RegisterTransactionRelayHandler(handler)
+			if i == 0 {
+				origin = g
+				handler.Never("HandleForwardedTransactions", mock.Any, mock.Any)
+			} else {
+				handler.When("HandleForwardedTransactions", mock.Any, mock.Any).Return(&gossiptopics.EmptyOutput{}, nil).Times(1)
+			}
+			handlers = append(handlers, handler)
+		}
+
+		_, err := origin.BroadcastForwardedTransactions(ctx, &gossiptopics.ForwardedTransactionsInput{Message: &gossipmessages.ForwardedTransactionsMessage{
+			Sender:             (&gossipmessages.SenderSignatureBuilder{SenderNodeAddress: nodeAddresses[0]}).Build(),
+			SignedTransactions: transactionpool.Transactions{builders.TransferTransaction().Build()},
+		}})
+		require.NoError(t, err)
+
+		for i, handler := range handlers {
+			require.NoError(t, test.EventuallyVerify(1*time.Second, handler), "expected node %d to receive the broadcast", i)
+		}
+		for i, handler := range handlers {
+			require.NoError(t, test.ConsistentlyVerify(100*time.Millisecond, handler), "expected node %d to receive the broadcast exactly once", i)
+		}
+	})
+}
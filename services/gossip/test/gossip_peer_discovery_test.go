@@ -0,0 +1,103 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type peerDiscoveryConf struct {
+	conf
+	nodeAddress primitives.NodeAddress
+	endpoint    string
+}
+
+func (c *peerDiscoveryConf) NodeAddress() primitives.NodeAddress {
+	return c.nodeAddress
+}
+
+func (c *peerDiscoveryConf) GossipListenPort() uint16 {
+	return 4401
+}
+
+func (c *peerDiscoveryConf) GossipPeerDiscoveryEnabled() bool {
+	return true
+}
+
+func (c *peerDiscoveryConf) GossipPeerDiscoveryEndpoint() string {
+	return c.endpoint
+}
+
+func (c *peerDiscoveryConf) GossipPeerDiscoveryAnnounceInterval() time.Duration {
+	return 20 * time.Millisecond
+}
+
+// the memory transport ignores the topology, this one remembers the last topology it was given
+type topologyRecordingTransport struct {
+	adapter.Transport
+	sync.Mutex
+	topology adapter.TransportPeers
+}
+
+func (t *topologyRecordingTransport) UpdateTopology(bgCtx context.Context, newPeers adapter.TransportPeers) {
+	t.Lock()
+	defer t.Unlock()
+	t.topology = newPeers
+	t.Transport.UpdateTopology(bgCtx, newPeers)
+}
+
+func (t *topologyRecordingTransport) peerOf(nodeAddress primitives.NodeAddress) adapter.TransportPeer {
+	t.Lock()
+	defer t.Unlock()
+	return t.topology[nodeAddress.KeyForMap()]
+}
+
+func TestPeerDiscovery_AnnouncedEndpointReachesThePeerTransports(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		moved := keys.EcdsaSecp256K1KeyPairForTests(0)
+		other := keys.EcdsaSecp256K1KeyPairForTests(1)
+		nodeAddresses := []primitives.NodeAddress{moved.NodeAddress(), other.NodeAddress()}
+		peers := []*services.GossipPeer{
+			{Address: moved.NodeAddress(), Endpoint: "old-endpoint", Port: 4400},
+			{Address: other.NodeAddress(), Endpoint: "other-endpoint", Port: 4400},
+		}
+
+		memoryTransport := memory.NewTransport(ctx, harness.Logger, nodeAddresses)
+		defer memoryTransport.GracefulShutdown(ctx)
+		harness.Supervise(memoryTransport)
+
+		movedGossip := gossip.NewGossip(ctx, memoryTransport, signer.NewLocalSigner(moved.PrivateKey()), &peerDiscoveryConf{nodeAddress: moved.NodeAddress(), endpoint: "new-endpoint"}, harness.Logger, metric.NewRegistry())
+		harness.Supervise(movedGossip)
+		_, _ = movedGossip.UpdateTopology(ctx, &services.UpdateTopologyInput{Peers: peers})
+
+		transport := &topologyRecordingTransport{Transport: memoryTransport}
+		otherGossip := gossip.NewGossip(ctx, transport, signer.NewLocalSigner(other.PrivateKey()), &peerDiscoveryConf{nodeAddress: other.NodeAddress()}, harness.Logger, metric.NewRegistry())
+		harness.Supervise(otherGossip)
+		_, _ = otherGossip.UpdateTopology(ctx, &services.UpdateTopologyInput{Peers: peers})
+		require.Equal(t, "old-endpoint", transport.peerOf(moved.NodeAddress()).Endpoint())
+
+		require.True(t, test.Eventually(1*time.Second, func() bool {
+			peer := transport.peerOf(moved.NodeAddress())
+			return peer.Endpoint() == "new-endpoint" && peer.Port() == 4401
+		}), "expected the announced endpoint to replace the management endpoint")
+		require.Equal(t, "other-endpoint", transport.peerOf(other.NodeAddress()).Endpoint(), "expected a node which did not announce to keep its management endpoint")
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/signer"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/gossip"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/services/gossip/codec"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/orbs-spec/types/go/services/gossiptopics"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type signedMessagesConf struct {
+	conf
+	nodeAddress primitives.NodeAddress
+}
+
+func (c *signedMessagesConf) NodeAddress() primitives.NodeAddress {
+	return c.nodeAddress
+}
+
+func (c *signedMessagesConf) GossipSignedMessagesEnabled() bool {
+	return true
+}
+
+type signedMessagesHarness struct {
+	transport      adapter.Transport
+	senderGossip   gossiptopics.TransactionRelay
+	receiverGossip *gossiptopics.MockTransactionRelayHandler
+	registry       metric.Registry
+}
+
+func newSignedMessagesHarness(ctx context.Context, harness *with.ConcurrencyHarness) *signedMessagesHarness {
+	sender, receiver := keys.EcdsaSecp256K1KeyPairForTests(0), keys.EcdsaSecp256K1KeyPairForTests(1)
+	transport := memory.NewTransport(ctx, harness.Logger, []primitives.NodeAddress{sender.NodeAddress(), receiver.NodeAddress()})
+	topology := &services.UpdateTopologyInput{Peers: []*services.GossipPeer{{Address: sender.NodeAddress()}, {Address: receiver.NodeAddress()}}}
+
+	senderGossip := gossip.NewGossip(ctx, transport, signer.NewLocalSigner(sender.PrivateKey()), &signedMessagesConf{nodeAddress: sender.NodeAddress()}, harness.Logger, metric.NewRegistry())
+	registry := metric.NewRegistry()
+	receiverGossip := gossip.NewGossip(ctx, transport, signer.NewLocalSigner(receiver.PrivateKey()), &signedMessagesConf{nodeAddress: receiver.NodeAddress()}, harness.Logger, registry)
+	_, _ = senderGossip.UpdateTopology(ctx, topology)
+	_, _ = receiverGossip.UpdateTopology(ctx, topology)
+
+	handler := &gossiptopics.MockTransactionRelayHandler{}
+	receiverGossip.RegisterTransactionRelayHandler(handler)
+
+	harness.Supervise(transport)
+	harness.Supervise(senderGossip)
+	harness.Supervise(receiverGossip)
+
+	return &signedMessagesHarness{
+		transport:      transport,
+		senderGossip:   senderGossip,
+		receiverGossip: handler,
+		registry:       registry,
+	}
+}
+
+func aForwardedTransactionsMessage() *gossipmessages.ForwardedTransactionsMessage {
+	return &gossipmessages.ForwardedTransactionsMessage{
+		Sender: (&gossipmessages.SenderSignatureBuilder{
+			SenderNodeAddress: keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress(),
+		}).Build(),
+		SignedTransactions: transactionpool.Transactions{builders.TransferTransaction().Build()},
+	}
+}
+
+func TestSignedMessages_DeliversMessagesSignedByTopologyNodes(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		h := newSignedMessagesHarness(ctx, harness)
+		defer h.transport.GracefulShutdown(ctx)
+
+		h.receiverGossip.When("HandleForwardedTransactions", mock.Any, mock.Any).Times(1).Return(&gossiptopics.EmptyOutput{}, nil)
+
+		_, err := h.senderGossip.BroadcastForwardedTransactions(ctx, &gossiptopics.ForwardedTransactionsInput{Message: aForwardedTransactionsMessage()})
+		require.NoError(t, err)
+
+		require.NoError(t, test.EventuallyVerify(1*time.Second, h.receiverGossip), "expected a signed message to be delivered")
+	})
+}
+
+func TestSignedMessages_DropsAndMetersUnsignedMessages(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		h := newSignedMessagesHarness(ctx, harness)
+		defer h.transport.GracefulShutdown(ctx)
+
+		h.receiverGossip.Never("HandleForwardedTransactions", mock.Any, mock.Any)
+
+		header := (&gossipmessages.HeaderBuilder{
+			Topic:            gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY,
+			TransactionRelay: gossipmessages.TRANSACTION_RELAY_FORWARDED_TRANSACTIONS,
+			RecipientMode:    gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
+			VirtualChainId:   42,
+		}).Build()
+		payloads, err := codec.EncodeForwardedTransactions(header, aForwardedTransactionsMessage())
+		require.NoError(t, err)
+
+		require.NoError(t, h.transport.Send(ctx, &adapter.TransportData{
+			SenderNodeAddress: keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress(),
+			RecipientMode:     gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
+			Payloads:          payloads,
+		}))
+
+		forged := h.registry.Get("Gossip.Topic.TransactionRelay.ForgedMessages.Count").(*metric.Gauge)
+		require.True(t, test.Eventually(1*time.Second, func() bool {
+			return forged.IntValue() == 1
+		}), "expected the unsigned message to be metered as forged")
+		require.NoError(t, test.ConsistentlyVerify(100*time.Millisecond, h.receiverGossip), "expected the unsigned message to be dropped")
+	})
+}
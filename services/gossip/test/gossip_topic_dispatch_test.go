@@ -32,13 +32,49 @@ func (c *conf) VirtualChainId() primitives.VirtualChainId {
 	return 42
 }
 
+func (c *conf) GossipSignedMessagesEnabled() bool {
+	return false
+}
+
+func (c *conf) GossipEpidemicTransactionRelayEnabled() bool {
+	return false
+}
+
+func (c *conf) GossipEpidemicBlockSyncEnabled() bool {
+	return false
+}
+
+func (c *conf) GossipEpidemicRelayFanout() uint32 {
+	return 0
+}
+
+func (c *conf) GossipEpidemicRelayTtl() uint32 {
+	return 0
+}
+
+func (c *conf) GossipListenPort() uint16 {
+	return 0
+}
+
+func (c *conf) GossipPeerDiscoveryEnabled() bool {
+	return false
+}
+
+func (c *conf) GossipPeerDiscoveryEndpoint() string {
+	return ""
+}
+
+func (c *conf) GossipPeerDiscoveryAnnounceInterval() time.Duration {
+	return 0
+}
+
 func TestDifferentTopicsDoNotBlockEachOtherForSamePeer(t *testing.T) {
 	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
 		nodeAddresses := []primitives.NodeAddress{{0x01}, {0x02}}
 		cfg := &conf{}
 
 		transport := memory.NewTransport(ctx, harness.Logger, nodeAddresses)
-		g := gossip.NewGossip(ctx, transport, cfg, harness.Logger, metric.NewRegistry())
+		g := gossip.NewGossip(ctx, transport, nil, cfg, harness.Logger, metric.NewRegistry())
 
 		harness.Supervise(transport)
 		harness.Supervise(g)
@@ -86,7 +122,7 @@ func TestGossipDispatcherPropagatesTracingContext(t *testing.T) {
 		transport := memory.NewTransport(ctx, harness.Logger, nodeAddresses)
 		defer transport.GracefulShutdown(ctx)
 
-		g := gossip.NewGossip(ctx, transport, cfg, harness.Logger, metric.NewRegistry())
+		g := gossip.NewGossip(ctx, transport, nil, cfg, harness.Logger, metric.NewRegistry())
 		trh := &gossiptopics.MockTransactionRelayHandler{}
 		g.RegisterTransactionRelayHandler(trh)
 
@@ -115,6 +151,34 @@ func TestGossipDispatcherPropagatesTracingContext(t *testing.T) {
 	})
 }
 
+func TestGossipDropsMessagesWithSenderOtherThanTheAuthenticatedPeer(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, harness *with.ConcurrencyHarness) {
+		nodeAddresses := []primitives.NodeAddress{{0x01}, {0x02}, {0x03}}
+		cfg := &conf{}
+
+		transport := memory.NewTransport(ctx, harness.Logger, nodeAddresses)
+		defer transport.GracefulShutdown(ctx)
+
+		g := gossip.NewGossip(ctx, transport, nil, cfg, harness.Logger, metric.NewRegistry())
+		trh := &gossiptopics.MockTransactionRelayHandler{}
+		g.RegisterTransactionRelayHandler(trh)
+
+		harness.Supervise(transport)
+		harness.Supervise(g)
+
+		trh.Never("HandleForwardedTransactions", mock.Any, mock.Any)
+
+		require.NoError(t, transport.Send(ctx, &adapter.TransportData{
+			SenderNodeAddress:      []byte{0x03}, // the message claims to be sent by 0x02
+			RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
+			RecipientNodeAddresses: []primitives.NodeAddress{cfg.NodeAddress()},
+			Payloads:               aTransactionRelayRequest(t),
+		}))
+
+		require.NoError(t, test.ConsistentlyVerify(100*time.Millisecond, trh), "expected a message with a spoofed sender to be dropped")
+	})
+}
+
 func aBlockSyncRequest(t testing.TB) [][]byte {
 	header := &gossipmessages.HeaderBuilder{
 		Topic:          gossipmessages.HEADER_TOPIC_BLOCK_SYNC,
@@ -130,7 +194,7 @@ func aBlockSyncRequest(t testing.TB) [][]byte {
 			LastCommittedBlockHeight: 3001,
 		}).Build(),
 		Sender: (&gossipmessages.SenderSignatureBuilder{
-			SenderNodeAddress: []byte{0x01, 0x02, 0x03},
+			SenderNodeAddress: []byte{0x02},
 			Signature:         []byte{0x04, 0x05, 0x06},
 		}).Build(),
 	})
@@ -149,7 +213,7 @@ func aTransactionRelayRequest(t testing.TB) [][]byte {
 
 	payloads, err := codec.EncodeForwardedTransactions(header, &gossipmessages.ForwardedTransactionsMessage{
 		Sender: (&gossipmessages.SenderSignatureBuilder{
-			SenderNodeAddress: []byte{0x01, 0x02, 0x03},
+			SenderNodeAddress: []byte{0x02},
 			Signature:         []byte{0x04, 0x05, 0x06},
 		}).Build(),
 		SignedTransactions: transactionpool.Transactions{builders.TransferTransaction().Build()},
@@ -47,7 +47,7 @@ func (s *service) BroadcastBenchmarkConsensusCommit(ctx context.Context, input *
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
+	return nil, s.send(ctx, &adapter.TransportData{
 		SenderNodeAddress: s.config.NodeAddress(),
 		RecipientMode:     gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
 		Payloads:          payloads,
@@ -59,6 +59,7 @@ func (s *service) receivedBenchmarkConsensusCommit(ctx context.Context, header *
 	message, err := codec.DecodeBenchmarkConsensusCommitMessage(payloads)
 	if err != nil {
 		logger.Info("HandleBenchmarkConsensusCommit failed to decode block pair", log.Error(err))
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
 		return
 	}
 
@@ -86,7 +87,7 @@ func (s *service) SendBenchmarkConsensusCommitted(ctx context.Context, input *go
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
+	return nil, s.send(ctx, &adapter.TransportData{
 		SenderNodeAddress:      s.config.NodeAddress(),
 		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
 		RecipientNodeAddresses: []primitives.NodeAddress{input.RecipientNodeAddress},
@@ -97,6 +98,10 @@ func (s *service) SendBenchmarkConsensusCommitted(ctx context.Context, input *go
 func (s *service) receivedBenchmarkConsensusCommitted(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
 	message, err := codec.DecodeBenchmarkConsensusCommittedMessage(payloads)
 	if err != nil {
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+	if !s.isSentByAuthenticatedPeer(ctx, message.Sender) {
 		return
 	}
 
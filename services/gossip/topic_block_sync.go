@@ -62,16 +62,16 @@ func (s *service) BroadcastBlockAvailabilityRequest(ctx context.Context, input *
 	if err != nil {
 		return nil, err
 	}
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
-		SenderNodeAddress: s.config.NodeAddress(),
-		RecipientMode:     gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
-		Payloads:          payloads,
-	})
+	return nil, s.broadcast(ctx, header, payloads)
 }
 
 func (s *service) receivedBlockSyncAvailabilityRequest(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
 	message, err := codec.DecodeBlockAvailabilityRequest(payloads)
 	if err != nil {
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+	if !s.isSentByAuthenticatedPeer(ctx, message.Sender) {
 		return
 	}
 
@@ -98,7 +98,7 @@ func (s *service) SendBlockAvailabilityResponse(ctx context.Context, input *goss
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
+	return nil, s.send(ctx, &adapter.TransportData{
 		SenderNodeAddress:      s.config.NodeAddress(),
 		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
 		RecipientNodeAddresses: []primitives.NodeAddress{input.RecipientNodeAddress},
@@ -109,6 +109,10 @@ func (s *service) SendBlockAvailabilityResponse(ctx context.Context, input *goss
 func (s *service) receivedBlockSyncAvailabilityResponse(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
 	message, err := codec.DecodeBlockAvailabilityResponse(payloads)
 	if err != nil {
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+	if !s.isSentByAuthenticatedPeer(ctx, message.Sender) {
 		return
 	}
 
@@ -136,7 +140,7 @@ func (s *service) SendBlockSyncRequest(ctx context.Context, input *gossiptopics.
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
+	return nil, s.send(ctx, &adapter.TransportData{
 		SenderNodeAddress:      s.config.NodeAddress(),
 		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
 		RecipientNodeAddresses: []primitives.NodeAddress{input.RecipientNodeAddress},
@@ -147,6 +151,10 @@ func (s *service) SendBlockSyncRequest(ctx context.Context, input *gossiptopics.
 func (s *service) receivedBlockSyncRequest(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
 	message, err := codec.DecodeBlockSyncRequest(payloads)
 	if err != nil {
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+	if !s.isSentByAuthenticatedPeer(ctx, message.Sender) {
 		return
 	}
 
@@ -178,7 +186,7 @@ func (s *service) SendBlockSyncResponse(ctx context.Context, input *gossiptopics
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
+	return nil, s.send(ctx, &adapter.TransportData{
 		SenderNodeAddress:      s.config.NodeAddress(),
 		RecipientMode:          gossipmessages.RECIPIENT_LIST_MODE_LIST,
 		RecipientNodeAddresses: []primitives.NodeAddress{input.RecipientNodeAddress},
@@ -189,6 +197,10 @@ func (s *service) SendBlockSyncResponse(ctx context.Context, input *gossiptopics
 func (s *service) receivedBlockSyncResponse(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
 	message, err := codec.DecodeBlockSyncResponse(payloads)
 	if err != nil {
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+	if !s.isSentByAuthenticatedPeer(ctx, message.Sender) {
 		return
 	}
 
@@ -25,6 +25,7 @@ func (s *service) RegisterLeanHelixHandler(handler gossiptopics.LeanHelixHandler
 func (s *service) receivedLeanHelixMessage(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
 	message, err := codec.DecodeLeanHelixMessage(header, payloads)
 	if err != nil {
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
 		return
 	}
 
@@ -52,7 +53,7 @@ func (s *service) SendLeanHelixMessage(ctx context.Context, input *gossiptopics.
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
+	return nil, s.send(ctx, &adapter.TransportData{
 		SenderNodeAddress:      s.config.NodeAddress(),
 		RecipientMode:          input.RecipientsList.RecipientMode,
 		RecipientNodeAddresses: input.RecipientsList.RecipientNodeAddresses,
@@ -0,0 +1,76 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package gossip
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/gossip/codec"
+	"github.com/orbs-network/orbs-network-go/synchronization"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
+	"github.com/orbs-network/scribe/log"
+)
+
+// the spec has no topic for peer discovery and the header cannot hold a new one, so announcements travel on the
+// transaction relay topic under a message type the spec does not define. nodes without peer discovery ignore them
+const TRANSACTION_RELAY_PEER_ANNOUNCEMENT gossipmessages.TransactionsRelayMessageType = 0x8001
+
+func (s *service) startPeerDiscovery(ctx context.Context) *synchronization.PeriodicalTrigger {
+	ticker := synchronization.NewTimeTicker(s.config.GossipPeerDiscoveryAnnounceInterval())
+	return synchronization.NewPeriodicalTrigger(ctx, "Gossip peer announcer", ticker, s.logger, func() {
+		s.discovery.expireAnnouncements(ctx)
+		if err := s.broadcastPeerAnnouncement(ctx); err != nil {
+			s.logger.Info("failed announcing this node to its peers", log.Error(err))
+		}
+	}, nil)
+}
+
+func (s *service) broadcastPeerAnnouncement(ctx context.Context) error {
+	announcement, err := s.discovery.announcement(ctx)
+	if err != nil || announcement == nil {
+		return err
+	}
+
+	header := (&gossipmessages.HeaderBuilder{
+		Topic:            gossipmessages.HEADER_TOPIC_TRANSACTION_RELAY,
+		TransactionRelay: TRANSACTION_RELAY_PEER_ANNOUNCEMENT,
+		RecipientMode:    gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
+		VirtualChainId:   s.config.VirtualChainId(),
+	}).Build()
+
+	payloads, err := codec.EncodePeerAnnouncement(header, announcement)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, &adapter.TransportData{
+		SenderNodeAddress: s.config.NodeAddress(),
+		RecipientMode:     gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
+		Payloads:          payloads,
+	})
+}
+
+func (s *service) receivedPeerAnnouncement(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
+	if s.discovery == nil {
+		return
+	}
+
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+	announcement, err := codec.DecodePeerAnnouncement(payloads)
+	if err != nil {
+		logger.Info("DecodePeerAnnouncement failed", log.Error(err))
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+
+	if err := s.discovery.accept(ctx, announcement); err != nil {
+		logger.Info("dropping a peer announcement", log.Error(err))
+		return
+	}
+	logger.Info("received peer announcement", log.Stringable("node-address", announcement.NodeAddress), log.String("endpoint", announcement.Endpoint), log.Int("port", int(announcement.Port)))
+}
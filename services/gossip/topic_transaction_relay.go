@@ -28,6 +28,8 @@ func (s *service) receivedTransactionRelayMessage(ctx context.Context, header *g
 	switch header.TransactionRelay() {
 	case gossipmessages.TRANSACTION_RELAY_FORWARDED_TRANSACTIONS:
 		s.receivedForwardedTransactions(ctx, header, payloads)
+	case TRANSACTION_RELAY_PEER_ANNOUNCEMENT:
+		s.receivedPeerAnnouncement(ctx, header, payloads)
 	}
 }
 
@@ -49,11 +51,7 @@ func (s *service) BroadcastForwardedTransactions(ctx context.Context, input *gos
 		return nil, err
 	}
 
-	return nil, s.transport.Send(ctx, &adapter.TransportData{
-		SenderNodeAddress: s.config.NodeAddress(),
-		RecipientMode:     gossipmessages.RECIPIENT_LIST_MODE_BROADCAST,
-		Payloads:          payloads,
-	})
+	return nil, s.broadcast(ctx, header, payloads)
 }
 
 func (s *service) receivedForwardedTransactions(ctx context.Context, header *gossipmessages.Header, payloads [][]byte) {
@@ -61,6 +59,11 @@ func (s *service) receivedForwardedTransactions(ctx context.Context, header *gos
 	message, err := codec.DecodeForwardedTransactions(payloads)
 	if err != nil {
 		logger.Info("DecodeForwardedTransactions failed", log.Error(err))
+		s.forwarededTransactionFailures.Inc()
+		adapter.ReportMisbehavior(ctx, adapter.MisbehaviorUndecodableMessage)
+		return
+	}
+	if !s.isSentByAuthenticatedPeer(ctx, message.Sender) {
 		s.forwarededTransactionFailures.Inc()
 		return
 	}
@@ -0,0 +1,102 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package publicapi
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+)
+
+type StateProofProvider interface {
+	GetStateProof(ctx context.Context, input *statestorage.GetStateProofInput) (*statestorage.GetStateProofOutput, error)
+}
+
+type GetStateProofInput struct {
+	BlockHeight  primitives.BlockHeight // zero means the most recent provable height
+	ContractName primitives.ContractName
+	Key          []byte
+}
+
+type GetStateProofOutput struct {
+	RequestStatus       protocol.RequestStatus
+	BlockHeight         primitives.BlockHeight
+	Value               []byte
+	StateMerkleRootHash primitives.Sha256
+	Proof               *statestorage.StateProof
+	ResultsBlockHeader  *protocol.ResultsBlockHeader
+	ResultsBlockProof   *protocol.ResultsBlockProof
+}
+
+// the state after block h is committed to by PreExecutionStateMerkleRootHash of results block h+1, so the state of the last committed block cannot be proven yet
+func (s *service) GetStateProof(parentCtx context.Context, input *GetStateProofInput) (*GetStateProofOutput, error) {
+	ctx := trace.NewContext(parentCtx, "PublicApi.GetStateProof")
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), logfields.BlockHeight(input.BlockHeight), log.String("flow", "checkpoint"))
+
+	if input.ContractName == "" {
+		err := errors.Errorf("missing contract name")
+		logger.Info("get state proof received input failed", log.Error(err))
+		return &GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_BAD_REQUEST}, err
+	}
+
+	logger.Info("get state proof request received")
+
+	lastCommitted, err := s.blockStorage.GetLastCommittedBlockHeight(ctx, &services.GetLastCommittedBlockHeightInput{})
+	if err != nil {
+		logger.Info("block storage failed", log.Error(err))
+		return &GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_SYSTEM_ERROR}, err
+	}
+
+	height := input.BlockHeight
+	if height == 0 && lastCommitted.LastCommittedBlockHeight > 0 {
+		height = lastCommitted.LastCommittedBlockHeight - 1
+	}
+	if height >= lastCommitted.LastCommittedBlockHeight {
+		err := errors.Errorf("state of block height %d is committed to by block height %d which is not committed yet, last committed block height is %d", height, height+1, lastCommitted.LastCommittedBlockHeight)
+		logger.Info("get state proof failed", log.Error(err))
+		return &GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_NOT_FOUND}, err
+	}
+
+	stateProof, err := s.stateStorage.GetStateProof(ctx, &statestorage.GetStateProofInput{
+		BlockHeight:  height,
+		ContractName: input.ContractName,
+		Key:          input.Key,
+	})
+	if err != nil {
+		logger.Info("state storage failed to prove key", log.Error(err))
+		return &GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_NOT_FOUND, BlockHeight: height}, err
+	}
+
+	header, err := s.blockStorage.GetResultsBlockHeader(ctx, &services.GetResultsBlockHeaderInput{BlockHeight: height + 1})
+	if err != nil {
+		logger.Info("block storage failed to get results block header", log.Error(err))
+		return &GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_SYSTEM_ERROR, BlockHeight: height}, err
+	}
+
+	if !bytes.Equal(header.ResultsBlockHeader.PreExecutionStateMerkleRootHash(), stateProof.StateMerkleRootHash) {
+		err := errors.Errorf("state merkle root of block height %d does not match results block header of block height %d", height, height+1)
+		logger.Error("get state proof failed", log.Error(err))
+		return &GetStateProofOutput{RequestStatus: protocol.REQUEST_STATUS_SYSTEM_ERROR, BlockHeight: height}, err
+	}
+
+	return &GetStateProofOutput{
+		RequestStatus:       protocol.REQUEST_STATUS_COMPLETED,
+		BlockHeight:         height,
+		Value:               stateProof.Value,
+		StateMerkleRootHash: stateProof.StateMerkleRootHash,
+		Proof:               stateProof.Proof,
+		ResultsBlockHeader:  header.ResultsBlockHeader,
+		ResultsBlockProof:   header.ResultsBlockProof,
+	}, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package publicapi
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/publicapi/pendingtransactions"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	defaultPendingTransactionsPageSize = 100
+	maxPendingTransactionsPageSize     = 1000
+)
+
+// PendingTransactionsInspector is implemented by the transaction pool, these calls are not part of the spec
+type PendingTransactionsInspector interface {
+	ListPendingTransactions(ctx context.Context, input *transactionpool.ListPendingTransactionsInput) (*transactionpool.ListPendingTransactionsOutput, error)
+	GetPendingTransaction(ctx context.Context, input *transactionpool.GetPendingTransactionInput) (*transactionpool.GetPendingTransactionOutput, error)
+}
+
+type ListPendingTransactionsInput struct {
+	Offset       uint32
+	Limit        uint32                  // zero means the default page size
+	Signer       []byte                  // public key of the signer, empty matches all signers
+	ContractName primitives.ContractName // empty matches all contracts
+}
+
+type ListPendingTransactionsOutput struct {
+	ClientResponse *pendingtransactions.ListPendingTransactionsResponse
+}
+
+type GetPendingTransactionInput struct {
+	Txhash primitives.Sha256
+}
+
+type GetPendingTransactionOutput struct {
+	ClientResponse *pendingtransactions.GetPendingTransactionResponse
+}
+
+func (s *service) ListPendingTransactions(parentCtx context.Context, input *ListPendingTransactionsInput) (*ListPendingTransactionsOutput, error) {
+	ctx := trace.NewContext(parentCtx, "PublicApi.ListPendingTransactions")
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), log.String("flow", "checkpoint"))
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultPendingTransactionsPageSize
+	}
+	if limit > maxPendingTransactionsPageSize {
+		limit = maxPendingTransactionsPageSize
+	}
+
+	logger.Info("list pending transactions request received", log.Uint32("offset", input.Offset), log.Uint32("limit", limit))
+
+	out, err := s.transactionPool.ListPendingTransactions(ctx, &transactionpool.ListPendingTransactionsInput{
+		Offset:          input.Offset,
+		Limit:           limit,
+		SignerPublicKey: input.Signer,
+		ContractName:    input.ContractName,
+	})
+	if err != nil {
+		logger.Info("transaction pool failed to list pending transactions", log.Error(err))
+		return toListPendingTransactionsOutput(protocol.REQUEST_STATUS_SYSTEM_ERROR, nil), err
+	}
+
+	return toListPendingTransactionsOutput(protocol.REQUEST_STATUS_COMPLETED, out), nil
+}
+
+func (s *service) GetPendingTransaction(parentCtx context.Context, input *GetPendingTransactionInput) (*GetPendingTransactionOutput, error) {
+	ctx := trace.NewContext(parentCtx, "PublicApi.GetPendingTransaction")
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), logfields.Transaction(input.Txhash), log.String("flow", "checkpoint"))
+
+	if len(input.Txhash) != 32 {
+		err := errors.Errorf("transaction hash of %d bytes is not a sha256 hash", len(input.Txhash))
+		logger.Info("get pending transaction received input failed", log.Error(err))
+		return toGetPendingTransactionOutput(protocol.REQUEST_STATUS_BAD_REQUEST, nil), err
+	}
+
+	logger.Info("get pending transaction request received")
+
+	out, err := s.transactionPool.GetPendingTransaction(ctx, &transactionpool.GetPendingTransactionInput{Txhash: input.Txhash})
+	if err != nil {
+		logger.Info("transaction pool failed to get pending transaction", log.Error(err))
+		return toGetPendingTransactionOutput(protocol.REQUEST_STATUS_SYSTEM_ERROR, nil), err
+	}
+
+	if out.PendingTransaction == nil {
+		return toGetPendingTransactionOutput(protocol.REQUEST_STATUS_NOT_FOUND, nil), nil
+	}
+
+	return toGetPendingTransactionOutput(protocol.REQUEST_STATUS_COMPLETED, out.PendingTransaction), nil
+}
+
+func toListPendingTransactionsOutput(requestStatus protocol.RequestStatus, out *transactionpool.ListPendingTransactionsOutput) *ListPendingTransactionsOutput {
+	response := &pendingtransactions.ListPendingTransactionsResponseBuilder{
+		RequestStatus: uint32(requestStatus),
+	}
+	if out != nil {
+		now := time.Now()
+		response.TotalCount = out.TotalCount
+		for _, ptx := range out.PendingTransactions {
+			response.PendingTransactions = append(response.PendingTransactions, toPendingTransactionBuilder(ptx, now))
+		}
+	}
+	return &ListPendingTransactionsOutput{ClientResponse: response.Build()}
+}
+
+func toGetPendingTransactionOutput(requestStatus protocol.RequestStatus, ptx *transactionpool.PendingTransaction) *GetPendingTransactionOutput {
+	response := &pendingtransactions.GetPendingTransactionResponseBuilder{
+		RequestStatus: uint32(requestStatus),
+	}
+	if ptx != nil {
+		response.PendingTransaction = toPendingTransactionBuilder(ptx, time.Now())
+	}
+	return &GetPendingTransactionOutput{ClientResponse: response.Build()}
+}
+
+func toPendingTransactionBuilder(ptx *transactionpool.PendingTransaction, now time.Time) *pendingtransactions.PendingTransactionBuilder {
+	var age time.Duration
+	if now.After(ptx.TimeAdded) {
+		age = now.Sub(ptx.TimeAdded)
+	}
+	return &pendingtransactions.PendingTransactionBuilder{
+		Txhash:             ptx.Txhash,
+		SignedTransaction:  ptx.SignedTransaction.Raw(),
+		GatewayNodeAddress: ptx.GatewayNodeAddress,
+		TimeAdded:          uint64(ptx.TimeAdded.UnixNano()),
+		AgeMillis:          uint64(age / time.Millisecond),
+	}
+}
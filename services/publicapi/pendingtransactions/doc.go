@@ -0,0 +1,10 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+// Package pendingtransactions holds the membuffers responses of the pending transactions inspection api
+package pendingtransactions
+
+//go:generate membufc --go pending_transactions.proto
@@ -0,0 +1,607 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+// AUTO GENERATED FILE (by membufc proto compiler v0.4.0)
+package pendingtransactions
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/orbs-network/membuffers/go"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// message PendingTransaction
+
+// reader
+
+type PendingTransaction struct {
+	// Txhash []byte
+	// SignedTransaction []byte
+	// GatewayNodeAddress []byte
+	// TimeAdded uint64
+	// AgeMillis uint64
+
+	// internal
+	// implements membuffers.Message
+	_message membuffers.InternalMessage
+}
+
+func (x *PendingTransaction) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{Txhash:%s,SignedTransaction:%s,GatewayNodeAddress:%s,TimeAdded:%s,AgeMillis:%s,}", x.StringTxhash(), x.StringSignedTransaction(), x.StringGatewayNodeAddress(), x.StringTimeAdded(), x.StringAgeMillis())
+}
+
+var _PendingTransaction_Scheme = []membuffers.FieldType{membuffers.TypeBytes, membuffers.TypeBytes, membuffers.TypeBytes, membuffers.TypeUint64, membuffers.TypeUint64}
+var _PendingTransaction_Unions = [][]membuffers.FieldType{}
+
+func PendingTransactionReader(buf []byte) *PendingTransaction {
+	x := &PendingTransaction{}
+	x._message.Init(buf, membuffers.Offset(len(buf)), _PendingTransaction_Scheme, _PendingTransaction_Unions)
+	return x
+}
+
+func (x *PendingTransaction) IsValid() bool {
+	return x._message.IsValid()
+}
+
+func (x *PendingTransaction) Raw() []byte {
+	return x._message.RawBuffer()
+}
+
+func (x *PendingTransaction) Equal(y *PendingTransaction) bool {
+	if x == nil && y == nil {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	return bytes.Equal(x.Raw(), y.Raw())
+}
+
+func (x *PendingTransaction) Txhash() []byte {
+	return x._message.GetBytes(0)
+}
+
+func (x *PendingTransaction) RawTxhash() []byte {
+	return x._message.RawBufferForField(0, 0)
+}
+
+func (x *PendingTransaction) RawTxhashWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(0, 0)
+}
+
+func (x *PendingTransaction) MutateTxhash(v []byte) error {
+	return x._message.SetBytes(0, v)
+}
+
+func (x *PendingTransaction) StringTxhash() string {
+	return fmt.Sprintf("%x", x.Txhash())
+}
+
+func (x *PendingTransaction) SignedTransaction() []byte {
+	return x._message.GetBytes(1)
+}
+
+func (x *PendingTransaction) RawSignedTransaction() []byte {
+	return x._message.RawBufferForField(1, 0)
+}
+
+func (x *PendingTransaction) RawSignedTransactionWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(1, 0)
+}
+
+func (x *PendingTransaction) MutateSignedTransaction(v []byte) error {
+	return x._message.SetBytes(1, v)
+}
+
+func (x *PendingTransaction) StringSignedTransaction() string {
+	return fmt.Sprintf("%x", x.SignedTransaction())
+}
+
+func (x *PendingTransaction) GatewayNodeAddress() []byte {
+	return x._message.GetBytes(2)
+}
+
+func (x *PendingTransaction) RawGatewayNodeAddress() []byte {
+	return x._message.RawBufferForField(2, 0)
+}
+
+func (x *PendingTransaction) RawGatewayNodeAddressWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(2, 0)
+}
+
+func (x *PendingTransaction) MutateGatewayNodeAddress(v []byte) error {
+	return x._message.SetBytes(2, v)
+}
+
+func (x *PendingTransaction) StringGatewayNodeAddress() string {
+	return fmt.Sprintf("%x", x.GatewayNodeAddress())
+}
+
+func (x *PendingTransaction) TimeAdded() uint64 {
+	return x._message.GetUint64(3)
+}
+
+func (x *PendingTransaction) RawTimeAdded() []byte {
+	return x._message.RawBufferForField(3, 0)
+}
+
+func (x *PendingTransaction) MutateTimeAdded(v uint64) error {
+	return x._message.SetUint64(3, v)
+}
+
+func (x *PendingTransaction) StringTimeAdded() string {
+	return fmt.Sprintf("%v", x.TimeAdded())
+}
+
+func (x *PendingTransaction) AgeMillis() uint64 {
+	return x._message.GetUint64(4)
+}
+
+func (x *PendingTransaction) RawAgeMillis() []byte {
+	return x._message.RawBufferForField(4, 0)
+}
+
+func (x *PendingTransaction) MutateAgeMillis(v uint64) error {
+	return x._message.SetUint64(4, v)
+}
+
+func (x *PendingTransaction) StringAgeMillis() string {
+	return fmt.Sprintf("%v", x.AgeMillis())
+}
+
+// builder
+
+type PendingTransactionBuilder struct {
+	Txhash             []byte
+	SignedTransaction  []byte
+	GatewayNodeAddress []byte
+	TimeAdded          uint64
+	AgeMillis          uint64
+
+	// internal
+	// implements membuffers.Builder
+	_builder               membuffers.InternalBuilder
+	_overrideWithRawBuffer []byte
+}
+
+func (w *PendingTransactionBuilder) Write(buf []byte) (err error) {
+	if w == nil {
+		return
+	}
+	w._builder.NotifyBuildStart()
+	defer w._builder.NotifyBuildEnd()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	if w._overrideWithRawBuffer != nil {
+		return w._builder.WriteOverrideWithRawBuffer(buf, w._overrideWithRawBuffer)
+	}
+	w._builder.Reset()
+	w._builder.WriteBytes(buf, w.Txhash)
+	w._builder.WriteBytes(buf, w.SignedTransaction)
+	w._builder.WriteBytes(buf, w.GatewayNodeAddress)
+	w._builder.WriteUint64(buf, w.TimeAdded)
+	w._builder.WriteUint64(buf, w.AgeMillis)
+	return nil
+}
+
+func (w *PendingTransactionBuilder) HexDump(prefix string, offsetFromStart membuffers.Offset) (err error) {
+	if w == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	w._builder.Reset()
+	w._builder.HexDumpBytes(prefix, offsetFromStart, "PendingTransaction.Txhash", w.Txhash)
+	w._builder.HexDumpBytes(prefix, offsetFromStart, "PendingTransaction.SignedTransaction", w.SignedTransaction)
+	w._builder.HexDumpBytes(prefix, offsetFromStart, "PendingTransaction.GatewayNodeAddress", w.GatewayNodeAddress)
+	w._builder.HexDumpUint64(prefix, offsetFromStart, "PendingTransaction.TimeAdded", w.TimeAdded)
+	w._builder.HexDumpUint64(prefix, offsetFromStart, "PendingTransaction.AgeMillis", w.AgeMillis)
+	return nil
+}
+
+func (w *PendingTransactionBuilder) GetSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	return w._builder.GetSize()
+}
+
+func (w *PendingTransactionBuilder) CalcRequiredSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	w.Write(nil)
+	return w._builder.GetSize()
+}
+
+func (w *PendingTransactionBuilder) Build() *PendingTransaction {
+	buf := make([]byte, w.CalcRequiredSize())
+	if w.Write(buf) != nil {
+		return nil
+	}
+	return PendingTransactionReader(buf)
+}
+
+func PendingTransactionBuilderFromRaw(raw []byte) *PendingTransactionBuilder {
+	return &PendingTransactionBuilder{_overrideWithRawBuffer: raw}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// message ListPendingTransactionsResponse
+
+// reader
+
+type ListPendingTransactionsResponse struct {
+	// RequestStatus uint32
+	// TotalCount uint32
+	// PendingTransactions []PendingTransaction
+
+	// internal
+	// implements membuffers.Message
+	_message membuffers.InternalMessage
+}
+
+func (x *ListPendingTransactionsResponse) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{RequestStatus:%s,TotalCount:%s,PendingTransactions:%s,}", x.StringRequestStatus(), x.StringTotalCount(), x.StringPendingTransactions())
+}
+
+var _ListPendingTransactionsResponse_Scheme = []membuffers.FieldType{membuffers.TypeUint32, membuffers.TypeUint32, membuffers.TypeMessageArray}
+var _ListPendingTransactionsResponse_Unions = [][]membuffers.FieldType{}
+
+func ListPendingTransactionsResponseReader(buf []byte) *ListPendingTransactionsResponse {
+	x := &ListPendingTransactionsResponse{}
+	x._message.Init(buf, membuffers.Offset(len(buf)), _ListPendingTransactionsResponse_Scheme, _ListPendingTransactionsResponse_Unions)
+	return x
+}
+
+func (x *ListPendingTransactionsResponse) IsValid() bool {
+	return x._message.IsValid()
+}
+
+func (x *ListPendingTransactionsResponse) Raw() []byte {
+	return x._message.RawBuffer()
+}
+
+func (x *ListPendingTransactionsResponse) Equal(y *ListPendingTransactionsResponse) bool {
+	if x == nil && y == nil {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	return bytes.Equal(x.Raw(), y.Raw())
+}
+
+func (x *ListPendingTransactionsResponse) RequestStatus() uint32 {
+	return x._message.GetUint32(0)
+}
+
+func (x *ListPendingTransactionsResponse) RawRequestStatus() []byte {
+	return x._message.RawBufferForField(0, 0)
+}
+
+func (x *ListPendingTransactionsResponse) MutateRequestStatus(v uint32) error {
+	return x._message.SetUint32(0, v)
+}
+
+func (x *ListPendingTransactionsResponse) StringRequestStatus() string {
+	return fmt.Sprintf("%v", x.RequestStatus())
+}
+
+func (x *ListPendingTransactionsResponse) TotalCount() uint32 {
+	return x._message.GetUint32(1)
+}
+
+func (x *ListPendingTransactionsResponse) RawTotalCount() []byte {
+	return x._message.RawBufferForField(1, 0)
+}
+
+func (x *ListPendingTransactionsResponse) MutateTotalCount(v uint32) error {
+	return x._message.SetUint32(1, v)
+}
+
+func (x *ListPendingTransactionsResponse) StringTotalCount() string {
+	return fmt.Sprintf("%v", x.TotalCount())
+}
+
+func (x *ListPendingTransactionsResponse) PendingTransactionsIterator() *ListPendingTransactionsResponsePendingTransactionsIterator {
+	return &ListPendingTransactionsResponsePendingTransactionsIterator{iterator: x._message.GetMessageArrayIterator(2)}
+}
+
+type ListPendingTransactionsResponsePendingTransactionsIterator struct {
+	iterator *membuffers.Iterator
+}
+
+func (i *ListPendingTransactionsResponsePendingTransactionsIterator) HasNext() bool {
+	return i.iterator.HasNext()
+}
+
+func (i *ListPendingTransactionsResponsePendingTransactionsIterator) NextPendingTransactions() *PendingTransaction {
+	b, s := i.iterator.NextMessage()
+	return PendingTransactionReader(b[:s])
+}
+
+func (x *ListPendingTransactionsResponse) RawPendingTransactionsArray() []byte {
+	return x._message.RawBufferForField(2, 0)
+}
+
+func (x *ListPendingTransactionsResponse) RawPendingTransactionsArrayWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(2, 0)
+}
+
+func (x *ListPendingTransactionsResponse) StringPendingTransactions() (res string) {
+	res = "["
+	for i := x.PendingTransactionsIterator(); i.HasNext(); {
+		res += i.NextPendingTransactions().String() + ","
+	}
+	res += "]"
+	return
+}
+
+// builder
+
+type ListPendingTransactionsResponseBuilder struct {
+	RequestStatus       uint32
+	TotalCount          uint32
+	PendingTransactions []*PendingTransactionBuilder
+
+	// internal
+	// implements membuffers.Builder
+	_builder               membuffers.InternalBuilder
+	_overrideWithRawBuffer []byte
+}
+
+func (w *ListPendingTransactionsResponseBuilder) arrayOfPendingTransactions() []membuffers.MessageWriter {
+	res := make([]membuffers.MessageWriter, len(w.PendingTransactions))
+	for i, v := range w.PendingTransactions {
+		res[i] = v
+	}
+	return res
+}
+
+func (w *ListPendingTransactionsResponseBuilder) Write(buf []byte) (err error) {
+	if w == nil {
+		return
+	}
+	w._builder.NotifyBuildStart()
+	defer w._builder.NotifyBuildEnd()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	if w._overrideWithRawBuffer != nil {
+		return w._builder.WriteOverrideWithRawBuffer(buf, w._overrideWithRawBuffer)
+	}
+	w._builder.Reset()
+	w._builder.WriteUint32(buf, w.RequestStatus)
+	w._builder.WriteUint32(buf, w.TotalCount)
+	err = w._builder.WriteMessageArray(buf, w.arrayOfPendingTransactions())
+	if err != nil {
+		return
+	}
+	return nil
+}
+
+func (w *ListPendingTransactionsResponseBuilder) HexDump(prefix string, offsetFromStart membuffers.Offset) (err error) {
+	if w == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	w._builder.Reset()
+	w._builder.HexDumpUint32(prefix, offsetFromStart, "ListPendingTransactionsResponse.RequestStatus", w.RequestStatus)
+	w._builder.HexDumpUint32(prefix, offsetFromStart, "ListPendingTransactionsResponse.TotalCount", w.TotalCount)
+	err = w._builder.HexDumpMessageArray(prefix, offsetFromStart, "ListPendingTransactionsResponse.PendingTransactions", w.arrayOfPendingTransactions())
+	if err != nil {
+		return
+	}
+	return nil
+}
+
+func (w *ListPendingTransactionsResponseBuilder) GetSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	return w._builder.GetSize()
+}
+
+func (w *ListPendingTransactionsResponseBuilder) CalcRequiredSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	w.Write(nil)
+	return w._builder.GetSize()
+}
+
+func (w *ListPendingTransactionsResponseBuilder) Build() *ListPendingTransactionsResponse {
+	buf := make([]byte, w.CalcRequiredSize())
+	if w.Write(buf) != nil {
+		return nil
+	}
+	return ListPendingTransactionsResponseReader(buf)
+}
+
+func ListPendingTransactionsResponseBuilderFromRaw(raw []byte) *ListPendingTransactionsResponseBuilder {
+	return &ListPendingTransactionsResponseBuilder{_overrideWithRawBuffer: raw}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// message GetPendingTransactionResponse
+
+// reader
+
+type GetPendingTransactionResponse struct {
+	// RequestStatus uint32
+	// PendingTransaction PendingTransaction
+
+	// internal
+	// implements membuffers.Message
+	_message membuffers.InternalMessage
+}
+
+func (x *GetPendingTransactionResponse) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("{RequestStatus:%s,PendingTransaction:%s,}", x.StringRequestStatus(), x.StringPendingTransaction())
+}
+
+var _GetPendingTransactionResponse_Scheme = []membuffers.FieldType{membuffers.TypeUint32, membuffers.TypeMessage}
+var _GetPendingTransactionResponse_Unions = [][]membuffers.FieldType{}
+
+func GetPendingTransactionResponseReader(buf []byte) *GetPendingTransactionResponse {
+	x := &GetPendingTransactionResponse{}
+	x._message.Init(buf, membuffers.Offset(len(buf)), _GetPendingTransactionResponse_Scheme, _GetPendingTransactionResponse_Unions)
+	return x
+}
+
+func (x *GetPendingTransactionResponse) IsValid() bool {
+	return x._message.IsValid()
+}
+
+func (x *GetPendingTransactionResponse) Raw() []byte {
+	return x._message.RawBuffer()
+}
+
+func (x *GetPendingTransactionResponse) Equal(y *GetPendingTransactionResponse) bool {
+	if x == nil && y == nil {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	return bytes.Equal(x.Raw(), y.Raw())
+}
+
+func (x *GetPendingTransactionResponse) RequestStatus() uint32 {
+	return x._message.GetUint32(0)
+}
+
+func (x *GetPendingTransactionResponse) RawRequestStatus() []byte {
+	return x._message.RawBufferForField(0, 0)
+}
+
+func (x *GetPendingTransactionResponse) MutateRequestStatus(v uint32) error {
+	return x._message.SetUint32(0, v)
+}
+
+func (x *GetPendingTransactionResponse) StringRequestStatus() string {
+	return fmt.Sprintf("%v", x.RequestStatus())
+}
+
+func (x *GetPendingTransactionResponse) PendingTransaction() *PendingTransaction {
+	b, s := x._message.GetMessage(1)
+	return PendingTransactionReader(b[:s])
+}
+
+func (x *GetPendingTransactionResponse) RawPendingTransaction() []byte {
+	return x._message.RawBufferForField(1, 0)
+}
+
+func (x *GetPendingTransactionResponse) RawPendingTransactionWithHeader() []byte {
+	return x._message.RawBufferWithHeaderForField(1, 0)
+}
+
+func (x *GetPendingTransactionResponse) StringPendingTransaction() string {
+	return x.PendingTransaction().String()
+}
+
+// builder
+
+type GetPendingTransactionResponseBuilder struct {
+	RequestStatus      uint32
+	PendingTransaction *PendingTransactionBuilder
+
+	// internal
+	// implements membuffers.Builder
+	_builder               membuffers.InternalBuilder
+	_overrideWithRawBuffer []byte
+}
+
+func (w *GetPendingTransactionResponseBuilder) Write(buf []byte) (err error) {
+	if w == nil {
+		return
+	}
+	w._builder.NotifyBuildStart()
+	defer w._builder.NotifyBuildEnd()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	if w._overrideWithRawBuffer != nil {
+		return w._builder.WriteOverrideWithRawBuffer(buf, w._overrideWithRawBuffer)
+	}
+	w._builder.Reset()
+	w._builder.WriteUint32(buf, w.RequestStatus)
+	err = w._builder.WriteMessage(buf, w.PendingTransaction)
+	if err != nil {
+		return
+	}
+	return nil
+}
+
+func (w *GetPendingTransactionResponseBuilder) HexDump(prefix string, offsetFromStart membuffers.Offset) (err error) {
+	if w == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &membuffers.ErrBufferOverrun{}
+		}
+	}()
+	w._builder.Reset()
+	w._builder.HexDumpUint32(prefix, offsetFromStart, "GetPendingTransactionResponse.RequestStatus", w.RequestStatus)
+	err = w._builder.HexDumpMessage(prefix, offsetFromStart, "GetPendingTransactionResponse.PendingTransaction", w.PendingTransaction)
+	if err != nil {
+		return
+	}
+	return nil
+}
+
+func (w *GetPendingTransactionResponseBuilder) GetSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	return w._builder.GetSize()
+}
+
+func (w *GetPendingTransactionResponseBuilder) CalcRequiredSize() membuffers.Offset {
+	if w == nil {
+		return 0
+	}
+	w.Write(nil)
+	return w._builder.GetSize()
+}
+
+func (w *GetPendingTransactionResponseBuilder) Build() *GetPendingTransactionResponse {
+	buf := make([]byte, w.CalcRequiredSize())
+	if w.Write(buf) != nil {
+		return nil
+	}
+	return GetPendingTransactionResponseReader(buf)
+}
+
+func GetPendingTransactionResponseBuilderFromRaw(raw []byte) *GetPendingTransactionResponseBuilder {
+	return &GetPendingTransactionResponseBuilder{_overrideWithRawBuffer: raw}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/orbs-network/crypto-lib-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/client"
 	"github.com/orbs-network/orbs-spec/types/go/services"
@@ -20,6 +21,11 @@ import (
 )
 
 func (s *service) RunQuery(parentCtx context.Context, input *services.RunQueryInput) (*services.RunQueryOutput, error) {
+	return s.RunQueryAtBlockHeight(parentCtx, input, 0)
+}
+
+// a zero block height runs the query on the most recent committed state
+func (s *service) RunQueryAtBlockHeight(parentCtx context.Context, input *services.RunQueryInput, blockHeight primitives.BlockHeight) (*services.RunQueryOutput, error) {
 	s.metrics.queriesPerSecond.Measure(1)
 	ctx := trace.NewContext(parentCtx, "PublicApi.RunQuery")
 
@@ -31,7 +37,7 @@ func (s *service) RunQuery(parentCtx context.Context, input *services.RunQueryIn
 
 	query := input.ClientRequest.SignedQuery().Query()
 	queryHash := digest.CalcQueryHash(query)
-	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), logfields.Query(queryHash), log.String("flow", "checkpoint"))
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), logfields.Query(queryHash), logfields.BlockHeight(blockHeight), log.String("flow", "checkpoint"))
 
 	if _, err := validateRequest(s.config, query.ProtocolVersion(), query.VirtualChainId()); err != nil {
 		logger.Info("run query received input failed", log.Error(err))
@@ -44,7 +50,7 @@ func (s *service) RunQuery(parentCtx context.Context, input *services.RunQueryIn
 	defer s.metrics.runQueryTime.RecordSince(start)
 
 	callOutput, err := s.virtualMachine.ProcessQuery(ctx, &services.ProcessQueryInput{
-		BlockHeight: 0, // recent block height
+		BlockHeight: blockHeight,
 		SignedQuery: input.ClientRequest.SignedQuery(),
 	})
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
@@ -21,11 +22,27 @@ import (
 
 var LogTag = log.Service("public-api")
 
+// public api calls which are not (yet) part of the spec
+type PublicApi interface {
+	services.PublicApi
+	RunQueryAtBlockHeight(ctx context.Context, input *services.RunQueryInput, blockHeight primitives.BlockHeight) (*services.RunQueryOutput, error)
+	GetStateProof(ctx context.Context, input *GetStateProofInput) (*GetStateProofOutput, error)
+	ListPendingTransactions(ctx context.Context, input *ListPendingTransactionsInput) (*ListPendingTransactionsOutput, error)
+	GetPendingTransaction(ctx context.Context, input *GetPendingTransactionInput) (*GetPendingTransactionOutput, error)
+}
+
+// TransactionPool is the spec transaction pool along with the calls of the public api which are not part of the spec
+type TransactionPool interface {
+	services.TransactionPool
+	PendingTransactionsInspector
+}
+
 type service struct {
 	config          config.PublicApiConfig
-	transactionPool services.TransactionPool
+	transactionPool TransactionPool
 	virtualMachine  services.VirtualMachine
 	blockStorage    services.BlockStorage
+	stateStorage    StateProofProvider
 	logger          log.Logger
 
 	waiter *waiter
@@ -63,17 +80,19 @@ func newMetrics(factory metric.Factory, sendTransactionTimeout time.Duration, ge
 
 func NewPublicApi(
 	config config.PublicApiConfig,
-	transactionPool services.TransactionPool,
+	transactionPool TransactionPool,
 	virtualMachine services.VirtualMachine,
 	blockStorage services.BlockStorage,
+	stateStorage StateProofProvider,
 	logger log.Logger,
 	metricFactory metric.Factory,
-) services.PublicApi {
+) PublicApi {
 	s := &service{
 		config:          config,
 		transactionPool: transactionPool,
 		virtualMachine:  virtualMachine,
 		blockStorage:    blockStorage,
+		stateStorage:    stateStorage,
 		logger:          logger.WithTags(LogTag),
 
 		waiter:  newWaiter(),
@@ -0,0 +1,101 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGetStateProof_ReturnsProofWithNextResultsBlockHeader(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			root := primitives.Sha256{0x01, 0x02}
+			harness.prepareStateProof(10, root, root)
+
+			result, err := harness.papi.GetStateProof(ctx, &publicapi.GetStateProofInput{BlockHeight: 5, ContractName: "contract", Key: []byte("key")})
+
+			harness.verifyMocks(t)
+			require.NoError(t, err)
+			require.Equal(t, protocol.REQUEST_STATUS_COMPLETED, result.RequestStatus)
+			require.EqualValues(t, 5, result.BlockHeight)
+			require.EqualValues(t, "value", result.Value)
+			require.EqualValues(t, 6, result.ResultsBlockHeader.BlockHeight(), "state of block 5 should be anchored in results block 6")
+			require.NotNil(t, result.ResultsBlockProof)
+		})
+	})
+}
+
+func TestGetStateProof_DefaultsToMostRecentProvableHeight(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			root := primitives.Sha256{0x01, 0x02}
+			harness.prepareStateProof(10, root, root)
+
+			result, err := harness.papi.GetStateProof(ctx, &publicapi.GetStateProofInput{ContractName: "contract", Key: []byte("key")})
+
+			harness.verifyMocks(t)
+			require.NoError(t, err)
+			require.EqualValues(t, 9, result.BlockHeight)
+			require.EqualValues(t, 10, result.ResultsBlockHeader.BlockHeight())
+		})
+	})
+}
+
+func TestGetStateProof_FailsForLastCommittedHeight(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			harness.prepareLastCommittedBlockHeight(10)
+			harness.ssMock.Never("GetStateProof", mock.Any, mock.Any)
+
+			result, err := harness.papi.GetStateProof(ctx, &publicapi.GetStateProofInput{BlockHeight: 10, ContractName: "contract", Key: []byte("key")})
+
+			harness.verifyMocks(t)
+			require.Error(t, err)
+			require.Equal(t, protocol.REQUEST_STATUS_NOT_FOUND, result.RequestStatus)
+		})
+	})
+}
+
+func TestGetStateProof_FailsWhenHeaderDoesNotCommitToStateRoot(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			parent.AllowErrorsMatching("does not match results block header")
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			harness.prepareStateProof(10, primitives.Sha256{0x01}, primitives.Sha256{0x02})
+
+			result, err := harness.papi.GetStateProof(ctx, &publicapi.GetStateProofInput{BlockHeight: 5, ContractName: "contract", Key: []byte("key")})
+
+			harness.verifyMocks(t)
+			require.Error(t, err)
+			require.Equal(t, protocol.REQUEST_STATUS_SYSTEM_ERROR, result.RequestStatus)
+		})
+	})
+}
+
+func TestGetStateProof_RejectsMissingContractName(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+
+			result, err := harness.papi.GetStateProof(ctx, &publicapi.GetStateProofInput{BlockHeight: 5, Key: []byte("key")})
+
+			require.Error(t, err)
+			require.Equal(t, protocol.REQUEST_STATUS_BAD_REQUEST, result.RequestStatus)
+		})
+	})
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
 	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/scribe/log"
@@ -23,10 +26,23 @@ import (
 )
 
 type harness struct {
-	papi    services.PublicApi
-	txpMock *services.MockTransactionPool
+	papi    publicapi.PublicApi
+	txpMock *transactionPoolMock
 	bksMock *services.MockBlockStorage
 	vmMock  *services.MockVirtualMachine
+	ssMock  *stateProofProviderMock
+}
+
+type stateProofProviderMock struct {
+	mock.Mock
+}
+
+func (m *stateProofProviderMock) GetStateProof(ctx context.Context, input *statestorage.GetStateProofInput) (*statestorage.GetStateProofOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*statestorage.GetStateProofOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
 }
 
 func newPublicApiHarness(logger log.Logger, txTimeout time.Duration, outOfSyncWarningTime time.Duration) *harness {
@@ -34,17 +50,40 @@ func newPublicApiHarness(logger log.Logger, txTimeout time.Duration, outOfSyncWa
 	txpMock := makeTxMock()
 	vmMock := &services.MockVirtualMachine{}
 	bksMock := &services.MockBlockStorage{}
-	papi := publicapi.NewPublicApi(cfg, txpMock, vmMock, bksMock, logger, metric.NewRegistry())
+	ssMock := &stateProofProviderMock{}
+	papi := publicapi.NewPublicApi(cfg, txpMock, vmMock, bksMock, ssMock, logger, metric.NewRegistry())
 	return &harness{
 		papi:    papi,
 		txpMock: txpMock,
 		bksMock: bksMock,
 		vmMock:  vmMock,
+		ssMock:  ssMock,
 	}
 }
 
-func makeTxMock() *services.MockTransactionPool {
-	txpMock := &services.MockTransactionPool{}
+// the spec transaction pool mock along with the calls which are not part of the spec
+type transactionPoolMock struct {
+	services.MockTransactionPool
+}
+
+func (m *transactionPoolMock) ListPendingTransactions(ctx context.Context, input *transactionpool.ListPendingTransactionsInput) (*transactionpool.ListPendingTransactionsOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*transactionpool.ListPendingTransactionsOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
+func (m *transactionPoolMock) GetPendingTransaction(ctx context.Context, input *transactionpool.GetPendingTransactionInput) (*transactionpool.GetPendingTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*transactionpool.GetPendingTransactionOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
+func makeTxMock() *transactionPoolMock {
+	txpMock := &transactionPoolMock{}
 	txpMock.When("RegisterTransactionResultsHandler", mock.Any).Return(nil)
 	return txpMock
 }
@@ -154,6 +193,33 @@ func (h *harness) getBlockFails() {
 	h.bksMock.When("GetBlockPair", mock.Any, mock.Any).Return(nil, errors.Errorf("someErr")).Times(1)
 }
 
+func (h *harness) prepareLastCommittedBlockHeight(lastCommittedHeight primitives.BlockHeight) {
+	h.bksMock.When("GetLastCommittedBlockHeight", mock.Any, mock.Any).Return(
+		&services.GetLastCommittedBlockHeightOutput{
+			LastCommittedBlockHeight: lastCommittedHeight,
+		}).Times(1)
+}
+
+func (h *harness) prepareStateProof(lastCommittedHeight primitives.BlockHeight, stateRoot primitives.Sha256, headerRoot primitives.Sha256) {
+	h.prepareLastCommittedBlockHeight(lastCommittedHeight)
+	h.ssMock.When("GetStateProof", mock.Any, mock.Any).Call(
+		func(ctx context.Context, input *statestorage.GetStateProofInput) (*statestorage.GetStateProofOutput, error) {
+			return &statestorage.GetStateProofOutput{
+				BlockHeight:         input.BlockHeight,
+				Value:               []byte("value"),
+				StateMerkleRootHash: stateRoot,
+				Proof:               &statestorage.StateProof{},
+			}, nil
+		}).Times(1)
+	h.bksMock.When("GetResultsBlockHeader", mock.Any, mock.Any).Call(
+		func(ctx context.Context, input *services.GetResultsBlockHeaderInput) (*services.GetResultsBlockHeaderOutput, error) {
+			return &services.GetResultsBlockHeaderOutput{
+				ResultsBlockHeader: (&protocol.ResultsBlockHeaderBuilder{BlockHeight: input.BlockHeight, PreExecutionStateMerkleRootHash: headerRoot}).Build(),
+				ResultsBlockProof:  (&protocol.ResultsBlockProofBuilder{}).Build(),
+			}, nil
+		}).Times(1)
+}
+
 func (h *harness) verifyMocks(t *testing.T) {
 	// contract test
 	ok, errCalled := h.txpMock.Verify()
@@ -165,4 +231,7 @@ func (h *harness) verifyMocks(t *testing.T) {
 	ok, errCalled = h.vmMock.Verify()
 	require.True(t, ok, "virtual machine mock called incorrectly")
 	require.NoError(t, errCalled, "error happened when it should not")
+	ok, errCalled = h.ssMock.Verify()
+	require.True(t, ok, "state storage mock called incorrectly")
+	require.NoError(t, errCalled, "error happened when it should not")
 }
@@ -0,0 +1,101 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestListPendingTransactions_ReturnsPageWithAges(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			tx := builders.TransferTransaction().Build()
+			harness.txpMock.When("ListPendingTransactions", mock.Any, mock.Any).Call(func(ctx context.Context, input *transactionpool.ListPendingTransactionsInput) (*transactionpool.ListPendingTransactionsOutput, error) {
+				require.EqualValues(t, 10, input.Offset)
+				require.EqualValues(t, 1000, input.Limit, "expected the page size to be capped")
+				require.EqualValues(t, "contract", input.ContractName)
+				return &transactionpool.ListPendingTransactionsOutput{
+					TotalCount: 11,
+					PendingTransactions: []*transactionpool.PendingTransaction{
+						{SignedTransaction: tx, Txhash: primitives.Sha256{0x01}, GatewayNodeAddress: primitives.NodeAddress{0x02}, TimeAdded: time.Now().Add(-time.Minute)},
+					},
+				}, nil
+			}).Times(1)
+
+			result, err := harness.papi.ListPendingTransactions(ctx, &publicapi.ListPendingTransactionsInput{Offset: 10, Limit: 5000, ContractName: "contract"})
+
+			harness.verifyMocks(t)
+			require.NoError(t, err)
+			require.EqualValues(t, protocol.REQUEST_STATUS_COMPLETED, result.ClientResponse.RequestStatus())
+			require.EqualValues(t, 11, result.ClientResponse.TotalCount())
+			i := result.ClientResponse.PendingTransactionsIterator()
+			require.True(t, i.HasNext())
+			ptx := i.NextPendingTransactions()
+			require.Equal(t, tx.Raw(), ptx.SignedTransaction())
+			require.EqualValues(t, primitives.NodeAddress{0x02}, ptx.GatewayNodeAddress())
+			require.True(t, ptx.AgeMillis() >= uint64(time.Minute/time.Millisecond), "expected the age to be measured from the time the transaction was added")
+			require.False(t, i.HasNext())
+		})
+	})
+}
+
+func TestListPendingTransactions_DefaultsPageSize(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			harness.txpMock.When("ListPendingTransactions", mock.Any, mock.Any).Call(func(ctx context.Context, input *transactionpool.ListPendingTransactionsInput) (*transactionpool.ListPendingTransactionsOutput, error) {
+				require.EqualValues(t, 100, input.Limit)
+				return &transactionpool.ListPendingTransactionsOutput{}, nil
+			}).Times(1)
+
+			result, err := harness.papi.ListPendingTransactions(ctx, &publicapi.ListPendingTransactionsInput{})
+
+			harness.verifyMocks(t)
+			require.NoError(t, err)
+			require.EqualValues(t, 0, result.ClientResponse.TotalCount())
+		})
+	})
+}
+
+func TestGetPendingTransaction_ReturnsNotFoundForTransactionWhichIsNotPending(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+			harness.txpMock.When("GetPendingTransaction", mock.Any, mock.Any).Return(&transactionpool.GetPendingTransactionOutput{}, nil).Times(1)
+
+			result, err := harness.papi.GetPendingTransaction(ctx, &publicapi.GetPendingTransactionInput{Txhash: make([]byte, 32)})
+
+			harness.verifyMocks(t)
+			require.NoError(t, err)
+			require.EqualValues(t, protocol.REQUEST_STATUS_NOT_FOUND, result.ClientResponse.RequestStatus())
+		})
+	})
+}
+
+func TestGetPendingTransaction_RejectsMalformedTxHash(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Second, time.Minute)
+
+			result, err := harness.papi.GetPendingTransaction(ctx, &publicapi.GetPendingTransactionInput{Txhash: []byte{0x01}})
+
+			require.Error(t, err)
+			require.EqualValues(t, protocol.REQUEST_STATUS_BAD_REQUEST, result.ClientResponse.RequestStatus())
+		})
+	})
+}
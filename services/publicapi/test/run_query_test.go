@@ -8,6 +8,7 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/with"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
@@ -38,3 +39,28 @@ func TestRunQuery_CallsVirtualMachine(t *testing.T) {
 		})
 	})
 }
+
+func TestRunQueryAtBlockHeight_PassesHeightToVirtualMachine(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+			harness := newPublicApiHarness(parent.Logger, time.Millisecond, time.Minute)
+
+			harness.vmMock.When("ProcessQuery", mock.Any, mock.Any).Times(1).
+				Call(func(ctx context.Context, input *services.ProcessQueryInput) (*services.ProcessQueryOutput, error) {
+					require.EqualValues(t, 7, input.BlockHeight, "historical block height should be passed to the virtual machine")
+					return &services.ProcessQueryOutput{CallResult: protocol.EXECUTION_RESULT_SUCCESS, ReferenceBlockHeight: 7}, nil
+				})
+
+			result, err := harness.papi.RunQueryAtBlockHeight(ctx, &services.RunQueryInput{
+				ClientRequest: (&client.RunQueryRequestBuilder{
+					SignedQuery: builders.Query().Builder(),
+				}).Build(),
+			}, 7)
+
+			harness.verifyMocks(t) // contract test
+
+			require.NoError(t, err, "error happened when it should not")
+			require.EqualValues(t, 7, result.ClientResponse.RequestResult().BlockHeight(), "got wrong reference block height")
+		})
+	})
+}
@@ -0,0 +1,261 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"path/filepath"
+	"sync"
+)
+
+const archiveDirname = "archive"
+
+const archivedRecordKeyPrefix = byte('a')
+const archivedBlockKeyPrefix = byte('b')
+
+// ArchivePersistence keeps the state diff of every committed block in an embedded key-value store next to the state database.
+// each record is keyed by contract, key and block height so the value at any height is a single reverse seek
+type ArchivePersistence struct {
+	config config.FilesystemStatePersistenceConfig
+	logger log.Logger
+	db     *leveldb.DB
+
+	mutex      sync.RWMutex
+	lastHeight primitives.BlockHeight
+}
+
+func NewArchivePersistence(conf config.FilesystemStatePersistenceConfig, parent log.Logger) (*ArchivePersistence, error) {
+	logger := parent.WithTags(log.String("adapter", "state-storage-archive"))
+
+	db, err := openStateDb(conf, archiveDirName(conf), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ap := &ArchivePersistence{
+		config: conf,
+		logger: logger,
+		db:     db,
+	}
+
+	if err := ap.loadLastHeight(); err != nil {
+		closeSilently(db, logger)
+		return nil, err
+	}
+
+	logger.Info("loaded state archive", logfields.BlockHeight(ap.lastHeight), log.String("dirname", archiveDirName(conf)))
+	return ap, nil
+}
+
+func (ap *ArchivePersistence) loadLastHeight() error {
+	iter := ap.db.NewIterator(util.BytesPrefix([]byte{archivedBlockKeyPrefix}), nil)
+	defer iter.Release()
+
+	if iter.Last() {
+		height, err := parseArchivedBlockKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		ap.lastHeight = height
+	}
+	return errors.Wrap(iter.Error(), "failed to read last archived block")
+}
+
+// the diff and the block info are written in a single synced batch, so the last archived block always has all of its records
+func (ap *ArchivePersistence) WriteBlock(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, proposer primitives.NodeAddress, diff adapter.ChainState) error {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+
+	if height != ap.lastHeight+1 {
+		return fmt.Errorf("archive expected block height %d, got %d", ap.lastHeight+1, height)
+	}
+
+	batch := &leveldb.Batch{}
+	for contract, records := range diff {
+		for key, value := range records {
+			batch.Put(archivedRecordKey(contract, key, height), value)
+		}
+	}
+	batch.Put(archivedBlockKey(height), encodeArchivedBlock(ts, refTime, proposer))
+
+	if err := ap.db.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return errors.Wrapf(err, "failed to archive state for block height %d", height)
+	}
+	ap.lastHeight = height
+	return nil
+}
+
+func (ap *ArchivePersistence) ReadRecord(height primitives.BlockHeight, contract primitives.ContractName, key string) ([]byte, bool, error) {
+	iter := ap.db.NewIterator(&util.Range{Start: archivedRecordKey(contract, key, 0), Limit: archivedRecordKey(contract, key, height+1)}, nil)
+	defer iter.Release()
+
+	if !iter.Last() {
+		return nil, false, errors.Wrapf(iter.Error(), "failed to read archived state record %s.%s", contract, key)
+	}
+	return append([]byte{}, iter.Value()...), true, nil
+}
+
+func (ap *ArchivePersistence) ReadBlockInfo(height primitives.BlockHeight) (primitives.TimestampNano, primitives.TimestampSeconds, primitives.NodeAddress, error) {
+	raw, err := ap.db.Get(archivedBlockKey(height), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, 0, nil, fmt.Errorf("block height %d is not archived", height)
+	}
+	if err != nil {
+		return 0, 0, nil, errors.Wrapf(err, "failed to read archived block %d", height)
+	}
+	return decodeArchivedBlock(raw)
+}
+
+// records are ordered by contract, key and height, so the value of each key at height is the last of its records not above it
+func (ap *ArchivePersistence) ScanState(height primitives.BlockHeight, cursor adapter.StateCursorFunc) error {
+	iter := ap.db.NewIterator(util.BytesPrefix([]byte{archivedRecordKeyPrefix}), nil)
+	defer iter.Release()
+
+	var pendingKey, pendingValue []byte
+	emitPending := func() (bool, error) {
+		if pendingKey == nil {
+			return true, nil
+		}
+		contract, key, _, err := parseArchivedRecordKey(pendingKey)
+		if err != nil {
+			return false, err
+		}
+		return cursor(contract, key, pendingValue), nil
+	}
+
+	for iter.Next() {
+		recordKey := iter.Key()
+		if len(recordKey) < 8 {
+			return fmt.Errorf("invalid archived state record key %x", recordKey)
+		}
+		if pendingKey != nil && !bytes.Equal(recordKey[:len(recordKey)-8], pendingKey[:len(pendingKey)-8]) {
+			if wantsMore, err := emitPending(); err != nil || !wantsMore {
+				return err
+			}
+			pendingKey = nil
+		}
+		if primitives.BlockHeight(binary.BigEndian.Uint64(recordKey[len(recordKey)-8:])) <= height {
+			// the iterator reuses its buffers between calls
+			pendingKey = append([]byte{}, recordKey...)
+			pendingValue = append([]byte{}, iter.Value()...)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "failed to scan archived state")
+	}
+	_, err := emitPending()
+	return err
+}
+
+func (ap *ArchivePersistence) GetLastBlockHeight() (primitives.BlockHeight, error) {
+	ap.mutex.RLock()
+	defer ap.mutex.RUnlock()
+
+	return ap.lastHeight, nil
+}
+
+func (ap *ArchivePersistence) GracefulShutdown(shutdownContext context.Context) {
+	logger := ap.logger.WithTags(log.String("dirname", archiveDirName(ap.config)))
+	if err := ap.db.Close(); err != nil {
+		logger.Error("failed to close state archive database", log.Error(err))
+		return
+	}
+	logger.Info("closed state archive database")
+}
+
+func archiveDirName(conf config.FilesystemStatePersistenceConfig) string {
+	return filepath.Join(conf.StateStorageFileSystemDataDir(), archiveDirname)
+}
+
+// heights are big endian so the records of a key are ordered by height
+func archivedRecordKey(contract primitives.ContractName, key string, height primitives.BlockHeight) []byte {
+	result := make([]byte, 0, 1+4+len(contract)+4+len(key)+8)
+	result = append(result, archivedRecordKeyPrefix)
+	result = appendUint32(result, uint32(len(contract)))
+	result = append(result, contract...)
+	result = appendUint32(result, uint32(len(key)))
+	result = append(result, key...)
+	return appendUint64(result, uint64(height))
+}
+
+func parseArchivedRecordKey(raw []byte) (primitives.ContractName, string, primitives.BlockHeight, error) {
+	invalid := fmt.Errorf("invalid archived state record key %x", raw)
+	if len(raw) < 1+4+4+8 || raw[0] != archivedRecordKeyPrefix {
+		return "", "", 0, invalid
+	}
+	contractLength := int(binary.BigEndian.Uint32(raw[1:5]))
+	if len(raw) < 1+4+contractLength+4+8 {
+		return "", "", 0, invalid
+	}
+	contract := primitives.ContractName(raw[5 : 5+contractLength])
+	rest := raw[5+contractLength:]
+	keyLength := int(binary.BigEndian.Uint32(rest[:4]))
+	if len(rest) != 4+keyLength+8 {
+		return "", "", 0, invalid
+	}
+	return contract, string(rest[4 : 4+keyLength]), primitives.BlockHeight(binary.BigEndian.Uint64(rest[4+keyLength:])), nil
+}
+
+func archivedBlockKey(height primitives.BlockHeight) []byte {
+	return appendUint64([]byte{archivedBlockKeyPrefix}, uint64(height))
+}
+
+func parseArchivedBlockKey(raw []byte) (primitives.BlockHeight, error) {
+	if len(raw) != 9 || raw[0] != archivedBlockKeyPrefix {
+		return 0, fmt.Errorf("invalid archived block key %x", raw)
+	}
+	return primitives.BlockHeight(binary.BigEndian.Uint64(raw[1:])), nil
+}
+
+type fixedArchivedBlock struct {
+	Ts      uint64
+	RefTime uint32
+}
+
+func encodeArchivedBlock(ts primitives.TimestampNano, refTime primitives.TimestampSeconds, proposer primitives.NodeAddress) []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, &fixedArchivedBlock{Ts: uint64(ts), RefTime: uint32(refTime)})
+	writeChunk(buf, proposer)
+	return buf.Bytes()
+}
+
+func decodeArchivedBlock(raw []byte) (primitives.TimestampNano, primitives.TimestampSeconds, primitives.NodeAddress, error) {
+	r := bytes.NewReader(raw)
+	fixed := &fixedArchivedBlock{}
+	if err := binary.Read(r, binary.LittleEndian, fixed); err != nil {
+		return 0, 0, nil, errors.Wrap(err, "failed to decode archived block")
+	}
+	proposer, err := readChunk(r)
+	if err != nil {
+		return 0, 0, nil, errors.Wrap(err, "failed to decode archived block proposer")
+	}
+	return primitives.TimestampNano(fixed.Ts), primitives.TimestampSeconds(fixed.RefTime), proposer, nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/scribe/log"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func openArchive(t *testing.T, conf *localConfig) *ArchivePersistence {
+	ap, err := NewArchivePersistence(conf, log.GetLogger().WithOutput())
+	require.NoError(t, err)
+	return ap
+}
+
+func archiveSingleValueBlock(ap *ArchivePersistence, h primitives.BlockHeight, c, k, v string) error {
+	diff := adapter.ChainState{primitives.ContractName(c): {k: []byte(v)}}
+	return ap.WriteBlock(h, primitives.TimestampNano(h*10), primitives.TimestampSeconds(h*2), []byte{byte(h)}, diff)
+}
+
+func TestFilesystemArchivePersistence_ReadsValueAtHeight(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	ap := openArchive(t, conf)
+	defer ap.GracefulShutdown(context.Background())
+
+	require.NoError(t, archiveSingleValueBlock(ap, 1, "foo", "key", "v1"))
+	require.NoError(t, archiveSingleValueBlock(ap, 2, "foo", "other", "x"))
+	require.NoError(t, archiveSingleValueBlock(ap, 3, "foo", "key", "v3"))
+
+	_, exists, err := ap.ReadRecord(0, "foo", "key")
+	require.NoError(t, err)
+	require.False(t, exists, "key should not exist before it was written")
+
+	for height, expected := range map[primitives.BlockHeight]string{1: "v1", 2: "v1", 3: "v3", 4: "v3"} {
+		value, exists, err := ap.ReadRecord(height, "foo", "key")
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.EqualValues(t, expected, value, "unexpected value at height %d", height)
+	}
+
+	_, exists, err = ap.ReadRecord(3, "foo", "ke")
+	require.NoError(t, err)
+	require.False(t, exists, "a prefix of a key should not match its records")
+
+	ts, ref, proposer, err := ap.ReadBlockInfo(2)
+	require.NoError(t, err)
+	require.EqualValues(t, 20, ts)
+	require.EqualValues(t, 4, ref)
+	require.EqualValues(t, []byte{2}, proposer)
+}
+
+func TestFilesystemArchivePersistence_RejectsNonContiguousBlocks(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	ap := openArchive(t, conf)
+	defer ap.GracefulShutdown(context.Background())
+
+	require.Error(t, archiveSingleValueBlock(ap, 2, "foo", "key", "v"), "archive should start from block height 1")
+	require.NoError(t, archiveSingleValueBlock(ap, 1, "foo", "key", "v"))
+	require.Error(t, archiveSingleValueBlock(ap, 1, "foo", "key", "v"), "archive should not write a block twice")
+}
+
+func TestFilesystemArchivePersistence_ReopenResumesFromLastBlock(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+
+	ap := openArchive(t, conf)
+	require.NoError(t, archiveSingleValueBlock(ap, 1, "foo", "key", "v1"))
+	require.NoError(t, archiveSingleValueBlock(ap, 2, "foo", "key", "v2"))
+	ap.GracefulShutdown(context.Background())
+
+	ap = openArchive(t, conf)
+	defer ap.GracefulShutdown(context.Background())
+
+	height, err := ap.GetLastBlockHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, height)
+
+	value, _, err := ap.ReadRecord(1, "foo", "key")
+	require.NoError(t, err)
+	require.EqualValues(t, "v1", value)
+
+	require.NoError(t, archiveSingleValueBlock(ap, 3, "foo", "key", "v3"))
+}
+
+func TestFilesystemArchivePersistence_CoexistsWithStatePersistence(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+
+	sp := openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+	ap := openArchive(t, conf)
+	defer ap.GracefulShutdown(context.Background())
+}
+
+func TestFilesystemArchivePersistence_ScansStateAtHeight(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	ap := openArchive(t, conf)
+	defer ap.GracefulShutdown(context.Background())
+
+	require.NoError(t, archiveSingleValueBlock(ap, 1, "foo", "key", "v1"))
+	require.NoError(t, archiveSingleValueBlock(ap, 2, "foo", "other", "x"))
+	require.NoError(t, archiveSingleValueBlock(ap, 3, "foo", "key", "v3"))
+	require.NoError(t, archiveSingleValueBlock(ap, 4, "bar", "key", "y"))
+
+	for height, expected := range map[primitives.BlockHeight]adapter.ChainState{
+		1: {"foo": {"key": []byte("v1")}},
+		3: {"foo": {"key": []byte("v3"), "other": []byte("x")}},
+		4: {"foo": {"key": []byte("v3"), "other": []byte("x")}, "bar": {"key": []byte("y")}},
+	} {
+		scanned := adapter.ChainState{}
+		require.NoError(t, ap.ScanState(height, func(contract primitives.ContractName, key string, value []byte) bool {
+			if _, ok := scanned[contract]; !ok {
+				scanned[contract] = map[string][]byte{}
+			}
+			scanned[contract][key] = value
+			return true
+		}))
+		require.Equal(t, expected, scanned, "unexpected state at height %d", height)
+	}
+}
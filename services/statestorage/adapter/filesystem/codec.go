@@ -0,0 +1,157 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+	"io"
+)
+
+const stateFormatMagic = uint32(0x54415453) // "STAT"
+const stateFormatVersion = 0
+
+const recordKeyPrefix = byte('r')
+
+var headerKey = []byte("h")
+var metadataKey = []byte("m")
+
+type stateFileHeader struct {
+	Magic         uint32
+	FormatVersion uint32
+	NetworkType   uint32
+	ChainId       uint32
+}
+
+func newStateFileHeader(networkType, vchainId uint32) *stateFileHeader {
+	return &stateFileHeader{
+		Magic:         stateFormatMagic,
+		FormatVersion: stateFormatVersion,
+		NetworkType:   networkType,
+		ChainId:       vchainId,
+	}
+}
+
+func (h *stateFileHeader) encode() []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, h) // writing to a bytes.Buffer never fails
+	return buf.Bytes()
+}
+
+func (h *stateFileHeader) decode(raw []byte) error {
+	err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, h)
+	if err != nil {
+		return err
+	}
+
+	if h.Magic != stateFormatMagic {
+		return fmt.Errorf("invalid state file magic number %v", h.Magic)
+	}
+
+	if h.FormatVersion != stateFormatVersion {
+		return fmt.Errorf("invalid state file version %d", h.FormatVersion)
+	}
+
+	return nil
+}
+
+type stateMetadata struct {
+	height      primitives.BlockHeight
+	ts          primitives.TimestampNano
+	refTime     primitives.TimestampSeconds
+	prevRefTime primitives.TimestampSeconds
+	proposer    primitives.NodeAddress
+	merkleRoot  primitives.Sha256
+}
+
+type fixedMetadata struct {
+	Height      uint64
+	Ts          uint64
+	RefTime     uint32
+	PrevRefTime uint32
+}
+
+func (m *stateMetadata) encode() []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, &fixedMetadata{
+		Height:      uint64(m.height),
+		Ts:          uint64(m.ts),
+		RefTime:     uint32(m.refTime),
+		PrevRefTime: uint32(m.prevRefTime),
+	})
+	writeChunk(buf, m.proposer)
+	writeChunk(buf, m.merkleRoot)
+	return buf.Bytes()
+}
+
+func (m *stateMetadata) decode(raw []byte) error {
+	r := bytes.NewReader(raw)
+	fixed := &fixedMetadata{}
+	if err := binary.Read(r, binary.LittleEndian, fixed); err != nil {
+		return errors.Wrap(err, "failed to decode state metadata")
+	}
+
+	proposer, err := readChunk(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode state metadata proposer")
+	}
+
+	root, err := readChunk(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode state metadata merkle root")
+	}
+
+	m.height = primitives.BlockHeight(fixed.Height)
+	m.ts = primitives.TimestampNano(fixed.Ts)
+	m.refTime = primitives.TimestampSeconds(fixed.RefTime)
+	m.prevRefTime = primitives.TimestampSeconds(fixed.PrevRefTime)
+	m.proposer = proposer
+	m.merkleRoot = root
+	return nil
+}
+
+func writeChunk(buf *bytes.Buffer, chunk []byte) {
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(chunk)))
+	buf.Write(chunk)
+}
+
+func readChunk(r io.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(r, chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// record keys are prefixed with the contract name length so contract names and keys never collide
+func recordKey(contract primitives.ContractName, key string) []byte {
+	result := make([]byte, 0, 1+4+len(contract)+len(key))
+	result = append(result, recordKeyPrefix)
+	result = append(result, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(result[1:5], uint32(len(contract)))
+	result = append(result, contract...)
+	result = append(result, key...)
+	return result
+}
+
+func parseRecordKey(raw []byte) (primitives.ContractName, string, error) {
+	if len(raw) < 5 || raw[0] != recordKeyPrefix {
+		return "", "", fmt.Errorf("invalid state record key %x", raw)
+	}
+	contractLength := int(binary.BigEndian.Uint32(raw[1:5]))
+	if len(raw) < 5+contractLength {
+		return "", "", fmt.Errorf("invalid state record key %x", raw)
+	}
+	return primitives.ContractName(raw[5 : 5+contractLength]), string(raw[5+contractLength:]), nil
+}
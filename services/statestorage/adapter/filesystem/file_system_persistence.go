@@ -0,0 +1,341 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/merkle"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const stateDirname = "state"
+
+type metrics struct {
+	numberOfKeys      *metric.Gauge
+	numberOfContracts *metric.Gauge
+}
+
+func newMetrics(m metric.Factory) *metrics {
+	return &metrics{
+		numberOfKeys:      m.NewGauge("StateStoragePersistence.TotalNumberOfKeys.Count"),
+		numberOfContracts: m.NewGauge("StateStoragePersistence.TotalNumberOfContracts.Count"),
+	}
+}
+
+// StatePersistence keeps the full state of the virtual chain in an embedded key-value store.
+// Every Write is applied as a single synced batch together with its metadata, so after a crash
+// ReadMetadata always describes exactly the records found on disk
+type StatePersistence struct {
+	config  config.FilesystemStatePersistenceConfig
+	logger  log.Logger
+	metrics *metrics
+	db      *leveldb.DB
+
+	mutex           sync.RWMutex
+	metadata        *stateMetadata
+	keysPerContract map[primitives.ContractName]int
+}
+
+func NewStatePersistence(conf config.FilesystemStatePersistenceConfig, parent log.Logger, metricFactory metric.Factory) (*StatePersistence, error) {
+	logger := parent.WithTags(log.String("adapter", "state-storage"))
+
+	db, err := openStateDb(conf, stateDirName(conf), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &StatePersistence{
+		config:          conf,
+		logger:          logger,
+		metrics:         newMetrics(metricFactory),
+		db:              db,
+		keysPerContract: make(map[primitives.ContractName]int),
+	}
+
+	if err := sp.loadMetadata(); err != nil {
+		closeSilently(db, logger)
+		return nil, err
+	}
+
+	if err := sp.countKeys(); err != nil {
+		closeSilently(db, logger)
+		return nil, err
+	}
+
+	logger.Info("loaded state", logfields.BlockHeight(sp.metadata.height), log.String("dirname", stateDirName(conf)))
+	return sp, nil
+}
+
+func openStateDb(conf config.FilesystemStatePersistenceConfig, dirname string, logger log.Logger) (*leveldb.DB, error) {
+	dir := conf.StateStorageFileSystemDataDir()
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to verify data directory exists %s", dir)
+	}
+
+	db, err := leveldb.OpenFile(dirname, nil) // leveldb holds an exclusive lock on the directory while open
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open state database %s", dirname)
+	}
+
+	err = validateHeader(db, dirname, conf, logger)
+	if err != nil {
+		closeSilently(db, logger)
+		return nil, errors.Wrapf(err, "failed to validate state database %s", dirname)
+	}
+
+	return db, nil
+}
+
+func validateHeader(db *leveldb.DB, dirname string, conf config.FilesystemStatePersistenceConfig, logger log.Logger) error {
+	raw, err := db.Get(headerKey, nil)
+	if err == leveldb.ErrNotFound {
+		logger.Info("creating new state database", log.String("dirname", dirname))
+		header := newStateFileHeader(uint32(conf.NetworkType()), uint32(conf.VirtualChainId()))
+		return db.Put(headerKey, header.encode(), &opt.WriteOptions{Sync: true})
+	}
+	if err != nil {
+		return errors.Wrap(err, "error reading state header")
+	}
+
+	header := &stateFileHeader{}
+	if err := header.decode(raw); err != nil {
+		return errors.Wrap(err, "error reading state header")
+	}
+
+	if header.NetworkType != uint32(conf.NetworkType()) {
+		return fmt.Errorf("state network type mismatch. found network type %d expected %d", header.NetworkType, conf.NetworkType())
+	}
+
+	if header.ChainId != uint32(conf.VirtualChainId()) {
+		return fmt.Errorf("state virtual chain id mismatch. found vchain id %d expected %d", header.ChainId, conf.VirtualChainId())
+	}
+
+	return nil
+}
+
+func (sp *StatePersistence) loadMetadata() error {
+	raw, err := sp.db.Get(metadataKey, nil)
+	if err == leveldb.ErrNotFound {
+		_, merkleRoot := merkle.NewForest()
+		// same hard coded Genesis (height 0) as the in memory adapter
+		sp.metadata = &stateMetadata{
+			proposer:   []byte{},
+			merkleRoot: merkleRoot,
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error reading state metadata")
+	}
+
+	metadata := &stateMetadata{}
+	if err := metadata.decode(raw); err != nil {
+		return err
+	}
+	sp.metadata = metadata
+	return nil
+}
+
+func (sp *StatePersistence) countKeys() error {
+	iter := sp.db.NewIterator(util.BytesPrefix([]byte{recordKeyPrefix}), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		contract, _, err := parseRecordKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		sp.keysPerContract[contract]++
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "failed to iterate state records")
+	}
+
+	sp.reportSize()
+	return nil
+}
+
+func (sp *StatePersistence) reportSize() {
+	nKeys := 0
+	for _, n := range sp.keysPerContract {
+		nKeys += n
+	}
+	sp.metrics.numberOfKeys.Update(int64(nKeys))
+	sp.metrics.numberOfContracts.Update(int64(len(sp.keysPerContract)))
+}
+
+func (sp *StatePersistence) Write(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, prevRefTime primitives.TimestampSeconds, proposer primitives.NodeAddress, root primitives.Sha256, diff adapter.ChainState) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	batch := &leveldb.Batch{}
+	keyCountDiffs := make(map[primitives.ContractName]int)
+	for contract, records := range diff {
+		for key, value := range records {
+			k := recordKey(contract, key)
+			exists, err := sp.db.Has(k, nil)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read state record %s.%s", contract, key)
+			}
+
+			if isZeroValue(value) {
+				if exists {
+					batch.Delete(k)
+					keyCountDiffs[contract]--
+				}
+				continue
+			}
+
+			batch.Put(k, value)
+			if !exists {
+				keyCountDiffs[contract]++
+			}
+		}
+	}
+
+	metadata := &stateMetadata{
+		height:      height,
+		ts:          ts,
+		refTime:     refTime,
+		prevRefTime: prevRefTime,
+		proposer:    proposer,
+		merkleRoot:  root,
+	}
+	batch.Put(metadataKey, metadata.encode())
+
+	if err := sp.db.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return errors.Wrapf(err, "failed to write state for block height %d", height)
+	}
+
+	sp.metadata = metadata
+	for contract, delta := range keyCountDiffs {
+		sp.keysPerContract[contract] += delta
+		if sp.keysPerContract[contract] == 0 {
+			delete(sp.keysPerContract, contract)
+		}
+	}
+	sp.reportSize()
+	return nil
+}
+
+func (sp *StatePersistence) Read(contract primitives.ContractName, key string) ([]byte, bool, error) {
+	value, err := sp.db.Get(recordKey(contract, key), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read state record %s.%s", contract, key)
+	}
+	return value, true, nil
+}
+
+func (sp *StatePersistence) ReadMetadata() (primitives.BlockHeight, primitives.TimestampNano, primitives.TimestampSeconds, primitives.TimestampSeconds, primitives.NodeAddress, primitives.Sha256, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	m := sp.metadata
+	return m.height, m.ts, m.refTime, m.prevRefTime, m.proposer, m.merkleRoot, nil
+}
+
+func (sp *StatePersistence) ScanState(cursor adapter.StateCursorFunc) error {
+	return scanState(sp.db, cursor)
+}
+
+// the metadata is read under the same lock Write updates it under, so it describes exactly the records in the database snapshot
+func (sp *StatePersistence) View() (adapter.StateView, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	snapshot, err := sp.db.GetSnapshot()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to take state database snapshot")
+	}
+	return &stateView{snapshot: snapshot, metadata: sp.metadata}, nil
+}
+
+type stateView struct {
+	snapshot *leveldb.Snapshot
+	metadata *stateMetadata
+}
+
+func (v *stateView) ReadMetadata() (primitives.BlockHeight, primitives.TimestampNano, primitives.TimestampSeconds, primitives.TimestampSeconds, primitives.NodeAddress, primitives.Sha256, error) {
+	m := v.metadata
+	return m.height, m.ts, m.refTime, m.prevRefTime, m.proposer, m.merkleRoot, nil
+}
+
+func (v *stateView) ScanState(cursor adapter.StateCursorFunc) error {
+	return scanState(v.snapshot, cursor)
+}
+
+func (v *stateView) Release() {
+	v.snapshot.Release()
+}
+
+// implemented by both the database and its snapshots
+type iterable interface {
+	NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator
+}
+
+func scanState(db iterable, cursor adapter.StateCursorFunc) error {
+	iter := db.NewIterator(util.BytesPrefix([]byte{recordKeyPrefix}), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		contract, key, err := parseRecordKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		// the iterator reuses its buffers between calls
+		if !cursor(contract, key, append([]byte{}, iter.Value()...)) {
+			break
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "failed to iterate state records")
+	}
+	return nil
+}
+
+func (sp *StatePersistence) GracefulShutdown(shutdownContext context.Context) {
+	logger := sp.logger.WithTags(log.String("dirname", stateDirName(sp.config)))
+	if err := sp.db.Close(); err != nil {
+		logger.Error("failed to close state database", log.Error(err))
+		return
+	}
+	logger.Info("closed state database")
+}
+
+func stateDirName(conf config.FilesystemStatePersistenceConfig) string {
+	return filepath.Join(conf.StateStorageFileSystemDataDir(), stateDirname)
+}
+
+func closeSilently(db *leveldb.DB, logger log.Logger) {
+	err := db.Close()
+	if err != nil {
+		logger.Error("failed to close state database", log.Error(err))
+	}
+}
+
+func isZeroValue(value []byte) bool {
+	return bytes.Equal(value, []byte{})
+}
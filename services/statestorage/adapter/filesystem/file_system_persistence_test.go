@@ -0,0 +1,187 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package filesystem
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/merkle"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type localConfig struct {
+	dir            string
+	virtualChainId primitives.VirtualChainId
+}
+
+func (l *localConfig) StateStorageFileSystemDataDir() string {
+	return l.dir
+}
+
+func (l *localConfig) VirtualChainId() primitives.VirtualChainId {
+	return l.virtualChainId
+}
+
+func (l *localConfig) NetworkType() protocol.SignerNetworkType {
+	return protocol.NETWORK_TYPE_TEST_NET
+}
+
+func newTempConfig(t *testing.T) *localConfig {
+	dir, err := ioutil.TempDir("", "state_storage_persist")
+	require.NoError(t, err, "failed to create a temp dir")
+	return &localConfig{dir: dir, virtualChainId: 42}
+}
+
+func (l *localConfig) cleanDir() {
+	_ = os.RemoveAll(l.dir) // ignore errors - nothing to do
+}
+
+func openPersistence(t *testing.T, conf *localConfig) *StatePersistence {
+	sp, err := NewStatePersistence(conf, log.GetLogger().WithOutput(), metric.NewRegistry())
+	require.NoError(t, err)
+	return sp
+}
+
+func writeSingleValueBlock(sp *StatePersistence, h primitives.BlockHeight, c, k, v string) error {
+	diff := adapter.ChainState{primitives.ContractName(c): {k: []byte(v)}}
+	return sp.Write(h, primitives.TimestampNano(h*10), primitives.TimestampSeconds(h*2), primitives.TimestampSeconds(h*2-1), []byte{0xaa}, []byte{byte(h)}, diff)
+}
+
+func TestFilesystemStatePersistence_StartsAtGenesis(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	sp := openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+
+	_, emptyRoot := merkle.NewForest()
+	height, _, _, _, _, root, err := sp.ReadMetadata()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, height)
+	require.EqualValues(t, emptyRoot, root, "genesis state should have the empty merkle root")
+}
+
+func TestFilesystemStatePersistence_WriteAndRemoveKey(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	sp := openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+
+	require.NoError(t, writeSingleValueBlock(sp, 1, "foo", "foo", "bar"))
+
+	record, ok, err := sp.Read("foo", "foo")
+	require.NoError(t, err)
+	require.True(t, ok, "after writing a key it should exist")
+	require.EqualValues(t, "bar", record, "after writing a key/value it should be returned")
+
+	require.NoError(t, writeSingleValueBlock(sp, 2, "foo", "foo", ""))
+
+	_, ok, err = sp.Read("foo", "foo")
+	require.NoError(t, err)
+	require.False(t, ok, "writing zero value to state did not remove key")
+}
+
+func TestFilesystemStatePersistence_ReopenResumesFromLastWrite(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+
+	sp := openPersistence(t, conf)
+	require.NoError(t, writeSingleValueBlock(sp, 1, "foo", "k1", "v1"))
+	require.NoError(t, writeSingleValueBlock(sp, 2, "bar", "k2", "v2"))
+	sp.GracefulShutdown(context.Background())
+
+	sp = openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+
+	height, ts, refTime, prevRefTime, proposer, root, err := sp.ReadMetadata()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, height)
+	require.EqualValues(t, 20, ts)
+	require.EqualValues(t, 4, refTime)
+	require.EqualValues(t, 3, prevRefTime)
+	require.EqualValues(t, []byte{0xaa}, proposer)
+	require.EqualValues(t, []byte{2}, root)
+
+	record, ok, err := sp.Read("bar", "k2")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, "v2", record)
+}
+
+func TestFilesystemStatePersistence_ScanState(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	sp := openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+
+	diff := adapter.ChainState{
+		"c1": {"k1": []byte("v1"), "k2": []byte("v2")},
+		"c2": {"k1": []byte("v3")},
+	}
+	require.NoError(t, sp.Write(1, 0, 0, 0, []byte{}, []byte{}, diff))
+
+	scanned := adapter.ChainState{}
+	err := sp.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		if _, ok := scanned[contract]; !ok {
+			scanned[contract] = adapter.ContractState{}
+		}
+		scanned[contract][key] = value
+		return true
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, diff, scanned)
+}
+
+func TestFilesystemStatePersistence_ViewIsNotAffectedByLaterWrites(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	sp := openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+
+	require.NoError(t, writeSingleValueBlock(sp, 1, "foo", "k1", "v1"))
+	view, err := sp.View()
+	require.NoError(t, err)
+	defer view.Release()
+	require.NoError(t, writeSingleValueBlock(sp, 2, "foo", "k1", "v2"))
+
+	height, _, _, _, _, _, err := view.ReadMetadata()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, height)
+
+	var values []string
+	require.NoError(t, view.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		values = append(values, string(value))
+		return true
+	}))
+	require.Equal(t, []string{"v1"}, values, "expected the view to hold the records as of the time it was taken")
+}
+
+func TestFilesystemStatePersistence_RejectsMismatchingVirtualChain(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	openPersistence(t, conf).GracefulShutdown(context.Background())
+
+	conf.virtualChainId = 43
+	_, err := NewStatePersistence(conf, log.GetLogger().WithOutput(), metric.NewRegistry())
+	require.Error(t, err, "should not open a state database of another virtual chain")
+}
+
+func TestFilesystemStatePersistence_RejectsConcurrentOpen(t *testing.T) {
+	conf := newTempConfig(t)
+	defer conf.cleanDir()
+	sp := openPersistence(t, conf)
+	defer sp.GracefulShutdown(context.Background())
+
+	_, err := NewStatePersistence(conf, log.GetLogger().WithOutput(), metric.NewRegistry())
+	require.Error(t, err, "state database should be locked while open")
+}
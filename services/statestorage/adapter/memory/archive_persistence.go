@@ -0,0 +1,110 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package memory
+
+import (
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"sort"
+	"sync"
+)
+
+type archivedValue struct {
+	height primitives.BlockHeight
+	value  []byte
+}
+
+type archivedBlockInfo struct {
+	ts       primitives.TimestampNano
+	ref      primitives.TimestampSeconds
+	proposer primitives.NodeAddress
+}
+
+// InMemoryArchivePersistence grows with every committed block and is lost on restart, it is meant for tests and
+// nodes without a data directory
+type InMemoryArchivePersistence struct {
+	mutex   sync.RWMutex
+	records map[primitives.ContractName]map[string][]*archivedValue // ascending by height
+	blocks  []*archivedBlockInfo                                    // blocks[i] is block height i+1
+}
+
+func NewArchivePersistence() *InMemoryArchivePersistence {
+	return &InMemoryArchivePersistence{
+		records: make(map[primitives.ContractName]map[string][]*archivedValue),
+	}
+}
+
+func (ap *InMemoryArchivePersistence) WriteBlock(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, proposer primitives.NodeAddress, diff adapter.ChainState) error {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+
+	if expected := primitives.BlockHeight(len(ap.blocks)) + 1; height != expected {
+		return fmt.Errorf("archive expected block height %d, got %d", expected, height)
+	}
+
+	for contract, records := range diff {
+		contractRecords, ok := ap.records[contract]
+		if !ok {
+			contractRecords = make(map[string][]*archivedValue)
+			ap.records[contract] = contractRecords
+		}
+		for key, value := range records {
+			contractRecords[key] = append(contractRecords[key], &archivedValue{height: height, value: value})
+		}
+	}
+	ap.blocks = append(ap.blocks, &archivedBlockInfo{ts: ts, ref: refTime, proposer: proposer})
+	return nil
+}
+
+func (ap *InMemoryArchivePersistence) ReadRecord(height primitives.BlockHeight, contract primitives.ContractName, key string) ([]byte, bool, error) {
+	ap.mutex.RLock()
+	defer ap.mutex.RUnlock()
+
+	history := ap.records[contract][key]
+	i := sort.Search(len(history), func(i int) bool { return history[i].height > height })
+	if i == 0 {
+		return nil, false, nil
+	}
+	return history[i-1].value, true, nil
+}
+
+func (ap *InMemoryArchivePersistence) ReadBlockInfo(height primitives.BlockHeight) (primitives.TimestampNano, primitives.TimestampSeconds, primitives.NodeAddress, error) {
+	ap.mutex.RLock()
+	defer ap.mutex.RUnlock()
+
+	if height == 0 || int(height) > len(ap.blocks) {
+		return 0, 0, nil, fmt.Errorf("block height %d is not archived", height)
+	}
+	block := ap.blocks[height-1]
+	return block.ts, block.ref, block.proposer, nil
+}
+
+func (ap *InMemoryArchivePersistence) ScanState(height primitives.BlockHeight, cursor adapter.StateCursorFunc) error {
+	ap.mutex.RLock()
+	defer ap.mutex.RUnlock()
+
+	for contract, records := range ap.records {
+		for key, history := range records {
+			i := sort.Search(len(history), func(i int) bool { return history[i].height > height })
+			if i == 0 {
+				continue
+			}
+			if !cursor(contract, key, history[i-1].value) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func (ap *InMemoryArchivePersistence) GetLastBlockHeight() (primitives.BlockHeight, error) {
+	ap.mutex.RLock()
+	defer ap.mutex.RUnlock()
+
+	return primitives.BlockHeight(len(ap.blocks)), nil
+}
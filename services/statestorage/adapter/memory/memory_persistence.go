@@ -117,6 +117,49 @@ func (sp *InMemoryStatePersistence) ReadMetadata() (primitives.BlockHeight, prim
 	return sp.height, sp.ts, sp.refTime, sp.prevRefTime, sp.proposer, sp.merkleRoot, nil
 }
 
+func (sp *InMemoryStatePersistence) ScanState(cursor adapter.StateCursorFunc) error {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	for contract, records := range sp.fullState {
+		for key, value := range records {
+			if !cursor(contract, key, value) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// the view holds a copy of the records, which are never modified in place, so later writes do not affect it
+func (sp *InMemoryStatePersistence) View() (adapter.StateView, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	view := &InMemoryStatePersistence{
+		fullState:   make(adapter.ChainState, len(sp.fullState)),
+		height:      sp.height,
+		ts:          sp.ts,
+		refTime:     sp.refTime,
+		prevRefTime: sp.prevRefTime,
+		proposer:    sp.proposer,
+		merkleRoot:  sp.merkleRoot,
+	}
+	for contract, records := range sp.fullState {
+		view.fullState[contract] = make(adapter.ContractState, len(records))
+		for key, value := range records {
+			view.fullState[contract][key] = value
+		}
+	}
+	return &stateView{view}, nil
+}
+
+type stateView struct {
+	*InMemoryStatePersistence
+}
+
+func (v *stateView) Release() {}
+
 func (sp *InMemoryStatePersistence) Dump() string {
 	sp.mutex.RLock()
 	defer sp.mutex.RUnlock()
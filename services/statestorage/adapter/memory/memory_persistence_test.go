@@ -38,6 +38,27 @@ func TestWriteStateAddAndRemoveKeyFromPersistentStorage(t *testing.T) {
 	require.EqualValues(t, false, ok, "writing zero value to state did not remove key")
 }
 
+func TestViewIsNotAffectedByLaterWrites(t *testing.T) {
+	d := newDriver()
+	require.NoError(t, d.writeSingleValueBlock(1, "foo", "foo", "bar"))
+
+	view, err := d.View()
+	require.NoError(t, err)
+	defer view.Release()
+	require.NoError(t, d.writeSingleValueBlock(2, "foo", "foo", ""))
+
+	height, _, _, _, _, _, err := view.ReadMetadata()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, height)
+
+	var values []string
+	require.NoError(t, view.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		values = append(values, string(value))
+		return true
+	}))
+	require.Equal(t, []string{"bar"}, values, "expected the view to hold the records as of the time it was taken")
+}
+
 type driver struct {
 	*InMemoryStatePersistence
 }
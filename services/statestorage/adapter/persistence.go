@@ -14,8 +14,31 @@ import (
 type ContractState map[string][]byte
 type ChainState map[primitives.ContractName]ContractState
 
+// StateCursorFunc is called once for every persisted state record, return false to stop the scan
+type StateCursorFunc func(contract primitives.ContractName, key string, value []byte) (wantsMore bool)
+
 type StatePersistence interface {
 	Write(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, prevRefTime primitives.TimestampSeconds, proposer primitives.NodeAddress, root primitives.Sha256, diff ChainState) error
 	Read(contract primitives.ContractName, key string) ([]byte, bool, error)
 	ReadMetadata() (primitives.BlockHeight, primitives.TimestampNano, primitives.TimestampSeconds, primitives.TimestampSeconds, primitives.NodeAddress, primitives.Sha256, error)
+	ScanState(cursor StateCursorFunc) error
+	View() (StateView, error)
+}
+
+// StateView is a consistent read-only view of the persisted state as it was when the view was taken, later writes do
+// not affect it. a view must be released once it is no longer read
+type StateView interface {
+	ReadMetadata() (primitives.BlockHeight, primitives.TimestampNano, primitives.TimestampSeconds, primitives.TimestampSeconds, primitives.NodeAddress, primitives.Sha256, error)
+	ScanState(cursor StateCursorFunc) error
+	Release()
+}
+
+// ArchivePersistence keeps the state diff of every committed block so state can be read at any past block height.
+// blocks are written in order starting from block height 1
+type ArchivePersistence interface {
+	WriteBlock(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, proposer primitives.NodeAddress, diff ChainState) error
+	ReadRecord(height primitives.BlockHeight, contract primitives.ContractName, key string) ([]byte, bool, error) // the value last written at or before height
+	ReadBlockInfo(height primitives.BlockHeight) (primitives.TimestampNano, primitives.TimestampSeconds, primitives.NodeAddress, error)
+	ScanState(height primitives.BlockHeight, cursor StateCursorFunc) error // every record as it was at height, deleted records have a zero value
+	GetLastBlockHeight() (primitives.BlockHeight, error)
 }
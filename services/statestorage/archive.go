@@ -0,0 +1,143 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package statestorage
+
+import (
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+)
+
+type GetBlockInfoInput struct {
+	BlockHeight primitives.BlockHeight
+}
+
+type GetBlockInfoOutput struct {
+	BlockHeight          primitives.BlockHeight
+	BlockTimestamp       primitives.TimestampNano
+	CurrentReferenceTime primitives.TimestampSeconds
+	PrevReferenceTime    primitives.TimestampSeconds
+	BlockProposerAddress primitives.NodeAddress
+}
+
+// stateArchive keeps every committed state diff so state can be read at any past block height.
+// it is only allocated in archive mode, the diffs themselves are kept by the archive persistence
+type stateArchive struct {
+	persist adapter.ArchivePersistence
+}
+
+func newStateArchive(persist adapter.ArchivePersistence) *stateArchive {
+	return &stateArchive{
+		persist: persist,
+	}
+}
+
+func (a *stateArchive) getHeight() (primitives.BlockHeight, error) {
+	return a.persist.GetLastBlockHeight()
+}
+
+// the archive is written on every commit while state persistence lags behind by the history window, so after a restart
+// blocks that are already archived are committed again and skipped here
+func (a *stateArchive) add(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, proposer primitives.NodeAddress, diff adapter.ChainState) error {
+	archivedHeight, err := a.getHeight()
+	if err != nil {
+		return err
+	}
+	if height <= archivedHeight {
+		return nil
+	}
+	if height != archivedHeight+1 {
+		return errors.Errorf("archive expected block height %d, got %d", archivedHeight+1, height)
+	}
+	return a.persist.WriteBlock(height, ts, refTime, proposer, diff)
+}
+
+func (a *stateArchive) read(height primitives.BlockHeight, contract primitives.ContractName, key string) ([]byte, bool, error) {
+	archivedHeight, err := a.getHeight()
+	if err != nil {
+		return nil, false, err
+	}
+	if height > archivedHeight {
+		return nil, false, errors.Errorf("requested height %d is not archived yet. most recent archived block height is %d", height, archivedHeight)
+	}
+
+	value, ok, err := a.persist.ReadRecord(height, contract, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return value, !isZeroValue(value), nil
+}
+
+func (a *stateArchive) scan(height primitives.BlockHeight, cursor adapter.StateCursorFunc) error {
+	archivedHeight, err := a.getHeight()
+	if err != nil {
+		return err
+	}
+	if height > archivedHeight {
+		return errors.Errorf("requested height %d is not archived yet. most recent archived block height is %d", height, archivedHeight)
+	}
+	return a.persist.ScanState(height, cursor)
+}
+
+func (a *stateArchive) getBlockInfo(height primitives.BlockHeight) (*GetBlockInfoOutput, error) {
+	archivedHeight, err := a.getHeight()
+	if err != nil {
+		return nil, err
+	}
+	if height == 0 || height > archivedHeight {
+		return nil, errors.Errorf("requested height %d is not archived. most recent archived block height is %d", height, archivedHeight)
+	}
+
+	ts, ref, proposer, err := a.persist.ReadBlockInfo(height)
+	if err != nil {
+		return nil, err
+	}
+	var prevRef primitives.TimestampSeconds
+	if height > 1 {
+		if _, prevRef, _, err = a.persist.ReadBlockInfo(height - 1); err != nil {
+			return nil, err
+		}
+	}
+	return &GetBlockInfoOutput{
+		BlockHeight:          height,
+		BlockTimestamp:       ts,
+		CurrentReferenceTime: ref,
+		PrevReferenceTime:    prevRef,
+		BlockProposerAddress: proposer,
+	}, nil
+}
+
+// blocks committed before state storage booted (restored from persistence or a snapshot) are never replayed, diffs missing from the archive are read from block storage
+func (a *stateArchive) backfill(blocks CommittedResultsBlocks, upTo primitives.BlockHeight) error {
+	archivedHeight, err := a.getHeight()
+	if err != nil {
+		return err
+	}
+	if archivedHeight >= upTo {
+		return nil
+	}
+
+	lastBlockHeight, err := blocks.GetLastBlockHeight()
+	if err != nil {
+		return err
+	}
+	if lastBlockHeight < upTo {
+		return errors.Errorf("cannot archive state up to block height %d, block storage is at block height %d", upTo, lastBlockHeight)
+	}
+
+	for height := archivedHeight + 1; height <= upTo; height++ {
+		block, err := blocks.GetResultsBlock(height)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read results block %d", height)
+		}
+		header := block.Header
+		if err := a.add(height, header.Timestamp(), header.ReferenceTime(), header.BlockProposerAddress(), inflateChainState(block.ContractStateDiffs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
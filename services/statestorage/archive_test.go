@@ -0,0 +1,96 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package statestorage
+
+import (
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStateArchive_BackfillsDiffsFromBlockStorage(t *testing.T) {
+	blocks := &blocksWithDiffs{}
+	for h := 1; h <= 3; h++ {
+		blocks.append(primitives.TimestampNano(h*1000), primitives.TimestampSeconds(h*10), builders.ContractStateDiff().WithContractName("contract1").WithStringRecord("key1", string([]byte{byte(h)})).Build())
+	}
+
+	archive := newStateArchive(memory.NewArchivePersistence())
+	require.NoError(t, archive.backfill(blocks, 2))
+	height, err := archive.getHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, height, "should archive only up to the requested height")
+
+	value, exists, err := archive.read(1, "contract1", "key1")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.EqualValues(t, []byte{1}, value)
+
+	info, err := archive.getBlockInfo(2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2000, info.BlockTimestamp)
+	require.EqualValues(t, 20, info.CurrentReferenceTime)
+	require.EqualValues(t, 10, info.PrevReferenceTime)
+}
+
+func TestStateArchive_BackfillFailsWhenBlockStorageIsBehind(t *testing.T) {
+	blocks := &blocksWithDiffs{}
+	blocks.append(1000, 10, builders.ContractStateDiff().WithContractName("contract1").WithStringRecord("key1", "v1").Build())
+
+	require.Error(t, newStateArchive(memory.NewArchivePersistence()).backfill(blocks, 2))
+}
+
+func TestStateArchive_BackfillStartsFromLastArchivedBlock(t *testing.T) {
+	blocks := &blocksWithDiffs{}
+	for h := 1; h <= 3; h++ {
+		blocks.append(primitives.TimestampNano(h*1000), primitives.TimestampSeconds(h*10), builders.ContractStateDiff().WithContractName("contract1").WithStringRecord("key1", string([]byte{byte(h)})).Build())
+	}
+
+	persistence := memory.NewArchivePersistence()
+	require.NoError(t, newStateArchive(persistence).backfill(blocks, 2))
+
+	blocks.blocks[0] = nil // already archived, must not be read again
+	archive := newStateArchive(persistence)
+	require.NoError(t, archive.backfill(blocks, 3))
+
+	value, exists, err := archive.read(3, "contract1", "key1")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.EqualValues(t, []byte{3}, value)
+}
+
+func TestStateArchive_RejectsNonContiguousHeights(t *testing.T) {
+	archive := newStateArchive(memory.NewArchivePersistence())
+	require.Error(t, archive.add(2, 0, 0, nil, nil), "should not archive a block before its predecessor")
+	require.NoError(t, archive.add(1, 0, 0, nil, nil))
+	require.NoError(t, archive.add(1, 0, 0, nil, nil), "should skip a block that is already archived")
+}
+
+type blocksWithDiffs struct {
+	blocks []*protocol.ResultsBlockContainer
+}
+
+func (b *blocksWithDiffs) append(ts primitives.TimestampNano, refTime primitives.TimestampSeconds, diff *protocol.ContractStateDiff) {
+	b.blocks = append(b.blocks, &protocol.ResultsBlockContainer{
+		Header:             (&protocol.ResultsBlockHeaderBuilder{BlockHeight: primitives.BlockHeight(len(b.blocks) + 1), Timestamp: ts, ReferenceTime: refTime}).Build(),
+		ContractStateDiffs: []*protocol.ContractStateDiff{diff},
+	})
+}
+
+func (b *blocksWithDiffs) GetLastBlockHeight() (primitives.BlockHeight, error) {
+	return primitives.BlockHeight(len(b.blocks)), nil
+}
+
+func (b *blocksWithDiffs) GetResultsBlock(height primitives.BlockHeight) (*protocol.ResultsBlockContainer, error) {
+	if height == 0 || int(height) > len(b.blocks) {
+		return nil, errors.Errorf("block %d not found", height)
+	}
+	return b.blocks[height-1], nil
+}
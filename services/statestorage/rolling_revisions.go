@@ -87,6 +87,10 @@ func (ls *rollingRevisions) getCurrentHeight() primitives.BlockHeight {
 	return ls.currentHeight
 }
 
+func (ls *rollingRevisions) getPersistedHeight() primitives.BlockHeight {
+	return ls.persistedHeight
+}
+
 func (ls *rollingRevisions) getCurrentTimestamp() primitives.TimestampNano {
 	return ls.currentTs
 }
@@ -111,6 +115,12 @@ func (ls *rollingRevisions) getCurrentSize() primitives.StorageSizeMegabyte {
 	return primitives.StorageSizeMegabyte(ls.currentSize / 1048576)
 }
 
+// the storage sizes are counted on every revision but not persisted, they are restored by scanning the persisted state
+func (ls *rollingRevisions) restoreSizes(numKeys primitives.StorageKeys, size uint64) {
+	ls.currentNumKeys = numKeys
+	ls.currentSize = size
+}
+
 func (ls *rollingRevisions) addRevision(height primitives.BlockHeight, ts primitives.TimestampNano, refTime primitives.TimestampSeconds, proposer primitives.NodeAddress, diff adapter.ChainState) error {
 	newRoot, err := ls.merkle.Update(ls.currentMerkleRoot, toMerkleInput(diff))
 	if err != nil {
@@ -238,6 +248,68 @@ func (ls *rollingRevisions) getRevisionHash(height primitives.BlockHeight) (prim
 	return ls.persistedRoot, nil
 }
 
+func (ls *rollingRevisions) getRevisionBlockInfo(height primitives.BlockHeight) (*GetBlockInfoOutput, error) {
+	for i := len(ls.revisions) - 1; i >= 0; i-- {
+		if r := ls.revisions[i]; r.height == height {
+			return &GetBlockInfoOutput{BlockHeight: r.height, BlockTimestamp: r.ts, CurrentReferenceTime: r.ref, PrevReferenceTime: r.prevRef, BlockProposerAddress: r.proposer}, nil
+		}
+	}
+
+	if height != ls.persistedHeight {
+		return nil, errors.Errorf("could not locate block info for height %d. oldest available block height is %d", height, ls.persistedHeight)
+	}
+
+	return &GetBlockInfoOutput{BlockHeight: ls.persistedHeight, BlockTimestamp: ls.persistedTs, CurrentReferenceTime: ls.persistedRefTime, PrevReferenceTime: ls.persistedPrevRefTime, BlockProposerAddress: ls.persistedProposer}, nil
+}
+
+// scanRevisionState feeds cursor the full state at height, the persisted state overlaid by the revisions up to height.
+// records deleted by a revision are fed with a zero value
+func (ls *rollingRevisions) scanRevisionState(height primitives.BlockHeight, cursor adapter.StateCursorFunc) error {
+	if ls.currentHeight < height {
+		return errors.Errorf("requested height %d is too new. most recent available block height is %d", height, ls.currentHeight)
+	}
+	if ls.persistedHeight > height {
+		return errors.Errorf("requested height %d is too old. oldest available block height is %d", height, ls.persistedHeight)
+	}
+
+	overlay := adapter.ChainState{}
+	for _, revision := range ls.revisions {
+		if revision.height > height {
+			break
+		}
+		for contract, records := range revision.diff {
+			if _, exists := overlay[contract]; !exists {
+				overlay[contract] = map[string][]byte{}
+			}
+			for key, value := range records {
+				overlay[contract][key] = value
+			}
+		}
+	}
+
+	wantsMore := true
+	err := ls.persist.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		if _, overridden := overlay[contract][key]; overridden {
+			return true
+		}
+		wantsMore = cursor(contract, key, value)
+		return wantsMore
+	})
+	if err != nil {
+		return err
+	}
+
+	for contract, records := range overlay {
+		for key, value := range records {
+			if !wantsMore {
+				return nil
+			}
+			wantsMore = cursor(contract, key, value)
+		}
+	}
+	return nil
+}
+
 func (ls *rollingRevisions) getRevisionRecordCurrentSize(contract primitives.ContractName, key string) (int, error) {
 	for i := len(ls.revisions) - 1; i >= 0; i-- {
 		if record, exists := ls.revisions[i].diff[contract][key]; exists {
@@ -286,6 +286,12 @@ func (spm *StatePersistenceMock) Read(contract primitives.ContractName, key stri
 func (spm *StatePersistenceMock) ReadMetadata() (primitives.BlockHeight, primitives.TimestampNano, primitives.TimestampSeconds, primitives.TimestampSeconds, primitives.NodeAddress, primitives.Sha256, error) {
 	return 0, 0, 0, 0, []byte{}, primitives.Sha256{}, nil
 }
+func (spm *StatePersistenceMock) ScanState(cursor adapter.StateCursorFunc) error {
+	return nil
+}
+func (spm *StatePersistenceMock) View() (adapter.StateView, error) {
+	return nil, fmt.Errorf("views are not supported by the mock")
+}
 
 type MerkleMock struct {
 	mock.Mock
@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/orbs-network/crypto-lib-go/crypto/merkle"
+	"github.com/orbs-network/govnr"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
@@ -21,17 +22,29 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/scribe/log"
 	"github.com/pkg/errors"
+	"os"
 	"sync"
+	"sync/atomic"
 )
 
 var LogTag = log.Service("state-storage")
 
+// state storage service calls which are not (yet) part of the spec
+type StateStorage interface {
+	services.StateStorage
+	GetStateProof(ctx context.Context, input *GetStateProofInput) (*GetStateProofOutput, error)
+	GetBlockInfo(ctx context.Context, input *GetBlockInfoInput) (*GetBlockInfoOutput, error)
+}
+
 type metrics struct {
 	readKeys       *metric.Rate
 	writeKeys      *metric.Rate
 	blockHeight    *metric.Gauge
 	currentNumKeys *metric.Gauge
 	currentSizeMB  *metric.Gauge
+
+	snapshotHeight   *metric.Gauge
+	snapshotFailures *metric.Gauge
 }
 
 func newMetrics(m metric.Factory) *metrics {
@@ -41,6 +54,9 @@ func newMetrics(m metric.Factory) *metrics {
 		blockHeight:    m.NewGauge("StateStorage.BlockHeight"),
 		currentNumKeys: m.NewGaugeWithValue("StateStorage.CurrentNumKeys", 0),
 		currentSizeMB:  m.NewGaugeWithValue("StateStorage.CurrentSizeMB", 0),
+
+		snapshotHeight:   m.NewGauge("StateStorage.Snapshot.BlockHeight"),
+		snapshotFailures: m.NewGauge("StateStorage.Snapshot.Failures.Count"),
 	}
 }
 
@@ -53,24 +69,118 @@ type service struct {
 
 	mutex     sync.RWMutex
 	revisions *rollingRevisions
+	archive   *stateArchive
+
+	blocks          CommittedResultsBlocks
+	pendingSnapshot *StateSnapshot
+
+	snapshotInProgress int32 // accessed atomically
 }
 
-func NewStateStorage(config config.StateStorageConfig, persistence adapter.StatePersistence, heightReporter adapter.BlockHeightReporter, parent log.Logger, metricFactory metric.Factory) services.StateStorage {
-	forest, _ := merkle.NewForest()
+// blocks are read to validate a state snapshot on boot and in archive mode, to archive the diffs of blocks committed before the persisted state was written.
+// archivePersistence is only used in archive mode
+func NewStateStorage(config config.StateStorageConfig, persistence adapter.StatePersistence, archivePersistence adapter.ArchivePersistence, blocks CommittedResultsBlocks, heightReporter adapter.BlockHeightReporter, parent log.Logger, metricFactory metric.Factory) StateStorage {
+	forest, emptyRoot := merkle.NewForest()
 	logger := parent.WithTags(LogTag)
 	if heightReporter == nil {
 		heightReporter = synchronization.NopHeightReporter{}
 	}
-	return &service{
+
+	var pendingSnapshot *StateSnapshot
+	if blocks != nil {
+		pendingSnapshot = importSnapshotOnBoot(config, persistence, blocks, logger)
+	}
+
+	numKeys, size, err := restoreMerkleForest(forest, emptyRoot, persistence)
+	if err != nil {
+		panic(fmt.Sprintf("could not restore state merkle tree from persistence, err=%s", err.Error()))
+	}
+
+	revisions := newRollingRevisions(logger, persistence, int(config.StateStorageHistorySnapshotNum()), forest)
+	revisions.restoreSizes(numKeys, size)
+	persistedHeight := revisions.getCurrentHeight()
+	if persistedHeight > 0 {
+		logger.Info("resuming state storage from persisted state", logfields.BlockHeight(persistedHeight))
+		heightReporter.IncrementTo(persistedHeight)
+	}
+
+	var archive *stateArchive
+	if config.StateStorageArchiveMode() {
+		if archivePersistence == nil {
+			panic("state storage archive mode requires archive persistence")
+		}
+		archive = newStateArchive(archivePersistence)
+		archivedHeight, err := archive.getHeight()
+		if err != nil {
+			panic(fmt.Sprintf("could not read state archive height, err=%s", err.Error()))
+		}
+		if persistedHeight > archivedHeight {
+			if blocks == nil {
+				panic("state storage archive mode requires block storage to archive persisted state")
+			}
+			if err := archive.backfill(blocks, persistedHeight); err != nil {
+				panic(fmt.Sprintf("could not archive state diffs from block storage, err=%s", err.Error()))
+			}
+			logger.Info("archived state diffs from block storage", log.Uint64("from-block-height", uint64(archivedHeight+1)), logfields.BlockHeight(persistedHeight))
+		}
+	}
+
+	s := &service{
 		config:         config,
-		blockTracker:   synchronization.NewBlockTracker(logger, 0, uint16(config.BlockTrackerGraceDistance())),
+		blockTracker:   synchronization.NewBlockTracker(logger, uint64(persistedHeight), uint16(config.BlockTrackerGraceDistance())),
 		heightReporter: heightReporter,
 		logger:         logger,
 		metrics:        newMetrics(metricFactory),
 
 		mutex:     sync.RWMutex{},
-		revisions: newRollingRevisions(logger, persistence, int(config.StateStorageHistorySnapshotNum()), forest),
+		revisions: revisions,
+		archive:   archive,
+
+		blocks:          blocks,
+		pendingSnapshot: pendingSnapshot,
+	}
+	s.metrics.blockHeight.Update(int64(persistedHeight))
+	s.metrics.currentNumKeys.Update(int64(revisions.getCurrentNumKeys()))
+	s.metrics.currentSizeMB.Update(int64(revisions.getCurrentSize()))
+	return s
+}
+
+// the merkle forest only lives in memory, persisted state must be re-hashed into it before new revisions can be added on top.
+// returns the number of keys and total size of the persisted state, which are not persisted on their own
+func restoreMerkleForest(forest *merkle.Forest, emptyRoot primitives.Sha256, persistence adapter.StatePersistence) (numKeys primitives.StorageKeys, size uint64, err error) {
+	_, _, _, _, _, persistedRoot, err := persistence.ReadMetadata()
+	if err != nil {
+		return 0, 0, err
+	}
+	if persistedRoot.Equal(emptyRoot) {
+		return 0, 0, nil
+	}
+
+	fullState := make(adapter.ChainState)
+	err = persistence.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		if _, ok := fullState[contract]; !ok {
+			fullState[contract] = make(adapter.ContractState)
+		}
+		fullState[contract][key] = value
+		if len(value) > 0 {
+			numKeys++
+			size += uint64(len(value))
+		}
+		return true
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	root, err := forest.Update(emptyRoot, toMerkleInput(fullState))
+	if err != nil {
+		return 0, 0, err
 	}
+	if !root.Equal(persistedRoot) {
+		return 0, 0, errors.Errorf("persisted state merkle root mismatch. calculated %s expected %s", root, persistedRoot)
+	}
+	forest.Forget(emptyRoot)
+	return numKeys, size, nil
 }
 
 func (s *service) CommitStateDiff(ctx context.Context, input *services.CommitStateDiffInput) (*services.CommitStateDiffOutput, error) {
@@ -90,6 +200,10 @@ func (s *service) CommitStateDiff(ctx context.Context, input *services.CommitSta
 
 	logger.Info("trying to commit state diff", logfields.BlockHeight(commitBlockHeight), log.Int("number-of-state-diffs", len(input.ContractStateDiffs)))
 
+	if s.pendingSnapshot != nil {
+		s.importPendingSnapshot(logger)
+	}
+
 	currentHeight := s.revisions.getCurrentHeight()
 	if currentHeight+1 != commitBlockHeight {
 		return &services.CommitStateDiffOutput{NextDesiredBlockHeight: currentHeight + 1}, nil
@@ -97,11 +211,23 @@ func (s *service) CommitStateDiff(ctx context.Context, input *services.CommitSta
 
 	// TODO(v1) assert input.ResultsBlockHeader.PreExecutionStateRootHash() == s.revisions.getRevisionHash(commitBlockHeight - 1)
 
-	err := s.revisions.addRevision(commitBlockHeight, commitTimestamp, commitRefTime, commitPorposerAddress, inflateChainState(input.ContractStateDiffs))
+	persistedHeight := s.revisions.getPersistedHeight()
+	diff := inflateChainState(input.ContractStateDiffs)
+	err := s.revisions.addRevision(commitBlockHeight, commitTimestamp, commitRefTime, commitPorposerAddress, diff)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to write state for block height %d", commitBlockHeight)
 	}
 
+	if s.archive != nil {
+		if err := s.archive.add(commitBlockHeight, commitTimestamp, commitRefTime, commitPorposerAddress, diff); err != nil {
+			return nil, errors.Wrapf(err, "failed to archive state for block height %d", commitBlockHeight)
+		}
+	}
+
+	if s.revisions.getPersistedHeight() != persistedHeight && s.isSnapshotHeight(s.revisions.getPersistedHeight()) {
+		s.writeSnapshot(logger)
+	}
+
 	s.metrics.writeKeys.Measure(int64(len(input.ContractStateDiffs)))
 
 	s.blockTracker.IncrementTo(commitBlockHeight)
@@ -129,13 +255,21 @@ func (s *service) ReadKeys(ctx context.Context, input *services.ReadKeysInput) (
 	defer s.mutex.RUnlock()
 
 	currentHeight := s.revisions.getCurrentHeight()
-	if input.BlockHeight+primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()) <= currentHeight {
+	isArchived := input.BlockHeight+primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()) <= currentHeight
+	if isArchived && s.archive == nil {
 		return nil, errors.Errorf("unsupported block height: block %v too old. currently at %v. keeping %v back", input.BlockHeight, currentHeight, primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()))
 	}
 
 	records := make([]*protocol.StateRecord, 0, len(input.Keys))
 	for _, key := range input.Keys {
-		record, ok, err := s.revisions.getRevisionRecord(input.BlockHeight, input.ContractName, string(key))
+		var record []byte
+		var ok bool
+		var err error
+		if isArchived {
+			record, ok, err = s.archive.read(input.BlockHeight, input.ContractName, string(key))
+		} else {
+			record, ok, err = s.revisions.getRevisionRecord(input.BlockHeight, input.ContractName, string(key))
+		}
 		if err != nil {
 			return nil, errors.Wrap(err, "persistence layer error")
 		}
@@ -196,6 +330,194 @@ func (s *service) GetStateHash(ctx context.Context, input *services.GetStateHash
 	return output, nil
 }
 
+func (s *service) GetStateProof(ctx context.Context, input *GetStateProofInput) (*GetStateProofOutput, error) {
+	if input.ContractName == "" {
+		return nil, errors.Errorf("missing contract name")
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.config.BlockTrackerGraceTimeout())
+	defer cancel()
+	if err := s.blockTracker.WaitForBlock(timeoutCtx, input.BlockHeight); err != nil {
+		return nil, errors.Wrapf(err, "GetStateProof(): unsupported block height: block %d is not yet committed", input.BlockHeight)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	currentHeight := s.revisions.getCurrentHeight()
+	isArchived := input.BlockHeight+primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()) <= currentHeight
+	if isArchived && s.archive == nil {
+		return nil, errors.Errorf("unsupported block height: block %v too old. currently at %v. keeping %v back", input.BlockHeight, currentHeight, primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()))
+	}
+
+	var value []byte
+	var ok bool
+	var err error
+	var scan stateScanner
+	if isArchived {
+		value, ok, err = s.archive.read(input.BlockHeight, input.ContractName, string(input.Key))
+		scan = func(cursor adapter.StateCursorFunc) error {
+			return s.archive.scan(input.BlockHeight, cursor)
+		}
+	} else {
+		value, ok, err = s.revisions.getRevisionRecord(input.BlockHeight, input.ContractName, string(input.Key))
+		scan = func(cursor adapter.StateCursorFunc) error {
+			return s.revisions.scanRevisionState(input.BlockHeight, cursor)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "persistence layer error")
+	}
+	if !ok {
+		value = newZeroValue()
+	}
+
+	proof, root, err := buildStateProof(scan, input.ContractName, input.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not generate a merkle proof for block height %d", input.BlockHeight)
+	}
+	// archived blocks keep no merkle root, the root of the rebuilt trie is only cross checked while the block is in the revision window
+	if !isArchived {
+		expectedRoot, err := s.revisions.getRevisionHash(input.BlockHeight)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find a merkle root for block height %d", input.BlockHeight)
+		}
+		if !root.Equal(expectedRoot) {
+			return nil, errors.Errorf("state rebuilt for block height %d has merkle root %s, expected %s", input.BlockHeight, root, expectedRoot)
+		}
+	}
+
+	s.metrics.readKeys.Measure(1)
+
+	return &GetStateProofOutput{
+		BlockHeight:         input.BlockHeight,
+		Value:               value,
+		StateMerkleRootHash: root,
+		Proof:               proof,
+	}, nil
+}
+
+func (s *service) GetBlockInfo(ctx context.Context, input *GetBlockInfoInput) (*GetBlockInfoOutput, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.config.BlockTrackerGraceTimeout())
+	defer cancel()
+	if err := s.blockTracker.WaitForBlock(timeoutCtx, input.BlockHeight); err != nil {
+		return nil, errors.Wrapf(err, "GetBlockInfo(): unsupported block height: block %d is not yet committed", input.BlockHeight)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.archive != nil {
+		return s.archive.getBlockInfo(input.BlockHeight)
+	}
+
+	currentHeight := s.revisions.getCurrentHeight()
+	if input.BlockHeight+primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()) <= currentHeight {
+		return nil, errors.Errorf("unsupported block height: block %v too old. currently at %v. keeping %v back", input.BlockHeight, currentHeight, primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum()))
+	}
+	return s.revisions.getRevisionBlockInfo(input.BlockHeight)
+}
+
+func (s *service) isSnapshotHeight(height primitives.BlockHeight) bool {
+	interval := primitives.BlockHeight(s.config.StateStorageSnapshotInterval())
+	return interval > 0 && s.config.StateStorageSnapshotFilePath() != "" && height%interval == 0
+}
+
+// snapshots are taken from persistence, which trails the current height. the view is taken while holding the write lock
+// so its state and metadata are consistent, and is written to the file in the background so commits do not wait for it
+func (s *service) writeSnapshot(logger log.Logger) {
+	filename := s.config.StateStorageSnapshotFilePath()
+	if !atomic.CompareAndSwapInt32(&s.snapshotInProgress, 0, 1) {
+		logger.Info("previous state snapshot is still being written, skipping state snapshot", logfields.BlockHeight(s.revisions.getPersistedHeight()), log.String("filename", filename))
+		return
+	}
+
+	view, err := s.revisions.persist.View()
+	if err != nil {
+		atomic.StoreInt32(&s.snapshotInProgress, 0)
+		s.metrics.snapshotFailures.Inc()
+		logger.Error("failed to write state snapshot", log.Error(err), log.String("filename", filename))
+		return
+	}
+
+	govnr.Once(logfields.GovnrErrorer(logger), func() {
+		defer atomic.StoreInt32(&s.snapshotInProgress, 0)
+		defer view.Release()
+
+		snapshot, err := snapshotFromPersistence(view)
+		if err == nil {
+			err = WriteSnapshotFile(filename, snapshot)
+		}
+		if err != nil {
+			s.metrics.snapshotFailures.Inc()
+			logger.Error("failed to write state snapshot", log.Error(err), log.String("filename", filename))
+			return
+		}
+		s.metrics.snapshotHeight.Update(int64(snapshot.Height))
+		logger.Info("wrote state snapshot", logfields.BlockHeight(snapshot.Height), log.String("filename", filename))
+	})
+}
+
+// a snapshot that fails validation is not fatal, state storage falls back to replaying blocks
+func importSnapshotOnBoot(config config.StateStorageConfig, persistence adapter.StatePersistence, blocks CommittedResultsBlocks, logger log.Logger) *StateSnapshot {
+	filename := config.StateStorageSnapshotFilePath()
+	if filename == "" {
+		return nil
+	}
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	pending, err := ImportSnapshotFile(filename, persistence, blocks, logger)
+	if err != nil {
+		logger.Error("failed to import state snapshot, state will be rebuilt from blocks", log.Error(err), log.String("filename", filename))
+		return nil
+	}
+	return pending
+}
+
+// called under the write lock. a snapshot ahead of block storage on boot is imported once block sync commits the block following it,
+// the in-memory revisions are then rebuilt on top of the imported state
+func (s *service) importPendingSnapshot(logger log.Logger) {
+	snapshot := s.pendingSnapshot
+	if s.revisions.getCurrentHeight() >= snapshot.Height {
+		logger.Info("state caught up with the pending state snapshot, dropping it", logfields.BlockHeight(s.revisions.getCurrentHeight()), log.Uint64("snapshot-height", uint64(snapshot.Height)))
+		s.pendingSnapshot = nil
+		return
+	}
+
+	imported, err := importSnapshot(snapshot, s.revisions.persist, s.blocks, logger)
+	if err != nil {
+		logger.Error("failed to import state snapshot, state will be rebuilt from blocks", log.Error(err))
+		s.pendingSnapshot = nil
+		return
+	}
+	if !imported {
+		return
+	}
+	s.pendingSnapshot = nil
+	prevHeight := s.revisions.getCurrentHeight()
+
+	forest, emptyRoot := merkle.NewForest()
+	numKeys, size, err := restoreMerkleForest(forest, emptyRoot, s.revisions.persist)
+	if err != nil {
+		panic(fmt.Sprintf("could not restore state merkle tree from imported state snapshot, err=%s", err.Error()))
+	}
+	s.revisions = newRollingRevisions(s.logger, s.revisions.persist, int(s.config.StateStorageHistorySnapshotNum()), forest)
+	s.revisions.restoreSizes(numKeys, size)
+
+	if s.archive != nil {
+		if err := s.archive.backfill(s.blocks, snapshot.Height); err != nil {
+			panic(fmt.Sprintf("could not archive state diffs from block storage, err=%s", err.Error()))
+		}
+	}
+
+	for height := prevHeight + 1; height <= snapshot.Height; height++ {
+		s.blockTracker.IncrementTo(height)
+		s.heightReporter.IncrementTo(height)
+	}
+	s.metrics.blockHeight.Update(int64(snapshot.Height))
+}
+
 func inflateChainState(csd []*protocol.ContractStateDiff) adapter.ChainState {
 	result := make(adapter.ChainState)
 	for _, stateDiffs := range csd {
@@ -0,0 +1,378 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package statestorage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/merkle"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const snapshotMagic = uint32(0x50414e53) // "SNAP"
+const snapshotVersion = 0
+const maxSnapshotChunkSize = 64 * 1024 * 1024
+
+// CommittedResultsBlocks is the part of block persistence a snapshot is validated against
+type CommittedResultsBlocks interface {
+	GetLastBlockHeight() (primitives.BlockHeight, error)
+	GetResultsBlock(height primitives.BlockHeight) (*protocol.ResultsBlockContainer, error)
+}
+
+// StateSnapshot is the full state of the virtual chain after committing the block at Height
+type StateSnapshot struct {
+	Height            primitives.BlockHeight
+	Timestamp         primitives.TimestampNano
+	ReferenceTime     primitives.TimestampSeconds
+	PrevReferenceTime primitives.TimestampSeconds
+	Proposer          primitives.NodeAddress
+	MerkleRoot        primitives.Sha256
+	State             adapter.ChainState
+}
+
+type snapshotHeader struct {
+	Magic             uint32
+	Version           uint32
+	Height            uint64
+	Timestamp         uint64
+	ReferenceTime     uint32
+	PrevReferenceTime uint32
+	NumContracts      uint32
+}
+
+// implemented by the persistence and by its consistent views
+type persistedState interface {
+	ReadMetadata() (primitives.BlockHeight, primitives.TimestampNano, primitives.TimestampSeconds, primitives.TimestampSeconds, primitives.NodeAddress, primitives.Sha256, error)
+	ScanState(cursor adapter.StateCursorFunc) error
+}
+
+func snapshotFromPersistence(persistence persistedState) (*StateSnapshot, error) {
+	h, ts, ref, prevRef, proposer, root, err := persistence.ReadMetadata()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state metadata")
+	}
+
+	state := make(adapter.ChainState)
+	err = persistence.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		if _, ok := state[contract]; !ok {
+			state[contract] = make(adapter.ContractState)
+		}
+		state[contract][key] = value
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state records")
+	}
+
+	return &StateSnapshot{
+		Height:            h,
+		Timestamp:         ts,
+		ReferenceTime:     ref,
+		PrevReferenceTime: prevRef,
+		Proposer:          proposer,
+		MerkleRoot:        root,
+		State:             state,
+	}, nil
+}
+
+func (s *StateSnapshot) CalcMerkleRoot() (primitives.Sha256, error) {
+	forest, emptyRoot := merkle.NewForest()
+	return forest.Update(emptyRoot, toMerkleInput(s.State))
+}
+
+// Write encodes the snapshot with contracts and keys in lexicographic order, followed by a checksum of the entire content
+func (s *StateSnapshot) Write(w io.Writer) error {
+	checksum := crc32.NewIEEE()
+	sw := &snapshotWriter{w: io.MultiWriter(w, checksum)}
+
+	contracts := make([]string, 0, len(s.State))
+	for contract := range s.State {
+		contracts = append(contracts, string(contract))
+	}
+	sort.Strings(contracts)
+
+	sw.write(&snapshotHeader{
+		Magic:             snapshotMagic,
+		Version:           snapshotVersion,
+		Height:            uint64(s.Height),
+		Timestamp:         uint64(s.Timestamp),
+		ReferenceTime:     uint32(s.ReferenceTime),
+		PrevReferenceTime: uint32(s.PrevReferenceTime),
+		NumContracts:      uint32(len(contracts)),
+	})
+	sw.writeChunk(s.Proposer)
+	sw.writeChunk(s.MerkleRoot)
+
+	for _, contract := range contracts {
+		records := s.State[primitives.ContractName(contract)]
+		keys := make([]string, 0, len(records))
+		for key := range records {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		sw.writeChunk([]byte(contract))
+		sw.write(uint32(len(keys)))
+		for _, key := range keys {
+			sw.writeChunk([]byte(key))
+			sw.writeChunk(records[key])
+		}
+	}
+
+	if sw.err != nil {
+		return errors.Wrap(sw.err, "failed to write state snapshot")
+	}
+
+	return errors.Wrap(binary.Write(w, binary.LittleEndian, checksum.Sum32()), "failed to write state snapshot checksum")
+}
+
+func decodeSnapshot(r io.Reader) (*StateSnapshot, error) {
+	checksum := crc32.NewIEEE()
+	sr := &snapshotReader{r: io.TeeReader(r, checksum)}
+
+	header := &snapshotHeader{}
+	sr.read(header)
+	if sr.err == nil && header.Magic != snapshotMagic {
+		return nil, fmt.Errorf("invalid state snapshot magic number %v", header.Magic)
+	}
+	if sr.err == nil && header.Version != snapshotVersion {
+		return nil, fmt.Errorf("invalid state snapshot version %d", header.Version)
+	}
+
+	result := &StateSnapshot{
+		Height:            primitives.BlockHeight(header.Height),
+		Timestamp:         primitives.TimestampNano(header.Timestamp),
+		ReferenceTime:     primitives.TimestampSeconds(header.ReferenceTime),
+		PrevReferenceTime: primitives.TimestampSeconds(header.PrevReferenceTime),
+		Proposer:          sr.readChunk(),
+		MerkleRoot:        sr.readChunk(),
+		State:             make(adapter.ChainState),
+	}
+
+	for i := uint32(0); i < header.NumContracts && sr.err == nil; i++ {
+		contract := primitives.ContractName(sr.readChunk())
+		var numRecords uint32
+		sr.read(&numRecords)
+		records := make(adapter.ContractState)
+		for j := uint32(0); j < numRecords && sr.err == nil; j++ {
+			key := string(sr.readChunk())
+			records[key] = sr.readChunk()
+		}
+		result.State[contract] = records
+	}
+
+	if sr.err != nil {
+		return nil, errors.Wrap(sr.err, "failed to read state snapshot")
+	}
+
+	expectedChecksum := checksum.Sum32()
+	var actualChecksum uint32
+	if err := binary.Read(r, binary.LittleEndian, &actualChecksum); err != nil {
+		return nil, errors.Wrap(err, "failed to read state snapshot checksum")
+	}
+	if actualChecksum != expectedChecksum {
+		return nil, fmt.Errorf("state snapshot checksum mismatch. found %x expected %x", actualChecksum, expectedChecksum)
+	}
+
+	return result, nil
+}
+
+// WriteSnapshotFile replaces the snapshot file atomically so a crash never leaves a partial snapshot behind
+func WriteSnapshotFile(filename string, snapshot *StateSnapshot) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to verify snapshot directory exists %s", dir)
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(filename)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary snapshot file")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	if err := snapshot.Write(w); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write snapshot file")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to sync snapshot file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close snapshot file")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), filename), "failed to replace snapshot file")
+}
+
+func ReadSnapshotFile(filename string) (*StateSnapshot, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open snapshot file %s", filename)
+	}
+	defer func() { _ = file.Close() }()
+
+	return decodeSnapshot(bufio.NewReader(file))
+}
+
+// ImportSnapshotFile loads a snapshot into persistence only after its merkle root matches the pre-execution
+// state root of the committed block following it. If block sync did not commit that block yet, the snapshot is
+// returned as pending and should be imported with importSnapshot once it does. Older snapshots than the persisted state are ignored
+func ImportSnapshotFile(filename string, persistence adapter.StatePersistence, blocks CommittedResultsBlocks, parent log.Logger) (*StateSnapshot, error) {
+	logger := parent.WithTags(LogTag, log.String("filename", filename))
+
+	snapshot, err := ReadSnapshotFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	persistedHeight, _, _, _, _, _, err := persistence.ReadMetadata()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state metadata")
+	}
+	if persistedHeight >= snapshot.Height {
+		logger.Info("persisted state is up to date, ignoring state snapshot", logfields.BlockHeight(persistedHeight), log.Uint64("snapshot-height", uint64(snapshot.Height)))
+		return nil, nil
+	}
+
+	root, err := snapshot.CalcMerkleRoot()
+	if err != nil {
+		return nil, err
+	}
+	if !root.Equal(snapshot.MerkleRoot) {
+		return nil, errors.Errorf("state snapshot for block height %d is not trusted: merkle root mismatch. calculated %s expected %s", snapshot.Height, root, snapshot.MerkleRoot)
+	}
+
+	imported, err := importSnapshot(snapshot, persistence, blocks, logger)
+	if err != nil {
+		return nil, err
+	}
+	if !imported {
+		logger.Info("state snapshot is ahead of block storage, it will be imported once the following block is synced", log.Uint64("snapshot-height", uint64(snapshot.Height)))
+		return snapshot, nil
+	}
+	return nil, nil
+}
+
+// the snapshot is trusted only through the committed block following it, which block storage accepted after validating its consensus proof.
+// returns false if that block is not committed yet
+func importSnapshot(snapshot *StateSnapshot, persistence adapter.StatePersistence, blocks CommittedResultsBlocks, logger log.Logger) (bool, error) {
+	lastCommitted, err := blocks.GetLastBlockHeight()
+	if err != nil {
+		return false, err
+	}
+	if lastCommitted <= snapshot.Height {
+		return false, nil
+	}
+
+	nextBlock, err := blocks.GetResultsBlock(snapshot.Height + 1)
+	if err != nil {
+		return false, err
+	}
+	if expected := nextBlock.Header.PreExecutionStateMerkleRootHash(); !bytes.Equal(expected, snapshot.MerkleRoot) {
+		return false, errors.Errorf("state snapshot for block height %d is not trusted: merkle root %s does not match pre execution state root %s of block %d", snapshot.Height, snapshot.MerkleRoot, expected, snapshot.Height+1)
+	}
+
+	diff, err := diffFromPersistedState(snapshot.State, persistence)
+	if err != nil {
+		return false, err
+	}
+
+	err = persistence.Write(snapshot.Height, snapshot.Timestamp, snapshot.ReferenceTime, snapshot.PrevReferenceTime, snapshot.Proposer, snapshot.MerkleRoot, diff)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to write state snapshot to persistence")
+	}
+
+	logger.Info("imported state snapshot", logfields.BlockHeight(snapshot.Height))
+	return true, nil
+}
+
+// keys not in the snapshot are removed by writing the zero value
+func diffFromPersistedState(state adapter.ChainState, persistence adapter.StatePersistence) (adapter.ChainState, error) {
+	diff := make(adapter.ChainState)
+	for contract, records := range state {
+		diff[contract] = make(adapter.ContractState)
+		for key, value := range records {
+			diff[contract][key] = value
+		}
+	}
+
+	err := persistence.ScanState(func(contract primitives.ContractName, key string, value []byte) bool {
+		if _, exists := state[contract][key]; !exists {
+			if _, ok := diff[contract]; !ok {
+				diff[contract] = make(adapter.ContractState)
+			}
+			diff[contract][key] = newZeroValue()
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read persisted state records")
+	}
+	return diff, nil
+}
+
+type snapshotWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *snapshotWriter) write(data interface{}) {
+	if sw.err == nil {
+		sw.err = binary.Write(sw.w, binary.LittleEndian, data)
+	}
+}
+
+func (sw *snapshotWriter) writeChunk(chunk []byte) {
+	sw.write(uint32(len(chunk)))
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(chunk)
+	}
+}
+
+type snapshotReader struct {
+	r   io.Reader
+	err error
+}
+
+func (sr *snapshotReader) read(data interface{}) {
+	if sr.err == nil {
+		sr.err = binary.Read(sr.r, binary.LittleEndian, data)
+	}
+}
+
+func (sr *snapshotReader) readChunk() []byte {
+	var size uint32
+	sr.read(&size)
+	if sr.err == nil && size > maxSnapshotChunkSize {
+		sr.err = fmt.Errorf("state snapshot chunk of %d bytes exceeds maximum size", size)
+	}
+	if sr.err != nil {
+		return nil
+	}
+	chunk := make([]byte, size)
+	_, sr.err = io.ReadFull(sr.r, chunk)
+	return chunk
+}
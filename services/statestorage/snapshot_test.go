@@ -0,0 +1,234 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package statestorage
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStateSnapshot_EncodeDecodeRoundTrip(t *testing.T) {
+	snapshot := aSnapshot(t, 7)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, snapshot.Write(buf))
+
+	decoded, err := decodeSnapshot(buf)
+	require.NoError(t, err)
+	require.EqualValues(t, snapshot, decoded)
+}
+
+func TestStateSnapshot_DecodeDetectsCorruption(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, aSnapshot(t, 7).Write(buf))
+
+	raw := buf.Bytes()
+	raw[len(raw)-10] ^= 0xff
+
+	_, err := decodeSnapshot(bytes.NewReader(raw))
+	require.Error(t, err, "corrupted snapshot should fail checksum validation")
+}
+
+func TestImportSnapshotFile_WritesValidatedSnapshotToPersistence(t *testing.T) {
+	snapshot := aSnapshot(t, 7)
+	filename := writeTempSnapshotFile(t, snapshot)
+	defer func() { _ = os.Remove(filename) }()
+
+	persistence := memory.NewStatePersistence(metric.NewRegistry())
+	require.NoError(t, persistence.Write(1, 0, 0, 0, []byte{}, []byte{}, adapter.ChainState{"stale": {"key": []byte("value")}}))
+
+	pending, err := ImportSnapshotFile(filename, persistence, committedBlocksWithPreExecutionRoot(8, snapshot.MerkleRoot), log.GetLogger().WithOutput())
+	require.NoError(t, err)
+	require.Nil(t, pending, "snapshot should not remain pending once imported")
+
+	imported, err := snapshotFromPersistence(persistence)
+	require.NoError(t, err)
+	require.EqualValues(t, snapshot, imported, "persistence should hold exactly the snapshot state")
+}
+
+func TestImportSnapshotFile_RejectsSnapshotNotMatchingCommittedBlock(t *testing.T) {
+	snapshot := aSnapshot(t, 7)
+	filename := writeTempSnapshotFile(t, snapshot)
+	defer func() { _ = os.Remove(filename) }()
+
+	persistence := memory.NewStatePersistence(metric.NewRegistry())
+	_, err := ImportSnapshotFile(filename, persistence, committedBlocksWithPreExecutionRoot(8, []byte{1, 2, 3}), log.GetLogger().WithOutput())
+	require.Error(t, err)
+
+	pending, err := ImportSnapshotFile(filename, persistence, committedBlocksWithPreExecutionRoot(7, snapshot.MerkleRoot), log.GetLogger().WithOutput())
+	require.NoError(t, err)
+	require.NotNil(t, pending, "snapshot cannot be validated before the following block is committed")
+
+	h, _, _, _, _, _, _ := persistence.ReadMetadata()
+	require.EqualValues(t, 0, h, "untrusted snapshot should not be written")
+}
+
+func TestImportSnapshotFile_RejectsTamperedState(t *testing.T) {
+	snapshot := aSnapshot(t, 7)
+	trustedRoot := snapshot.MerkleRoot
+	snapshot.State["contract1"]["key1"] = []byte("tampered")
+	filename := writeTempSnapshotFile(t, snapshot)
+	defer func() { _ = os.Remove(filename) }()
+
+	_, err := ImportSnapshotFile(filename, memory.NewStatePersistence(metric.NewRegistry()), committedBlocksWithPreExecutionRoot(8, trustedRoot), log.GetLogger().WithOutput())
+	require.Error(t, err)
+}
+
+func TestStateStorageImportsPendingSnapshotOnceFollowingBlockIsSynced(t *testing.T) {
+	snapshot := aSnapshot(t, 7)
+	filename := writeTempSnapshotFile(t, snapshot)
+	defer func() { _ = os.Remove(filename) }()
+
+	blocks := committedBlocksWithPreExecutionRoot(1, snapshot.MerkleRoot)
+	cfg := &snapshotConfig{snapshotFilePath: filename}
+	s := NewStateStorage(cfg, memory.NewStatePersistence(metric.NewRegistry()), nil, blocks, nil, log.GetLogger().WithOutput(), metric.NewRegistry())
+
+	ctx := context.Background()
+	out, err := s.CommitStateDiff(ctx, &services.CommitStateDiffInput{
+		ResultsBlockHeader: (&protocol.ResultsBlockHeaderBuilder{BlockHeight: 1}).Build(),
+		ContractStateDiffs: []*protocol.ContractStateDiff{},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, out.NextDesiredBlockHeight, "snapshot should not be imported before block storage has the following block")
+
+	blocks.lastHeight = 8
+	out, err = s.CommitStateDiff(ctx, &services.CommitStateDiffInput{
+		ResultsBlockHeader: (&protocol.ResultsBlockHeaderBuilder{BlockHeight: 2}).Build(),
+		ContractStateDiffs: []*protocol.ContractStateDiff{},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 8, out.NextDesiredBlockHeight, "state should resume from the imported snapshot")
+
+	records, err := s.ReadKeys(ctx, &services.ReadKeysInput{BlockHeight: 7, ContractName: "contract1", Keys: [][]byte{[]byte("key1")}})
+	require.NoError(t, err)
+	require.EqualValues(t, []byte("v1"), records.StateRecords[0].Value())
+}
+
+func TestStateStorageWritesSnapshotsPeriodically(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_snapshot")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cfg := &snapshotConfig{snapshotFilePath: filepath.Join(dir, "state.snapshot")}
+	s := NewStateStorage(cfg, memory.NewStatePersistence(metric.NewRegistry()), nil, nil, nil, log.GetLogger().WithOutput(), metric.NewRegistry())
+
+	ctx := context.Background()
+	for h := 1; h <= 6; h++ {
+		diff := builders.ContractStateDiff().WithContractName("contract1").WithStringRecord("key1", string([]byte{byte(h)})).Build()
+		_, err := s.CommitStateDiff(ctx, &services.CommitStateDiffInput{
+			ResultsBlockHeader: (&protocol.ResultsBlockHeaderBuilder{BlockHeight: primitives.BlockHeight(h)}).Build(),
+			ContractStateDiffs: []*protocol.ContractStateDiff{diff},
+		})
+		require.NoError(t, err)
+		waitForSnapshotToBeWritten(t, s)
+	}
+
+	// persistence trails the current height by one revision, so the last snapshot taken is of height 4
+	snapshot, err := ReadSnapshotFile(cfg.snapshotFilePath)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, snapshot.Height)
+	require.EqualValues(t, []byte{4}, snapshot.State["contract1"]["key1"])
+}
+
+func waitForSnapshotToBeWritten(t *testing.T, s StateStorage) {
+	require.True(t, test.Eventually(time.Second, func() bool {
+		return atomic.LoadInt32(&s.(*service).snapshotInProgress) == 0
+	}), "expected the state snapshot to be written in the background")
+}
+
+func aSnapshot(t *testing.T, height primitives.BlockHeight) *StateSnapshot {
+	snapshot := &StateSnapshot{
+		Height:            height,
+		Timestamp:         primitives.TimestampNano(time.Now().UnixNano()),
+		ReferenceTime:     20,
+		PrevReferenceTime: 19,
+		Proposer:          []byte{0xaa, 0xbb},
+		State: adapter.ChainState{
+			"contract1": {"key1": []byte("v1"), "key2": []byte("v2")},
+			"contract2": {"key1": []byte("v3")},
+		},
+	}
+	root, err := snapshot.CalcMerkleRoot()
+	require.NoError(t, err)
+	snapshot.MerkleRoot = root
+	return snapshot
+}
+
+func writeTempSnapshotFile(t *testing.T, snapshot *StateSnapshot) string {
+	file, err := ioutil.TempFile("", "state_snapshot")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.NoError(t, WriteSnapshotFile(file.Name(), snapshot))
+	return file.Name()
+}
+
+type committedBlocks struct {
+	lastHeight primitives.BlockHeight
+	root       primitives.Sha256
+}
+
+func committedBlocksWithPreExecutionRoot(lastHeight primitives.BlockHeight, root primitives.Sha256) *committedBlocks {
+	return &committedBlocks{lastHeight: lastHeight, root: root}
+}
+
+func (c *committedBlocks) GetLastBlockHeight() (primitives.BlockHeight, error) {
+	return c.lastHeight, nil
+}
+
+func (c *committedBlocks) GetResultsBlock(height primitives.BlockHeight) (*protocol.ResultsBlockContainer, error) {
+	if height > c.lastHeight {
+		return nil, errors.Errorf("block %d not found", height)
+	}
+	return &protocol.ResultsBlockContainer{
+		Header: (&protocol.ResultsBlockHeaderBuilder{BlockHeight: height, PreExecutionStateMerkleRootHash: c.root}).Build(),
+	}, nil
+}
+
+type snapshotConfig struct {
+	snapshotFilePath string
+}
+
+func (c *snapshotConfig) StateStorageHistorySnapshotNum() uint32 {
+	return 1
+}
+
+func (c *snapshotConfig) StateStorageSnapshotInterval() uint32 {
+	return 2
+}
+
+func (c *snapshotConfig) StateStorageSnapshotFilePath() string {
+	return c.snapshotFilePath
+}
+
+func (c *snapshotConfig) StateStorageArchiveMode() bool {
+	return false
+}
+
+func (c *snapshotConfig) BlockTrackerGraceDistance() uint32 {
+	return 0
+}
+
+func (c *snapshotConfig) BlockTrackerGraceTimeout() time.Duration {
+	return 0
+}
@@ -0,0 +1,240 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package statestorage
+
+import (
+	"bytes"
+	"github.com/orbs-network/crypto-lib-go/crypto/hash"
+	"github.com/orbs-network/crypto-lib-go/crypto/merkle"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+type GetStateProofInput struct {
+	BlockHeight  primitives.BlockHeight
+	ContractName primitives.ContractName
+	Key          []byte
+}
+
+type GetStateProofOutput struct {
+	BlockHeight         primitives.BlockHeight
+	Value               []byte
+	StateMerkleRootHash primitives.Sha256
+	Proof               *StateProof
+}
+
+type StateProofNode struct {
+	OtherChildHash primitives.Sha256
+	PrefixSize     int
+}
+
+// StateProof is a serializable merkle trie proof for the key sha256(contract, key), in the layout of merkle.TrieProof.
+// an empty value is proven by exclusion, in which case ExtraHashLeft/ExtraHashRight describe the node where the path diverged
+type StateProof struct {
+	Nodes          []*StateProofNode
+	Path           []byte // bit path the proof was built on, packed 8 bits per byte
+	ExtraHashLeft  []byte
+	ExtraHashRight []byte
+}
+
+// stateScanner feeds every record of the state at a single block height to cursor
+type stateScanner func(cursor adapter.StateCursorFunc) error
+
+type trieLeaf struct {
+	path      primitives.Sha256 // sha256(contract, key), read bit by bit
+	valueHash primitives.Sha256
+}
+
+// buildStateProof produces the proof merkle.Forest.GetProof would return for contract/key, and the root it proves against,
+// without holding the trie. the state trie is canonical for a given set of records, so it is rebuilt from the full state
+// at the block height. this costs O(records) hashing per proof
+func buildStateProof(scan stateScanner, contract primitives.ContractName, key []byte) (*StateProof, primitives.Sha256, error) {
+	leaves, err := collectTrieLeaves(scan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := hash.CalcSha256([]byte(contract), key)
+	path := unpackBits(target)
+	proof := &StateProof{Nodes: []*StateProofNode{}}
+	if len(leaves) == 0 { // an empty trie proves every key absent with no nodes
+		proof.Path = packBits(path)
+		return proof, hash.CalcSha256(merkle.GetZeroValueHash()), nil
+	}
+	root := hashTrie(leaves, 0)
+
+	depth := 0
+	for {
+		if len(leaves) == 1 {
+			proof.Nodes = append(proof.Nodes, &StateProofNode{OtherChildHash: hashTrie(leaves, depth), PrefixSize: len(path) - depth})
+			proof.ExtraHashLeft = copyBytes(leaves[0].valueHash)
+			copy(path[depth:], bitRange(leaves[0].path, depth, len(path)))
+			break
+		}
+
+		branch := branchIndex(leaves, depth)
+		split := splitIndex(leaves, branch)
+		prefix := bitRange(leaves[0].path, depth, branch)
+		if !bytes.Equal(path[depth:branch], prefix) { // the key diverges inside this node's prefix, prove its absence here
+			left, right := hashTrie(leaves[:split], branch+1), hashTrie(leaves[split:], branch+1)
+			proof.Nodes = append(proof.Nodes, &StateProofNode{OtherChildHash: hash.CalcSha256(left, right, prefix), PrefixSize: branch - depth})
+			proof.ExtraHashLeft = left
+			proof.ExtraHashRight = right
+			copy(path[depth:branch], prefix)
+			break
+		}
+
+		onPath, other := leaves[:split], leaves[split:]
+		if path[branch] == 1 {
+			onPath, other = other, onPath
+		}
+		proof.Nodes = append(proof.Nodes, &StateProofNode{OtherChildHash: hashTrie(other, branch+1), PrefixSize: branch - depth})
+		leaves = onPath
+		depth = branch + 1
+	}
+
+	proof.Path = packBits(path)
+	return proof, root, nil
+}
+
+// absent keys and zero values are not part of the trie
+func collectTrieLeaves(scan stateScanner) ([]*trieLeaf, error) {
+	var leaves []*trieLeaf
+	err := scan(func(contract primitives.ContractName, key string, value []byte) bool {
+		if !isZeroValue(value) {
+			leaves = append(leaves, &trieLeaf{path: hash.CalcSha256([]byte(contract), []byte(key)), valueHash: hash.CalcSha256(value)})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan state for merkle proof")
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].path, leaves[j].path) < 0
+	})
+	return leaves, nil
+}
+
+// hashTrie hashes the sub trie holding leaves, all of which share their first depth bits.
+// a leaf hashes its value with the rest of its path, an inner node hashes both children with its prefix
+func hashTrie(leaves []*trieLeaf, depth int) primitives.Sha256 {
+	if len(leaves) == 1 {
+		return hash.CalcSha256(leaves[0].valueHash, bitRange(leaves[0].path, depth, len(leaves[0].path)*8))
+	}
+	branch := branchIndex(leaves, depth)
+	split := splitIndex(leaves, branch)
+	return hash.CalcSha256(hashTrie(leaves[:split], branch+1), hashTrie(leaves[split:], branch+1), bitRange(leaves[0].path, depth, branch))
+}
+
+// leaves are sorted, so the first bit where the first and last leaf differ is where the sub trie branches
+func branchIndex(leaves []*trieLeaf, depth int) int {
+	first, last := leaves[0].path, leaves[len(leaves)-1].path
+	for bitAt(first, depth) == bitAt(last, depth) {
+		depth++
+	}
+	return depth
+}
+
+func splitIndex(leaves []*trieLeaf, branch int) int {
+	return sort.Search(len(leaves), func(i int) bool {
+		return bitAt(leaves[i].path, branch) == 1
+	})
+}
+
+func bitAt(packed []byte, i int) byte {
+	return 1 & (packed[i/8] >> uint(7-i%8))
+}
+
+func bitRange(packed []byte, from int, to int) []byte {
+	bits := make([]byte, to-from)
+	for i := range bits {
+		bits[i] = bitAt(packed, from+i)
+	}
+	return bits
+}
+
+// Verify checks that the proof ties value (an empty value meaning the key is absent) of contract/key to root.
+// it mirrors merkle.Forest.Verify so light clients can verify proofs without holding a forest
+func (p *StateProof) Verify(root primitives.Sha256, contract primitives.ContractName, key []byte, value []byte) (bool, error) {
+	valueHash := hash.CalcSha256(value)
+	zeroValueHash := merkle.GetZeroValueHash()
+	if len(p.Nodes) == 0 {
+		return valueHash.Equal(zeroValueHash), nil
+	}
+
+	keyPath := unpackBits(hash.CalcSha256([]byte(contract), key))
+	lastNode := p.Nodes[len(p.Nodes)-1]
+	lastNodePathIndex := 0
+	for _, node := range p.Nodes[:len(p.Nodes)-1] {
+		lastNodePathIndex += node.PrefixSize + 1
+	}
+	if lastNode.PrefixSize < 0 || lastNodePathIndex+lastNode.PrefixSize > len(keyPath) {
+		return false, errors.New("proof is longer than the key path")
+	}
+
+	if !p.isSelfConsistent(root, keyPath, lastNodePathIndex) {
+		return false, errors.New("proof is not self consistent with given key")
+	}
+
+	if !valueHash.Equal(zeroValueHash) {
+		return bytes.Equal(lastNode.OtherChildHash, hash.CalcSha256(valueHash, keyPath[lastNodePathIndex:])), nil
+	}
+
+	proofPath := unpackBits(p.Path)
+	if len(proofPath) != len(keyPath) {
+		return false, errors.New("proof length is not consistent with given key length")
+	}
+	lastNodePrefix := proofPath[lastNodePathIndex : lastNodePathIndex+lastNode.PrefixSize]
+	var lastNodeHash primitives.Sha256
+	if p.ExtraHashRight != nil {
+		lastNodeHash = hash.CalcSha256(p.ExtraHashLeft, p.ExtraHashRight, lastNodePrefix)
+	} else {
+		lastNodeHash = hash.CalcSha256(p.ExtraHashLeft, lastNodePrefix)
+	}
+	return lastNodeHash.Equal(lastNode.OtherChildHash) &&
+		bytes.Equal(proofPath[:lastNodePathIndex], keyPath[:lastNodePathIndex]) &&
+		!bytes.Equal(lastNodePrefix, keyPath[lastNodePathIndex:]), nil
+}
+
+func (p *StateProof) isSelfConsistent(root primitives.Sha256, keyPath []byte, lastNodePathIndex int) bool {
+	keyStart := lastNodePathIndex
+	currentHash := p.Nodes[len(p.Nodes)-1].OtherChildHash
+	for i := len(p.Nodes) - 2; i >= 0; i-- {
+		keyEnd := keyStart - 1
+		keyStart = keyEnd - p.Nodes[i].PrefixSize
+		if p.Nodes[i].PrefixSize < 0 || keyStart < 0 || keyEnd >= len(keyPath) {
+			return false
+		}
+		if keyPath[keyEnd] == 0 {
+			currentHash = hash.CalcSha256(currentHash, p.Nodes[i].OtherChildHash, keyPath[keyStart:keyEnd])
+		} else {
+			currentHash = hash.CalcSha256(p.Nodes[i].OtherChildHash, currentHash, keyPath[keyStart:keyEnd])
+		}
+	}
+	return currentHash.Equal(root)
+}
+
+func packBits(bits []byte) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		packed[i/8] |= (bit & 1) << uint(7-i%8)
+	}
+	return packed
+}
+
+func unpackBits(packed []byte) []byte {
+	return bitRange(packed, 0, len(packed)*8)
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
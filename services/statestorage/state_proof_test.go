@@ -0,0 +1,93 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package statestorage
+
+import (
+	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/hash"
+	"github.com/orbs-network/crypto-lib-go/crypto/merkle"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStateProofAgreesWithMerkleForest(t *testing.T) {
+	state := adapter.ChainState{"contract1": {}, "contract2": {}}
+	for i := 0; i < 50; i++ {
+		state["contract1"][fmt.Sprintf("key%d", i)] = []byte(fmt.Sprintf("value%d", i))
+		state["contract2"][fmt.Sprintf("key%d", i*3)] = []byte(fmt.Sprintf("other%d", i))
+	}
+	state["contract2"]["deleted"] = []byte{}
+	forest, emptyRoot := merkle.NewForest()
+	root, err := forest.Update(emptyRoot, toMerkleInput(state))
+	require.NoError(t, err)
+
+	for _, contract := range []primitives.ContractName{"contract1", "contract2", "contract3"} {
+		for i := 0; i < 100; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			value := state[contract][string(key)]
+			path := hash.CalcSha256([]byte(contract), key)
+
+			trieProof, err := forest.GetProof(root, path)
+			require.NoError(t, err)
+			expected, err := forest.Verify(root, trieProof, path, hash.CalcSha256(value))
+			require.NoError(t, err)
+			require.True(t, expected, "forest should verify its own proof for %s/%s", contract, key)
+
+			proof, proofRoot, err := buildStateProof(scannerOf(state), contract, key)
+			require.NoError(t, err)
+			require.Equal(t, root, proofRoot, "rebuilt trie should have the forest root")
+			verified, err := proof.Verify(root, contract, key, value)
+			require.NoError(t, err)
+			require.True(t, verified, "state proof should verify against the forest root for %s/%s", contract, key)
+
+			verified, _ = proof.Verify(root, contract, key, []byte("tampered"))
+			require.False(t, verified, "state proof should not verify a tampered value for %s/%s", contract, key)
+		}
+	}
+}
+
+func TestStateProofOfSmallTries(t *testing.T) {
+	for _, state := range []adapter.ChainState{
+		{},
+		{"contract1": {"key1": []byte("value1")}},
+		{"contract1": {"key1": []byte("value1"), "key2": []byte("value2")}},
+	} {
+		forest, emptyRoot := merkle.NewForest()
+		root, err := forest.Update(emptyRoot, toMerkleInput(state))
+		require.NoError(t, err)
+
+		for _, key := range []string{"key1", "key2", "key3"} {
+			value := state["contract1"][key]
+			proof, proofRoot, err := buildStateProof(scannerOf(state), "contract1", []byte(key))
+			require.NoError(t, err)
+			require.Equal(t, root, proofRoot, "rebuilt trie should have the forest root")
+			verified, err := proof.Verify(root, "contract1", []byte(key), value)
+			require.NoError(t, err)
+			require.True(t, verified, "state proof should verify for %s in a trie of %d contracts", key, len(state))
+		}
+	}
+}
+
+func TestStateProofPathPacking(t *testing.T) {
+	packed := []byte{0xA5, 0x01, 0xFF}
+	require.Equal(t, packed, packBits(unpackBits(packed)))
+}
+
+func scannerOf(state adapter.ChainState) stateScanner {
+	return func(cursor adapter.StateCursorFunc) error {
+		for contract, records := range state {
+			for key, value := range records {
+				if !cursor(contract, key, value) {
+					return nil
+				}
+			}
+		}
+		return nil
+	}
+}
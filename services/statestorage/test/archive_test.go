@@ -0,0 +1,74 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestArchiveModeReadsKeysBeyondRevisionWindow(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewArchiveStateStorageDriver(1)
+		d.CommitValuePairsAtHeight(ctx, 1, "foo", "bar", "v1", "baz", "b1")
+		d.CommitValuePairsAtHeight(ctx, 2, "foo", "bar", "v2")
+		d.CommitValuePairsAtHeight(ctx, 3, "foo", "bar", "")
+		d.CommitValuePairsAtHeight(ctx, 4, "foo", "baz", "b4")
+
+		for height, expected := range map[int]string{1: "v1", 2: "v2", 3: "", 4: ""} {
+			value, err := d.ReadSingleKeyFromRevision(ctx, height, "foo", "bar")
+			require.NoError(t, err, "expected height %d to be readable in archive mode", height)
+			require.EqualValues(t, expected, value, "unexpected value at height %d", height)
+		}
+
+		value, err := d.ReadSingleKeyFromRevision(ctx, 3, "foo", "baz")
+		require.NoError(t, err)
+		require.EqualValues(t, "b1", value, "expected an untouched key to keep its last written value")
+	})
+}
+
+func TestArchiveModeReturnsBlockInfoOfPastHeights(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewArchiveStateStorageDriver(1)
+		for h := 1; h <= 3; h++ {
+			diff := builders.ContractStateDiff().WithContractName("foo").WithStringRecord("bar", "baz").Build()
+			_, err := d.CommitStateDiff(ctx, CommitStateDiff().WithBlockHeight(h).WithBlockTimestamp(h*1000).WithDiff(diff).Build())
+			require.NoError(t, err)
+		}
+
+		output, err := d.service.GetBlockInfo(ctx, &statestorage.GetBlockInfoInput{BlockHeight: 1})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, output.BlockHeight)
+		require.EqualValues(t, 1000, output.BlockTimestamp)
+
+		_, err = d.service.GetBlockInfo(ctx, &statestorage.GetBlockInfoInput{BlockHeight: 4})
+		require.Error(t, err, "expected a future height to fail")
+	})
+}
+
+func TestWithoutArchiveModeReadingBeyondRevisionWindowFails(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewStateStorageDriver(1)
+		for h := 1; h <= 4; h++ {
+			d.CommitValuePairsAtHeight(ctx, h, "foo", "bar", "baz")
+		}
+
+		_, err := d.ReadSingleKeyFromRevision(ctx, 1, "foo", "bar")
+		require.Error(t, err, "expected a height out of the revision window to fail")
+
+		_, err = d.service.GetBlockInfo(ctx, &statestorage.GetBlockInfoInput{BlockHeight: 1})
+		require.Error(t, err, "expected block info out of the revision window to fail")
+
+		output, err := d.service.GetBlockInfo(ctx, &statestorage.GetBlockInfoInput{BlockHeight: 4})
+		require.NoError(t, err)
+		require.EqualValues(t, 4, output.BlockHeight)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
 	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -21,7 +22,7 @@ import (
 )
 
 type Driver struct {
-	service services.StateStorage
+	service statestorage.StateStorage
 }
 
 type keyValue struct {
@@ -38,13 +39,23 @@ func newStateStorageDriverWithGrace(numOfStateRevisionsToRetain uint32, graceBlo
 		numOfStateRevisionsToRetain = 1
 	}
 
-	cfg := config.ForStateStorageTest(numOfStateRevisionsToRetain, graceBlockDiff, graceTimeoutMillis)
 	registry := metric.NewRegistry()
+	return newStateStorageDriverWithPersistence(numOfStateRevisionsToRetain, graceBlockDiff, graceTimeoutMillis, memory.NewStatePersistence(registry), registry)
+}
+
+func newStateStorageDriverWithPersistence(numOfStateRevisionsToRetain uint32, graceBlockDiff uint32, graceTimeoutMillis uint64, p adapter.StatePersistence, registry metric.Registry) *Driver {
+	cfg := config.ForStateStorageTest(numOfStateRevisionsToRetain, graceBlockDiff, graceTimeoutMillis)
+	logger := log.GetLogger().WithOutput() // a mute logger
+
+	return &Driver{service: statestorage.NewStateStorage(cfg, p, nil, nil, nil, logger, registry)}
+}
 
-	p := memory.NewStatePersistence(registry)
+func NewArchiveStateStorageDriver(numOfStateRevisionsToRetain uint32) *Driver {
+	registry := metric.NewRegistry()
+	cfg := config.ForStateStorageArchiveTest(numOfStateRevisionsToRetain)
 	logger := log.GetLogger().WithOutput() // a mute logger
 
-	return &Driver{service: statestorage.NewStateStorage(cfg, p, nil, logger, registry)}
+	return &Driver{service: statestorage.NewStateStorage(cfg, memory.NewStatePersistence(registry), memory.NewArchivePersistence(), nil, nil, logger, registry)}
 }
 
 func (d *Driver) ReadSingleKey(ctx context.Context, contract string, key string) ([]byte, error) {
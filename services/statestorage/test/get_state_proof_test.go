@@ -0,0 +1,91 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetStateProofProvesInclusionOfExistingKey(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewStateStorageDriver(5)
+		d.CommitValuePairsAtHeight(ctx, 1, "foo", "bar", "baz", "qux", "quux")
+		d.CommitValuePairsAtHeight(ctx, 2, "foo", "bar", "new-baz")
+
+		output, err := d.service.GetStateProof(ctx, &statestorage.GetStateProofInput{BlockHeight: 1, ContractName: "foo", Key: []byte("bar")})
+		require.NoError(t, err)
+		require.EqualValues(t, "baz", output.Value, "expected the value at the requested height")
+
+		root, err := d.service.GetStateHash(ctx, &services.GetStateHashInput{BlockHeight: 1})
+		require.NoError(t, err)
+		require.EqualValues(t, root.StateMerkleRootHash, output.StateMerkleRootHash)
+
+		verified, err := output.Proof.Verify(root.StateMerkleRootHash, "foo", []byte("bar"), output.Value)
+		require.NoError(t, err)
+		require.True(t, verified, "expected proof to verify against the state root")
+
+		verified, _ = output.Proof.Verify(root.StateMerkleRootHash, "foo", []byte("bar"), []byte("new-baz"))
+		require.False(t, verified, "expected proof not to verify a different value")
+	})
+}
+
+func TestGetStateProofProvesExclusionOfMissingKey(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewStateStorageDriver(5)
+		d.CommitValuePairsAtHeight(ctx, 1, "foo", "bar", "baz", "qux", "quux")
+
+		output, err := d.service.GetStateProof(ctx, &statestorage.GetStateProofInput{BlockHeight: 1, ContractName: "foo", Key: []byte("missing")})
+		require.NoError(t, err)
+		require.Empty(t, output.Value, "expected zero value for a missing key")
+
+		verified, err := output.Proof.Verify(output.StateMerkleRootHash, "foo", []byte("missing"), output.Value)
+		require.NoError(t, err)
+		require.True(t, verified, "expected proof of exclusion to verify")
+
+		verified, _ = output.Proof.Verify(output.StateMerkleRootHash, "foo", []byte("missing"), []byte("baz"))
+		require.False(t, verified, "expected proof of exclusion not to verify a value")
+	})
+}
+
+func TestGetStateProofFailsForHeightOutOfHistory(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewStateStorageDriver(1)
+		d.CommitValuePairsAtHeight(ctx, 1, "foo", "bar", "baz")
+		d.CommitValuePairsAtHeight(ctx, 2, "foo", "bar", "qux")
+
+		_, err := d.service.GetStateProof(ctx, &statestorage.GetStateProofInput{BlockHeight: 1, ContractName: "foo", Key: []byte("bar")})
+		require.Error(t, err, "expected an error for a height which is no longer kept")
+	})
+}
+
+func TestGetStateProofOfArchivedHeightMatchesItsStateRoot(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		d := NewArchiveStateStorageDriver(1)
+		d.CommitValuePairsAtHeight(ctx, 1, "foo", "bar", "baz", "qux", "quux")
+		root, err := d.service.GetStateHash(ctx, &services.GetStateHashInput{BlockHeight: 1})
+		require.NoError(t, err)
+
+		d.CommitValuePairsAtHeight(ctx, 2, "foo", "bar", "new-baz")
+		d.CommitValuePairsAtHeight(ctx, 3, "foo", "qux", "")
+
+		for key, expected := range map[string]string{"bar": "baz", "qux": "quux", "missing": ""} {
+			output, err := d.service.GetStateProof(ctx, &statestorage.GetStateProofInput{BlockHeight: 1, ContractName: "foo", Key: []byte(key)})
+			require.NoError(t, err, "expected archived height to be provable")
+			require.EqualValues(t, expected, output.Value, "expected the value at the archived height")
+			require.EqualValues(t, root.StateMerkleRootHash, output.StateMerkleRootHash, "expected the root the block had while in the revision window")
+
+			verified, err := output.Proof.Verify(root.StateMerkleRootHash, "foo", []byte(key), output.Value)
+			require.NoError(t, err)
+			require.True(t, verified, "expected proof of %s to verify against the archived state root", key)
+		}
+	})
+}
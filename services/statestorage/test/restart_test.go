@@ -0,0 +1,85 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter/filesystem"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/scribe/log"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type filesystemStateConfig struct {
+	dir string
+}
+
+func (c *filesystemStateConfig) StateStorageFileSystemDataDir() string {
+	return c.dir
+}
+
+func (c *filesystemStateConfig) VirtualChainId() primitives.VirtualChainId {
+	return 42
+}
+
+func (c *filesystemStateConfig) NetworkType() protocol.SignerNetworkType {
+	return protocol.NETWORK_TYPE_TEST_NET
+}
+
+func TestStateStorageResumesFromPersistedStateAfterRestart(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		dir, err := ioutil.TempDir("", "state_storage_restart")
+		require.NoError(t, err)
+		defer func() { _ = os.RemoveAll(dir) }()
+		conf := &filesystemStateConfig{dir: dir}
+
+		p, err := filesystem.NewStatePersistence(conf, log.GetLogger().WithOutput(), metric.NewRegistry())
+		require.NoError(t, err)
+		d := newStateStorageDriverWithPersistence(1, 0, 0, p, metric.NewRegistry())
+		for h := 1; h <= 4; h++ {
+			_, err := d.CommitValuePairsAtHeight(ctx, h, "contract1", "key1", string([]byte{byte(h)}), "key2", "v2")
+			require.NoError(t, err)
+		}
+		hashBeforeRestart, err := d.service.GetStateHash(ctx, &services.GetStateHashInput{BlockHeight: 4})
+		require.NoError(t, err)
+		_, err = d.CommitValuePairsAtHeight(ctx, 5, "contract1", "key1", string([]byte{5}))
+		require.NoError(t, err)
+		p.GracefulShutdown(ctx)
+
+		p, err = filesystem.NewStatePersistence(conf, log.GetLogger().WithOutput(), metric.NewRegistry())
+		require.NoError(t, err)
+		defer p.GracefulShutdown(ctx)
+		d = newStateStorageDriverWithPersistence(1, 0, 0, p, metric.NewRegistry())
+
+		height, _, err := d.GetBlockHeightAndTimestamp(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 4, height, "state storage should resume from the last evicted revision")
+
+		hashAfterRestart, err := d.service.GetStateHash(ctx, &services.GetStateHashInput{BlockHeight: 4})
+		require.NoError(t, err)
+		require.EqualValues(t, hashBeforeRestart.StateMerkleRootHash, hashAfterRestart.StateMerkleRootHash, "merkle root should be restored from persisted state")
+
+		info, err := d.service.GetLastCommittedBlockInfo(ctx, &services.GetLastCommittedBlockInfoInput{})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, info.CurrentNumKeys, "storage key count should be restored from persisted state")
+
+		value, err := d.ReadSingleKey(ctx, "contract1", "key1")
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{4}, value)
+
+		output, err := d.CommitValuePairsAtHeight(ctx, 5, "contract1", "key1", string([]byte{5}))
+		require.NoError(t, err)
+		require.EqualValues(t, 6, output.NextDesiredBlockHeight, "should accept the first missing block after restart")
+	})
+}
@@ -43,6 +43,8 @@ func (s *service) CommitTransactionReceipts(ctx context.Context, input *services
 
 	s.notifyHandlers(ctx, c)
 
+	s.restorePendingTransactions(logger)
+
 	transactionReceiptsCount := len(input.TransactionReceipts)
 	s.metrics.commitRate.Measure(int64(transactionReceiptsCount))
 	s.metrics.commitCount.Add(int64(transactionReceiptsCount))
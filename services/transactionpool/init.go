@@ -33,6 +33,11 @@ func NewTransactionPool(ctx context.Context,
 	parent log.Logger,
 	metricFactory metric.Factory) *service {
 
+	ordering, err := newOrderingPolicy(config.TransactionPoolOrderingPolicy(), config.TransactionPoolPriorityContracts())
+	if err != nil {
+		panic(err.Error())
+	}
+
 	if blockHeightReporter == nil {
 		blockHeightReporter = synchronization.NopHeightReporter{}
 	}
@@ -69,8 +74,20 @@ func NewTransactionPool(ctx context.Context,
 	s.metrics.commitRate = metricFactory.NewRate("TransactionPool.CommitRate")
 	s.metrics.commitCount = metricFactory.NewGauge("TransactionPool.TotalCommits.Count")
 
-	gossip.RegisterTransactionRelayHandler(s)
 	pendingPool.onTransactionRemoved = s.onTransactionError
+	pendingPool.ordering = ordering
+	pendingPool.maxTransactionsPerSigner = config.TransactionPoolPendingPoolMaxTransactionsPerSigner
+	pendingPool.maxTransactionsPerContract = config.TransactionPoolPendingPoolMaxTransactionsPerContract
+
+	if filename := config.TransactionPoolPendingPoolJournalFilePath(); filename != "" {
+		journal, err := openPendingPoolJournal(filename, logger)
+		if err != nil {
+			panic(err.Error())
+		}
+		pendingPool.journal = journal
+	}
+
+	gossip.RegisterTransactionRelayHandler(s)
 
 	s.Supervise(startCleaningProcess(ctx, "committed pool", config.TransactionPoolCommittedPoolClearExpiredInterval, config.TransactionExpirationWindow, s.committedPool, s.lastCommittedBlockInfo, logger))
 	s.Supervise(startCleaningProcess(ctx, "pending pool", config.TransactionPoolPendingPoolClearExpiredInterval, config.TransactionExpirationWindow, s.pendingPool, s.lastCommittedBlockInfo, logger))
@@ -0,0 +1,83 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/signers"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"time"
+)
+
+// PendingTransaction is a transaction waiting in the pending pool along with the node it entered the network through
+type PendingTransaction struct {
+	SignedTransaction  *protocol.SignedTransaction
+	Txhash             primitives.Sha256
+	GatewayNodeAddress primitives.NodeAddress
+	TimeAdded          time.Time
+}
+
+type ListPendingTransactionsInput struct {
+	Offset          uint32
+	Limit           uint32
+	SignerPublicKey []byte                  // empty matches all signers
+	ContractName    primitives.ContractName // empty matches all contracts
+}
+
+type ListPendingTransactionsOutput struct {
+	TotalCount          uint32 // pending transactions matching the filter across all pages
+	PendingTransactions []*PendingTransaction
+}
+
+type GetPendingTransactionInput struct {
+	Txhash primitives.Sha256
+}
+
+type GetPendingTransactionOutput struct {
+	PendingTransaction *PendingTransaction // nil unless the transaction is pending
+}
+
+// ListPendingTransactions pages through the pending transactions in arrival order
+func (s *service) ListPendingTransactions(ctx context.Context, input *ListPendingTransactionsInput) (*ListPendingTransactionsOutput, error) {
+	total, page := s.pendingPool.inspectPage(input.Offset, input.Limit, func(tx *protocol.SignedTransaction) bool {
+		if len(input.SignerPublicKey) > 0 {
+			if publicKey, supported := signerPublicKeyOf(tx); !supported || !bytes.Equal(publicKey, input.SignerPublicKey) {
+				return false
+			}
+		}
+		if input.ContractName != "" && tx.Transaction().ContractName() != input.ContractName {
+			return false
+		}
+		return true
+	})
+
+	return &ListPendingTransactionsOutput{
+		TotalCount:          total,
+		PendingTransactions: page,
+	}, nil
+}
+
+func (s *service) GetPendingTransaction(ctx context.Context, input *GetPendingTransactionInput) (*GetPendingTransactionOutput, error) {
+	return &GetPendingTransactionOutput{
+		PendingTransaction: s.pendingPool.inspect(input.Txhash),
+	}, nil
+}
+
+// signerPublicKeyOf returns false for signer schemes it does not support, which no signer filter matches
+func signerPublicKeyOf(tx *protocol.SignedTransaction) (publicKey []byte, supported bool) {
+	signer := signers.Of(tx.Transaction().Signer())
+	switch signer.Scheme() {
+	case signers.SIGNER_SCHEME_EDDSA:
+		return tx.Transaction().Signer().Eddsa().SignerPublicKey(), true
+	case signers.SIGNER_SCHEME_ECDSA_SECP256K1:
+		return signer.EcdsaSecp256K1().SignerPublicKey(), true
+	default:
+		return nil, false
+	}
+}
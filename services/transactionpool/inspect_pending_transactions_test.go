@@ -0,0 +1,93 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestListPendingTransactions_PagesThroughTransactionsInArrivalOrder(t *testing.T) {
+	s := &service{pendingPool: makePendingPool()}
+	tx1 := builders.TransferTransaction().Build()
+	tx2 := builders.TransferTransaction().Build()
+	tx3 := builders.TransferTransaction().Build()
+	add(s.pendingPool, tx1, tx2, tx3)
+
+	out, err := s.ListPendingTransactions(context.Background(), &ListPendingTransactionsInput{Offset: 1, Limit: 1})
+
+	require.NoError(t, err)
+	require.EqualValues(t, 3, out.TotalCount)
+	require.Len(t, out.PendingTransactions, 1)
+	require.Equal(t, tx2, out.PendingTransactions[0].SignedTransaction)
+	require.Equal(t, digest.CalcTxHash(tx2.Transaction()), out.PendingTransactions[0].Txhash)
+	require.Equal(t, nodeAddress, out.PendingTransactions[0].GatewayNodeAddress)
+}
+
+func TestListPendingTransactions_FiltersBySignerAndContract(t *testing.T) {
+	s := &service{pendingPool: makePendingPool()}
+	signer := keys.Ed25519KeyPairForTests(1)
+	match := builders.TransferTransaction().WithEd25519Signer(signer).WithContract("Contract").Build()
+	otherContract := builders.TransferTransaction().WithEd25519Signer(signer).WithContract("OtherContract").Build()
+	otherSigner := builders.TransferTransaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(2)).WithContract("Contract").Build()
+	add(s.pendingPool, otherContract, match, otherSigner)
+
+	out, err := s.ListPendingTransactions(context.Background(), &ListPendingTransactionsInput{Limit: 10, SignerPublicKey: signer.PublicKey(), ContractName: "Contract"})
+
+	require.NoError(t, err)
+	require.EqualValues(t, 1, out.TotalCount)
+	require.Equal(t, match, out.PendingTransactions[0].SignedTransaction)
+}
+
+func TestListPendingTransactions_FiltersByEcdsaSecp256K1Signer(t *testing.T) {
+	s := &service{pendingPool: makePendingPool()}
+	signer := keys.EcdsaSecp256K1KeyPairForTests(1)
+	match := builders.TransferTransaction().WithEcdsaSecp256K1Signer(signer.EcdsaSecp256K1KeyPair).Build()
+	add(s.pendingPool, builders.TransferTransaction().Build(), match)
+
+	out, err := s.ListPendingTransactions(context.Background(), &ListPendingTransactionsInput{Limit: 10, SignerPublicKey: signer.PublicKey()})
+
+	require.NoError(t, err)
+	require.EqualValues(t, 1, out.TotalCount)
+	require.Equal(t, match, out.PendingTransactions[0].SignedTransaction)
+}
+
+func TestListPendingTransactions_SignerFilterDoesNotMatchUnsupportedSignerSchemes(t *testing.T) {
+	s := &service{pendingPool: makePendingPool()}
+	unsupported := builders.TransferTransaction().WithInvalidSignerScheme().Build()
+	add(s.pendingPool, unsupported)
+
+	_, supported := signerPublicKeyOf(unsupported)
+	require.False(t, supported)
+
+	out, err := s.ListPendingTransactions(context.Background(), &ListPendingTransactionsInput{Limit: 10, SignerPublicKey: []byte{}})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, out.TotalCount, "expected an empty signer filter to match all signers")
+
+	out, err = s.ListPendingTransactions(context.Background(), &ListPendingTransactionsInput{Limit: 10, SignerPublicKey: keys.Ed25519KeyPairForTests(1).PublicKey()})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, out.TotalCount, "expected a signer filter not to match a transaction of an unsupported signer scheme")
+}
+
+func TestGetPendingTransaction_ReturnsGatewayAndTimeAdded(t *testing.T) {
+	s := &service{pendingPool: makePendingPool()}
+	tx := builders.TransferTransaction().Build()
+	add(s.pendingPool, tx)
+
+	out, err := s.GetPendingTransaction(context.Background(), &GetPendingTransactionInput{Txhash: digest.CalcTxHash(tx.Transaction())})
+	require.NoError(t, err)
+	require.Equal(t, nodeAddress, out.PendingTransaction.GatewayNodeAddress)
+	require.False(t, out.PendingTransaction.TimeAdded.IsZero())
+
+	out, err = s.GetPendingTransaction(context.Background(), &GetPendingTransactionInput{Txhash: digest.CalcTxHash(builders.TransferTransaction().Build().Transaction())})
+	require.NoError(t, err)
+	require.Nil(t, out.PendingTransaction, "expected a transaction which is not pending not to be found")
+}
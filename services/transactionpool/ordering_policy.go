@@ -0,0 +1,239 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"container/list"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// orderingPolicy picks the order in which pending transactions are proposed for ordering. the pool keeps it up to date
+// with every transaction added and removed, so it holds its queues ready and ordering a batch only visits the
+// transactions it proposes. it passes them to take, which returns false once the batch is full
+type orderingPolicy interface {
+	added(tx *protocol.SignedTransaction)
+	removed(tx *protocol.SignedTransaction)
+	order(take func(tx *protocol.SignedTransaction) bool)
+}
+
+// priorityContracts is a comma separated list of contract names, highest priority first. it is only used by the priority policy
+func newOrderingPolicy(name string, priorityContracts string) (orderingPolicy, error) {
+	switch name {
+	case config.TRANSACTION_POOL_ORDERING_POLICY_FIFO:
+		return newFifoOrdering(), nil
+	case config.TRANSACTION_POOL_ORDERING_POLICY_CONTRACT_FAIRNESS:
+		return newRoundRobinOrdering(contractOf), nil
+	case config.TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN:
+		return newRoundRobinOrdering(signerOf), nil
+	case config.TRANSACTION_POOL_ORDERING_POLICY_PRIORITY:
+		return newPriorityOrdering(priorityContracts)
+	default:
+		return nil, errors.Errorf("unknown transaction pool ordering policy %q", name)
+	}
+}
+
+// transactionQueue holds transactions in arrival order and removes any of them in constant time
+type transactionQueue struct {
+	transactions *list.List
+	elements     map[*protocol.SignedTransaction]*list.Element
+}
+
+type queuedTransaction struct {
+	tx      *protocol.SignedTransaction
+	arrival uint64
+}
+
+func newTransactionQueue() *transactionQueue {
+	return &transactionQueue{transactions: list.New(), elements: make(map[*protocol.SignedTransaction]*list.Element)}
+}
+
+func (q *transactionQueue) push(tx *protocol.SignedTransaction, arrival uint64) {
+	q.elements[tx] = q.transactions.PushBack(&queuedTransaction{tx: tx, arrival: arrival})
+}
+
+func (q *transactionQueue) remove(tx *protocol.SignedTransaction) bool {
+	e, found := q.elements[tx]
+	if found {
+		q.transactions.Remove(e)
+		delete(q.elements, tx)
+	}
+	return found
+}
+
+func (q *transactionQueue) oldestArrival() uint64 {
+	return q.transactions.Front().Value.(*queuedTransaction).arrival
+}
+
+// takeAll passes the queued transactions to take in arrival order, returning false once take does
+func (q *transactionQueue) takeAll(take func(tx *protocol.SignedTransaction) bool) bool {
+	for e := q.transactions.Front(); e != nil; e = e.Next() {
+		if !take(e.Value.(*queuedTransaction).tx) {
+			return false
+		}
+	}
+	return true
+}
+
+// proposes transactions in arrival order
+type fifoOrdering struct {
+	queue *transactionQueue
+}
+
+func newFifoOrdering() *fifoOrdering {
+	return &fifoOrdering{queue: newTransactionQueue()}
+}
+
+func (o *fifoOrdering) added(tx *protocol.SignedTransaction) {
+	o.queue.push(tx, 0)
+}
+
+func (o *fifoOrdering) removed(tx *protocol.SignedTransaction) {
+	o.queue.remove(tx)
+}
+
+func (o *fifoOrdering) order(take func(tx *protocol.SignedTransaction) bool) {
+	o.queue.takeAll(take)
+}
+
+// groups the pending transactions by a key, such as their contract or signer, and lets the groups take turns so a single
+// key flooding the pool cannot starve the others. each group is proposed in arrival order and groups take their turns
+// in the arrival order of their oldest transaction
+type roundRobinOrdering struct {
+	keyOf    func(tx *protocol.SignedTransaction) string
+	arrivals uint64
+	groups   *list.List // of *roundRobinGroup, by the arrival of their oldest transaction
+	byKey    map[string]*list.Element
+}
+
+type roundRobinGroup struct {
+	key   string
+	queue *transactionQueue
+}
+
+func newRoundRobinOrdering(keyOf func(tx *protocol.SignedTransaction) string) *roundRobinOrdering {
+	return &roundRobinOrdering{keyOf: keyOf, groups: list.New(), byKey: make(map[string]*list.Element)}
+}
+
+func (o *roundRobinOrdering) added(tx *protocol.SignedTransaction) {
+	o.arrivals++
+	key := o.keyOf(tx)
+	e, found := o.byKey[key]
+	if !found { // its oldest transaction is the newest of all
+		e = o.groups.PushBack(&roundRobinGroup{key: key, queue: newTransactionQueue()})
+		o.byKey[key] = e
+	}
+	e.Value.(*roundRobinGroup).queue.push(tx, o.arrivals)
+}
+
+func (o *roundRobinOrdering) removed(tx *protocol.SignedTransaction) {
+	e, found := o.byKey[o.keyOf(tx)]
+	if !found {
+		return
+	}
+	group := e.Value.(*roundRobinGroup)
+	if !group.queue.remove(tx) {
+		return
+	}
+	if group.queue.transactions.Len() == 0 {
+		o.groups.Remove(e)
+		delete(o.byKey, group.key)
+		return
+	}
+
+	// the oldest transaction of the group may have been removed, moving its turn behind groups with older transactions
+	oldest := group.queue.oldestArrival()
+	next := e.Next()
+	for next != nil && next.Value.(*roundRobinGroup).queue.oldestArrival() < oldest {
+		next = next.Next()
+	}
+	if next == nil {
+		o.groups.MoveToBack(e)
+	} else if next != e.Next() {
+		o.groups.MoveBefore(e, next)
+	}
+}
+
+func (o *roundRobinOrdering) order(take func(tx *protocol.SignedTransaction) bool) {
+	// the first turn walks the groups until the batch is full, later turns walk only the groups with more transactions
+	var nextInGroups []*list.Element
+	for g := o.groups.Front(); g != nil; g = g.Next() {
+		e := g.Value.(*roundRobinGroup).queue.transactions.Front()
+		if !take(e.Value.(*queuedTransaction).tx) {
+			return
+		}
+		if e.Next() != nil {
+			nextInGroups = append(nextInGroups, e.Next())
+		}
+	}
+
+	for len(nextInGroups) > 0 {
+		remaining := nextInGroups[:0]
+		for _, e := range nextInGroups {
+			if !take(e.Value.(*queuedTransaction).tx) {
+				return
+			}
+			if e.Next() != nil {
+				remaining = append(remaining, e.Next())
+			}
+		}
+		nextInGroups = remaining
+	}
+}
+
+// proposes the transactions of the priority contracts first, in the order the contracts are listed, followed by all
+// other transactions. transactions of the same priority are proposed in arrival order
+type priorityOrdering struct {
+	priorities map[string]int      // lower is proposed first
+	levels     []*transactionQueue // the last level holds all other contracts
+}
+
+func newPriorityOrdering(priorityContracts string) (*priorityOrdering, error) {
+	o := &priorityOrdering{priorities: make(map[string]int)}
+	for _, contract := range strings.Split(priorityContracts, ",") {
+		contract = strings.TrimSpace(contract)
+		if contract == "" {
+			continue
+		}
+		if _, found := o.priorities[contract]; !found {
+			o.priorities[contract] = len(o.priorities)
+		}
+	}
+	if len(o.priorities) == 0 {
+		return nil, errors.Errorf("transaction pool ordering policy %q requires priority contracts", config.TRANSACTION_POOL_ORDERING_POLICY_PRIORITY)
+	}
+	for i := 0; i <= len(o.priorities); i++ {
+		o.levels = append(o.levels, newTransactionQueue())
+	}
+	return o, nil
+}
+
+func (o *priorityOrdering) levelOf(tx *protocol.SignedTransaction) *transactionQueue {
+	level, found := o.priorities[contractOf(tx)]
+	if !found {
+		level = len(o.priorities)
+	}
+	return o.levels[level]
+}
+
+func (o *priorityOrdering) added(tx *protocol.SignedTransaction) {
+	o.levelOf(tx).push(tx, 0)
+}
+
+func (o *priorityOrdering) removed(tx *protocol.SignedTransaction) {
+	o.levelOf(tx).remove(tx)
+}
+
+func (o *priorityOrdering) order(take func(tx *protocol.SignedTransaction) bool) {
+	for _, level := range o.levels {
+		if !level.takeAll(take) {
+			return
+		}
+	}
+}
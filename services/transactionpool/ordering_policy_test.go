@@ -0,0 +1,128 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func makePendingPoolOrderedBy(t *testing.T, policy string) *pendingTxPool {
+	return makePendingPoolOrderedByPriority(t, policy, "")
+}
+
+func makePendingPoolOrderedByPriority(t *testing.T, policy string, priorityContracts string) *pendingTxPool {
+	p := makePendingPool()
+	ordering, err := newOrderingPolicy(policy, priorityContracts)
+	require.NoError(t, err)
+	p.ordering = ordering
+	return p
+}
+
+func TestPendingTransactionPool_FifoOrderingReturnsTransactionsInArrivalOrder(t *testing.T) {
+	p := makePendingPoolOrderedBy(t, config.TRANSACTION_POOL_ORDERING_POLICY_FIFO)
+	tx1 := builders.TransferTransaction().Build()
+	tx2 := builders.TransferTransaction().Build()
+	tx3 := builders.TransferTransaction().Build()
+	add(p, tx1, tx2, tx3)
+
+	require.Equal(t, Transactions{tx1, tx2}, p.getBatch(2, 0))
+}
+
+func TestPendingTransactionPool_SignerRoundRobinOrderingLetsSignersTakeTurns(t *testing.T) {
+	p := makePendingPoolOrderedBy(t, config.TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN)
+	spammer := keys.Ed25519KeyPairForTests(1)
+	other := keys.Ed25519KeyPairForTests(2)
+
+	spam1 := builders.TransferTransaction().WithEd25519Signer(spammer).Build()
+	spam2 := builders.TransferTransaction().WithEd25519Signer(spammer).Build()
+	spam3 := builders.TransferTransaction().WithEd25519Signer(spammer).Build()
+	otherTx := builders.TransferTransaction().WithEd25519Signer(other).Build()
+	add(p, spam1, spam2, spam3, otherTx)
+
+	require.Equal(t, Transactions{spam1, otherTx, spam2}, p.getBatch(3, 0), "expected the signers to take turns")
+	require.Equal(t, Transactions{spam1, otherTx, spam2, spam3}, p.getBatch(10, 0))
+}
+
+func TestPendingTransactionPool_ContractFairnessOrderingLetsContractsTakeTurns(t *testing.T) {
+	p := makePendingPoolOrderedBy(t, config.TRANSACTION_POOL_ORDERING_POLICY_CONTRACT_FAIRNESS)
+	busy1 := builders.TransferTransaction().WithContract("BusyContract").Build()
+	busy2 := builders.TransferTransaction().WithContract("BusyContract").Build()
+	quiet := builders.TransferTransaction().WithContract("QuietContract").Build()
+	add(p, busy1, busy2, quiet)
+
+	require.Equal(t, Transactions{busy1, quiet}, p.getBatch(2, 0), "expected the contracts to take turns")
+}
+
+func TestPendingTransactionPool_RoundRobinOrderingStopsAtTheSizeLimit(t *testing.T) {
+	p := makePendingPoolOrderedBy(t, config.TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN)
+	tx1 := builders.TransferTransaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(1)).Build()
+	tx2 := builders.TransferTransaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(2)).Build()
+	add(p, tx1, tx2)
+
+	require.Equal(t, Transactions{tx1}, p.getBatch(10, sizeOfSignedTransaction(tx1)+1))
+}
+
+func TestPendingTransactionPool_RoundRobinOrderingGivesTurnsByTheOldestPendingTransaction(t *testing.T) {
+	p := makePendingPoolOrderedBy(t, config.TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN)
+	first := keys.Ed25519KeyPairForTests(1)
+	second := keys.Ed25519KeyPairForTests(2)
+
+	first1 := builders.TransferTransaction().WithEd25519Signer(first).Build()
+	second1 := builders.TransferTransaction().WithEd25519Signer(second).Build()
+	first2 := builders.TransferTransaction().WithEd25519Signer(first).Build()
+	add(p, first1, second1, first2)
+	p.remove(context.Background(), digest.CalcTxHash(first1.Transaction()), protocol.TRANSACTION_STATUS_COMMITTED)
+
+	require.Equal(t, Transactions{second1, first2}, p.getBatch(10, 0), "expected a signer to lose its turn once its oldest transaction is removed")
+}
+
+func TestOrderingPolicy_VisitsOnlyTheTransactionsOfTheBatch(t *testing.T) {
+	for _, policy := range []string{config.TRANSACTION_POOL_ORDERING_POLICY_FIFO, config.TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN, config.TRANSACTION_POOL_ORDERING_POLICY_PRIORITY} {
+		ordering, err := newOrderingPolicy(policy, "Urgent")
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			ordering.added(builders.TransferTransaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(i % 4)).Build())
+		}
+
+		visited := 0
+		ordering.order(func(tx *protocol.SignedTransaction) bool {
+			visited++
+			return visited < 3
+		})
+		require.Equal(t, 3, visited, "expected policy %s to stop once the batch is full", policy)
+	}
+}
+
+func TestPendingTransactionPool_PriorityOrderingProposesPriorityContractsFirst(t *testing.T) {
+	p := makePendingPoolOrderedByPriority(t, config.TRANSACTION_POOL_ORDERING_POLICY_PRIORITY, "Urgent, Important")
+	other1 := builders.TransferTransaction().WithContract("Other").Build()
+	important := builders.TransferTransaction().WithContract("Important").Build()
+	other2 := builders.TransferTransaction().WithContract("Other").Build()
+	urgent1 := builders.TransferTransaction().WithContract("Urgent").Build()
+	urgent2 := builders.TransferTransaction().WithContract("Urgent").Build()
+	add(p, other1, important, other2, urgent1, urgent2)
+
+	require.Equal(t, Transactions{urgent1, urgent2, important}, p.getBatch(3, 0), "expected priority contracts to be proposed first in the order they are listed")
+	require.Equal(t, Transactions{urgent1, urgent2, important, other1, other2}, p.getBatch(10, 0), "expected other contracts to be proposed last in arrival order")
+}
+
+func TestOrderingPolicy_RejectsUnknownPolicy(t *testing.T) {
+	_, err := newOrderingPolicy("by-fee", "")
+	require.Error(t, err)
+}
+
+func TestOrderingPolicy_RejectsPriorityPolicyWithoutPriorityContracts(t *testing.T) {
+	_, err := newOrderingPolicy(config.TRANSACTION_POOL_ORDERING_POLICY_PRIORITY, " , ")
+	require.Error(t, err)
+}
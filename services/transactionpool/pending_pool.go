@@ -9,10 +9,12 @@ package transactionpool
 import (
 	"container/list"
 	"context"
+	"fmt"
 	"github.com/orbs-network/crypto-lib-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
 	"sync"
 	"time"
 )
@@ -21,11 +23,16 @@ type transactionRemovedListener func(ctx context.Context, txHash primitives.Sha2
 
 func NewPendingPool(pendingPoolSizeInBytes func() uint32, metricFactory metric.Factory, onNewTransaction func()) *pendingTxPool {
 	return &pendingTxPool{
-		pendingPoolSizeInBytes: pendingPoolSizeInBytes,
-		transactionsByHash:     make(map[string]*pendingTransaction),
-		transactionList:        list.New(),
-		lock:                   &sync.RWMutex{},
-		onNewTransaction:       onNewTransaction,
+		pendingPoolSizeInBytes:     pendingPoolSizeInBytes,
+		transactionsByHash:         make(map[string]*pendingTransaction),
+		transactionsBySigner:       make(map[string]uint32),
+		transactionsByContract:     make(map[string]uint32),
+		transactionList:            list.New(),
+		lock:                       &sync.RWMutex{},
+		onNewTransaction:           onNewTransaction,
+		ordering:                   newFifoOrdering(),
+		maxTransactionsPerSigner:   noQuota,
+		maxTransactionsPerContract: noQuota,
 
 		metrics: newPendingPoolMetrics(metricFactory),
 	}
@@ -38,12 +45,23 @@ type pendingTransaction struct {
 	timeAdded          time.Time
 }
 
+func (ptx *pendingTransaction) inspect(txHash primitives.Sha256) *PendingTransaction {
+	return &PendingTransaction{
+		SignedTransaction:  ptx.transaction,
+		Txhash:             txHash,
+		GatewayNodeAddress: ptx.gatewayNodeAddress,
+		TimeAdded:          ptx.timeAdded,
+	}
+}
+
 type pendingPoolMetrics struct {
 	transactionCountGauge    *metric.Gauge
 	poolSizeInBytesGauge     *metric.Gauge
 	transactionRatePerSecond *metric.Rate
 	transactionSpentInQueue  *metric.Histogram
 	transactionServiceTime   *metric.Histogram
+	signerQuotaHits          *metric.Gauge
+	contractQuotaHits        *metric.Gauge
 }
 
 func newPendingPoolMetrics(factory metric.Factory) *pendingPoolMetrics {
@@ -53,18 +71,30 @@ func newPendingPoolMetrics(factory metric.Factory) *pendingPoolMetrics {
 		poolSizeInBytesGauge:     factory.NewGauge("TransactionPool.PendingPool.PoolSize.Bytes"),
 		transactionRatePerSecond: factory.NewRate("TransactionPool.TransactionsEnteringPool"),
 		transactionSpentInQueue:  factory.NewLatency("TransactionPool.PendingPool.TimeSpentInQueue.Millis", 30*time.Minute),
+		signerQuotaHits:          factory.NewGauge("TransactionPool.PendingPool.SignerQuotaHits.Count"),
+		contractQuotaHits:        factory.NewGauge("TransactionPool.PendingPool.ContractQuotaHits.Count"),
 	}
 }
 
 type pendingTxPool struct {
 	currentSizeInBytes uint32
 	transactionsByHash map[string]*pendingTransaction
-	transactionList    *list.List
-	onNewTransaction   func()
-	lock               *sync.RWMutex
+	// pending transaction counts per signer and per contract, for enforcing the quotas
+	transactionsBySigner   map[string]uint32
+	transactionsByContract map[string]uint32
+	transactionList        *list.List
+	onNewTransaction       func()
+	lock                   *sync.RWMutex
 
 	pendingPoolSizeInBytes func() uint32
 	onTransactionRemoved   transactionRemovedListener
+	ordering               orderingPolicy
+
+	// quotas on the pending transactions of a single signer or targeting a single contract, 0 means no quota
+	maxTransactionsPerSigner   func() uint32
+	maxTransactionsPerContract func() uint32
+
+	journal *pendingPoolJournal // nil when the journal is disabled
 
 	metrics *pendingPoolMetrics
 }
@@ -73,6 +103,25 @@ func (p *pendingTxPool) add(transaction *protocol.SignedTransaction, gatewayNode
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	entry := &journaledTransaction{transaction: transaction, gatewayNodeAddress: gatewayNodeAddress, timeAdded: time.Now()}
+	key, err := p.insertUnderMutex(entry)
+	if err == nil && p.journal != nil {
+		p.journal.appendAdded(entry)
+	}
+
+	return key, err
+}
+
+// restore adds a transaction read from the journal, which does not need to be journaled again
+func (p *pendingTxPool) restore(entry *journaledTransaction) (primitives.Sha256, *ErrTransactionRejected) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.insertUnderMutex(entry)
+}
+
+func (p *pendingTxPool) insertUnderMutex(entry *journaledTransaction) (primitives.Sha256, *ErrTransactionRejected) {
+	transaction := entry.transaction
 	size := sizeOfSignedTransaction(transaction)
 
 	if p.currentSizeInBytes+size > p.pendingPoolSizeInBytes() {
@@ -85,13 +134,28 @@ func (p *pendingTxPool) add(transaction *protocol.SignedTransaction, gatewayNode
 		return nil, &ErrTransactionRejected{TransactionStatus: protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_PENDING}
 	}
 
+	signer, contract := signerOf(transaction), contractOf(transaction)
+
+	if max := p.maxTransactionsPerSigner(); max > 0 && p.transactionsBySigner[signer] >= max {
+		p.metrics.signerQuotaHits.Inc()
+		return nil, &ErrTransactionRejected{TransactionStatus: protocol.TRANSACTION_STATUS_REJECTED_CONGESTION, Expected: log.String("max-pending-transactions-per-signer", fmt.Sprintf("fewer than %d pending transactions of the signer", max)), Actual: log.String("pending-transactions-of-signer", fmt.Sprint(p.transactionsBySigner[signer]))}
+	}
+
+	if max := p.maxTransactionsPerContract(); max > 0 && p.transactionsByContract[contract] >= max {
+		p.metrics.contractQuotaHits.Inc()
+		return nil, &ErrTransactionRejected{TransactionStatus: protocol.TRANSACTION_STATUS_REJECTED_CONGESTION, Expected: log.String("max-pending-transactions-per-contract", fmt.Sprintf("fewer than %d pending transactions to the contract", max)), Actual: log.String("pending-transactions-of-contract", fmt.Sprint(p.transactionsByContract[contract]))}
+	}
+
 	p.currentSizeInBytes += size
+	p.transactionsBySigner[signer]++
+	p.transactionsByContract[contract]++
 	p.transactionsByHash[key.KeyForMap()] = &pendingTransaction{
 		transaction:        transaction,
-		gatewayNodeAddress: gatewayNodeAddress,
+		gatewayNodeAddress: entry.gatewayNodeAddress,
 		listElement:        p.transactionList.PushFront(transaction),
-		timeAdded:          time.Now(),
+		timeAdded:          entry.timeAdded,
 	}
+	p.ordering.added(transaction)
 
 	p.metrics.transactionCountGauge.Inc()
 	p.metrics.poolSizeInBytesGauge.AddUint32(size)
@@ -111,6 +175,15 @@ func (p *pendingTxPool) has(transaction *protocol.SignedTransaction) bool {
 }
 
 func (p *pendingTxPool) remove(ctx context.Context, txHash primitives.Sha256, removalReason protocol.TransactionStatus) *primitives.NodeAddress {
+	gatewayNodeAddress, journalSnapshot := p.removeUnderMutex(ctx, txHash, removalReason)
+	if journalSnapshot != nil {
+		p.journal.compact(journalSnapshot) // rewriting and syncing the journal does not hold the pool
+	}
+	return gatewayNodeAddress
+}
+
+// removeUnderMutex also returns the snapshot of the pool to compact the journal to, when it needs compaction
+func (p *pendingTxPool) removeUnderMutex(ctx context.Context, txHash primitives.Sha256, removalReason protocol.TransactionStatus) (*primitives.NodeAddress, []*journaledTransaction) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -119,6 +192,15 @@ func (p *pendingTxPool) remove(ctx context.Context, txHash primitives.Sha256, re
 		delete(p.transactionsByHash, txHash.KeyForMap())
 		p.currentSizeInBytes -= sizeOfSignedTransaction(pendingTx.transaction)
 		p.transactionList.Remove(pendingTx.listElement)
+		p.ordering.removed(pendingTx.transaction)
+		decrementOrDelete(p.transactionsBySigner, signerOf(pendingTx.transaction))
+		decrementOrDelete(p.transactionsByContract, contractOf(pendingTx.transaction))
+
+		var journalSnapshot []*journaledTransaction
+		if p.journal != nil {
+			p.journal.appendRemoved(txHash)
+			journalSnapshot = p.journal.snapshotForCompactionIfNeeded(p.journaledTransactionsUnderMutex)
+		}
 
 		if p.onTransactionRemoved != nil {
 			p.onTransactionRemoved(ctx, txHash, removalReason)
@@ -128,10 +210,10 @@ func (p *pendingTxPool) remove(ctx context.Context, txHash primitives.Sha256, re
 		p.metrics.poolSizeInBytesGauge.SubUint32(sizeOfSignedTransaction(pendingTx.transaction))
 		p.metrics.transactionServiceTime.RecordSince(pendingTx.timeAdded)
 
-		return &pendingTx.gatewayNodeAddress
+		return &pendingTx.gatewayNodeAddress, journalSnapshot
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (p *pendingTxPool) getBatch(maxNumOfTransactions uint32, sizeLimitInBytes uint32) (txs Transactions) {
@@ -140,30 +222,22 @@ func (p *pendingTxPool) getBatch(maxNumOfTransactions uint32, sizeLimitInBytes u
 
 	var sizeInBytes uint32
 
-	e := p.transactionList.Back()
-	for {
-		if e == nil {
-			break
-		}
-
+	p.ordering.order(func(tx *protocol.SignedTransaction) bool {
 		if uint32(len(txs)) >= maxNumOfTransactions {
-			break
+			return false
 		}
 
-		tx := e.Value.(*protocol.SignedTransaction)
-		//
 		txSize := sizeOfSignedTransaction(tx)
 		if sizeLimitInBytes > 0 && sizeInBytes+txSize > sizeLimitInBytes {
-			break
+			return false
 		}
 
 		sizeInBytes += txSize
 		txs = append(txs, tx)
 
-		e = e.Prev()
-
 		p.transactionPickedFromQueueUnderMutex(tx)
-	}
+		return true
+	})
 
 	return
 }
@@ -179,6 +253,38 @@ func (p *pendingTxPool) get(txHash primitives.Sha256) *protocol.SignedTransactio
 	return nil
 }
 
+func (p *pendingTxPool) inspect(txHash primitives.Sha256) *PendingTransaction {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if ptx, ok := p.transactionsByHash[txHash.KeyForMap()]; ok {
+		return ptx.inspect(txHash)
+	}
+
+	return nil
+}
+
+// inspectPage walks the pending transactions in arrival order, returning how many match and the requested page of them
+func (p *pendingTxPool) inspectPage(offset uint32, limit uint32, matches func(tx *protocol.SignedTransaction) bool) (total uint32, page []*PendingTransaction) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	for e := p.transactionList.Back(); e != nil; e = e.Prev() {
+		tx := e.Value.(*protocol.SignedTransaction)
+		if !matches(tx) {
+			continue
+		}
+
+		if total >= offset && uint32(len(page)) < limit {
+			txHash := digest.CalcTxHash(tx.Transaction())
+			page = append(page, p.transactionsByHash[txHash.KeyForMap()].inspect(txHash))
+		}
+		total++
+	}
+
+	return
+}
+
 func (p *pendingTxPool) clearTransactionsOlderThan(ctx context.Context, timestamp primitives.TimestampNano) {
 	for e := p.lastTransaction(); e != nil; e = p.prevTransaction(e) {
 		tx := e.Value.(*protocol.SignedTransaction)
@@ -201,6 +307,16 @@ func (p *pendingTxPool) prevTransaction(e *list.Element) *list.Element {
 	return e.Prev()
 }
 
+// oldest first, so a compacted journal restores them in the order they arrived
+func (p *pendingTxPool) journaledTransactionsUnderMutex() (entries []*journaledTransaction) {
+	for e := p.transactionList.Back(); e != nil; e = e.Prev() {
+		tx := e.Value.(*protocol.SignedTransaction)
+		ptx := p.transactionsByHash[digest.CalcTxHash(tx.Transaction()).KeyForMap()]
+		entries = append(entries, &journaledTransaction{transaction: tx, gatewayNodeAddress: ptx.gatewayNodeAddress, timeAdded: ptx.timeAdded})
+	}
+	return
+}
+
 func (p *pendingTxPool) transactionPickedFromQueueUnderMutex(tx *protocol.SignedTransaction) {
 	txHash := digest.CalcTxHash(tx.Transaction())
 	ptx, found := p.transactionsByHash[txHash.KeyForMap()]
@@ -209,6 +325,26 @@ func (p *pendingTxPool) transactionPickedFromQueueUnderMutex(tx *protocol.Signed
 	}
 }
 
+func decrementOrDelete(counts map[string]uint32, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+	} else {
+		counts[key]--
+	}
+}
+
+func noQuota() uint32 {
+	return 0
+}
+
+func signerOf(transaction *protocol.SignedTransaction) string {
+	return string(transaction.Transaction().Signer().Raw())
+}
+
+func contractOf(transaction *protocol.SignedTransaction) string {
+	return string(transaction.Transaction().ContractName())
+}
+
 func sizeOfSignedTransaction(transaction *protocol.SignedTransaction) uint32 {
 	return uint32(len(transaction.Raw()))
 }
@@ -0,0 +1,447 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	journalRecordAdded   = uint8(1)
+	journalRecordRemoved = uint8(2)
+)
+
+const maxJournalRecordSize = 64 * 1024 * 1024
+
+// the journal is compacted once it holds more removed than live transactions, but not before this many removals
+const minJournalRemovalsBeforeCompaction = 10000
+
+type journaledTransaction struct {
+	transaction        *protocol.SignedTransaction
+	gatewayNodeAddress primitives.NodeAddress
+	timeAdded          time.Time
+}
+
+// pendingPoolJournal appends a record for every transaction entering the pending pool and a tombstone for every one
+// leaving it, so the pending pool survives restarts. records are not synced to disk one by one, a crash of the machine
+// may lose the last few of them
+type pendingPoolJournal struct {
+	sync.Mutex
+	filename string
+	file     *os.File
+	logger   log.Logger
+
+	live       int
+	removals   int
+	unrestored []*journaledTransaction
+
+	// while compacting, the records appended since the snapshot of the pool are kept to be appended to the compacted journal
+	compacting            bool
+	snapshotRemovals      int
+	appendedSinceSnapshot bytes.Buffer
+}
+
+// openPendingPoolJournal reads the transactions journaled before the restart and rewrites the journal with only them.
+// a journal damaged before its last record is kept aside rather than overwritten
+func openPendingPoolJournal(filename string, logger log.Logger) (*pendingPoolJournal, error) {
+	j := &pendingPoolJournal{
+		filename: filename,
+		logger:   logger.WithTags(log.String("filename", filename)),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "failed to verify pending pool journal directory exists %s", filepath.Dir(filename))
+	}
+
+	entries, damaged, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if damaged {
+		if err := os.Rename(filename, damagedJournalFilename(filename)); err != nil {
+			return nil, errors.Wrapf(err, "failed to keep aside damaged pending pool journal %s", filename)
+		}
+		j.logger.Error("pending pool journal is damaged, kept it aside and restoring the transactions of its intact records", log.String("damaged-filename", damagedJournalFilename(filename)))
+	}
+
+	if err := j.rewrite(entries); err != nil {
+		return nil, err
+	}
+
+	j.unrestored = entries
+	j.logger.Info("opened pending pool journal", log.Int("journaled-transactions", len(entries)))
+	return j, nil
+}
+
+// read returns the journaled transactions and whether records before the end of the journal were damaged and skipped
+func (j *pendingPoolJournal) read() ([]*journaledTransaction, bool, error) {
+	file, err := os.Open(j.filename)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to open pending pool journal %s", j.filename)
+	}
+	defer func() { _ = file.Close() }()
+
+	decoded, err := decodeJournal(bufio.NewReader(file))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read pending pool journal %s, move it aside to start with an empty pending pool", j.filename)
+	}
+	if decoded.tornEnd != nil {
+		// a crash while appending leaves a partial record at the end of the journal, everything before it is intact
+		j.logger.Info("ignoring the partial record at the end of the pending pool journal", log.Error(decoded.tornEnd), log.Int("journaled-transactions", len(decoded.entries)))
+	}
+	for _, damage := range decoded.damagedRecords {
+		j.logger.Error("skipped damaged pending pool journal record", log.Error(damage))
+	}
+	return decoded.entries, len(decoded.damagedRecords) > 0, nil
+}
+
+type decodedJournal struct {
+	entries        []*journaledTransaction // added and not removed, in the order they were added
+	tornEnd        error                   // why the last record is partial, if it is
+	damagedRecords []error                 // why each skipped record before the last one is damaged
+}
+
+// decodeJournal reads the records of the journal. only the last record may be partial, as left by a crash while
+// appending it. a record damaged before the last one is skipped as its size is known, but a record whose size is
+// damaged leaves the rest of the journal unreadable and fails the decoding
+func decodeJournal(r *bufio.Reader) (*decodedJournal, error) {
+	decoded := &decodedJournal{}
+	var order []string
+	live := make(map[string]*journaledTransaction)
+
+	for {
+		kind, payload, err := readJournalRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if errors.Cause(err) == io.ErrUnexpectedEOF {
+			decoded.tornEnd = err
+			break
+		}
+		if err == nil {
+			err = applyJournalRecord(kind, payload, &order, live)
+		}
+		if _, isDamaged := err.(*damagedJournalRecordError); isDamaged {
+			if _, peekErr := r.Peek(1); peekErr == io.EOF {
+				decoded.tornEnd = err
+				break
+			}
+			decoded.damagedRecords = append(decoded.damagedRecords, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decoded.entries = liveInOrder(order, live)
+	return decoded, nil
+}
+
+// damagedJournalRecordError is a record read in full whose content is damaged, the records following it are still readable
+type damagedJournalRecordError struct {
+	reason error
+}
+
+func (e *damagedJournalRecordError) Error() string {
+	return fmt.Sprintf("damaged pending pool journal record: %s", e.reason)
+}
+
+func applyJournalRecord(kind uint8, payload []byte, order *[]string, live map[string]*journaledTransaction) error {
+	switch kind {
+	case journalRecordAdded:
+		entry, err := decodeJournaledTransaction(payload)
+		if err != nil {
+			return &damagedJournalRecordError{err}
+		}
+		key := digest.CalcTxHash(entry.transaction.Transaction()).KeyForMap()
+		if _, exists := live[key]; !exists {
+			*order = append(*order, key)
+			live[key] = entry
+		}
+	case journalRecordRemoved:
+		delete(live, primitives.Sha256(payload).KeyForMap())
+	default:
+		return &damagedJournalRecordError{fmt.Errorf("unknown record kind %d", kind)}
+	}
+	return nil
+}
+
+func damagedJournalFilename(filename string) string {
+	return filename + ".damaged"
+}
+
+func liveInOrder(order []string, live map[string]*journaledTransaction) (entries []*journaledTransaction) {
+	for _, key := range order {
+		if entry, ok := live[key]; ok {
+			entries = append(entries, entry)
+			delete(live, key) // a transaction added again after its removal is listed once
+		}
+	}
+	return
+}
+
+// rewrite replaces the journal atomically with the given transactions and reopens it for appending
+func (j *pendingPoolJournal) rewrite(entries []*journaledTransaction) error {
+	tmp, err := j.writeTemporary(entries)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op once renamed
+
+	if err := j.replaceWith(tmp); err != nil {
+		return err
+	}
+	j.live = len(entries)
+	j.removals = 0
+	return nil
+}
+
+// writeTemporary writes and syncs the given transactions to a new journal next to the current one, open for appending more
+func (j *pendingPoolJournal) writeTemporary(entries []*journaledTransaction) (*os.File, error) {
+	dir := filepath.Dir(j.filename)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(j.filename)+".tmp")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary pending pool journal")
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		if _, err = w.Write(encodeJournalRecord(journalRecordAdded, encodeJournaledTransaction(entry))); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "failed to write pending pool journal")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "failed to sync pending pool journal")
+	}
+	return tmp, nil
+}
+
+// replaceWith renames the temporary journal over the current one and reopens it for appending
+func (j *pendingPoolJournal) replaceWith(tmp *os.File) error {
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close pending pool journal")
+	}
+	if err := os.Rename(tmp.Name(), j.filename); err != nil {
+		return errors.Wrap(err, "failed to replace pending pool journal")
+	}
+
+	file, err := os.OpenFile(j.filename, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open pending pool journal %s", j.filename)
+	}
+	if j.file != nil {
+		_ = j.file.Close()
+	}
+	j.file = file
+	return nil
+}
+
+func (j *pendingPoolJournal) appendAdded(entry *journaledTransaction) {
+	j.Lock()
+	defer j.Unlock()
+
+	j.live++
+	j.append(journalRecordAdded, encodeJournaledTransaction(entry))
+}
+
+func (j *pendingPoolJournal) appendRemoved(txHash primitives.Sha256) {
+	j.Lock()
+	defer j.Unlock()
+
+	j.live--
+	j.removals++
+	j.append(journalRecordRemoved, txHash)
+}
+
+// a failed write is logged and does not fail the pool, the journal only matters after a restart
+func (j *pendingPoolJournal) append(kind uint8, payload []byte) {
+	record := encodeJournalRecord(kind, payload)
+	if j.compacting {
+		j.appendedSinceSnapshot.Write(record)
+	}
+	if _, err := j.file.Write(record); err != nil {
+		j.logger.Error("failed to append to pending pool journal", log.Error(err))
+	}
+}
+
+// snapshotForCompactionIfNeeded returns the transactions to compact the journal to once it holds more removed than
+// live transactions, or nil. live returns the transactions of the pool, it is called under the pool lock so the
+// snapshot matches the records appended to the journal until then
+func (j *pendingPoolJournal) snapshotForCompactionIfNeeded(live func() []*journaledTransaction) []*journaledTransaction {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.compacting || j.removals < minJournalRemovalsBeforeCompaction || j.removals <= j.live {
+		return nil
+	}
+
+	j.compacting = true
+	j.snapshotRemovals = j.removals
+	j.appendedSinceSnapshot.Reset()
+	return append(append([]*journaledTransaction{}, j.unrestored...), live()...)
+}
+
+// compact rewrites the journal with the snapshot of the pool, followed by the records appended since. the snapshot is
+// written and synced without holding the journal, so it must not be called under the pool lock
+func (j *pendingPoolJournal) compact(snapshot []*journaledTransaction) {
+	tmp, err := j.writeTemporary(snapshot)
+
+	j.Lock()
+	defer j.Unlock()
+	defer func() {
+		j.compacting = false
+		j.appendedSinceSnapshot.Reset()
+	}()
+
+	if err == nil {
+		defer func() { _ = os.Remove(tmp.Name()) }() // no-op once renamed
+		if _, err = tmp.Write(j.appendedSinceSnapshot.Bytes()); err != nil {
+			_ = tmp.Close()
+			err = errors.Wrap(err, "failed to write pending pool journal")
+		} else {
+			err = j.replaceWith(tmp)
+		}
+	}
+	if err != nil {
+		j.logger.Error("failed to compact pending pool journal", log.Error(err))
+		j.removals = 0 // try again after another round of removals
+		return
+	}
+	j.removals -= j.snapshotRemovals
+}
+
+// restoring returns the transactions journaled before the restart which were not restored to the pool yet
+func (j *pendingPoolJournal) restoring() []*journaledTransaction {
+	j.Lock()
+	defer j.Unlock()
+	return j.unrestored
+}
+
+func (j *pendingPoolJournal) restored() {
+	j.Lock()
+	defer j.Unlock()
+	j.unrestored = nil
+}
+
+func encodeJournaledTransaction(entry *journaledTransaction) []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, entry.timeAdded.UnixNano())
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(entry.gatewayNodeAddress)))
+	buf.Write(entry.gatewayNodeAddress)
+	buf.Write(entry.transaction.Raw())
+	return buf.Bytes()
+}
+
+func decodeJournaledTransaction(payload []byte) (*journaledTransaction, error) {
+	r := bytes.NewReader(payload)
+	var timeAdded int64
+	var gatewaySize uint32
+	if err := binary.Read(r, binary.LittleEndian, &timeAdded); err != nil {
+		return nil, errors.Wrap(err, "failed to read journaled transaction")
+	}
+	if err := binary.Read(r, binary.LittleEndian, &gatewaySize); err != nil {
+		return nil, errors.Wrap(err, "failed to read journaled transaction")
+	}
+	if int(gatewaySize) > r.Len() {
+		return nil, fmt.Errorf("journaled transaction gateway node address of %d bytes exceeds the record", gatewaySize)
+	}
+
+	gateway := make([]byte, gatewaySize)
+	_, _ = r.Read(gateway)
+	raw := make([]byte, r.Len())
+	_, _ = r.Read(raw)
+
+	return &journaledTransaction{
+		transaction:        protocol.SignedTransactionReader(raw),
+		gatewayNodeAddress: gateway,
+		timeAdded:          time.Unix(0, timeAdded),
+	}, nil
+}
+
+// a record is its kind, the size of its payload, the payload and a checksum of the kind and payload
+func encodeJournalRecord(kind uint8, payload []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteByte(kind)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(payload)))
+	buf.Write(payload)
+	_ = binary.Write(buf, binary.LittleEndian, journalRecordChecksum(kind, payload))
+	return buf.Bytes()
+}
+
+func readJournalRecord(r io.Reader) (kind uint8, payload []byte, err error) {
+	if err = binary.Read(r, binary.LittleEndian, &kind); err != nil {
+		return // io.EOF only when the journal ends between records
+	}
+
+	var size uint32
+	if err = binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return 0, nil, errors.Wrap(noEOF(err), "failed to read pending pool journal record")
+	}
+	if size > maxJournalRecordSize {
+		return 0, nil, fmt.Errorf("pending pool journal record of %d bytes exceeds maximum size", size)
+	}
+
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return 0, nil, errors.Wrap(noEOF(err), "failed to read pending pool journal record")
+	}
+
+	var checksum uint32
+	if err = binary.Read(r, binary.LittleEndian, &checksum); err != nil {
+		return 0, nil, errors.Wrap(noEOF(err), "failed to read pending pool journal record checksum")
+	}
+	if expected := journalRecordChecksum(kind, payload); checksum != expected {
+		return 0, nil, &damagedJournalRecordError{fmt.Errorf("checksum mismatch. found %x expected %x", checksum, expected)}
+	}
+
+	return kind, payload, nil
+}
+
+func journalRecordChecksum(kind uint8, payload []byte) uint32 {
+	checksum := crc32.NewIEEE()
+	_, _ = checksum.Write([]byte{kind})
+	_, _ = checksum.Write(payload)
+	return checksum.Sum32()
+}
+
+// a record cut short is not the end of the journal
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
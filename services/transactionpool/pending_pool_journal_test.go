@@ -0,0 +1,235 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"context"
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withJournalFile(t *testing.T, f func(filename string)) {
+	dir, err := ioutil.TempDir("", "pending_pool_journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	f(filepath.Join(dir, "pending-pool.journal"))
+}
+
+func transactionsOf(entries []*journaledTransaction) (txs Transactions) {
+	for _, entry := range entries {
+		txs = append(txs, entry.transaction)
+	}
+	return
+}
+
+func TestPendingPoolJournal_ReopenedJournalHoldsTransactionsAddedAndNotRemoved(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			ctx := context.Background()
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Empty(t, journal.restoring())
+
+			p := makePendingPool()
+			p.journal = journal
+			tx1 := builders.TransferTransaction().Build()
+			tx2 := builders.TransferTransaction().Build()
+			tx3 := builders.TransferTransaction().Build()
+			add(p, tx1, tx2, tx3)
+			p.remove(ctx, digest.CalcTxHash(tx2.Transaction()), protocol.TRANSACTION_STATUS_COMMITTED)
+
+			reopened, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Equal(t, Transactions{tx1, tx3}, transactionsOf(reopened.restoring()), "expected the journal to hold the pending transactions in arrival order")
+			require.Equal(t, nodeAddress, reopened.restoring()[0].gatewayNodeAddress)
+			require.Equal(t, p.transactionsByHash[digest.CalcTxHash(tx1.Transaction()).KeyForMap()].timeAdded.UnixNano(), reopened.restoring()[0].timeAdded.UnixNano())
+		})
+	})
+}
+
+func TestPendingPoolJournal_IgnoresARecordCutShortByACrash(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+
+			p := makePendingPool()
+			p.journal = journal
+			tx1 := builders.TransferTransaction().Build()
+			tx2 := builders.TransferTransaction().Build()
+			add(p, tx1, tx2)
+
+			info, err := os.Stat(filename)
+			require.NoError(t, err)
+			require.NoError(t, os.Truncate(filename, info.Size()-3))
+
+			reopened, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Equal(t, Transactions{tx1}, transactionsOf(reopened.restoring()))
+		})
+	})
+}
+
+func TestPendingPoolJournal_CompactionKeepsOnlyLiveTransactions(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			ctx := context.Background()
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+
+			p := makePendingPool()
+			p.journal = journal
+			pending := builders.TransferTransaction().Build()
+			add(p, pending)
+			for i := 0; i < 3; i++ {
+				tx := builders.TransferTransaction().Build()
+				add(p, tx)
+				journal.removals = minJournalRemovalsBeforeCompaction - 1
+				p.remove(ctx, digest.CalcTxHash(tx.Transaction()), protocol.TRANSACTION_STATUS_COMMITTED)
+			}
+
+			compacted, err := ioutil.ReadFile(filename)
+			require.NoError(t, err)
+			require.Len(t, compacted, len(encodeJournalRecord(journalRecordAdded, encodeJournaledTransaction(p.journaledTransactionsUnderMutex()[0]))), "expected the compacted journal to hold only the pending transaction")
+
+			reopened, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Equal(t, Transactions{pending}, transactionsOf(reopened.restoring()))
+		})
+	})
+}
+
+func journaledRecordSize(entry *journaledTransaction) int64 {
+	return int64(len(encodeJournalRecord(journalRecordAdded, encodeJournaledTransaction(entry))))
+}
+
+func damageJournalByte(t *testing.T, filename string, offset int64) {
+	file, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	require.NoError(t, err)
+	defer file.Close()
+
+	b := make([]byte, 1)
+	_, err = file.ReadAt(b, offset)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte{^b[0]}, offset)
+	require.NoError(t, err)
+}
+
+func TestPendingPoolJournal_SkipsADamagedRecordAndKeepsTheDamagedJournalAside(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			harness.AllowErrorsMatching("damaged")
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+
+			p := makePendingPool()
+			p.journal = journal
+			tx1 := builders.TransferTransaction().Build()
+			tx2 := builders.TransferTransaction().Build()
+			tx3 := builders.TransferTransaction().Build()
+			add(p, tx1, tx2, tx3)
+
+			recordSize := journaledRecordSize(p.journaledTransactionsUnderMutex()[0])
+			damageJournalByte(t, filename, recordSize+recordSize/2) // the payload of the second record
+			damaged, err := ioutil.ReadFile(filename)
+			require.NoError(t, err)
+
+			reopened, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Equal(t, Transactions{tx1, tx3}, transactionsOf(reopened.restoring()), "expected the records following the damaged one to be restored")
+
+			keptAside, err := ioutil.ReadFile(damagedJournalFilename(filename))
+			require.NoError(t, err, "expected the damaged journal to be kept aside")
+			require.Equal(t, damaged, keptAside)
+		})
+	})
+}
+
+func TestPendingPoolJournal_FailsToOpenAJournalWithADamagedRecordSize(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+
+			p := makePendingPool()
+			p.journal = journal
+			add(p, builders.TransferTransaction().Build(), builders.TransferTransaction().Build())
+
+			recordSize := journaledRecordSize(p.journaledTransactionsUnderMutex()[0])
+			damageJournalByte(t, filename, recordSize+4) // the most significant byte of the size of the second record
+			damaged, err := ioutil.ReadFile(filename)
+			require.NoError(t, err)
+
+			_, err = openPendingPoolJournal(filename, harness.Logger)
+			require.Error(t, err, "expected a journal which cannot be read past a record to fail opening")
+
+			untouched, err := ioutil.ReadFile(filename)
+			require.NoError(t, err)
+			require.Equal(t, damaged, untouched, "expected the damaged journal not to be overwritten")
+		})
+	})
+}
+
+func TestPendingPoolJournal_IgnoresADamagedLastRecord(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+
+			p := makePendingPool()
+			p.journal = journal
+			tx1 := builders.TransferTransaction().Build()
+			add(p, tx1, builders.TransferTransaction().Build())
+
+			info, err := os.Stat(filename)
+			require.NoError(t, err)
+			damageJournalByte(t, filename, info.Size()-1) // the checksum of the last record
+
+			reopened, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Equal(t, Transactions{tx1}, transactionsOf(reopened.restoring()))
+			_, err = os.Stat(damagedJournalFilename(filename))
+			require.True(t, os.IsNotExist(err), "expected a journal damaged only in its last record not to be kept aside")
+		})
+	})
+}
+
+func TestPendingPoolJournal_CompactionKeepsRecordsAppendedWhileCompacting(t *testing.T) {
+	with.Logging(t, func(harness *with.LoggingHarness) {
+		withJournalFile(t, func(filename string) {
+			ctx := context.Background()
+			journal, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+
+			p := makePendingPool()
+			p.journal = journal
+			removed := builders.TransferTransaction().Build()
+			pending := builders.TransferTransaction().Build()
+			add(p, removed, pending)
+
+			journal.removals = minJournalRemovalsBeforeCompaction
+			snapshot := journal.snapshotForCompactionIfNeeded(p.journaledTransactionsUnderMutex)
+			require.Equal(t, Transactions{removed, pending}, transactionsOf(snapshot))
+
+			addedWhileCompacting := builders.TransferTransaction().Build()
+			add(p, addedWhileCompacting)
+			p.remove(ctx, digest.CalcTxHash(removed.Transaction()), protocol.TRANSACTION_STATUS_COMMITTED)
+			journal.compact(snapshot)
+
+			reopened, err := openPendingPoolJournal(filename, harness.Logger)
+			require.NoError(t, err)
+			require.Equal(t, Transactions{pending, addedWhileCompacting}, transactionsOf(reopened.restoring()))
+		})
+	})
+}
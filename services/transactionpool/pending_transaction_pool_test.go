@@ -214,6 +214,68 @@ func TestPendingTransactionPool_CleanupWhileRemove_NoRaces(t *testing.T) {
 	})
 }
 
+func TestPendingTransactionPool_RejectsTransactionsOfASignerOverItsQuota(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		p := makePendingPool()
+		p.maxTransactionsPerSigner = func() uint32 { return 2 }
+		spammer := keys.Ed25519KeyPairForTests(1)
+
+		k1, err := p.add(builders.TransferTransaction().WithEd25519Signer(spammer).Build(), nodeAddress)
+		require.Nil(t, err)
+		_, err = p.add(builders.TransferTransaction().WithEd25519Signer(spammer).Build(), nodeAddress)
+		require.Nil(t, err)
+
+		_, err = p.add(builders.TransferTransaction().WithEd25519Signer(spammer).Build(), nodeAddress)
+		require.NotNil(t, err, "expected a transaction over the signer quota to be rejected")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_CONGESTION, err.TransactionStatus)
+		require.Contains(t, err.Error(), "expected fewer than 2 pending transactions of the signer but got 2")
+		require.EqualValues(t, 1, p.metrics.signerQuotaHits.Value())
+
+		_, err = p.add(builders.TransferTransaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(2)).Build(), nodeAddress)
+		require.Nil(t, err, "expected another signer to be unaffected by the quota")
+
+		p.remove(ctx, k1, protocol.TRANSACTION_STATUS_COMMITTED)
+		_, err = p.add(builders.TransferTransaction().WithEd25519Signer(spammer).Build(), nodeAddress)
+		require.Nil(t, err, "expected a removed transaction to free its place in the signer quota")
+	})
+}
+
+func TestPendingTransactionPool_RejectsTransactionsToAContractOverItsQuota(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		p := makePendingPool()
+		p.maxTransactionsPerContract = func() uint32 { return 1 }
+
+		_, err := p.add(builders.TransferTransaction().WithContract("BusyContract").Build(), nodeAddress)
+		require.Nil(t, err)
+
+		_, err = p.add(builders.TransferTransaction().WithContract("BusyContract").Build(), nodeAddress)
+		require.NotNil(t, err, "expected a transaction over the contract quota to be rejected")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_CONGESTION, err.TransactionStatus)
+		require.Contains(t, err.Error(), "expected fewer than 1 pending transactions to the contract but got 1")
+		require.EqualValues(t, 1, p.metrics.contractQuotaHits.Value())
+		require.EqualValues(t, 0, p.metrics.signerQuotaHits.Value())
+
+		_, err = p.add(builders.TransferTransaction().WithContract("QuietContract").Build(), nodeAddress)
+		require.Nil(t, err, "expected another contract to be unaffected by the quota")
+	})
+}
+
+func TestPendingTransactionPool_QuotasDoNotCountTransactionsRejectedAsDuplicates(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		p := makePendingPool()
+		p.maxTransactionsPerSigner = func() uint32 { return 2 }
+		tx := builders.TransferTransaction().Build()
+
+		_, err := p.add(tx, nodeAddress)
+		require.Nil(t, err)
+		_, err = p.add(tx, nodeAddress)
+		require.Equal(t, protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_PENDING, err.TransactionStatus)
+
+		_, err = p.add(builders.TransferTransaction().Build(), nodeAddress)
+		require.Nil(t, err, "expected the duplicate not to take a place in the signer quota")
+	})
+}
+
 func add(p *pendingTxPool, txs ...*protocol.SignedTransaction) {
 	for _, tx := range txs {
 		p.add(tx, nodeAddress)
@@ -0,0 +1,74 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package transactionpool
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/scribe/log"
+	"time"
+)
+
+// restorePendingTransactions adds the transactions journaled before the restart back to the pending pool. it waits
+// for the pool to catch up with the committed blocks, since only then the committed pool can tell which of them were
+// committed meanwhile. must be called with addCommitLock held
+func (s *service) restorePendingTransactions(logger log.Logger) {
+	journal := s.pendingPool.journal
+	if journal == nil {
+		return
+	}
+
+	entries := journal.restoring()
+	if len(entries) == 0 {
+		return
+	}
+
+	currentTime := time.Now()
+	_, lastCommittedBlockTimestamp, _ := s.lastCommittedBlockInfo()
+	if err := s.validationContext.validateNodeIsInSync(currentTime, lastCommittedBlockTimestamp); err != nil {
+		return
+	}
+
+	var forwarded []*protocol.SignedTransaction
+	for _, entry := range entries {
+		txHash := digest.CalcTxHash(entry.transaction.Transaction())
+		if err := s.restorePendingTransaction(entry, txHash, currentTime, lastCommittedBlockTimestamp); err != nil {
+			logger.Info("dropping journaled transaction", logfields.Transaction(txHash), log.Error(err))
+			if err.TransactionStatus != protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_PENDING {
+				journal.appendRemoved(txHash)
+			}
+			continue
+		}
+
+		// peers which restarted as well lost the transactions this node forwarded to them
+		if entry.gatewayNodeAddress.Equal(s.config.NodeAddress()) {
+			forwarded = append(forwarded, entry.transaction)
+		}
+	}
+	journal.restored()
+
+	if len(forwarded) > 0 {
+		s.transactionForwarder.submit(forwarded...)
+	}
+
+	logger.Info("restored journaled pending transactions", log.Int("journaled-transactions", len(entries)), log.Int("forwarded-transactions", len(forwarded)))
+}
+
+func (s *service) restorePendingTransaction(entry *journaledTransaction, txHash primitives.Sha256, currentTime time.Time, lastCommittedBlockTimestamp primitives.TimestampNano) *ErrTransactionRejected {
+	if alreadyCommitted := s.committedPool.get(txHash); alreadyCommitted != nil {
+		return &ErrTransactionRejected{TransactionStatus: protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_COMMITTED}
+	}
+
+	if err := s.validationContext.ValidateAddedTransaction(entry.transaction, currentTime, lastCommittedBlockTimestamp); err != nil {
+		return err
+	}
+
+	_, err := s.pendingPool.restore(entry)
+	return err
+}
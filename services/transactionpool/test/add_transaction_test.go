@@ -198,3 +198,19 @@ func TestDoesNotAddTransactionIfPoolIsFull(t *testing.T) {
 		require.NoError(t, h.verifyMocks(), "mocks were not called as expected")
 	})
 }
+
+func TestDoesNotAddTransactionIfSignerIsOverItsQuota(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, parent *with.ConcurrencyHarness) {
+		h := newHarness(parent).withSignerQuota(1).start(ctx)
+		h.AllowErrorsMatching("error adding transaction to pending pool")
+		h.ignoringForwardMessages()
+
+		_, err := h.addNewTransaction(ctx, builders.TransferTransaction().Build())
+		require.NoError(t, err)
+
+		receipt, err := h.addNewTransaction(ctx, builders.TransferTransaction().Build())
+		require.Error(t, err, "a transaction was added over the signer quota")
+		require.NotNil(t, receipt, "receipt should never be nil")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_CONGESTION, receipt.TransactionStatus, "expected transaction status: congestion")
+	})
+}
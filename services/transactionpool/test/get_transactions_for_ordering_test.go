@@ -10,7 +10,9 @@ import (
 	"context"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
 	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -175,3 +177,42 @@ func TestGetTransactionsForOrdering_ReturnsNonZeroTransactions(t *testing.T) {
 		require.NotZero(t, out.ProposedBlockTimestamp, "proposed block timestamp should not be zero")
 	})
 }
+
+func TestGetTransactionsForOrdering_SignerRoundRobinDoesNotLetOneSignerStarveOthers(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, parent *with.ConcurrencyHarness) {
+		h := newHarness(parent).withOrderingPolicy(config.TRANSACTION_POOL_ORDERING_POLICY_SIGNER_ROUND_ROBIN).start(ctx)
+		spammer := keys.Ed25519KeyPairForTests(1)
+		other := keys.Ed25519KeyPairForTests(2)
+
+		spam := []*protocol.SignedTransaction{
+			builders.TransferTransaction().WithEd25519Signer(spammer).Build(),
+			builders.TransferTransaction().WithEd25519Signer(spammer).Build(),
+			builders.TransferTransaction().WithEd25519Signer(spammer).Build(),
+		}
+		otherTx := builders.TransferTransaction().WithEd25519Signer(other).Build()
+		h.handleForwardFrom(ctx, otherNodeKeyPair, append(spam, otherTx)...)
+
+		out, err := h.getTransactionsForOrdering(ctx, 2, 2)
+
+		require.NoError(t, err, "GetTransactionsForOrdering should not fail")
+		require.Equal(t, []*protocol.SignedTransaction{spam[0], otherTx}, out.SignedTransactions, "expected the other signer to take its turn before the rest of the spam")
+	})
+}
+
+func TestGetTransactionsForOrdering_PriorityProposesPriorityContractsBeforeOthers(t *testing.T) {
+	with.Concurrency(t, func(ctx context.Context, parent *with.ConcurrencyHarness) {
+		h := newHarness(parent).withOrderingPolicy(config.TRANSACTION_POOL_ORDERING_POLICY_PRIORITY).withPriorityContracts("Urgent").start(ctx)
+
+		spam := []*protocol.SignedTransaction{
+			builders.TransferTransaction().WithContract("Busy").Build(),
+			builders.TransferTransaction().WithContract("Busy").Build(),
+		}
+		urgent := builders.TransferTransaction().WithContract("Urgent").Build()
+		h.handleForwardFrom(ctx, otherNodeKeyPair, append(spam, urgent)...)
+
+		out, err := h.getTransactionsForOrdering(ctx, 2, 2)
+
+		require.NoError(t, err, "GetTransactionsForOrdering should not fail")
+		require.Equal(t, []*protocol.SignedTransaction{urgent, spam[0]}, out.SignedTransactions, "expected the priority contract to be proposed before the earlier transactions")
+	})
+}
@@ -233,11 +233,73 @@ func (h *harness) getTxReceipt(ctx context.Context, tx *protocol.SignedTransacti
 	})
 }
 
+type orderingPolicyConfig struct {
+	config.TransactionPoolConfig
+	orderingPolicy string
+}
+
+func (c *orderingPolicyConfig) TransactionPoolOrderingPolicy() string {
+	return c.orderingPolicy
+}
+
+func (h *harness) withOrderingPolicy(policy string) *harness {
+	h.config = &orderingPolicyConfig{TransactionPoolConfig: h.config, orderingPolicy: policy}
+	return h
+}
+
+type priorityContractsConfig struct {
+	config.TransactionPoolConfig
+	priorityContracts string
+}
+
+func (c *priorityContractsConfig) TransactionPoolPriorityContracts() string {
+	return c.priorityContracts
+}
+
+func (h *harness) withPriorityContracts(priorityContracts string) *harness {
+	h.config = &priorityContractsConfig{TransactionPoolConfig: h.config, priorityContracts: priorityContracts}
+	return h
+}
+
+type signerQuotaConfig struct {
+	config.TransactionPoolConfig
+	maxTransactionsPerSigner uint32
+}
+
+func (c *signerQuotaConfig) TransactionPoolPendingPoolMaxTransactionsPerSigner() uint32 {
+	return c.maxTransactionsPerSigner
+}
+
+func (h *harness) withSignerQuota(maxTransactionsPerSigner uint32) *harness {
+	h.config = &signerQuotaConfig{TransactionPoolConfig: h.config, maxTransactionsPerSigner: maxTransactionsPerSigner}
+	return h
+}
+
+type pendingPoolJournalConfig struct {
+	config.TransactionPoolConfig
+	filename string
+}
+
+func (c *pendingPoolJournalConfig) TransactionPoolPendingPoolJournalFilePath() string {
+	return c.filename
+}
+
+func (h *harness) withPendingPoolJournal(filename string) *harness {
+	h.config = &pendingPoolJournalConfig{TransactionPoolConfig: h.config, filename: filename}
+	return h
+}
+
 func (h *harness) start(ctx context.Context) *harness {
+	h.startWithoutCommittingBlocks(ctx)
+	h.fastForwardTo(ctx, 1)
+	return h
+}
+
+// lets a test commit the first blocks, such as blocks older than the node sync reject time
+func (h *harness) startWithoutCommittingBlocks(ctx context.Context) *harness {
 	service := transactionpool.NewTransactionPool(ctx, adapter.NewSystemClock(), h.gossip, h.vm, h.signer, nil, h.config, h.Logger, metric.NewRegistry())
 	service.RegisterTransactionResultsHandler(h.trh)
 	h.txpool = service
-	h.fastForwardTo(ctx, 1)
 	h.Supervise(service)
 	return h
 }
@@ -0,0 +1,60 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/with"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPendingPoolJournal_RestartRestoresPendingTransactionsWhichWereNotCommitted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pending_pool_journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "pending-pool.journal")
+
+	with.Concurrency(t, func(ctx context.Context, parent *with.ConcurrencyHarness) {
+		committedMeanwhile := builders.TransferTransaction().Build()
+		stillPending := builders.TransferTransaction().Build()
+		alreadyCommitted := builders.TransferTransaction().Build()
+
+		h := newHarness(parent).withPendingPoolJournal(filename).start(ctx)
+		h.ignoringForwardMessages()
+		_, err := h.addNewTransaction(ctx, committedMeanwhile)
+		require.NoError(t, err)
+		_, err = h.addNewTransaction(ctx, stillPending)
+		require.NoError(t, err)
+		_, err = h.addNewTransaction(ctx, alreadyCommitted)
+		require.NoError(t, err)
+		_, err = h.reportTransactionsAsCommitted(ctx, alreadyCommitted)
+		require.NoError(t, err)
+
+		restarted := newHarness(parent).withPendingPoolJournal(filename).startWithoutCommittingBlocks(ctx)
+		restarted.ignoringForwardMessages()
+		restarted.fastForwardToHeightAndTime(ctx, 1, primitives.TimestampNano(time.Now().Add(-1*time.Hour).UnixNano()))
+
+		out, err := restarted.getTransactionsForOrdering(ctx, 2, 10)
+		require.NoError(t, err)
+		require.Empty(t, out.SignedTransactions, "expected the journaled transactions to wait for the pool to catch up with the committed blocks")
+
+		_, err = restarted.reportTransactionsAsCommitted(ctx, committedMeanwhile)
+		require.NoError(t, err)
+
+		out, err = restarted.getTransactionsForOrdering(ctx, 3, 10)
+		require.NoError(t, err)
+		require.Len(t, out.SignedTransactions, 1, "expected only the transaction which was not committed to be restored")
+		require.Equal(t, stillPending.Raw(), out.SignedTransactions[0].Raw(), "expected only the transaction which was not committed to be restored")
+	})
+}
@@ -7,8 +7,10 @@
 package transactionpool
 
 import (
+	ethereumKeys "github.com/orbs-network/crypto-lib-go/crypto/ethereum/keys"
 	"github.com/orbs-network/crypto-lib-go/crypto/keys"
 	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/signers"
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
@@ -89,12 +91,18 @@ func (c *validationContext) validateTransactionVirtualChainId(transaction *proto
 
 func (c *validationContext) validateSignatureType(transaction *protocol.SignedTransaction) *ErrTransactionRejected {
 	tx := transaction.Transaction()
-	if !tx.Signer().IsSchemeEddsa() {
-		return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME, log.String("signer-scheme", "Eddsa"), log.Stringable("signer", tx.Signer())}
-	}
-
-	if len(tx.Signer().Eddsa().SignerPublicKey()) != keys.ED25519_PUBLIC_KEY_SIZE_BYTES {
-		return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH, log.Int("signature-length", keys.ED25519_PUBLIC_KEY_SIZE_BYTES), log.Int("signature-length", len(tx.Signer().Eddsa().SignerPublicKey()))}
+	signer := signers.Of(tx.Signer())
+	switch signer.Scheme() {
+	case signers.SIGNER_SCHEME_EDDSA:
+		if len(tx.Signer().Eddsa().SignerPublicKey()) != keys.ED25519_PUBLIC_KEY_SIZE_BYTES {
+			return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH, log.Int("signature-length", keys.ED25519_PUBLIC_KEY_SIZE_BYTES), log.Int("signature-length", len(tx.Signer().Eddsa().SignerPublicKey()))}
+		}
+	case signers.SIGNER_SCHEME_ECDSA_SECP256K1:
+		if len(signer.EcdsaSecp256K1().SignerPublicKey()) != ethereumKeys.ECDSA_SECP256K1_PUBLIC_KEY_SIZE_BYTES {
+			return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH, log.Int("signature-length", ethereumKeys.ECDSA_SECP256K1_PUBLIC_KEY_SIZE_BYTES), log.Int("signature-length", len(signer.EcdsaSecp256K1().SignerPublicKey()))}
+		}
+	default:
+		return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME, log.String("signer-scheme", "Eddsa or EcdsaSecp256K1"), log.Stringable("signer", tx.Signer())}
 	}
 
 	return nil
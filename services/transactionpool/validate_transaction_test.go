@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/stretchr/testify/require"
@@ -41,6 +42,14 @@ func TestValidateTransaction_Add_ValidTransaction(t *testing.T) {
 	require.Nil(t, err, "a valid transaction was rejected")
 }
 
+func TestValidateTransaction_Add_ValidEcdsaSecp256K1SignedTransaction(t *testing.T) {
+	currentTime := time.Now()
+	lastCommittedBlockTime := primitives.TimestampNano(currentTime.Add(nodeSyncRejectInterval / 2).UnixNano())
+	tx := aTransactionAtNodeTimestamp(lastCommittedBlockTime).WithEcdsaSecp256K1Signer(keys.EcdsaSecp256K1KeyPairForTests(1).EcdsaSecp256K1KeyPair).Build()
+	err := aValidationContextAsOf().ValidateAddedTransaction(tx, currentTime, lastCommittedBlockTime)
+	require.Nil(t, err, "a valid transaction signed by an ecdsa secp256k1 signer was rejected")
+}
+
 func TestValidateTransaction_Add_RejectsTransactionsWhenTimestampIsZero(t *testing.T) {
 	vctx := &validationContext{
 		expiryWindow:         expirationWindowInterval,
@@ -71,6 +80,7 @@ func TestValidateTransaction_Add_InvalidTransactions(t *testing.T) {
 		{"protocol version", aTransactionAtNodeTimestamp(lastCommittedBlockTime).WithProtocolVersion(config.MAXIMAL_CONSENSUS_BLOCK_PROTOCOL_VERSION + 1), protocol.TRANSACTION_STATUS_REJECTED_UNSUPPORTED_VERSION},
 		{"signer scheme", aTransactionAtNodeTimestamp(lastCommittedBlockTime).WithInvalidSignerScheme(), protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME},
 		{"signer public key (wrong length)", aTransactionAtNodeTimestamp(lastCommittedBlockTime).WithInvalidPublicKey(), protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH},
+		{"ecdsa secp256k1 signer public key (wrong length)", aTransactionAtNodeTimestamp(lastCommittedBlockTime).WithInvalidEcdsaSecp256K1PublicKey(), protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH},
 		{"timestamp (created prior to the expiry window)", builders.TransferTransaction().WithTimestamp(currentTime.Add(expirationWindowInterval * -2)), protocol.TRANSACTION_STATUS_REJECTED_TIMESTAMP_WINDOW_EXCEEDED},
 		{"timestamp (ahead of timestamp for last committed block)", builders.TransferTransaction().WithTimestamp(futureTimeAfterGracePeriod(lastCommittedBlockTime)), protocol.TRANSACTION_STATUS_REJECTED_TIMESTAMP_AHEAD_OF_NODE_TIME},
 		{"virtual chain id", aTransactionAtNodeTimestamp(lastCommittedBlockTime).WithVirtualChainId(primitives.VirtualChainId(1)), protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_MISMATCH},
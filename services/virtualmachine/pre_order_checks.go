@@ -8,7 +8,9 @@ package virtualmachine
 
 import (
 	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	ethereumSignature "github.com/orbs-network/crypto-lib-go/crypto/ethereum/signature"
 	"github.com/orbs-network/crypto-lib-go/crypto/signature"
+	"github.com/orbs-network/orbs-network-go/crypto/signers"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
@@ -20,13 +22,19 @@ import (
 func (s *service) verifyTransactionSignatures(signedTransactions []*protocol.SignedTransaction, resultStatuses []protocol.TransactionStatus) {
 	for i, signedTransaction := range signedTransactions {
 		// check transaction signature
-		switch signedTransaction.Transaction().Signer().Scheme() {
-		case protocol.SIGNER_SCHEME_EDDSA:
+		switch signers.Of(signedTransaction.Transaction().Signer()).Scheme() {
+		case signers.SIGNER_SCHEME_EDDSA:
 			if verifyEd25519Signer(signedTransaction) {
 				resultStatuses[i] = protocol.TRANSACTION_STATUS_PRE_ORDER_VALID
 			} else {
 				resultStatuses[i] = protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH
 			}
+		case signers.SIGNER_SCHEME_ECDSA_SECP256K1:
+			if verifyEcdsaSecp256K1Signer(signedTransaction) {
+				resultStatuses[i] = protocol.TRANSACTION_STATUS_PRE_ORDER_VALID
+			} else {
+				resultStatuses[i] = protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH
+			}
 		default:
 			resultStatuses[i] = protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME
 		}
@@ -39,6 +47,13 @@ func verifyEd25519Signer(signedTransaction *protocol.SignedTransaction) bool {
 	return signature.VerifyEd25519(signerPublicKey, txHash, signedTransaction.Signature())
 }
 
+// the transaction hash is signed directly, as with ed25519, the recovery byte of an ethereum signature is ignored
+func verifyEcdsaSecp256K1Signer(signedTransaction *protocol.SignedTransaction) bool {
+	signerPublicKey := signers.Of(signedTransaction.Transaction().Signer()).EcdsaSecp256K1().SignerPublicKey()
+	txHash := digest.CalcTxHash(signedTransaction.Transaction())
+	return ethereumSignature.VerifyEcdsaSecp256K1(signerPublicKey, txHash, signedTransaction.Signature())
+}
+
 func (s *service) verifySubscription(ctx context.Context, reference primitives.TimestampSeconds) bool {
 	res, err := s.management.GetSubscriptionStatus(ctx, &services.GetSubscriptionStatusInput{Reference: reference})
 	if err != nil {
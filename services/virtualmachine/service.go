@@ -11,6 +11,8 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/logfields"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
 	"github.com/orbs-network/orbs-network-go/services/processor/sdk"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
@@ -25,8 +27,14 @@ type ManagementConfig interface {
 	CommitteeGracePeriod() time.Duration
 }
 
+// state storage calls which are not (yet) part of the spec
+type StateStorage interface {
+	services.StateStorage
+	GetBlockInfo(ctx context.Context, input *statestorage.GetBlockInfoInput) (*statestorage.GetBlockInfoOutput, error)
+}
+
 type service struct {
-	stateStorage         services.StateStorage
+	stateStorage         StateStorage
 	processors           map[protocol.ProcessorType]services.Processor
 	crosschainConnectors map[protocol.CrosschainConnectorType]services.CrosschainConnector
 	management           services.Management
@@ -36,7 +44,7 @@ type service struct {
 	contexts *executionContextProvider
 }
 
-func NewVirtualMachine(stateStorage services.StateStorage, processors map[protocol.ProcessorType]services.Processor, crosschainConnectors map[protocol.CrosschainConnectorType]services.CrosschainConnector, management services.Management, cfg ManagementConfig, logger log.Logger) services.VirtualMachine {
+func NewVirtualMachine(stateStorage StateStorage, processors map[protocol.ProcessorType]services.Processor, crosschainConnectors map[protocol.CrosschainConnectorType]services.CrosschainConnector, management services.Management, cfg ManagementConfig, logger log.Logger) services.VirtualMachine {
 	s := &service{
 		processors:           processors,
 		crosschainConnectors: crosschainConnectors,
@@ -69,13 +77,20 @@ func (s *service) ProcessQuery(ctx context.Context, input *services.ProcessQuery
 	}
 
 	if input.BlockHeight != 0 {
-		err := errors.New("Run local method with specific block height is not yet supported")
-		return &services.ProcessQueryOutput{
-			CallResult:              protocol.EXECUTION_RESULT_ERROR_INPUT,
-			OutputArgumentArray:     protocol.ArgumentsArrayEmpty().Raw(),
-			ReferenceBlockHeight:    committedBlockHeight,
-			ReferenceBlockTimestamp: committedBlockTimestamp,
-		}, err
+		blockInfo, err := s.getHistoricalBlockInfo(ctx, input.BlockHeight, committedBlockHeight)
+		if err != nil {
+			return &services.ProcessQueryOutput{
+				CallResult:              protocol.EXECUTION_RESULT_ERROR_INPUT,
+				OutputArgumentArray:     protocol.ArgumentsArrayEmpty().Raw(),
+				ReferenceBlockHeight:    committedBlockHeight,
+				ReferenceBlockTimestamp: committedBlockTimestamp,
+			}, err
+		}
+		committedBlockHeight = blockInfo.BlockHeight
+		committedBlockTimestamp = blockInfo.BlockTimestamp
+		committeeReferenceTime = blockInfo.CurrentReferenceTime
+		committedPrevReferenceTime = blockInfo.PrevReferenceTime
+		committedBlockProposerAddress = blockInfo.BlockProposerAddress
 	}
 
 	logger.Info("running local method", log.Stringable("contract", input.SignedQuery.Query().ContractName()), log.Stringable("method", input.SignedQuery.Query().MethodName()), logfields.BlockHeight(committedBlockHeight))
@@ -96,6 +111,14 @@ func (s *service) ProcessQuery(ctx context.Context, input *services.ProcessQuery
 	}, err
 }
 
+// queries at a specific block height need the block info of that height, which state storage keeps for its history (or for every block in archive mode)
+func (s *service) getHistoricalBlockInfo(ctx context.Context, blockHeight primitives.BlockHeight, committedBlockHeight primitives.BlockHeight) (*statestorage.GetBlockInfoOutput, error) {
+	if blockHeight > committedBlockHeight {
+		return nil, errors.Errorf("Run local method with block height %d which is not committed yet, last committed block height is %d", blockHeight, committedBlockHeight)
+	}
+	return s.stateStorage.GetBlockInfo(ctx, &statestorage.GetBlockInfoInput{BlockHeight: blockHeight})
+}
+
 func (s *service) ProcessTransactionSet(ctx context.Context, input *services.ProcessTransactionSetInput) (*services.ProcessTransactionSetOutput, error) {
 	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
 
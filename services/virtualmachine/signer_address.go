@@ -8,6 +8,7 @@ package virtualmachine
 
 import (
 	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/crypto/signers"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/pkg/errors"
@@ -19,9 +20,11 @@ func (s *service) getSignerAddress(signer *protocol.Signer) (primitives.ClientAd
 	if len(signer.Raw()) == 0 {
 		return EmptySignerAddress, nil
 	}
-	switch signer.Scheme() {
-	case protocol.SIGNER_SCHEME_EDDSA:
+	switch signers.Of(signer).Scheme() {
+	case signers.SIGNER_SCHEME_EDDSA:
 		return digest.CalcClientAddressOfEd25519Signer(signer)
+	case signers.SIGNER_SCHEME_ECDSA_SECP256K1:
+		return signers.CalcClientAddressOfEcdsaSecp256K1Signer(signers.Of(signer))
 	default:
 		return nil, errors.New("transaction is not signed by any Signer")
 	}
@@ -0,0 +1,43 @@
+// Copyright 2019 the orbs-network-go authors
+// This file is part of the orbs-network-go library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package virtualmachine
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSignerAddress_OfEd25519SignerIsDerivedFromItsPublicKey(t *testing.T) {
+	keyPair := keys.Ed25519KeyPairForTests(1)
+	tx := builders.Transaction().WithEd25519Signer(keyPair).Build()
+
+	address, err := (&service{}).getSignerAddress(tx.Transaction().Signer())
+	require.NoError(t, err)
+
+	expected, _ := digest.CalcClientAddressOfEd25519PublicKey(keyPair.PublicKey())
+	require.EqualValues(t, expected, address)
+}
+
+func TestSignerAddress_OfEcdsaSecp256K1SignerIsItsEthereumAddress(t *testing.T) {
+	keyPair := keys.EcdsaSecp256K1KeyPairForTests(1)
+	tx := builders.Transaction().WithEcdsaSecp256K1Signer(keyPair.EcdsaSecp256K1KeyPair).Build()
+
+	address, err := (&service{}).getSignerAddress(tx.Transaction().Signer())
+	require.NoError(t, err)
+	require.Len(t, address, digest.CLIENT_ADDRESS_SIZE_BYTES)
+	require.EqualValues(t, keyPair.NodeAddress(), address, "expected the last 20 bytes of the keccak256 of the public key")
+}
+
+func TestSignerAddress_OfEcdsaSecp256K1SignerWithInvalidPublicKeyFails(t *testing.T) {
+	tx := builders.Transaction().WithInvalidEcdsaSecp256K1PublicKey().Build()
+
+	_, err := (&service{}).getSignerAddress(tx.Transaction().Signer())
+	require.Error(t, err)
+}
@@ -11,6 +11,12 @@ import (
 	"fmt"
 	"github.com/orbs-network/crypto-lib-go/crypto/hash"
 	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-network-go/services/statestorage/adapter/memory"
+	stateStorageTest "github.com/orbs-network/orbs-network-go/services/statestorage/test"
 	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
@@ -33,9 +39,48 @@ type harness struct {
 	service              services.VirtualMachine
 }
 
+type historicalStateStorageMock struct {
+	*services.MockStateStorage
+}
+
+func (m *historicalStateStorageMock) GetBlockInfo(ctx context.Context, input *statestorage.GetBlockInfoInput) (*statestorage.GetBlockInfoOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*statestorage.GetBlockInfoOutput), ret.Error(1)
+	}
+	return nil, ret.Error(1)
+}
+
 func newHarness(logger log.Logger) *harness {
+	h, _ := newHarnessWithHistoricalStateStorage(logger)
+	return h
+}
+
+func newHarnessWithHistoricalStateStorage(logger log.Logger) (*harness, *historicalStateStorageMock) {
+	stateStorage := &historicalStateStorageMock{&services.MockStateStorage{}}
+	return newHarnessWithStateStorage(logger, stateStorage, stateStorage.MockStateStorage), stateStorage
+}
+
+// newHarnessWithCommittedBlocks runs the virtual machine on an in memory state storage which committed blocks 1 to lastHeight,
+// block h having timestamp h*1000
+func newHarnessWithCommittedBlocks(ctx context.Context, logger log.Logger, cfg config.StateStorageConfig, lastHeight int) (*harness, error) {
+	registry := metric.NewRegistry()
+	var archivePersistence adapter.ArchivePersistence
+	if cfg.StateStorageArchiveMode() {
+		archivePersistence = memory.NewArchivePersistence()
+	}
+	stateStorage := statestorage.NewStateStorage(cfg, memory.NewStatePersistence(registry), archivePersistence, nil, nil, logger, registry)
+	for h := 1; h <= lastHeight; h++ {
+		diff := builders.ContractStateDiff().WithContractName("Contract1").WithStringRecord("height", fmt.Sprintf("%d", h)).Build()
+		if _, err := stateStorage.CommitStateDiff(ctx, stateStorageTest.CommitStateDiff().WithBlockHeight(h).WithBlockTimestamp(h*1000).WithDiff(diff).Build()); err != nil {
+			return nil, err
+		}
+	}
+	return newHarnessWithStateStorage(logger, stateStorage, &services.MockStateStorage{}), nil
+}
+
+func newHarnessWithStateStorage(logger log.Logger, stateStorageService virtualmachine.StateStorage, stateStorage *services.MockStateStorage) *harness {
 	blockStorage := &services.MockBlockStorage{}
-	stateStorage := &services.MockStateStorage{}
 
 	processors := make(map[protocol.ProcessorType]*services.MockProcessor)
 	processors[protocol.PROCESSOR_TYPE_NATIVE] = &services.MockProcessor{}
@@ -70,7 +115,7 @@ func newHarness(logger log.Logger) *harness {
 			CurrentSize:          10,
 		}, nil)
 
-	service := virtualmachine.NewVirtualMachine(stateStorage, processorsForService, crosschainConnectorsForService, management, cfg, logger)
+	service := virtualmachine.NewVirtualMachine(stateStorageService, processorsForService, crosschainConnectorsForService, management, cfg, logger)
 
 	return &harness{
 		blockStorage:         blockStorage,
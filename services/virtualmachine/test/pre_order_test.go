@@ -41,6 +41,21 @@ func TestPreOrder_DifferentSignerSchemes(t *testing.T) {
 			tx:     builders.Transaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(1)).Build(),
 			status: protocol.TRANSACTION_STATUS_PRE_ORDER_VALID,
 		},
+		{
+			name:   "InvalidEcdsaSecp256K1Signature",
+			tx:     withCorruptSignature(builders.Transaction().WithEcdsaSecp256K1Signer(keys.EcdsaSecp256K1KeyPairForTests(1).EcdsaSecp256K1KeyPair).Build()),
+			status: protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH,
+		},
+		{
+			name:   "MismatchedEcdsaSecp256K1Signature",
+			tx:     builders.Transaction().WithInvalidEcdsaSecp256K1Signer(keys.EcdsaSecp256K1KeyPairForTests(1).EcdsaSecp256K1KeyPair).Build(),
+			status: protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH,
+		},
+		{
+			name:   "ValidEcdsaSecp256K1Signature",
+			tx:     builders.Transaction().WithEcdsaSecp256K1Signer(keys.EcdsaSecp256K1KeyPairForTests(1).EcdsaSecp256K1KeyPair).Build(),
+			status: protocol.TRANSACTION_STATUS_PRE_ORDER_VALID,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -59,6 +74,14 @@ func TestPreOrder_DifferentSignerSchemes(t *testing.T) {
 	}
 }
 
+func withCorruptSignature(tx *protocol.SignedTransaction) *protocol.SignedTransaction {
+	corruptSignature := make([]byte, len(tx.Signature()))
+	copy(corruptSignature, tx.Signature())
+	corruptSignature[0] ^= 0xff
+	tx.MutateSignature(corruptSignature)
+	return tx
+}
+
 func TestPreOrder_SubscriptionNotApproved(t *testing.T) {
 	with.Context(func(ctx context.Context) {
 		with.Logging(t, func(parent *with.LoggingHarness) {
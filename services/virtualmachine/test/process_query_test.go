@@ -8,7 +8,10 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/with"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -97,29 +100,126 @@ func TestProcessQuery_UnexpectedError(t *testing.T) {
 	})
 }
 
-func TestProcessQuery_WithSpecificBlockHeight(t *testing.T) {
+func TestProcessQuery_WithBlockHeightAboveLastCommittedBlockFails(t *testing.T) {
 	with.Context(func(ctx context.Context) {
 		with.Logging(t, func(parent *with.LoggingHarness) {
 
 			h := newHarness(parent.Logger)
 
 			h.expectStateStorageLastCommittedBlockInfoBlockHeightRequested(12)
-			output, err := h.service.ProcessQuery(ctx, &services.ProcessQueryInput{
-				BlockHeight: 123,
-				SignedQuery: (&protocol.SignedQueryBuilder{
-					Query: &protocol.QueryBuilder{
-						Signer:             nil,
-						ContractName:       "SomeContract",
-						MethodName:         "SomeMethod",
-						InputArgumentArray: []byte{},
-					},
-				}).Build(),
-			})
+			output, err := h.service.ProcessQuery(ctx, queryAtHeight(123, "SomeContract", "SomeMethod"))
 
-			require.Error(t, err, "Run local method with specific block height is not yet supported")
+			require.Error(t, err, "query at a block height above the last committed block should fail")
 			require.EqualValues(t, protocol.EXECUTION_RESULT_ERROR_INPUT, output.CallResult)
 			require.EqualValues(t, 12, output.ReferenceBlockHeight)
 			h.verifyStateStorageBlockHeightRequested(t)
 		})
 	})
 }
+
+func TestProcessQuery_WithBlockHeightInRevisionWindow(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+
+			h, err := newHarnessWithCommittedBlocks(ctx, parent.Logger, config.ForStateStorageTest(5, 0, 0), 12)
+			require.NoError(t, err)
+			h.expectSystemContractCalled(deployments_systemcontract.CONTRACT_NAME, deployments_systemcontract.METHOD_GET_INFO, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+			h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.ArgumentArray) (protocol.ExecutionResult, *protocol.ArgumentArray, error) {
+				return protocol.EXECUTION_RESULT_SUCCESS, builders.ArgumentsArray(), nil
+			})
+
+			output, err := h.service.ProcessQuery(ctx, queryAtHeight(10, "Contract1", "method1"))
+
+			require.NoError(t, err, "query at a block height in the revision window should not fail")
+			require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult)
+			require.EqualValues(t, 10, output.ReferenceBlockHeight)
+			require.EqualValues(t, 10000, output.ReferenceBlockTimestamp)
+			h.verifyNativeContractMethodCalled(t)
+		})
+	})
+}
+
+func TestProcessQuery_WithBlockHeightBeyondRevisionWindow(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+
+			h, err := newHarnessWithCommittedBlocks(ctx, parent.Logger, config.ForStateStorageTest(5, 0, 0), 12)
+			require.NoError(t, err)
+
+			output, err := h.service.ProcessQuery(ctx, queryAtHeight(3, "Contract1", "method1"))
+
+			require.Error(t, err, "query at a block height beyond the revision window should fail without archive mode")
+			require.EqualValues(t, protocol.EXECUTION_RESULT_ERROR_INPUT, output.CallResult)
+			require.EqualValues(t, 12, output.ReferenceBlockHeight)
+		})
+	})
+}
+
+func TestProcessQuery_WithArchivedBlockHeight(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+
+			h, err := newHarnessWithCommittedBlocks(ctx, parent.Logger, config.ForStateStorageArchiveTest(5), 12)
+			require.NoError(t, err)
+			h.expectSystemContractCalled(deployments_systemcontract.CONTRACT_NAME, deployments_systemcontract.METHOD_GET_INFO, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+			h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.ArgumentArray) (protocol.ExecutionResult, *protocol.ArgumentArray, error) {
+				return protocol.EXECUTION_RESULT_SUCCESS, builders.ArgumentsArray(), nil
+			})
+
+			output, err := h.service.ProcessQuery(ctx, queryAtHeight(3, "Contract1", "method1"))
+
+			require.NoError(t, err, "query at an archived block height should not fail in archive mode")
+			require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult)
+			require.EqualValues(t, 3, output.ReferenceBlockHeight)
+			require.EqualValues(t, 3000, output.ReferenceBlockTimestamp)
+			h.verifyNativeContractMethodCalled(t)
+		})
+	})
+}
+
+func TestProcessQuery_WithHistoricalBlockHeight(t *testing.T) {
+	with.Context(func(ctx context.Context) {
+		with.Logging(t, func(parent *with.LoggingHarness) {
+
+			h, stateStorage := newHarnessWithHistoricalStateStorage(parent.Logger)
+			h.expectSystemContractCalled(deployments_systemcontract.CONTRACT_NAME, deployments_systemcontract.METHOD_GET_INFO, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+			h.expectStateStorageLastCommittedBlockInfoBlockHeightRequested(12)
+			stateStorage.When("GetBlockInfo", mock.Any, &statestorage.GetBlockInfoInput{BlockHeight: 7}).Return(&statestorage.GetBlockInfoOutput{
+				BlockHeight:          7,
+				BlockTimestamp:       777,
+				CurrentReferenceTime: 3000,
+				PrevReferenceTime:    2000,
+			}, nil).Times(1)
+			h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.ArgumentArray) (protocol.ExecutionResult, *protocol.ArgumentArray, error) {
+				return protocol.EXECUTION_RESULT_SUCCESS, builders.ArgumentsArray(), nil
+			})
+
+			output, err := h.service.ProcessQuery(ctx, queryAtHeight(7, "Contract1", "method1"))
+
+			require.NoError(t, err, "process query at a historical height should not fail")
+			require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult)
+			require.EqualValues(t, 7, output.ReferenceBlockHeight)
+			require.EqualValues(t, 777, output.ReferenceBlockTimestamp)
+
+			ok, err := stateStorage.Verify()
+			require.True(t, ok, "state storage mock called incorrectly")
+			require.NoError(t, err)
+			h.verifySystemContractCalled(t)
+			h.verifyNativeContractMethodCalled(t)
+		})
+	})
+}
+
+func queryAtHeight(blockHeight primitives.BlockHeight, contractName primitives.ContractName, methodName primitives.MethodName) *services.ProcessQueryInput {
+	return &services.ProcessQueryInput{
+		BlockHeight: blockHeight,
+		SignedQuery: (&protocol.SignedQueryBuilder{
+			Query: &protocol.QueryBuilder{
+				ContractName:       contractName,
+				MethodName:         methodName,
+				InputArgumentArray: []byte{},
+			},
+		}).Build(),
+	}
+}
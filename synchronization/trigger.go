@@ -24,11 +24,11 @@ type Ticker interface {
 }
 
 type timeTicker struct {
-	time.Ticker
+	*time.Ticker // not copied, the runtime tracks the timer by its address
 }
 
 func NewTimeTicker(d time.Duration) Ticker {
-	return &timeTicker{*time.NewTicker(d)}
+	return &timeTicker{time.NewTicker(d)}
 }
 
 func (t *timeTicker) C() <-chan time.Time {
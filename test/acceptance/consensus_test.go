@@ -10,6 +10,7 @@ import (
 	"context"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/internodesync"
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter/testkit"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/scribe/log"
@@ -55,3 +56,40 @@ func TestBenchmarkConsensus_LeaderGetsVotesBeforeNextBlock(t *testing.T) {
 			blockSyncTamper.StopTampering(ctx)
 		})
 }
+
+func TestBenchmarkConsensus_LeaderDropsVotesWithSpoofedSender(t *testing.T) {
+	NewHarness().
+		WithLogFilters(log.ExcludeField(internodesync.LogTag), log.ExcludeEntryPoint("BlockSync")).
+		WithConsensusAlgos(consensus.CONSENSUS_ALGO_TYPE_BENCHMARK_CONSENSUS). // override default consensus algo
+		WithMaxTxPerBlock(1).
+		Start(t, func(t testing.TB, parent context.Context, network *Network) {
+			ctx, cancel := context.WithTimeout(parent, 1*time.Second)
+			defer cancel()
+
+			contract := network.DeployBenchmarkTokenContract(ctx, 5)
+
+			// validators claim their votes were sent by the leader
+			spoofTamper := network.TransportTamperer().Spoof(testkit.BenchmarkConsensusMessage(consensus.BENCHMARK_CONSENSUS_COMMITTED), keys.EcdsaSecp256K1KeyPairForTests(0).NodeAddress())
+			blockSyncTamper := network.TransportTamperer().Fail(testkit.BlockSyncMessage(gossipmessages.BLOCK_SYNC_AVAILABILITY_REQUEST)) // block sync discovery message so it does not add the blocks in a 'back door'
+			committedLatch := network.TransportTamperer().LatchOn(testkit.BenchmarkConsensusMessage(consensus.BENCHMARK_CONSENSUS_COMMITTED))
+
+			heightBeforeSpoofing, err := network.BlockPersistence(0).GetLastBlockHeight()
+			require.NoError(t, err)
+
+			txHash := contract.TransferInBackground(ctx, 0, 17, 5, 6)
+			committedLatch.Wait() // wait for validators to acknowledge the next block (with a spoofed sender)
+			committedLatch.Wait() // and for another consensus round which the leader cannot close without valid votes
+
+			heightWhileSpoofing, err := network.BlockPersistence(0).GetLastBlockHeight()
+			require.NoError(t, err)
+			require.True(t, heightWhileSpoofing <= heightBeforeSpoofing+1, "expected the leader not to progress on votes with a spoofed sender")
+
+			committedLatch.Remove()
+			spoofTamper.StopTampering(ctx)
+
+			network.WaitForTransactionInNodeState(ctx, txHash, 0)
+			require.EqualValues(t, 17, contract.GetBalance(ctx, 0, 6), "eventual getBalance result on leader")
+
+			blockSyncTamper.StopTampering(ctx)
+		})
+}
@@ -8,9 +8,12 @@ package builders
 
 import (
 	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	ethereumKeys "github.com/orbs-network/crypto-lib-go/crypto/ethereum/keys"
+	ethereumSignature "github.com/orbs-network/crypto-lib-go/crypto/ethereum/signature"
 	"github.com/orbs-network/crypto-lib-go/crypto/keys"
 	"github.com/orbs-network/crypto-lib-go/crypto/signature"
 	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/signers"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/Triggers"
 	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -23,9 +26,10 @@ import (
 
 // do not create this struct directly although it's exported
 type TransactionBuilder struct {
-	signer   primitives.Ed25519PrivateKey
-	dontSign bool // special case for nil signer (trigger) will be set to true
-	builder  *protocol.SignedTransactionBuilder
+	signer               primitives.Ed25519PrivateKey
+	ecdsaSecp256K1Signer primitives.EcdsaSecp256K1PrivateKey // signs instead of signer when set
+	dontSign             bool                                // special case for nil signer (trigger) will be set to true
+	builder              *protocol.SignedTransactionBuilder
 }
 
 func TransferTransaction() *TransactionBuilder {
@@ -58,6 +62,9 @@ func Transaction() *TransactionBuilder {
 }
 
 func (t *TransactionBuilder) Build() *protocol.SignedTransaction {
+	if t.ecdsaSecp256K1Signer != nil {
+		return t.buildSignedByEcdsaSecp256K1()
+	}
 	if !t.dontSign {
 		t.builder.Signature = make([]byte, signature.ED25519_SIGNATURE_SIZE_BYTES)
 	}
@@ -73,6 +80,18 @@ func (t *TransactionBuilder) Build() *protocol.SignedTransaction {
 	return signedTransaction
 }
 
+func (t *TransactionBuilder) buildSignedByEcdsaSecp256K1() *protocol.SignedTransaction {
+	t.builder.Signature = make([]byte, ethereumSignature.ECDSA_SECP256K1_SIGNATURE_SIZE_BYTES)
+	signedTransaction := t.builder.Build()
+	txHash := digest.CalcTxHash(signedTransaction.Transaction())
+	sig, err := ethereumSignature.SignEcdsaSecp256K1(t.ecdsaSecp256K1Signer, txHash)
+	if err != nil {
+		panic(err)
+	}
+	signedTransaction.MutateSignature(sig)
+	return signedTransaction
+}
+
 func (t *TransactionBuilder) Builder() *protocol.SignedTransactionBuilder {
 	signedTransaction := t.Build()
 	t.builder.Signature = signedTransaction.Signature()
@@ -85,6 +104,35 @@ func (t *TransactionBuilder) WithEd25519Signer(keyPair *keys.Ed25519KeyPair) *Tr
 	return t
 }
 
+func (t *TransactionBuilder) WithEcdsaSecp256K1Signer(keyPair *ethereumKeys.EcdsaSecp256K1KeyPair) *TransactionBuilder {
+	return t.withEcdsaSecp256K1SignerPublicKey(keyPair.PublicKey(), keyPair.PrivateKey())
+}
+
+func (t *TransactionBuilder) WithInvalidEcdsaSecp256K1Signer(keyPair *ethereumKeys.EcdsaSecp256K1KeyPair) *TransactionBuilder {
+	corruptPrivateKey := make([]byte, len(keyPair.PrivateKey()))
+	corruptPrivateKey[len(corruptPrivateKey)-1] = 1 // a zero private key cannot sign at all
+	return t.withEcdsaSecp256K1SignerPublicKey(keyPair.PublicKey(), corruptPrivateKey)
+}
+
+func (t *TransactionBuilder) WithInvalidEcdsaSecp256K1PublicKey() *TransactionBuilder {
+	keyPair := testKeys.EcdsaSecp256K1KeyPairForTests(1)
+	return t.withEcdsaSecp256K1SignerPublicKey(keyPair.PublicKey()[1:], keyPair.PrivateKey())
+}
+
+// the spec signer has no ecdsa secp256k1 scheme, so the signer is built by the signers extension and passed as raw
+func (t *TransactionBuilder) withEcdsaSecp256K1SignerPublicKey(publicKey primitives.EcdsaSecp256K1PublicKey, privateKey primitives.EcdsaSecp256K1PrivateKey) *TransactionBuilder {
+	signer := (&signers.SignerBuilder{
+		Scheme: signers.SIGNER_SCHEME_ECDSA_SECP256K1,
+		EcdsaSecp256K1: &signers.EcdsaSecp256K1SignerBuilder{
+			NetworkType:     uint16(protocol.NETWORK_TYPE_TEST_NET),
+			SignerPublicKey: publicKey,
+		},
+	}).Build()
+	t.builder.Transaction.Signer = protocol.SignerBuilderFromRaw(signer.Raw())
+	t.ecdsaSecp256K1Signer = privateKey
+	return t
+}
+
 func (t *TransactionBuilder) WithTimestamp(timestamp time.Time) *TransactionBuilder {
 	t.builder.Transaction.Timestamp = primitives.TimestampNano(timestamp.UnixNano())
 	return t
@@ -46,7 +46,7 @@ func newVmHarness(logger log.Logger) *harness {
 
 	ssCfg := config.ForStateStorageTest(10, 5, 5000)
 	ssPersistence := stateAdapter.NewStatePersistence(registry)
-	stateStorage := statestorage.NewStateStorage(ssCfg, ssPersistence, nil, logger, registry)
+	stateStorage := statestorage.NewStateStorage(ssCfg, ssPersistence, nil, nil, nil, logger, registry)
 
 	management := &services.MockManagement{}
 	management.When("GetCommittee", mock.Any, mock.Any).Return(&services.GetCommitteeOutput{Members: testKeys.NodeAddressesForTests()[:5]}, nil)